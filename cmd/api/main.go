@@ -4,8 +4,14 @@ import (
 	"log"
 	"my-go-api/internal/config"
 	"my-go-api/internal/routes"
+	"my-go-api/internal/utils"
 	"my-go-api/internal/validation"
 	"my-go-api/pkg/database"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
 func main() {
@@ -27,10 +33,46 @@ func main() {
 	defer rdb.Close()
 	defer db.Close()
 
-	validate := validation.Init()
-	router := routes.RegisterRoutes(db, rdb, validate, cfg)
+	keyring, err := utils.LoadKeyring(cfg.JWT.KeysDir, cfg.JWT.SigningKeyId)
+	if err != nil {
+		log.Fatalf("Could not load jwt keys: %v", err)
+	}
+	go reloadKeysOnSIGHUP(keyring)
+
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+	})
+	if err != nil {
+		log.Fatalf("Could not configure webauthn: %v", err)
+	}
+
+	var breached *validation.BreachedPasswords
+	if cfg.Password.BreachedDir != "" {
+		breached, err = validation.OpenBreachedPasswords(cfg.Password.BreachedDir, cfg.Password.BreachedThreshold)
+		if err != nil {
+			log.Fatalf("Could not load breached passwords: %v", err)
+		}
+	}
+	validate := validation.Init(breached)
+	router := routes.RegisterRoutes(db, rdb, validate, cfg, keyring, relyingParty)
 
 	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("Could not start server: %v", err)
 	}
 }
+
+// reloadKeysOnSIGHUP picks up added or removed jwt verification keys without a
+// restart. Switching JWT_SIGNING_KEY_ID still needs one.
+func reloadKeysOnSIGHUP(keyring *utils.Keyring) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := keyring.Reload(); err != nil {
+			log.Printf("Could not reload jwt keys: %v", err)
+			continue
+		}
+		log.Println("Reloaded jwt keys")
+	}
+}
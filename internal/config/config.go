@@ -1,19 +1,31 @@
 package config
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DB           DbConfig
-	RDB          RedisConfig
-	Port         string
-	JWtSecretKey string
-	GoogleOAuth2 GoogleOAuth2Config
-	AppUri       string
+	DB             DbConfig
+	RDB            RedisConfig
+	Port           string
+	JWT            JWTConfig
+	GoogleOAuth2   GoogleOAuth2Config
+	GoogleSignIn   GoogleSignInConfig
+	AppUri         string
+	Auth           AuthConfig
+	OAuthProviders map[string]OAuthProviderConfig
+	WebAuthn       WebAuthnConfig
+	RateLimit      RateLimitConfig
+	Policy         PolicyConfig
+	Password       PasswordConfig
 }
 
 type RedisConfig struct {
@@ -36,6 +48,143 @@ type GoogleOAuth2Config struct {
 	RefreshToken string
 }
 
+// JWTConfig points at the PEM keys access tokens are signed and verified with
+// and sets the registered claims they carry.
+type JWTConfig struct {
+	KeysDir        string
+	SigningKeyId   string
+	Issuer         string
+	Audience       string
+	AccessTokenTTL time.Duration
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// WebAuthnConfig identifies this service as a WebAuthn relying party. RPID is the
+// domain passkeys are bound to and RPOrigins the origins allowed to use them.
+type WebAuthnConfig struct {
+	RPID          string
+	RPDisplayName string
+	RPOrigins     []string
+	ChallengeTTL  time.Duration
+}
+
+// RateLimitConfig selects where requests are counted, "redis" or "memory", and
+// holds the named policies routes are limited by.
+type RateLimitConfig struct {
+	Store    string
+	Policies map[string]RateLimitPolicy
+}
+
+// RateLimitPolicy allows Limit requests per Window, counted with Algorithm
+// ("token_bucket" or "sliding_window") for each client as told apart by KeyBy
+// ("ip", "user" or "api_key").
+type RateLimitPolicy struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+	KeyBy     string
+}
+
+// defaultRateLimitPolicies are used for policies the environment does not configure.
+var defaultRateLimitPolicies = map[string]RateLimitPolicy{
+	"global": {Algorithm: "token_bucket", Limit: 120, Window: time.Minute, KeyBy: "ip"},
+	"auth":   {Algorithm: "sliding_window", Limit: 10, Window: time.Minute, KeyBy: "ip"},
+	"user":   {Algorithm: "token_bucket", Limit: 120, Window: time.Minute, KeyBy: "user"},
+}
+
+// preAuthRateLimitPolicies are enforced before RequireAuth, when the user is not
+// known yet, so they cannot be keyed by user.
+var preAuthRateLimitPolicies = []string{"global", "auth"}
+
+// PolicyConfig selects where access policies are loaded from, "file" for the
+// YAML file at File or "database" for the policies table, and how often they
+// are reloaded, never when ReloadInterval is 0.
+type PolicyConfig struct {
+	Source         string
+	File           string
+	ReloadInterval time.Duration
+}
+
+// PasswordConfig sets the argon2id cost passwords are hashed with, Argon2Memory
+// in KiB, and the pepper mixed into them. The pepper is kept out of the database
+// so a leaked users table alone is not enough to guess passwords.
+type PasswordConfig struct {
+	Argon2Memory      uint32
+	Argon2Time        uint32
+	Argon2Parallelism uint8
+	Pepper            string
+	// BreachedDir holds the Pwned Passwords range files new passwords are
+	// checked against, none when empty. Passwords seen BreachedThreshold times
+	// or more are refused.
+	BreachedDir       string
+	BreachedThreshold int
+}
+
+type GoogleSignInConfig struct {
+	ClientIds  []string
+	JWKSSource string
+}
+
+// OAuthProviderConfig describes an OpenID Connect provider used for social login.
+// When only Issuer is set the endpoints are taken from its discovery document.
+type OAuthProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	AuthURL      string
+	TokenURL     string
+	JWKSURL      string
+	RedirectURL  string
+	Scopes       []string
+	// TrustEmail lets a first login with the provider sign in to the local
+	// account with the same email. Only set it for providers that own or
+	// reliably verify the addresses they assert.
+	TrustEmail bool
+}
+
+type AuthConfig struct {
+	RequireEmailVerification        bool
+	EmailVerificationTTL            time.Duration
+	EmailVerificationResendInterval time.Duration
+	PasswordResetTTL                time.Duration
+	PasswordResetRequestInterval    time.Duration
+	OAuthStateTTL                   time.Duration
+	// SessionStore selects where refresh token sessions live, "postgres" or "redis".
+	SessionStore    string
+	RefreshTokenTTL time.Duration
+	// DenylistCacheTTL is how long an instance trusts that an access token was not revoked.
+	DenylistCacheTTL time.Duration
+	// MfaIssuer is the account label authenticator apps show next to the code.
+	MfaIssuer    string
+	MfaTicketTTL time.Duration
+	// MagicLinkTTL is how long an emailed login link stays usable.
+	MagicLinkTTL             time.Duration
+	MagicLinkRequestInterval time.Duration
+	// LoginFailureWindow is how long failed password logins are counted for.
+	LoginFailureWindow time.Duration
+	// LoginLockoutThreshold failures within the window lock the account for
+	// LoginLockoutDuration; fewer failures only slow further attempts down, up
+	// to LoginMaxBackoff between attempts.
+	LoginLockoutThreshold int
+	LoginLockoutDuration  time.Duration
+	LoginMaxBackoff       time.Duration
+	// OAuthCodeTTL is how long a client has to redeem an authorization code of
+	// our own authorization server.
+	OAuthCodeTTL time.Duration
+	// OAuthConsentUri is the page of our frontend where users consent to the
+	// authorization requests of clients, published as the authorization
+	// endpoint of our OpenID provider.
+	OAuthConsentUri string
+	// InternalAPIKeys authenticate our own services at the token introspection
+	// and revocation endpoints.
+	InternalAPIKeys []string
+	// ImpersonationTTL is how long the access token of an admin impersonating a
+	// user lasts, it cannot be refreshed.
+	ImpersonationTTL time.Duration
+}
+
 func LoadEnv() (*Config, error) {
 	env := os.Getenv("GO_ENV")
 	envFile := ".env.prod"
@@ -57,6 +206,136 @@ func LoadEnv() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	vRequireEmailVerification, err := getEnvBool("REQUIRE_EMAIL_VERIFICATION", true)
+	if err != nil {
+		return nil, err
+	}
+	vEmailVerificationTTL, err := getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	vEmailVerificationResendInterval, err := getEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vPasswordResetTTL, err := getEnvDuration("PASSWORD_RESET_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	vPasswordResetRequestInterval, err := getEnvDuration("PASSWORD_RESET_REQUEST_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vOAuthStateTTL, err := getEnvDuration("OAUTH_STATE_TTL", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vSessionStore := getEnvString("SESSION_STORE", "postgres")
+	if vSessionStore != "postgres" && vSessionStore != "redis" {
+		return nil, fmt.Errorf("unsupported SESSION_STORE %q, expected postgres or redis", vSessionStore)
+	}
+	vRefreshTokenTTL, err := getEnvDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	vDenylistCacheTTL, err := getEnvDuration("JTI_DENYLIST_CACHE_TTL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	vMfaTicketTTL, err := getEnvDuration("MFA_TICKET_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vMagicLinkTTL, err := getEnvDuration("MAGIC_LINK_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vMagicLinkRequestInterval, err := getEnvDuration("MAGIC_LINK_REQUEST_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vLoginFailureWindow, err := getEnvDuration("LOGIN_FAILURE_WINDOW", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	vLoginLockoutThreshold, err := getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10)
+	if err != nil {
+		return nil, err
+	}
+	vLoginLockoutDuration, err := getEnvDuration("LOGIN_LOCKOUT_DURATION", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vLoginMaxBackoff, err := getEnvDuration("LOGIN_MAX_BACKOFF", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vOAuthCodeTTL, err := getEnvDuration("OAUTH_CODE_TTL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vImpersonationTTL, err := getEnvDuration("IMPERSONATION_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vWebAuthnChallengeTTL, err := getEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	vRateLimitStore := getEnvString("RATE_LIMIT_STORE", "redis")
+	if vRateLimitStore != "redis" && vRateLimitStore != "memory" {
+		return nil, fmt.Errorf("unsupported RATE_LIMIT_STORE %q, expected redis or memory", vRateLimitStore)
+	}
+	vRateLimitPolicies, err := loadRateLimitPolicies()
+	if err != nil {
+		return nil, err
+	}
+	vPolicySource := getEnvString("POLICY_SOURCE", "file")
+	if vPolicySource != "file" && vPolicySource != "database" {
+		return nil, fmt.Errorf("unsupported POLICY_SOURCE %q, expected file or database", vPolicySource)
+	}
+	vPolicyReloadInterval, err := getEnvDuration("POLICY_RELOAD_INTERVAL", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	vArgon2Memory, err := getEnvInt("PASSWORD_ARGON2_MEMORY", 64*1024)
+	if err != nil {
+		return nil, err
+	}
+	vArgon2Time, err := getEnvInt("PASSWORD_ARGON2_TIME", 3)
+	if err != nil {
+		return nil, err
+	}
+	vArgon2Parallelism, err := getEnvInt("PASSWORD_ARGON2_PARALLELISM", 2)
+	if err != nil {
+		return nil, err
+	}
+	if vArgon2Memory < 8*vArgon2Parallelism || vArgon2Time < 1 || vArgon2Parallelism < 1 || vArgon2Parallelism > 255 {
+		return nil, fmt.Errorf("PASSWORD_ARGON2_TIME must be at least 1, PASSWORD_ARGON2_PARALLELISM between 1 and 255 and PASSWORD_ARGON2_MEMORY at least 8 KiB per lane")
+	}
+	vPasswordPepper, err := loadPasswordPepper()
+	if err != nil {
+		return nil, err
+	}
+	vBreachedPasswordThreshold, err := getEnvInt("BREACHED_PASSWORD_THRESHOLD", 1)
+	if err != nil {
+		return nil, err
+	}
+	if vBreachedPasswordThreshold < 1 {
+		return nil, fmt.Errorf("BREACHED_PASSWORD_THRESHOLD must be at least 1")
+	}
+	vOAuthProviders, err := loadOAuthProviders(os.Getenv("APP_URI"))
+	if err != nil {
+		return nil, err
+	}
+	vAccessTokenTTL, err := getEnvDuration("ACCESS_TOKEN_TTL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	vJWTLeeway, err := getEnvDuration("JWT_LEEWAY", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
 	cfg := &Config{
 		DB: DbConfig{
 			DbUrl:        os.Getenv("DB_URL"),
@@ -69,15 +348,220 @@ func LoadEnv() (*Config, error) {
 			Password: os.Getenv("REDIS_PWD"),
 			DB:       vRedisDb,
 		},
-		AppUri:       os.Getenv("APP_URI"),
-		Port:         os.Getenv("PORT"),
-		JWtSecretKey: os.Getenv("SECRET_KEY"),
+		AppUri: os.Getenv("APP_URI"),
+		Port:   os.Getenv("PORT"),
+		JWT: JWTConfig{
+			KeysDir:        getEnvString("JWT_KEYS_DIR", "keys"),
+			SigningKeyId:   os.Getenv("JWT_SIGNING_KEY_ID"),
+			Issuer:         getEnvString("JWT_ISSUER", "my-go-api"),
+			Audience:       getEnvString("JWT_AUDIENCE", "my-go-api"),
+			AccessTokenTTL: vAccessTokenTTL,
+			Leeway:         vJWTLeeway,
+		},
 		GoogleOAuth2: GoogleOAuth2Config{
 			ProjectId:    os.Getenv("GOOGLE_PROJECT_ID"),
 			ClientId:     os.Getenv("GOOGLE_CLIENT_ID"),
 			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
 			RefreshToken: os.Getenv("GOOGLE_REFRESH_TOKEN"),
 		},
+		GoogleSignIn: GoogleSignInConfig{
+			ClientIds:  getEnvList("GOOGLE_SIGNIN_CLIENT_IDS", []string{os.Getenv("GOOGLE_CLIENT_ID")}),
+			JWKSSource: getEnvString("GOOGLE_JWKS_SOURCE", "https://www.googleapis.com/oauth2/v3/certs"),
+		},
+		Auth: AuthConfig{
+			RequireEmailVerification:        vRequireEmailVerification,
+			EmailVerificationTTL:            vEmailVerificationTTL,
+			EmailVerificationResendInterval: vEmailVerificationResendInterval,
+			PasswordResetTTL:                vPasswordResetTTL,
+			PasswordResetRequestInterval:    vPasswordResetRequestInterval,
+			OAuthStateTTL:                   vOAuthStateTTL,
+			SessionStore:                    vSessionStore,
+			RefreshTokenTTL:                 vRefreshTokenTTL,
+			DenylistCacheTTL:                vDenylistCacheTTL,
+			MfaIssuer:                       getEnvString("MFA_ISSUER", "my-go-api"),
+			MfaTicketTTL:                    vMfaTicketTTL,
+			MagicLinkTTL:                    vMagicLinkTTL,
+			MagicLinkRequestInterval:        vMagicLinkRequestInterval,
+			LoginFailureWindow:              vLoginFailureWindow,
+			LoginLockoutThreshold:           vLoginLockoutThreshold,
+			LoginLockoutDuration:            vLoginLockoutDuration,
+			LoginMaxBackoff:                 vLoginMaxBackoff,
+			OAuthCodeTTL:                    vOAuthCodeTTL,
+			OAuthConsentUri:                 getEnvString("OAUTH_CONSENT_URI", os.Getenv("APP_URI")+"/oauth/authorize"),
+			InternalAPIKeys:                 getEnvList("INTERNAL_API_KEYS", nil),
+			ImpersonationTTL:                vImpersonationTTL,
+		},
+		OAuthProviders: vOAuthProviders,
+		WebAuthn: WebAuthnConfig{
+			RPID:          getEnvString("WEBAUTHN_RP_ID", hostname(os.Getenv("APP_URI"))),
+			RPDisplayName: getEnvString("WEBAUTHN_RP_NAME", "my-go-api"),
+			RPOrigins:     getEnvList("WEBAUTHN_RP_ORIGINS", []string{os.Getenv("APP_URI")}),
+			ChallengeTTL:  vWebAuthnChallengeTTL,
+		},
+		RateLimit: RateLimitConfig{
+			Store:    vRateLimitStore,
+			Policies: vRateLimitPolicies,
+		},
+		Policy: PolicyConfig{
+			Source:         vPolicySource,
+			File:           getEnvString("POLICY_FILE", "policies.yaml"),
+			ReloadInterval: vPolicyReloadInterval,
+		},
+		Password: PasswordConfig{
+			Argon2Memory:      uint32(vArgon2Memory),
+			Argon2Time:        uint32(vArgon2Time),
+			Argon2Parallelism: uint8(vArgon2Parallelism),
+			Pepper:            vPasswordPepper,
+			BreachedDir:       os.Getenv("BREACHED_PASSWORDS_DIR"),
+			BreachedThreshold: vBreachedPasswordThreshold,
+		},
 	}
 	return cfg, nil
 }
+
+// loadOAuthProviders builds the provider registry from OAUTH_PROVIDERS, a comma
+// separated list of names, and the OAUTH_<NAME>_* variables of each provider.
+func loadOAuthProviders(appUri string) (map[string]OAuthProviderConfig, error) {
+	providers := make(map[string]OAuthProviderConfig)
+	for _, name := range getEnvList("OAUTH_PROVIDERS", nil) {
+		name = strings.ToLower(name)
+		prefix := "OAUTH_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		trustEmail, err := getEnvBool(prefix+"TRUST_EMAIL", false)
+		if err != nil {
+			return nil, err
+		}
+		providers[name] = OAuthProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientId:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			AuthURL:      os.Getenv(prefix + "AUTH_URL"),
+			TokenURL:     os.Getenv(prefix + "TOKEN_URL"),
+			JWKSURL:      os.Getenv(prefix + "JWKS_URL"),
+			RedirectURL:  getEnvString(prefix+"REDIRECT_URL", appUri+"/api/v1/auth/oauth/"+name+"/callback"),
+			Scopes:       getEnvList(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+			TrustEmail:   trustEmail,
+		}
+	}
+	return providers, nil
+}
+
+// loadRateLimitPolicies builds the policies named in RATE_LIMIT_POLICIES, each
+// configured through RATE_LIMIT_<NAME>_* variables on top of its defaults.
+func loadRateLimitPolicies() (map[string]RateLimitPolicy, error) {
+	policies := make(map[string]RateLimitPolicy)
+	for _, name := range getEnvList("RATE_LIMIT_POLICIES", []string{"global", "auth", "user"}) {
+		name = strings.ToLower(name)
+		prefix := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		fallback, ok := defaultRateLimitPolicies[name]
+		if !ok {
+			fallback = RateLimitPolicy{Algorithm: "token_bucket", Limit: 60, Window: time.Minute, KeyBy: "ip"}
+		}
+		limit, err := getEnvInt(prefix+"LIMIT", fallback.Limit)
+		if err != nil {
+			return nil, err
+		}
+		window, err := getEnvDuration(prefix+"WINDOW", fallback.Window)
+		if err != nil {
+			return nil, err
+		}
+		policy := RateLimitPolicy{
+			Algorithm: getEnvString(prefix+"ALGORITHM", fallback.Algorithm),
+			Limit:     limit,
+			Window:    window,
+			KeyBy:     getEnvString(prefix+"KEY", fallback.KeyBy),
+		}
+		if policy.Algorithm != "token_bucket" && policy.Algorithm != "sliding_window" {
+			return nil, fmt.Errorf("unsupported %sALGORITHM %q, expected token_bucket or sliding_window", prefix, policy.Algorithm)
+		}
+		if policy.KeyBy != "ip" && policy.KeyBy != "user" && policy.KeyBy != "api_key" {
+			return nil, fmt.Errorf("unsupported %sKEY %q, expected ip, user or api_key", prefix, policy.KeyBy)
+		}
+		if policy.KeyBy == "user" && slices.Contains(preAuthRateLimitPolicies, name) {
+			return nil, fmt.Errorf("%sKEY cannot be user, the %s policy is enforced before the user is authenticated", prefix, name)
+		}
+		if policy.Limit <= 0 || policy.Window <= 0 {
+			return nil, fmt.Errorf("%sLIMIT and %sWINDOW must be positive", prefix, prefix)
+		}
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// loadPasswordPepper reads the pepper from the file at PASSWORD_PEPPER_FILE, or
+// from PASSWORD_PEPPER when no file is given.
+func loadPasswordPepper() (string, error) {
+	file := os.Getenv("PASSWORD_PEPPER_FILE")
+	if file == "" {
+		return os.Getenv("PASSWORD_PEPPER"), nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read PASSWORD_PEPPER_FILE: %w", err)
+	}
+	pepper := strings.TrimSpace(string(content))
+	if pepper == "" {
+		return "", fmt.Errorf("PASSWORD_PEPPER_FILE %s is empty", file)
+	}
+	return pepper, nil
+}
+
+// hostname returns the host of rawURL without its port, or "" when it is not a URL.
+func hostname(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// getEnvBool reads an optional boolean variable, returning fallback when it is unset.
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// getEnvInt reads an optional integer variable, returning fallback when it is unset.
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// getEnvDuration reads an optional duration variable such as "15m", returning fallback when it is unset.
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// getEnvString reads an optional variable, returning fallback when it is unset.
+func getEnvString(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// getEnvList reads an optional comma separated variable, returning fallback when it is unset.
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -4,4 +4,64 @@ const (
 	COOKIE_REFRESH_TOKEN = "mygoapi-refresh-token"
 	COOKIE_DEVICE_ID     = "mygoapi-device-id"
 	COOKIE_USER_ID       = "mygoapi-user-id"
+	COOKIE_MAGIC_LINK    = "mygoapi-magic-link"
+	COOKIE_OAUTH_STATE   = "mygoapi-oauth-state"
+)
+
+const (
+	PROVIDER_CREDENTIALS = "credentials"
+	PROVIDER_GOOGLE      = "google"
+	PROVIDER_OIDC        = "oidc"
+)
+
+// API keys start with API_KEY_PREFIX so they can be told apart from access
+// tokens and spotted by secret scanners.
+const API_KEY_PREFIX = "mga_"
+
+const (
+	API_KEY_SCOPE_READ  = "read"
+	API_KEY_SCOPE_WRITE = "write"
+)
+
+// OAuth2 clients are either confidential, holding a secret, or public such as
+// single page and mobile apps, which prove themselves with PKCE instead.
+const (
+	OAUTH_CLIENT_CONFIDENTIAL = "confidential"
+	OAUTH_CLIENT_PUBLIC       = "public"
+)
+
+const (
+	OAUTH_GRANT_AUTHORIZATION_CODE = "authorization_code"
+	OAUTH_GRANT_REFRESH_TOKEN      = "refresh_token"
+	OAUTH_GRANT_CLIENT_CREDENTIALS = "client_credentials"
+)
+
+// Scopes a client may be granted besides API_KEY_SCOPE_READ and API_KEY_SCOPE_WRITE,
+// which grant the same access as they do to API keys.
+const (
+	OAUTH_SCOPE_OPENID  = "openid"
+	OAUTH_SCOPE_PROFILE = "profile"
+	OAUTH_SCOPE_EMAIL   = "email"
+)
+
+// Roles every installation has. The admin role always holds every permission.
+const (
+	ROLE_USER  = "user"
+	ROLE_ADMIN = "admin"
+)
+
+// Permissions are named resource:action, routes require them with
+// middleware.RequirePermission.
+const (
+	PERMISSION_USERS_READ          = "users:read"
+	PERMISSION_USERS_WRITE         = "users:write"
+	PERMISSION_ROLES_READ          = "roles:read"
+	PERMISSION_ROLES_WRITE         = "roles:write"
+	PERMISSION_LOCKOUTS_READ       = "lockouts:read"
+	PERMISSION_LOCKOUTS_WRITE      = "lockouts:write"
+	PERMISSION_OAUTH_CLIENTS_READ  = "oauth_clients:read"
+	PERMISSION_OAUTH_CLIENTS_WRITE = "oauth_clients:write"
+	PERMISSION_POLICIES_READ       = "policies:read"
+	PERMISSION_USERS_IMPERSONATE   = "users:impersonate"
+	PERMISSION_AUDIT_LOG_READ      = "audit_log:read"
 )
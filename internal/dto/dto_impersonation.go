@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartImpersonation tells the audit log why an admin acts as a user.
+type StartImpersonation struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// Impersonation is shown in GetAuth while an admin acts as the user.
+type Impersonation struct {
+	ActorId   uuid.UUID `json:"actor_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type AuditLog struct {
+	ID        int64          `json:"id"`
+	ActorId   uuid.UUID      `json:"actor_id"`
+	Action    string         `json:"action"`
+	TargetId  *uuid.UUID     `json:"target_id"`
+	Details   map[string]any `json:"details"`
+	IP        string         `json:"ip"`
+	UserAgent string         `json:"user_agent"`
+	CreatedAt time.Time      `json:"created_at"`
+}
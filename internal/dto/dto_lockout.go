@@ -0,0 +1,15 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LockedAccount is how an account locked after too many failed logins is shown to admins.
+type LockedAccount struct {
+	UserId      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username"`
+	LockedUntil time.Time `json:"locked_until"`
+}
@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"my-go-api/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateOAuthClient struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	Type         string   `json:"type" validate:"required,oneof=confidential public"`
+	RedirectURIs []string `json:"redirect_uris" validate:"dive,url"`
+	Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=openid profile email read write"`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code refresh_token client_credentials"`
+}
+
+// OAuthClient is how a registered client is shown to admins, the secret is only
+// returned when the client is registered.
+type OAuthClient struct {
+	ID           uuid.UUID `json:"client_id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	GrantTypes   []string  `json:"grant_types"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthAuthorize is the authorization request of a client, passed on by our
+// frontend once the signed in user consented to it.
+type OAuthAuthorize struct {
+	ResponseType        string `json:"response_type" validate:"required"`
+	ClientId            string `json:"client_id" validate:"required"`
+	RedirectURI         string `json:"redirect_uri" validate:"required"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Nonce               string `json:"nonce"`
+}
+
+// OAuthToken is the successful response of the token endpoint, RFC 6749 section 5.1.
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// OAuthIntrospection is the response of the introspection endpoint, RFC 7662
+// section 2.2. Inactive tokens only carry Active.
+type OAuthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+}
+
+// OpenIDConfiguration is the discovery document of OpenID Connect Discovery
+// section 3, describing us as an OpenID provider.
+type OpenIDConfiguration struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// UserInfo is the response of the userinfo endpoint, the claims about the user
+// the scope of the access token covers.
+type UserInfo struct {
+	Subject string `json:"sub"`
+	utils.UserClaims
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EvaluatePolicy is a dry run of the access policies for the attributes given,
+// nothing is looked up. A request without a time is evaluated for now.
+type EvaluatePolicy struct {
+	Subject  PolicySubject  `json:"subject"`
+	Action   string         `json:"action" validate:"required,max=100"`
+	Resource PolicyResource `json:"resource"`
+	Context  PolicyContext  `json:"context"`
+}
+
+type PolicySubject struct {
+	UserId uuid.UUID `json:"user_id"`
+	Role   string    `json:"role" validate:"max=50"`
+	Org    string    `json:"org" validate:"max=100"`
+}
+
+type PolicyResource struct {
+	Type  string    `json:"type" validate:"required,max=50"`
+	Id    string    `json:"id" validate:"max=100"`
+	Owner uuid.UUID `json:"owner"`
+}
+
+type PolicyContext struct {
+	IP       string     `json:"ip" validate:"omitempty,ip"`
+	Time     *time.Time `json:"time"`
+	MfaLevel int        `json:"mfa_level" validate:"oneof=0 1 2"`
+}
+
+type PolicyDecision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	Policy  string `json:"policy,omitempty"`
+}
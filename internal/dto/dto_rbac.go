@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+type CreateRole struct {
+	Name        string   `json:"name" validate:"required,max=50,roleName"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+// SetRolePermissions replaces all permissions of a role, an empty list takes
+// them all away.
+type SetRolePermissions struct {
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type AssignRole struct {
+	Role string `json:"role" validate:"required,max=50"`
+}
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
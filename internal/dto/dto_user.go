@@ -1,13 +1,76 @@
 package dto
 
+import "encoding/json"
+
 type CreateUser struct {
 	Name     string `json:"name" validate:"required,min=5"`
 	Email    string `json:"email" validate:"required,email"`
 	Username string `json:"username" validate:"required,min=5"`
-	Password string `json:"password" validate:"required,strongPassword"`
+	Password string `json:"password" validate:"required,strongPassword,notBreached"`
 }
 
 type Login struct {
 	Identity string `json:"identity" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationEmail struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,strongPassword,notBreached"`
+}
+
+type UnlockAccount struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type GoogleLogin struct {
+	IdToken string `json:"id_token" validate:"required"`
+}
+
+type MagicLink struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MfaCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type VerifyMfa struct {
+	Ticket string `json:"mfa_ticket" validate:"required"`
+	Code   string `json:"code" validate:"required"`
+}
+
+type MfaTicket struct {
+	Ticket string `json:"mfa_ticket" validate:"required"`
+}
+
+// WebAuthnRegistration carries the PublicKeyCredential returned by navigator.credentials.create.
+type WebAuthnRegistration struct {
+	Name       string          `json:"name" validate:"max=100"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+// WebAuthnLogin carries the PublicKeyCredential returned by navigator.credentials.get
+// and the session of the ceremony it answers.
+type WebAuthnLogin struct {
+	Session    string          `json:"session" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+type WebAuthnMfa struct {
+	Ticket     string          `json:"mfa_ticket" validate:"required"`
+	Session    string          `json:"session" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// Passkey is how a registered WebAuthn credential is shown to its owner. ID is
+// the base64url credential id used to delete it.
+type Passkey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	Synced     bool       `json:"synced"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type IAPIKeyHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+}
+
+type apiKeyHandler struct {
+	aks services.IAPIKeyService
+}
+
+func NewAPIKeyHandler(aks services.IAPIKeyService) IAPIKeyHandler {
+	return &apiKeyHandler{aks: aks}
+}
+
+func toAPIKeyDto(key *models.APIKey) dto.APIKey {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return dto.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+// Create returns the new key in full, the only time it is shown.
+func (h *apiKeyHandler) Create(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.CreateAPIKey)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"expires_at": "Must be in the future"}})
+		return
+	}
+	raw, key, err := h.aks.Create(c.Request.Context(), userId, body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Copy the key now, it will not be shown again",
+		"key":     raw,
+		"api_key": toAPIKeyDto(key),
+	})
+}
+
+func (h *apiKeyHandler) List(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	keys, err := h.aks.List(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	apiKeys := make([]dto.APIKey, 0, len(keys))
+	for _, key := range keys {
+		apiKeys = append(apiKeys, toAPIKeyDto(&key))
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": apiKeys})
+}
+
+func (h *apiKeyHandler) Revoke(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	keyId, err := uuid.Parse(c.Param("keyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+	if err := h.aks.Revoke(c.Request.Context(), userId, keyId); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
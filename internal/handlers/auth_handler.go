@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"my-go-api/internal/constants"
 	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
 	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,11 +23,18 @@ type IAuthHandler interface {
 	RefreshToken(c *gin.Context)
 	GetAuth(c *gin.Context)
 	Login(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerificationEmail(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	UnlockAccount(c *gin.Context)
 }
 
 type authHandler struct {
 	as services.IAuthService
 	us services.IUserService
+	ms services.IMfaService
+	ls services.ILockoutService
 }
 
 type Cookie struct {
@@ -33,8 +43,8 @@ type Cookie struct {
 	deviceId uuid.UUID
 }
 
-func NewAuthHandler(service services.IAuthService, us services.IUserService) IAuthHandler {
-	return &authHandler{as: service, us: us}
+func NewAuthHandler(service services.IAuthService, us services.IUserService, ms services.IMfaService, ls services.ILockoutService) IAuthHandler {
+	return &authHandler{as: service, us: us, ms: ms, ls: ls}
 }
 
 func getCookies(c *gin.Context) (*Cookie, error) {
@@ -82,7 +92,7 @@ func (h *authHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusConflict, gin.H{"errors": err.Error()})
 		return
 	}
-	token, err := h.as.GenerateToken(user.ID)
+	token, err := h.as.CreateEmailVerificationToken(c.Request.Context(), user.ID)
 	if err != nil {
 		log.Println(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"errors": "Something went wrong"})
@@ -110,10 +120,14 @@ func (h *authHandler) Logout(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
+	clearSessionCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Logout"})
+}
+
+func clearSessionCookies(c *gin.Context) {
 	c.SetCookie(constants.COOKIE_REFRESH_TOKEN, "", -1, "/", "", false, false)
 	c.SetCookie(constants.COOKIE_DEVICE_ID, "", -1, "/", "", false, false)
 	c.SetCookie(constants.COOKIE_USER_ID, "", -1, "/", "", false, false)
-	c.JSON(http.StatusOK, gin.H{"message": "Logout"})
 }
 
 func (h *authHandler) RefreshToken(c *gin.Context) {
@@ -122,29 +136,21 @@ func (h *authHandler) RefreshToken(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.as.VerifyRefreshToken(c.Request.Context(), cookies.userId, cookies.deviceId, cookies.token); err != nil {
-		log.Println(err.Error())
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
-	}
-	tokenAcc, err := h.as.GenerateToken(cookies.userId)
+	jti := uuid.New()
+	tokenAcc, err := h.as.GenerateToken(cookies.userId, jti)
 	if err != nil {
 		log.Println("failed to generate a token")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
-	if err := h.as.DeleteRefreshToken(c.Request.Context(), cookies.userId, cookies.deviceId); err != nil {
-		log.Println("failed to delete the old token")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
-		return
-	}
-	newRefreshToken, hashToken, err := h.as.GenerateRefreshToken()
+	newRefreshToken, err := h.as.RotateRefreshToken(c.Request.Context(), jti, cookies.userId, cookies.deviceId, cookies.token, sessionMetadata(c))
 	if err != nil {
-		log.Println(err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
-		return
-	}
-	if err := h.as.StoreRefreshToken(c.Request.Context(), cookies.userId, cookies.deviceId, hashToken); err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			log.Println(err.Error())
+			clearSessionCookies(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
 		log.Println(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
@@ -169,9 +175,19 @@ func (h *authHandler) GetAuth(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "user not found"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	response := gin.H{"user": user}
+	if value, exist := c.Get("impersonation"); exist {
+		if token, ok := value.(*services.TokenPayload); ok {
+			response["impersonation"] = dto.Impersonation{ActorId: token.ActorId, ExpiresAt: token.ExpiresAt}
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
+// Login answers unknown accounts, password-less accounts and wrong passwords with
+// the same error after the same work, so it cannot be used to find out which
+// accounts exist. Failures are counted per account and client IP and slow down
+// or lock further attempts.
 func (h *authHandler) Login(c *gin.Context) {
 	value, exist := c.Get("validatedBody")
 	if !exist {
@@ -184,42 +200,244 @@ func (h *authHandler) Login(c *gin.Context) {
 		return
 	}
 	existingUser, err := h.as.GetUserByIdentity(c.Request.Context(), body.Identity)
-	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if err != nil && !errors.Is(err, services.ErrUserNotFound) {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	if err := h.ls.Check(c.Request.Context(), existingUser, body.Identity, c.ClientIP()); err != nil {
+		loginThrottled(c, err)
+		return
+	}
+	// Unknown and password-less accounts are checked against an empty hash,
+	// which takes as long as a wrong password for a real account.
+	hashedPassword := ""
+	if existingUser != nil {
+		hashedPassword = existingUser.Password
+	}
+	if isMatch := h.as.VerifyPassword(hashedPassword, body.Password); !isMatch || hashedPassword == "" {
+		if err := h.ls.RecordFailure(c.Request.Context(), existingUser, body.Identity, c.ClientIP()); err != nil {
+			log.Println(err.Error())
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	if err := h.ls.RecordSuccess(c.Request.Context(), existingUser); err != nil {
+		log.Println(err.Error())
+	}
+	if err := h.as.UpgradePasswordHash(c.Request.Context(), existingUser, body.Password); err != nil {
+		log.Println(err.Error())
+	}
+	if err := h.as.EnsureEmailVerified(existingUser); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email before logging in"})
+		return
+	}
+	completeLogin(c, h.as, h.ms, existingUser)
+}
+
+// loginThrottled answers a login attempt made while the account or client is
+// throttled. Unknown accounts are locked too, so the answer reveals nothing.
+func loginThrottled(c *gin.Context, err error) {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	if throttled.Locked {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "This account is temporarily locked after too many failed login attempts. Check your email to unlock it or try again later"})
+		return
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, please try again later"})
+}
+
+// UnlockAccount lifts a lockout with the link emailed when it was locked. It
+// accepts the token as a query parameter or in the body.
+func (h *authHandler) UnlockAccount(c *gin.Context) {
+	token := c.Query("token")
+	if value, exist := c.Get("validatedBody"); exist {
+		if body, ok := value.(dto.UnlockAccount); ok {
+			token = body.Token
+		}
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	if err := h.ls.Unlock(c.Request.Context(), token); err != nil {
+		if errors.Is(err, services.ErrInvalidUnlockToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
-	if isMatch := h.as.VerifyPassword(existingUser.Password, body.Password); !isMatch {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "wrong password"})
+	c.JSON(http.StatusOK, gin.H{"message": "Your account has been unlocked"})
+}
+
+// completeLogin finishes a sign in whose first factor succeeded. Users with
+// two-factor enabled get a short-lived MFA ticket to redeem at /auth/mfa/verify
+// instead of a session.
+func completeLogin(c *gin.Context, as services.IAuthService, ms services.IMfaService, user *models.User) {
+	enabled, err := ms.IsEnabled(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
+	if !enabled {
+		issueSession(c, as, user)
+		return
+	}
+	ticket, err := ms.CreateTicket(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_ticket": ticket})
+}
+
+// issueSession starts a new device session for an authenticated user: it stores a
+// refresh token, sets the session cookies and responds with the access token.
+// Every sign-in method finishes through here so they all behave like Login.
+func issueSession(c *gin.Context, as services.IAuthService, user *models.User) {
 	jti := uuid.New()
-	tokenAcc, err := h.as.GenerateToken(existingUser.ID, jti)
+	tokenAcc, err := as.GenerateToken(user.ID, jti)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	deviceId := uuid.New()
-	newRefreshToken, hashToken, err := h.as.GenerateRefreshToken()
+	newRefreshToken, hashToken, err := as.GenerateRefreshToken()
 	if err != nil {
 		log.Println(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
 
-	if err := h.as.StoreRefreshToken(c.Request.Context(), jti, existingUser.ID, deviceId, hashToken); err != nil {
+	if err := as.StoreRefreshToken(c.Request.Context(), jti, user.ID, deviceId, hashToken, sessionMetadata(c)); err != nil {
 		log.Println(err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		return
 	}
 	c.SetCookie(constants.COOKIE_REFRESH_TOKEN, newRefreshToken, 3600*24*365, "/", "", false, true)
 	c.SetCookie(constants.COOKIE_DEVICE_ID, deviceId.String(), 3600*24*365, "/", "", false, false)
-	c.SetCookie(constants.COOKIE_USER_ID, existingUser.ID.String(), 3600*24*365, "/", "", false, false)
+	c.SetCookie(constants.COOKIE_USER_ID, user.ID.String(), 3600*24*365, "/", "", false, false)
 	c.JSON(http.StatusOK, gin.H{
-		"user":  existingUser,
+		"user":  user,
 		"token": "Bearer " + tokenAcc,
 	})
 }
+
+// sessionMetadata describes the client of the request for the session list.
+func sessionMetadata(c *gin.Context) models.SessionMetadata {
+	userAgent := c.Request.UserAgent()
+	return models.SessionMetadata{
+		DeviceLabel: utils.DescribeUserAgent(userAgent),
+		UserAgent:   userAgent,
+		IP:          c.ClientIP(),
+	}
+}
+
+func (h *authHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if value, exist := c.Get("validatedBody"); exist {
+		if body, ok := value.(dto.VerifyEmail); ok {
+			token = body.Token
+		}
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	user, err := h.as.VerifyEmail(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Your email has been verified",
+		"user":    user,
+	})
+}
+
+func (h *authHandler) ResendVerificationEmail(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.ResendVerificationEmail)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if err := h.as.ResendVerificationEmail(c.Request.Context(), body.Email); err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait before requesting another verification email"})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("If %s belongs to an unverified account, a new verification email has been sent.", body.Email),
+	})
+}
+
+func (h *authHandler) ForgotPassword(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.ForgotPassword)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if err := h.as.RequestPasswordReset(c.Request.Context(), body.Email); err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait before requesting another password reset"})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("If %s is registered, an email with instructions to reset your password has been sent.", body.Email),
+	})
+}
+
+func (h *authHandler) ResetPassword(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.ResetPassword)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if err := h.as.ResetPassword(c.Request.Context(), body.Token, body.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Your password has been reset. Please login with your new password."})
+}
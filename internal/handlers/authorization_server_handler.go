@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type IAuthorizationServerHandler interface {
+	Authorize(c *gin.Context)
+	Token(c *gin.Context)
+	Introspect(c *gin.Context)
+	Revoke(c *gin.Context)
+	Discovery(c *gin.Context)
+	UserInfo(c *gin.Context)
+	RegisterClient(c *gin.Context)
+	ListClients(c *gin.Context)
+	DeleteClient(c *gin.Context)
+}
+
+type authorizationServerHandler struct {
+	server services.IAuthorizationServer
+}
+
+func NewAuthorizationServerHandler(server services.IAuthorizationServer) IAuthorizationServerHandler {
+	return &authorizationServerHandler{server: server}
+}
+
+func toOAuthClientDto(client *models.OAuthClient) dto.OAuthClient {
+	nonNil := func(values []string) []string {
+		if values == nil {
+			return []string{}
+		}
+		return values
+	}
+	return dto.OAuthClient{
+		ID:           client.ID,
+		Name:         client.Name,
+		Type:         client.Type,
+		RedirectURIs: nonNil(client.RedirectURIs),
+		Scopes:       nonNil(client.Scopes),
+		GrantTypes:   nonNil(client.GrantTypes),
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// oauthErrorResponse answers with the error body of RFC 6749 section 5.2.
+func oauthErrorResponse(c *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if errors.As(err, &oauthErr) {
+		status := http.StatusBadRequest
+		if oauthErr.Code == "invalid_client" {
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		if oauthErr.Code == "invalid_token" {
+			status = http.StatusUnauthorized
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, oauthErr.Description))
+		}
+		c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+		return
+	}
+	log.Println(err.Error())
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error", "error_description": "Something went wrong"})
+}
+
+// Authorize is called by our frontend once the signed in user consented to the
+// authorization request of a client, and answers where to send the browser.
+func (h *authorizationServerHandler) Authorize(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.OAuthAuthorize)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	redirectTo, err := h.server.Authorize(c.Request.Context(), userId, body)
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"redirect_to": redirectTo})
+}
+
+// Token is the token endpoint. Clients authenticate with HTTP Basic or with
+// client_id and client_secret in the form body, RFC 6749 section 2.3.1.
+func (h *authorizationServerHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+	client, ok := h.authenticateClient(c)
+	if !ok {
+		return
+	}
+	token, err := h.server.Token(c.Request.Context(), client, services.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		RefreshToken: c.PostForm("refresh_token"),
+		Scope:        c.PostForm("scope"),
+	}, sessionMetadata(c))
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+// authenticateClient answers with invalid_client unless the request carries the
+// credentials of a client.
+func (h *authorizationServerHandler) authenticateClient(c *gin.Context) (*models.OAuthClient, bool) {
+	clientId, secret, ok := c.Request.BasicAuth()
+	if ok {
+		// Basic credentials are form encoded before they are joined
+		clientId, _ = url.QueryUnescape(clientId)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientId, secret = c.PostForm("client_id"), c.PostForm("client_secret")
+	}
+	if clientId == "" {
+		oauthErrorResponse(c, &services.OAuthError{Code: "invalid_client", Description: "client authentication failed"})
+		return nil, false
+	}
+	client, err := h.server.AuthenticateClient(c.Request.Context(), clientId, secret)
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// authenticateCaller accepts an internal API key in the X-Internal-API-Key
+// header, returning a nil client for it, or the credentials of a client.
+func (h *authorizationServerHandler) authenticateCaller(c *gin.Context) (*models.OAuthClient, bool) {
+	if key := c.GetHeader("X-Internal-API-Key"); key != "" {
+		if !h.server.AuthenticateInternalKey(key) {
+			oauthErrorResponse(c, &services.OAuthError{Code: "invalid_client", Description: "invalid internal api key"})
+			return nil, false
+		}
+		return nil, true
+	}
+	return h.authenticateClient(c)
+}
+
+// Introspect is the introspection endpoint of RFC 7662 for resource servers.
+// Public clients cannot keep a secret, so they are not allowed to introspect.
+func (h *authorizationServerHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	caller, ok := h.authenticateCaller(c)
+	if !ok {
+		return
+	}
+	if caller != nil && caller.IsPublic() {
+		oauthErrorResponse(c, &services.OAuthError{Code: "invalid_client", Description: "public clients cannot introspect tokens"})
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		oauthErrorResponse(c, &services.OAuthError{Code: "invalid_request", Description: "token is required"})
+		return
+	}
+	introspection, err := h.server.Introspect(c.Request.Context(), caller, token, c.PostForm("token_type_hint"))
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, introspection)
+}
+
+// Revoke is the revocation endpoint of RFC 7009. It answers 200 for tokens that
+// are unknown or already invalid, the client cannot do anything about them.
+func (h *authorizationServerHandler) Revoke(c *gin.Context) {
+	caller, ok := h.authenticateCaller(c)
+	if !ok {
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		oauthErrorResponse(c, &services.OAuthError{Code: "invalid_request", Description: "token is required"})
+		return
+	}
+	if err := h.server.Revoke(c.Request.Context(), caller, token, c.PostForm("token_type_hint")); err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Discovery serves the OpenID Connect discovery document.
+func (h *authorizationServerHandler) Discovery(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.server.Discovery())
+}
+
+// UserInfo is the userinfo endpoint of OpenID Connect. It runs after
+// RequireScope("openid"), so a client's token carries the scope it was granted,
+// while the user's own session may see all of their claims.
+func (h *authorizationServerHandler) UserInfo(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	scope := strings.Join([]string{constants.OAUTH_SCOPE_OPENID, constants.OAUTH_SCOPE_PROFILE, constants.OAUTH_SCOPE_EMAIL}, " ")
+	if value, exist := c.Get("oauthScope"); exist {
+		scope, _ = value.(string)
+	}
+	userInfo, err := h.server.UserInfo(c.Request.Context(), userId, scope)
+	if err != nil {
+		oauthErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userInfo)
+}
+
+// RegisterClient returns the secret of a confidential client, the only time it is shown.
+func (h *authorizationServerHandler) RegisterClient(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.CreateOAuthClient)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	client, secret, err := h.server.RegisterClient(c.Request.Context(), body)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	response := gin.H{"client": toOAuthClientDto(client)}
+	if secret != "" {
+		response["message"] = "Copy the client secret now, it will not be shown again"
+		response["client_secret"] = secret
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *authorizationServerHandler) ListClients(c *gin.Context) {
+	clients, err := h.server.ListClients(c.Request.Context())
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	result := make([]dto.OAuthClient, 0, len(clients))
+	for _, client := range clients {
+		result = append(result, toOAuthClientDto(&client))
+	}
+	c.JSON(http.StatusOK, gin.H{"clients": result})
+}
+
+func (h *authorizationServerHandler) DeleteClient(c *gin.Context) {
+	clientId, err := uuid.Parse(c.Param("clientId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	if err := h.server.DeleteClient(c.Request.Context(), clientId); err != nil {
+		if errors.Is(err, services.ErrOAuthClientNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "OAuth client deleted"})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IGoogleAuthHandler interface {
+	Login(c *gin.Context)
+}
+
+type googleAuthHandler struct {
+	gs services.IGoogleAuthService
+	as services.IAuthService
+	ms services.IMfaService
+}
+
+func NewGoogleAuthHandler(gs services.IGoogleAuthService, as services.IAuthService, ms services.IMfaService) IGoogleAuthHandler {
+	return &googleAuthHandler{gs: gs, as: as, ms: ms}
+}
+
+func (h *googleAuthHandler) Login(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.GoogleLogin)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	user, err := h.gs.Authenticate(c.Request.Context(), body.IdToken)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidIDToken) {
+			log.Println(err.Error())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid google id token"})
+			return
+		}
+		if errors.Is(err, services.ErrExternalEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrExternalAccountUnverified) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	completeLogin(c, h.as, h.ms, user)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IImpersonationHandler lets admins act as a user and read the audit log.
+type IImpersonationHandler interface {
+	Start(c *gin.Context)
+	Stop(c *gin.Context)
+	AuditLog(c *gin.Context)
+}
+
+type impersonationHandler struct {
+	service services.IImpersonationService
+}
+
+func NewImpersonationHandler(service services.IImpersonationService) IImpersonationHandler {
+	return &impersonationHandler{service: service}
+}
+
+func impersonationErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImpersonateSelf), errors.Is(err, services.ErrNotImpersonating):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrImpersonateAdmin), errors.Is(err, services.ErrImpersonateAbove):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
+
+// Start answers with an access token for the user, there is no refresh token
+// and no cookie so the admin's own session stays as it is.
+func (h *impersonationHandler) Start(c *gin.Context) {
+	actorId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.StartImpersonation)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	impersonation, err := h.service.Start(c.Request.Context(), actorId, userId, body.Reason, sessionMetadata(c))
+	if err != nil {
+		impersonationErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"user":       impersonation.User,
+		"token":      "Bearer " + impersonation.AccessToken,
+		"expires_at": impersonation.ExpiresAt,
+	})
+}
+
+// Stop ends the impersonation the request is made with.
+func (h *impersonationHandler) Stop(c *gin.Context) {
+	value, _ := c.Get("impersonation")
+	token, _ := value.(*services.TokenPayload)
+	if err := h.service.Stop(c.Request.Context(), token, sessionMetadata(c)); err != nil {
+		impersonationErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation stopped"})
+}
+
+// AuditLog returns the latest entries, ?limit=50 by default and 200 at most.
+func (h *impersonationHandler) AuditLog(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return
+	}
+	entries, err := h.service.AuditLog(c.Request.Context(), limit)
+	if err != nil {
+		impersonationErrorResponse(c, err)
+		return
+	}
+	result := make([]dto.AuditLog, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, dto.AuditLog{
+			ID:        entry.ID,
+			ActorId:   entry.ActorId,
+			Action:    entry.Action,
+			TargetId:  entry.TargetId,
+			Details:   entry.Details,
+			IP:        entry.IP,
+			UserAgent: entry.UserAgent,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": result})
+}
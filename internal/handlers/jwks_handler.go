@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"my-go-api/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IJWKSHandler interface {
+	Get(c *gin.Context)
+}
+
+type jwksHandler struct {
+	keyring *utils.Keyring
+}
+
+func NewJWKSHandler(keyring *utils.Keyring) IJWKSHandler {
+	return &jwksHandler{keyring: keyring}
+}
+
+// Get publishes the public keys access tokens can be verified with. The cache
+// lifetime stays short so consumers pick up a newly added key well before it
+// starts signing.
+func (h *jwksHandler) Get(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": h.keyring.JWKS()})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ILockoutHandler lets admins see and lift the lockouts of accounts.
+type ILockoutHandler interface {
+	ListLocked(c *gin.Context)
+	Unlock(c *gin.Context)
+}
+
+type lockoutHandler struct {
+	ls services.ILockoutService
+}
+
+func NewLockoutHandler(ls services.ILockoutService) ILockoutHandler {
+	return &lockoutHandler{ls: ls}
+}
+
+func (h *lockoutHandler) ListLocked(c *gin.Context) {
+	locked, err := h.ls.ListLocked(c.Request.Context())
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	accounts := make([]dto.LockedAccount, 0, len(locked))
+	for _, account := range locked {
+		accounts = append(accounts, dto.LockedAccount{
+			UserId:      account.User.ID,
+			Email:       account.User.Email,
+			Username:    account.User.Username,
+			LockedUntil: account.LockedUntil,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"locked_accounts": accounts})
+}
+
+func (h *lockoutHandler) Unlock(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	if err := h.ls.UnlockUser(c.Request.Context(), userId); err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlocked"})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// magicLinkCookiePath limits the device binding cookie to the magic link endpoints.
+const magicLinkCookiePath = "/api/v1/auth/magic-link"
+
+type IMagicLinkHandler interface {
+	Request(c *gin.Context)
+	Consume(c *gin.Context)
+}
+
+type magicLinkHandler struct {
+	mls     services.IMagicLinkService
+	as      services.IAuthService
+	ms      services.IMfaService
+	linkTTL time.Duration
+}
+
+func NewMagicLinkHandler(mls services.IMagicLinkService, as services.IAuthService, ms services.IMfaService, linkTTL time.Duration) IMagicLinkHandler {
+	return &magicLinkHandler{mls: mls, as: as, ms: ms, linkTTL: linkTTL}
+}
+
+// Request emails a login link and binds it to this browser with a cookie, a link
+// opened in another browser is rejected.
+func (h *magicLinkHandler) Request(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.MagicLink)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	binding, err := h.mls.RequestLink(c.Request.Context(), body.Email)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Please wait before requesting another login link"})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.SetCookie(constants.COOKIE_MAGIC_LINK, binding, int(h.linkTTL.Seconds()), magicLinkCookiePath, "", false, true)
+	c.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("If %s is registered, an email with a login link has been sent.", body.Email),
+	})
+}
+
+// Consume logs the owner of a link in, asking for the second factor when enabled.
+func (h *magicLinkHandler) Consume(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	binding, _ := c.Cookie(constants.COOKIE_MAGIC_LINK)
+	user, err := h.mls.ConsumeLink(c.Request.Context(), token, binding)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMagicLink) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.SetCookie(constants.COOKIE_MAGIC_LINK, "", -1, magicLinkCookiePath, "", false, true)
+	completeLogin(c, h.as, h.ms, user)
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IMfaHandler interface {
+	Enroll(c *gin.Context)
+	ConfirmEnrollment(c *gin.Context)
+	Disable(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	Verify(c *gin.Context)
+}
+
+type mfaHandler struct {
+	ms services.IMfaService
+	as services.IAuthService
+	us services.IUserService
+}
+
+func NewMfaHandler(ms services.IMfaService, as services.IAuthService, us services.IUserService) IMfaHandler {
+	return &mfaHandler{ms: ms, as: as, us: us}
+}
+
+// mfaErrorStatus maps the errors of codes submitted by a signed in user.
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrMfaAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrMfaNotEnrolled),
+		errors.Is(err, services.ErrMfaNotEnabled),
+		errors.Is(err, services.ErrInvalidMfaCode):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrTooManyMfaCodes):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func respondMfaError(c *gin.Context, err error) {
+	status := mfaErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		log.Println(err.Error())
+		c.JSON(status, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+func (h *mfaHandler) Enroll(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	enrollment, err := h.ms.StartEnrollment(c.Request.Context(), user)
+	if err != nil {
+		respondMfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *mfaHandler) ConfirmEnrollment(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.MfaCode)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	codes, err := h.ms.ConfirmEnrollment(c.Request.Context(), userId, body.Code)
+	if err != nil {
+		respondMfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (h *mfaHandler) Disable(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.MfaCode)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if err := h.ms.Disable(c.Request.Context(), userId, body.Code); err != nil {
+		respondMfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication has been disabled"})
+}
+
+func (h *mfaHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.MfaCode)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	codes, err := h.ms.RegenerateRecoveryCodes(c.Request.Context(), userId, body.Code)
+	if err != nil {
+		respondMfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Verify redeems the MFA ticket of a password login with a TOTP or recovery
+// code and starts the session the login was holding back.
+func (h *mfaHandler) Verify(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.VerifyMfa)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	userId, err := h.ms.RedeemTicket(c.Request.Context(), body.Ticket, body.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMfaTicket) || errors.Is(err, services.ErrInvalidMfaCode) || errors.Is(err, services.ErrMfaNotEnabled) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	issueSession(c, h.as, user)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthCookiePath limits the state binding cookie to the social login endpoints.
+const oauthCookiePath = "/api/v1/auth/oauth"
+
+type IOAuthHandler interface {
+	Start(c *gin.Context)
+	Callback(c *gin.Context)
+}
+
+type oauthHandler struct {
+	os       services.IOAuthService
+	as       services.IAuthService
+	ms       services.IMfaService
+	stateTTL time.Duration
+}
+
+func NewOAuthHandler(os services.IOAuthService, as services.IAuthService, ms services.IMfaService, stateTTL time.Duration) IOAuthHandler {
+	return &oauthHandler{os: os, as: as, ms: ms, stateTTL: stateTTL}
+}
+
+// Start redirects to the provider and binds the flow to this browser with a
+// cookie. It is SameSite=Lax so it still comes along when the provider
+// redirects back to the callback.
+func (h *oauthHandler) Start(c *gin.Context) {
+	authURL, binding, err := h.os.Start(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, utils.ErrUnknownOAuthProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(constants.COOKIE_OAUTH_STATE, binding, int(h.stateTTL.Seconds()), oauthCookiePath, "", false, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *oauthHandler) Callback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": providerErr, "error_description": c.Query("error_description")})
+		return
+	}
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return
+	}
+	binding, _ := c.Cookie(constants.COOKIE_OAUTH_STATE)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(constants.COOKIE_OAUTH_STATE, "", -1, oauthCookiePath, "", false, true)
+	user, err := h.os.Callback(c.Request.Context(), c.Param("provider"), code, state, binding)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrUnknownOAuthProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidOAuthState):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrOAuthExchangeFailed), errors.Is(err, utils.ErrInvalidIDToken):
+			log.Println(err.Error())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication with the provider failed"})
+		case errors.Is(err, services.ErrExternalEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrExternalAccountUnverified), errors.Is(err, services.ErrExternalEmailInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		}
+		return
+	}
+	completeLogin(c, h.as, h.ms, user)
+}
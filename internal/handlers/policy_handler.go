@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"my-go-api/internal/dto"
+	"my-go-api/internal/policy"
+	"my-go-api/internal/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IPolicyHandler lets admins try the access policies.
+type IPolicyHandler interface {
+	Evaluate(c *gin.Context)
+}
+
+type policyHandler struct {
+	policyService services.IPolicyService
+}
+
+func NewPolicyHandler(policyService services.IPolicyService) IPolicyHandler {
+	return &policyHandler{policyService: policyService}
+}
+
+// Evaluate answers what the loaded policies decide for the request in the body,
+// and which policy decided it.
+func (h *policyHandler) Evaluate(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.EvaluatePolicy)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	at := time.Now()
+	if body.Context.Time != nil {
+		at = *body.Context.Time
+	}
+	decision := h.policyService.Evaluate(policy.Request{
+		Subject:  policy.Subject{UserId: body.Subject.UserId, Role: body.Subject.Role, Org: body.Subject.Org},
+		Action:   policy.Action(body.Action),
+		Resource: policy.Resource{Type: body.Resource.Type, Id: body.Resource.Id, Owner: body.Resource.Owner},
+		Context:  policy.Context{IP: body.Context.IP, Time: at, MfaLevel: body.Context.MfaLevel},
+	})
+	c.JSON(http.StatusOK, dto.PolicyDecision{Allowed: decision.Allowed, Reason: decision.Reason, Policy: decision.Policy})
+}
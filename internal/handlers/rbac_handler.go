@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// IRBACHandler lets admins manage roles and assign them to users.
+type IRBACHandler interface {
+	ListRoles(c *gin.Context)
+	CreateRole(c *gin.Context)
+	SetRolePermissions(c *gin.Context)
+	DeleteRole(c *gin.Context)
+	ListPermissions(c *gin.Context)
+	AssignRole(c *gin.Context)
+}
+
+type rbacHandler struct {
+	rbacService services.IRBACService
+}
+
+func NewRBACHandler(rbacService services.IRBACService) IRBACHandler {
+	return &rbacHandler{rbacService: rbacService}
+}
+
+func toRoleDto(role *models.Role) dto.Role {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return dto.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+	}
+}
+
+// rbacErrorResponse answers with the status of the RBAC errors, and 500 for
+// anything else.
+func rbacErrorResponse(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrRoleInUse), errors.Is(err, services.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrBuiltInRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+	}
+}
+
+func (h *rbacHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles(c.Request.Context())
+	if err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	result := make([]dto.Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, toRoleDto(&role))
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": result})
+}
+
+func (h *rbacHandler) CreateRole(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.CreateRole)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	role, err := h.rbacService.CreateRole(c.Request.Context(), body)
+	if err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"role": toRoleDto(role)})
+}
+
+func (h *rbacHandler) SetRolePermissions(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.SetRolePermissions)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	role, err := h.rbacService.SetRolePermissions(c.Request.Context(), c.Param("role"), body.Permissions)
+	if err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"role": toRoleDto(role)})
+}
+
+func (h *rbacHandler) DeleteRole(c *gin.Context) {
+	if err := h.rbacService.DeleteRole(c.Request.Context(), c.Param("role")); err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
+}
+
+func (h *rbacHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.ListPermissions(c.Request.Context())
+	if err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	result := make([]dto.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		result = append(result, dto.Permission{Name: permission.Name, Description: permission.Description})
+	}
+	c.JSON(http.StatusOK, gin.H{"permissions": result})
+}
+
+func (h *rbacHandler) AssignRole(c *gin.Context) {
+	userId, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.AssignRole)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	if err := h.rbacService.AssignRole(c.Request.Context(), userId, body.Role); err != nil {
+		rbacErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned"})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ISessionHandler interface {
+	List(c *gin.Context)
+	Revoke(c *gin.Context)
+	RevokeAll(c *gin.Context)
+}
+
+type sessionHandler struct {
+	ss services.ISessionService
+}
+
+func NewSessionHandler(ss services.ISessionService) ISessionHandler {
+	return &sessionHandler{ss: ss}
+}
+
+func authenticatedUserId(c *gin.Context) (uuid.UUID, bool) {
+	value, exist := c.Get("authenticatedUserId")
+	if !exist {
+		return uuid.Nil, false
+	}
+	userId, ok := value.(uuid.UUID)
+	return userId, ok
+}
+
+// currentDeviceId returns the device of the request from its device id cookie,
+// uuid.Nil when the cookie is missing.
+func currentDeviceId(c *gin.Context) uuid.UUID {
+	cookie, err := c.Cookie(constants.COOKIE_DEVICE_ID)
+	if err != nil {
+		return uuid.Nil
+	}
+	deviceId, err := uuid.Parse(cookie)
+	if err != nil {
+		return uuid.Nil
+	}
+	return deviceId
+}
+
+func (h *sessionHandler) List(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	tokens, err := h.ss.ListSessions(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	current := currentDeviceId(c)
+	sessions := make([]dto.Session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, dto.Session{
+			DeviceId:    token.DeviceId,
+			DeviceLabel: token.DeviceLabel,
+			UserAgent:   token.UserAgent,
+			IP:          token.IP,
+			CreatedAt:   token.CreatedAt,
+			LastUsedAt:  token.LastUsedAt,
+			Current:     token.DeviceId == current,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func (h *sessionHandler) Revoke(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	deviceId, err := uuid.Parse(c.Param("deviceId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device id"})
+		return
+	}
+	if err := h.ss.RevokeSession(c.Request.Context(), userId, deviceId); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	if deviceId == currentDeviceId(c) {
+		clearSessionCookies(c)
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAll signs out every device, or every other device with ?except=current.
+func (h *sessionHandler) RevokeAll(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	switch c.Query("except") {
+	case "current":
+		current := currentDeviceId(c)
+		if current == uuid.Nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "the current session is unknown"})
+			return
+		}
+		if err := h.ss.RevokeOtherSessions(c.Request.Context(), userId, current); err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Signed out of all other sessions"})
+	case "":
+		if err := h.ss.RevokeAllSessions(c.Request.Context(), userId); err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+		clearSessionCookies(c)
+		c.JSON(http.StatusOK, gin.H{"message": "Signed out of all sessions"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "except only supports \"current\""})
+	}
+}
@@ -0,0 +1,95 @@
+package handlers_test
+
+import (
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeyService := mock_services.NewMockIAPIKeyService(ctrl)
+	apiKeyHandler := handlers.NewAPIKeyHandler(mockAPIKeyService)
+	userId := uuid.New()
+
+	var body dto.CreateAPIKey
+	router := gin.Default()
+	authenticated := func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}
+	router.POST("/api-keys", authenticated, func(c *gin.Context) {
+		c.Set("validatedBody", body)
+		c.Next()
+	}, apiKeyHandler.Create)
+	router.GET("/api-keys", authenticated, apiKeyHandler.List)
+	router.DELETE("/api-keys/:keyId", authenticated, apiKeyHandler.Revoke)
+
+	request := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should return the key once on create", func(t *testing.T) {
+		body = dto.CreateAPIKey{Name: "CI", Scopes: []string{"read"}}
+		key := &models.APIKey{ID: uuid.New(), UserId: userId, Name: "CI", Prefix: "mga_01234567", Scopes: []string{"read"}}
+		mockAPIKeyService.EXPECT().Create(gomock.Any(), userId, "CI", []string{"read"}, nil).Return("mga_0123456789", key, nil)
+
+		w := request(http.MethodPost, "/api-keys")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"key":"mga_0123456789"`)
+		assert.Contains(t, w.Body.String(), `"prefix":"mga_01234567"`)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("it should reject an expiry in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		body = dto.CreateAPIKey{Name: "CI", Scopes: []string{"read"}, ExpiresAt: &expiresAt}
+
+		w := request(http.MethodPost, "/api-keys")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"errors": {"expires_at": "Must be in the future"}}`, w.Body.String())
+	})
+
+	t.Run("it should list keys without their secret", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().List(gomock.Any(), userId).Return([]models.APIKey{{ID: uuid.New(), Name: "CI", Prefix: "mga_01234567", Hash: "secret-hash"}}, nil)
+
+		w := request(http.MethodGet, "/api-keys")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"scopes":[]`)
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+	})
+
+	t.Run("it should return 404 when revoking a key of someone else", func(t *testing.T) {
+		keyId := uuid.New()
+		mockAPIKeyService.EXPECT().Revoke(gomock.Any(), userId, keyId).Return(services.ErrAPIKeyNotFound)
+
+		w := request(http.MethodDelete, "/api-keys/"+keyId.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("it should revoke a key", func(t *testing.T) {
+		keyId := uuid.New()
+		mockAPIKeyService.EXPECT().Revoke(gomock.Any(), userId, keyId).Return(nil)
+
+		w := request(http.MethodDelete, "/api-keys/"+keyId.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
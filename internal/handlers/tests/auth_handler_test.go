@@ -9,9 +9,11 @@ import (
 	"my-go-api/internal/handlers"
 	"my-go-api/internal/mocks/mock_services"
 	"my-go-api/internal/models"
+	"my-go-api/internal/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -27,7 +29,7 @@ func TestLogout(t *testing.T) {
 
 	mockAuthService := mock_services.NewMockIAuthService(ctrl)
 	mockUserService := mock_services.NewMockIUserService(ctrl)
-	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
 
 	t.Run("should return 500 if cookies are missing", func(t *testing.T) {
 		router := gin.Default()
@@ -107,7 +109,7 @@ func TestRegister(t *testing.T) {
 
 	mockAuthService := mock_services.NewMockIAuthService(ctrl)
 	mockUserService := mock_services.NewMockIUserService(ctrl)
-	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
 
 	t.Run("should return 400 when validatedBody is missing", func(t *testing.T) {
 		router := gin.Default()
@@ -157,7 +159,7 @@ func TestRegister(t *testing.T) {
 		assert.JSONEq(t, `{"errors": "failed to create user"}`, w.Body.String())
 	})
 
-	t.Run("should return 500 if GenerateToken fails", func(t *testing.T) {
+	t.Run("should return 500 if CreateEmailVerificationToken fails", func(t *testing.T) {
 		user := &models.User{ID: uuid.New(), Email: "john@example.com"}
 		router := gin.Default()
 		router.POST("/register", func(c *gin.Context) {
@@ -170,7 +172,7 @@ func TestRegister(t *testing.T) {
 			authHandler.Register(c)
 		})
 		mockAuthService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(user, nil)
-		mockAuthService.EXPECT().GenerateToken(user.ID).Return("", errors.New("token generation failed"))
+		mockAuthService.EXPECT().CreateEmailVerificationToken(gomock.Any(), user.ID).Return("", errors.New("token generation failed"))
 		req, _ := http.NewRequest(http.MethodPost, "/register", nil)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
@@ -192,7 +194,7 @@ func TestRegister(t *testing.T) {
 			authHandler.Register(c)
 		})
 		mockAuthService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(user, nil)
-		mockAuthService.EXPECT().GenerateToken(user.ID).Return(token, nil)
+		mockAuthService.EXPECT().CreateEmailVerificationToken(gomock.Any(), user.ID).Return(token, nil)
 		mockAuthService.EXPECT().SendVerificationEmail(user.Name, user.Email, token).Return(errors.New("email send failed"))
 		req, _ := http.NewRequest(http.MethodPost, "/register", nil)
 		w := httptest.NewRecorder()
@@ -215,7 +217,7 @@ func TestRegister(t *testing.T) {
 			authHandler.Register(c)
 		})
 		mockAuthService.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(user, nil)
-		mockAuthService.EXPECT().GenerateToken(user.ID).Return(token, nil)
+		mockAuthService.EXPECT().CreateEmailVerificationToken(gomock.Any(), user.ID).Return(token, nil)
 		mockAuthService.EXPECT().SendVerificationEmail(user.Name, user.Email, token).Return(nil)
 		reqBody, _ := json.Marshal(dto.CreateUser{
 			Name:     "John Doe",
@@ -241,7 +243,7 @@ func TestRefreshToken(t *testing.T) {
 
 	mockAuthService := mock_services.NewMockIAuthService(ctrl)
 	mockUserService := mock_services.NewMockIUserService(ctrl)
-	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
 
 	t.Run("should return 401 if cookies are missing", func(t *testing.T) {
 		router := gin.Default()
@@ -255,17 +257,25 @@ func TestRefreshToken(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
-	t.Run("should return 401 if refresh token verification fails", func(t *testing.T) {
+	refreshRouter := func(userId, deviceId uuid.UUID, token string) *gin.Engine {
 		router := gin.Default()
 		router.GET("/refresh-token", func(c *gin.Context) {
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: "invalid-token"})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: uuid.New().String()})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: uuid.New().String()})
+			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: token})
+			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: deviceId.String()})
+			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: userId.String()})
 
 			authHandler.RefreshToken(c)
 		})
+		return router
+	}
 
-		mockAuthService.EXPECT().VerifyRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), "invalid-token").Return(errors.New("invalid token"))
+	t.Run("should return 401 and clear cookies if the refresh token is invalid", func(t *testing.T) {
+		userId := uuid.New()
+		deviceId := uuid.New()
+		router := refreshRouter(userId, deviceId, "invalid-token")
+
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("new-access-token", nil)
+		mockAuthService.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, "invalid-token", gomock.Any()).Return("", services.ErrInvalidRefreshToken)
 
 		req, _ := http.NewRequest(http.MethodGet, "/refresh-token", nil)
 		w := httptest.NewRecorder()
@@ -274,49 +284,34 @@ func TestRefreshToken(t *testing.T) {
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 		assert.JSONEq(t, `{"error": "Unauthorized"}`, w.Body.String())
+		for _, cookie := range w.Result().Cookies() {
+			assert.Equal(t, -1, cookie.MaxAge)
+		}
 	})
 
-	t.Run("should return 500 if generating new token fails", func(t *testing.T) {
+	t.Run("should return 401 and clear cookies if the refresh token was reused", func(t *testing.T) {
 		userId := uuid.New()
 		deviceId := uuid.New()
+		router := refreshRouter(userId, deviceId, "rotated-token")
 
-		router := gin.Default()
-		router.GET("/refresh-token", func(c *gin.Context) {
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: "valid-token"})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: deviceId.String()})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: userId.String()})
-
-			authHandler.RefreshToken(c)
-		})
-
-		mockAuthService.EXPECT().VerifyRefreshToken(gomock.Any(), userId, deviceId, "valid-token").Return(nil)
-		mockAuthService.EXPECT().GenerateToken(userId).Return("", errors.New("failed to generate token"))
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("new-access-token", nil)
+		mockAuthService.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, "rotated-token", gomock.Any()).Return("", services.ErrRefreshTokenReused)
 
 		req, _ := http.NewRequest(http.MethodGet, "/refresh-token", nil)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
-		assert.JSONEq(t, `{"error": "Something went wrong"}`, w.Body.String())
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Len(t, w.Result().Cookies(), 3)
 	})
 
-	t.Run("should return 500 if deleting old refresh token fails", func(t *testing.T) {
+	t.Run("should return 500 if generating new token fails", func(t *testing.T) {
 		userId := uuid.New()
 		deviceId := uuid.New()
+		router := refreshRouter(userId, deviceId, "valid-token")
 
-		router := gin.Default()
-		router.GET("/refresh-token", func(c *gin.Context) {
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: "valid-token"})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: deviceId.String()})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: userId.String()})
-
-			authHandler.RefreshToken(c)
-		})
-
-		mockAuthService.EXPECT().VerifyRefreshToken(gomock.Any(), userId, deviceId, "valid-token").Return(nil)
-		mockAuthService.EXPECT().GenerateToken(userId).Return("new-access-token", nil)
-		mockAuthService.EXPECT().DeleteRefreshToken(gomock.Any(), userId, deviceId).Return(errors.New("failed to delete old token"))
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("", errors.New("failed to generate token"))
 
 		req, _ := http.NewRequest(http.MethodGet, "/refresh-token", nil)
 		w := httptest.NewRecorder()
@@ -327,23 +322,13 @@ func TestRefreshToken(t *testing.T) {
 		assert.JSONEq(t, `{"error": "Something went wrong"}`, w.Body.String())
 	})
 
-	t.Run("should return 500 if new refresh token generation fails", func(t *testing.T) {
+	t.Run("should return 500 if rotating the refresh token fails", func(t *testing.T) {
 		userId := uuid.New()
 		deviceId := uuid.New()
+		router := refreshRouter(userId, deviceId, "valid-token")
 
-		router := gin.Default()
-		router.GET("/refresh-token", func(c *gin.Context) {
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: "valid-token"})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: deviceId.String()})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: userId.String()})
-
-			authHandler.RefreshToken(c)
-		})
-
-		mockAuthService.EXPECT().VerifyRefreshToken(gomock.Any(), userId, deviceId, "valid-token").Return(nil)
-		mockAuthService.EXPECT().GenerateToken(userId).Return("new-access-token", nil)
-		mockAuthService.EXPECT().DeleteRefreshToken(gomock.Any(), userId, deviceId).Return(nil)
-		mockAuthService.EXPECT().GenerateRefreshToken().Return("", "", errors.New("failed to generate refresh token"))
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("new-access-token", nil)
+		mockAuthService.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, "valid-token", gomock.Any()).Return("", errors.New("failed to store token"))
 
 		req, _ := http.NewRequest(http.MethodGet, "/refresh-token", nil)
 		w := httptest.NewRecorder()
@@ -357,23 +342,19 @@ func TestRefreshToken(t *testing.T) {
 	t.Run("should return 200 with new tokens on success", func(t *testing.T) {
 		userId := uuid.New()
 		deviceId := uuid.New()
+		router := refreshRouter(userId, deviceId, "valid-token")
 
-		router := gin.Default()
-		router.GET("/refresh-token", func(c *gin.Context) {
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_REFRESH_TOKEN, Value: "valid-token"})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: deviceId.String()})
-			c.Request.AddCookie(&http.Cookie{Name: constants.COOKIE_USER_ID, Value: userId.String()})
-
-			authHandler.RefreshToken(c)
-		})
-
-		mockAuthService.EXPECT().VerifyRefreshToken(gomock.Any(), userId, deviceId, "valid-token").Return(nil)
-		mockAuthService.EXPECT().GenerateToken(userId).Return("new-access-token", nil)
-		mockAuthService.EXPECT().DeleteRefreshToken(gomock.Any(), userId, deviceId).Return(nil)
-		mockAuthService.EXPECT().GenerateRefreshToken().Return("new-refresh-token", "hashed-token", nil)
-		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), userId, deviceId, "hashed-token").Return(nil)
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("new-access-token", nil)
+		firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+		mockAuthService.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, "valid-token", models.SessionMetadata{
+			DeviceLabel: "Firefox on Linux",
+			UserAgent:   firefox,
+			IP:          "203.0.113.7",
+		}).Return("new-refresh-token", nil)
 
 		req, _ := http.NewRequest(http.MethodGet, "/refresh-token", nil)
+		req.Header.Set("User-Agent", firefox)
+		req.RemoteAddr = "203.0.113.7:51234"
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -396,7 +377,7 @@ func TestGetAuth(t *testing.T) {
 
 	mockAuthService := mock_services.NewMockIAuthService(ctrl)
 	mockUserService := mock_services.NewMockIUserService(ctrl)
-	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
 
 	t.Run("should return 400 if authenticatedUserId is missing", func(t *testing.T) {
 		router := gin.Default()
@@ -489,6 +470,28 @@ func TestGetAuth(t *testing.T) {
 		assert.JSONEq(t, expectedJSON, w.Body.String())
 	})
 
+	t.Run("should show who impersonates the user", func(t *testing.T) {
+		userId := uuid.New()
+		actorId := uuid.New()
+		expiresAt := time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)
+
+		router := gin.Default()
+		router.GET("/get-auth", func(c *gin.Context) {
+			c.Set("authenticatedUserId", userId)
+			c.Set("impersonation", &services.TokenPayload{UserId: userId, ActorId: actorId, ExpiresAt: expiresAt})
+			authHandler.GetAuth(c)
+		})
+
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(&models.User{ID: userId}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/get-auth", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"impersonation":{"actor_id":"`+actorId.String()+`","expires_at":"2026-03-02T09:15:00Z"}`)
+	})
 }
 
 func TestLogin(t *testing.T) {
@@ -497,8 +500,10 @@ func TestLogin(t *testing.T) {
 
 	mockAuthService := mock_services.NewMockIAuthService(ctrl)
 	mockUserService := mock_services.NewMockIUserService(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	mockLockoutService := mock_services.NewMockILockoutService(ctrl)
 
-	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, mockMfaService, mockLockoutService)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
@@ -519,10 +524,15 @@ func TestLogin(t *testing.T) {
 		}
 
 		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
 		mockAuthService.EXPECT().VerifyPassword("hashed_password", "password123").Return(true)
-		mockAuthService.EXPECT().GenerateToken(userID).Return("test_token", nil)
+		mockLockoutService.EXPECT().RecordSuccess(gomock.Any(), existingUser).Return(nil)
+		mockAuthService.EXPECT().UpgradePasswordHash(gomock.Any(), existingUser, "password123").Return(nil)
+		mockAuthService.EXPECT().EnsureEmailVerified(existingUser).Return(nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), userID).Return(false, nil)
+		mockAuthService.EXPECT().GenerateToken(userID, gomock.Any()).Return("test_token", nil)
 		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
-		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), userID, gomock.Any(), "hashed_refresh_token").Return(nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), userID, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
 
 		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
 		w := httptest.NewRecorder()
@@ -532,7 +542,33 @@ func TestLogin(t *testing.T) {
 		assert.Contains(t, w.Body.String(), "Bearer test_token")
 	})
 
-	t.Run("should return 401 if password is incorrect", func(t *testing.T) {
+	t.Run("should return an mfa ticket instead of tokens when two-factor is enabled", func(t *testing.T) {
+		userID := uuid.New()
+		existingUser := &models.User{
+			ID:       userID,
+			Email:    "test@example.com",
+			Password: "hashed_password",
+		}
+
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
+		mockAuthService.EXPECT().VerifyPassword("hashed_password", "password123").Return(true)
+		mockLockoutService.EXPECT().RecordSuccess(gomock.Any(), existingUser).Return(nil)
+		mockAuthService.EXPECT().UpgradePasswordHash(gomock.Any(), existingUser, "password123").Return(nil)
+		mockAuthService.EXPECT().EnsureEmailVerified(existingUser).Return(nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), userID).Return(true, nil)
+		mockMfaService.EXPECT().CreateTicket(gomock.Any(), userID).Return("mfa_ticket", nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"mfa_required": true, "mfa_ticket": "mfa_ticket"}`, w.Body.String())
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("should return 401 and count the failure if password is incorrect", func(t *testing.T) {
 		existingUser := &models.User{
 			ID:       uuid.New(),
 			Email:    "test@example.com",
@@ -540,24 +576,317 @@ func TestLogin(t *testing.T) {
 		}
 
 		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
 		mockAuthService.EXPECT().VerifyPassword("hashed_password", "password123").Return(false)
+		mockLockoutService.EXPECT().RecordFailure(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
 
 		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
-		assert.Contains(t, w.Body.String(), "wrong password")
+		assert.JSONEq(t, `{"error": "invalid credentials"}`, w.Body.String())
 	})
 
-	t.Run("should return 404 if user is not found", func(t *testing.T) {
-		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(nil, errors.New("user not found"))
+	t.Run("should answer a password-less google account like a wrong password", func(t *testing.T) {
+		existingUser := &models.User{
+			ID:       uuid.New(),
+			Email:    "test@example.com",
+			Provider: "google",
+		}
+
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
+		mockAuthService.EXPECT().VerifyPassword("", "password123").Return(false)
+		mockLockoutService.EXPECT().RecordFailure(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
 
 		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
 		w := httptest.NewRecorder()
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
-		assert.Contains(t, w.Body.String(), "user not found")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "invalid credentials"}`, w.Body.String())
+	})
+
+	t.Run("should return 403 if email is not verified", func(t *testing.T) {
+		existingUser := &models.User{
+			ID:       uuid.New(),
+			Email:    "test@example.com",
+			Password: "hashed_password",
+			Provider: "credentials",
+		}
+
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).Return(nil)
+		mockAuthService.EXPECT().VerifyPassword("hashed_password", "password123").Return(true)
+		mockLockoutService.EXPECT().RecordSuccess(gomock.Any(), existingUser).Return(nil)
+		mockAuthService.EXPECT().UpgradePasswordHash(gomock.Any(), existingUser, "password123").Return(nil)
+		mockAuthService.EXPECT().EnsureEmailVerified(existingUser).Return(services.ErrEmailNotVerified)
+
+		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "Please verify your email before logging in"}`, w.Body.String())
+	})
+
+	t.Run("should answer an unknown user like a wrong password after checking a password", func(t *testing.T) {
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(nil, services.ErrUserNotFound)
+		mockLockoutService.EXPECT().Check(gomock.Any(), nil, "test@example.com", gomock.Any()).Return(nil)
+		mockAuthService.EXPECT().VerifyPassword("", "password123").Return(false)
+		mockLockoutService.EXPECT().RecordFailure(gomock.Any(), nil, "test@example.com", gomock.Any()).Return(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "invalid credentials"}`, w.Body.String())
+	})
+
+	t.Run("should return 429 with Retry-After without checking the password while throttled", func(t *testing.T) {
+		existingUser := &models.User{ID: uuid.New(), Email: "test@example.com", Password: "hashed_password"}
+
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(existingUser, nil)
+		mockLockoutService.EXPECT().Check(gomock.Any(), existingUser, "test@example.com", gomock.Any()).
+			Return(&services.LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+
+		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error": "Too many failed login attempts, please try again later"}`, w.Body.String())
+	})
+
+	t.Run("should return 429 while the account is locked", func(t *testing.T) {
+		mockAuthService.EXPECT().GetUserByIdentity(gomock.Any(), "test@example.com").Return(nil, services.ErrUserNotFound)
+		mockLockoutService.EXPECT().Check(gomock.Any(), nil, "test@example.com", gomock.Any()).
+			Return(&services.LoginThrottledError{RetryAfter: 30 * time.Minute, Locked: true})
+
+		req, _ := http.NewRequest(http.MethodPost, "/login", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "1800", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "temporarily locked")
+	})
+}
+
+func TestUnlockAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLockoutService := mock_services.NewMockILockoutService(ctrl)
+	authHandler := handlers.NewAuthHandler(nil, nil, nil, mockLockoutService)
+
+	router := gin.Default()
+	router.GET("/unlock", authHandler.UnlockAccount)
+
+	t.Run("it should return 400 without a token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/unlock", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("it should return 400 for an invalid link", func(t *testing.T) {
+		mockLockoutService.EXPECT().Unlock(gomock.Any(), "token").Return(services.ErrInvalidUnlockToken)
+
+		req, _ := http.NewRequest(http.MethodGet, "/unlock?token=token", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "invalid or expired unlock link"}`, w.Body.String())
+	})
+
+	t.Run("it should unlock the account", func(t *testing.T) {
+		mockLockoutService.EXPECT().Unlock(gomock.Any(), "token").Return(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/unlock?token=token", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
+
+	router := gin.Default()
+	router.GET("/email-verification", authHandler.VerifyEmail)
+	router.POST("/email-verification", func(c *gin.Context) {
+		c.Set("validatedBody", dto.VerifyEmail{Token: "body-token"})
+		authHandler.VerifyEmail(c)
+	})
+
+	t.Run("should return 400 if token is missing", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/email-verification", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "token is required"}`, w.Body.String())
+	})
+
+	t.Run("should return 400 if token is invalid or expired", func(t *testing.T) {
+		mockAuthService.EXPECT().VerifyEmail(gomock.Any(), "stale-token").Return(nil, services.ErrInvalidVerificationToken)
+
+		req, _ := http.NewRequest(http.MethodGet, "/email-verification?token=stale-token", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "invalid or expired verification token"}`, w.Body.String())
+	})
+
+	t.Run("should return 200 when verifying with the query token", func(t *testing.T) {
+		mockAuthService.EXPECT().VerifyEmail(gomock.Any(), "query-token").Return(&models.User{ID: uuid.New()}, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/email-verification?token=query-token", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Your email has been verified")
+	})
+
+	t.Run("should return 200 when verifying with the body token", func(t *testing.T) {
+		mockAuthService.EXPECT().VerifyEmail(gomock.Any(), "body-token").Return(&models.User{ID: uuid.New()}, nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/email-verification", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
+
+	router := gin.Default()
+	router.POST("/email-verification/resend", func(c *gin.Context) {
+		c.Set("validatedBody", dto.ResendVerificationEmail{Email: "john@example.com"})
+		authHandler.ResendVerificationEmail(c)
+	})
+
+	t.Run("should return 429 when throttled", func(t *testing.T) {
+		mockAuthService.EXPECT().ResendVerificationEmail(gomock.Any(), "john@example.com").Return(services.ErrTooManyRequests)
+
+		req, _ := http.NewRequest(http.MethodPost, "/email-verification/resend", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("should return 200 with a generic message", func(t *testing.T) {
+		mockAuthService.EXPECT().ResendVerificationEmail(gomock.Any(), "john@example.com").Return(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/email-verification/resend", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "If john@example.com belongs to an unverified account")
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
+
+	router := gin.Default()
+	router.POST("/password/forgot", func(c *gin.Context) {
+		c.Set("validatedBody", dto.ForgotPassword{Email: "john@example.com"})
+		authHandler.ForgotPassword(c)
+	})
+
+	t.Run("should return the same response whether or not the email exists", func(t *testing.T) {
+		mockAuthService.EXPECT().RequestPasswordReset(gomock.Any(), "john@example.com").Return(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/password/forgot", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"message": "If john@example.com is registered, an email with instructions to reset your password has been sent."}`, w.Body.String())
+	})
+
+	t.Run("should return 429 when throttled", func(t *testing.T) {
+		mockAuthService.EXPECT().RequestPasswordReset(gomock.Any(), "john@example.com").Return(services.ErrTooManyRequests)
+
+		req, _ := http.NewRequest(http.MethodPost, "/password/forgot", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	authHandler := handlers.NewAuthHandler(mockAuthService, mockUserService, nil, nil)
+
+	router := gin.Default()
+	router.POST("/password/reset", func(c *gin.Context) {
+		c.Set("validatedBody", dto.ResetPassword{Token: "reset-token", Password: "NewPassword1"})
+		authHandler.ResetPassword(c)
+	})
+
+	t.Run("should return 400 if token is invalid", func(t *testing.T) {
+		mockAuthService.EXPECT().ResetPassword(gomock.Any(), "reset-token", "NewPassword1").Return(services.ErrInvalidResetToken)
+
+		req, _ := http.NewRequest(http.MethodPost, "/password/reset", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "invalid or expired password reset token"}`, w.Body.String())
+	})
+
+	t.Run("should return 200 on success", func(t *testing.T) {
+		mockAuthService.EXPECT().ResetPassword(gomock.Any(), "reset-token", "NewPassword1").Return(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/password/reset", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
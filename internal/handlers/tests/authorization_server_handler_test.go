@@ -0,0 +1,323 @@
+package handlers_test
+
+import (
+	"errors"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationServerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServer := mock_services.NewMockIAuthorizationServer(ctrl)
+	handler := handlers.NewAuthorizationServerHandler(mockServer)
+	router := gin.Default()
+	router.POST("/oauth/token", handler.Token)
+
+	client := &models.OAuthClient{ID: uuid.New(), Name: "Reports", Type: "confidential"}
+	token := func(form url.Values, basic ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if len(basic) == 2 {
+			req.SetBasicAuth(url.QueryEscape(basic[0]), url.QueryEscape(basic[1]))
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should authenticate the client with HTTP Basic", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), client.ID.String(), "s3cret/+").Return(client, nil)
+		mockServer.EXPECT().Token(gomock.Any(), client, services.TokenRequest{GrantType: "client_credentials", Scope: "read"}, gomock.Any()).
+			Return(&dto.OAuthToken{AccessToken: "access-token", TokenType: "Bearer", ExpiresIn: 900, Scope: "read"}, nil)
+
+		w := token(url.Values{"grant_type": {"client_credentials"}, "scope": {"read"}}, client.ID.String(), "s3cret/+")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, `{"access_token":"access-token","token_type":"Bearer","expires_in":900,"scope":"read"}`, w.Body.String())
+	})
+
+	t.Run("it should pass the authorization code grant on with credentials from the form", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), client.ID.String(), "").Return(client, nil)
+		mockServer.EXPECT().Token(gomock.Any(), client, services.TokenRequest{
+			GrantType:    "authorization_code",
+			Code:         "code",
+			RedirectURI:  "https://dashboard.example.com/callback",
+			CodeVerifier: "verifier",
+		}, gomock.Any()).Return(&dto.OAuthToken{AccessToken: "access-token", TokenType: "Bearer", RefreshToken: "refresh-token"}, nil)
+
+		w := token(url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {client.ID.String()},
+			"code":          {"code"},
+			"redirect_uri":  {"https://dashboard.example.com/callback"},
+			"code_verifier": {"verifier"},
+		})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"refresh_token":"refresh-token"`)
+	})
+
+	t.Run("it should answer 401 invalid_client for bad client credentials", func(t *testing.T) {
+		w := token(url.Values{"grant_type": {"client_credentials"}})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_client","error_description":"client authentication failed"}`, w.Body.String())
+
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), client.ID.String(), "guess").Return(nil, &services.OAuthError{Code: "invalid_client", Description: "client authentication failed"})
+		w = token(url.Values{"grant_type": {"client_credentials"}}, client.ID.String(), "guess")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("it should answer 400 with the error of a failed grant", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(client, nil)
+		mockServer.EXPECT().Token(gomock.Any(), client, gomock.Any(), gomock.Any()).Return(nil, &services.OAuthError{Code: "invalid_grant", Description: "invalid or expired authorization code"})
+
+		w := token(url.Values{"grant_type": {"authorization_code"}, "client_id": {client.ID.String()}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_grant","error_description":"invalid or expired authorization code"}`, w.Body.String())
+	})
+
+	t.Run("it should not leak internal errors", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		w := token(url.Values{"grant_type": {"client_credentials"}, "client_id": {client.ID.String()}})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "server_error")
+		assert.NotContains(t, w.Body.String(), "connection refused")
+	})
+}
+
+func TestAuthorizationServerAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServer := mock_services.NewMockIAuthorizationServer(ctrl)
+	handler := handlers.NewAuthorizationServerHandler(mockServer)
+	userId := uuid.New()
+	body := dto.OAuthAuthorize{ResponseType: "code", ClientId: uuid.NewString(), RedirectURI: "https://dashboard.example.com/callback"}
+
+	router := gin.Default()
+	router.POST("/oauth/authorize", func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Set("validatedBody", body)
+		c.Next()
+	}, handler.Authorize)
+
+	authorize := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/oauth/authorize", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should answer where to send the browser", func(t *testing.T) {
+		mockServer.EXPECT().Authorize(gomock.Any(), userId, body).Return("https://dashboard.example.com/callback?code=abc", nil)
+
+		w := authorize()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"redirect_to":"https://dashboard.example.com/callback?code=abc"}`, w.Body.String())
+	})
+
+	t.Run("it should answer 400 when the browser must not be sent back", func(t *testing.T) {
+		mockServer.EXPECT().Authorize(gomock.Any(), userId, body).Return("", &services.OAuthError{Code: "invalid_request", Description: "unknown client"})
+
+		w := authorize()
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_request","error_description":"unknown client"}`, w.Body.String())
+	})
+}
+
+func TestOAuthClientAdministration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServer := mock_services.NewMockIAuthorizationServer(ctrl)
+	handler := handlers.NewAuthorizationServerHandler(mockServer)
+	var body dto.CreateOAuthClient
+
+	router := gin.Default()
+	router.POST("/oauth/clients", func(c *gin.Context) {
+		c.Set("validatedBody", body)
+		c.Next()
+	}, handler.RegisterClient)
+	router.DELETE("/oauth/clients/:clientId", handler.DeleteClient)
+
+	request := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should return the secret once on register", func(t *testing.T) {
+		body = dto.CreateOAuthClient{Name: "Reports", Type: "confidential", Scopes: []string{"read"}, GrantTypes: []string{"client_credentials"}}
+		client := &models.OAuthClient{ID: uuid.New(), Name: "Reports", Type: "confidential", SecretHash: "hash-secret", Scopes: []string{"read"}}
+		mockServer.EXPECT().RegisterClient(gomock.Any(), body).Return(client, "s3cret", nil)
+
+		w := request(http.MethodPost, "/oauth/clients")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"client_secret":"s3cret"`)
+		assert.Contains(t, w.Body.String(), `"client_id":"`+client.ID.String()+`"`)
+		assert.NotContains(t, w.Body.String(), "hash-secret")
+	})
+
+	t.Run("it should reject inconsistent client metadata", func(t *testing.T) {
+		mockServer.EXPECT().RegisterClient(gomock.Any(), gomock.Any()).Return(nil, "", &services.OAuthError{Code: "invalid_client_metadata", Description: "public clients cannot use the client_credentials grant"})
+
+		w := request(http.MethodPost, "/oauth/clients")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("it should return 404 when deleting an unknown client", func(t *testing.T) {
+		clientId := uuid.New()
+		mockServer.EXPECT().DeleteClient(gomock.Any(), clientId).Return(services.ErrOAuthClientNotFound)
+
+		w := request(http.MethodDelete, "/oauth/clients/"+clientId.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAuthorizationServerIntrospectAndRevoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServer := mock_services.NewMockIAuthorizationServer(ctrl)
+	handler := handlers.NewAuthorizationServerHandler(mockServer)
+	router := gin.Default()
+	router.POST("/oauth/introspect", handler.Introspect)
+	router.POST("/oauth/revoke", handler.Revoke)
+
+	resourceServer := &models.OAuthClient{ID: uuid.New(), Type: "confidential"}
+	spa := &models.OAuthClient{ID: uuid.New(), Type: "public"}
+	post := func(path string, form url.Values, internalKey string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if internalKey != "" {
+			req.Header.Set("X-Internal-API-Key", internalKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should introspect for an internal service", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateInternalKey("internal-key").Return(true)
+		mockServer.EXPECT().Introspect(gomock.Any(), nil, "token", "access_token").Return(&dto.OAuthIntrospection{Active: true, Subject: "user"}, nil)
+
+		w := post("/oauth/introspect", url.Values{"token": {"token"}, "token_type_hint": {"access_token"}}, "internal-key")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"active":true,"sub":"user"}`, w.Body.String())
+	})
+
+	t.Run("it should answer inactive tokens with active false only", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), resourceServer.ID.String(), "secret").Return(resourceServer, nil)
+		mockServer.EXPECT().Introspect(gomock.Any(), resourceServer, "token", "").Return(&dto.OAuthIntrospection{Active: false}, nil)
+
+		w := post("/oauth/introspect", url.Values{"token": {"token"}, "client_id": {resourceServer.ID.String()}, "client_secret": {"secret"}}, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"active":false}`, w.Body.String())
+	})
+
+	t.Run("it should reject callers without valid credentials", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateInternalKey("guess").Return(false)
+		assert.Equal(t, http.StatusUnauthorized, post("/oauth/introspect", url.Values{"token": {"token"}}, "guess").Code)
+		assert.Equal(t, http.StatusUnauthorized, post("/oauth/revoke", url.Values{"token": {"token"}}, "").Code)
+	})
+
+	t.Run("it should not let public clients introspect", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), spa.ID.String(), "").Return(spa, nil)
+		w := post("/oauth/introspect", url.Values{"token": {"token"}, "client_id": {spa.ID.String()}}, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("it should let a public client revoke its token", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), spa.ID.String(), "").Return(spa, nil)
+		mockServer.EXPECT().Revoke(gomock.Any(), spa, "refresh-token", "refresh_token").Return(nil)
+
+		w := post("/oauth/revoke", url.Values{"token": {"refresh-token"}, "token_type_hint": {"refresh_token"}, "client_id": {spa.ID.String()}}, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("it should need the token", func(t *testing.T) {
+		mockServer.EXPECT().AuthenticateClient(gomock.Any(), spa.ID.String(), "").Return(spa, nil)
+		w := post("/oauth/revoke", url.Values{"client_id": {spa.ID.String()}}, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_request","error_description":"token is required"}`, w.Body.String())
+	})
+}
+
+func TestOpenIDUserInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockServer := mock_services.NewMockIAuthorizationServer(ctrl)
+	handler := handlers.NewAuthorizationServerHandler(mockServer)
+	userId := uuid.New()
+	var scope any
+
+	router := gin.Default()
+	router.GET("/userinfo", func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		if scope != nil {
+			c.Set("oauthScope", scope)
+		}
+		c.Next()
+	}, handler.UserInfo)
+	userInfo := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/userinfo", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should pass on the scope of a client's token", func(t *testing.T) {
+		scope = "openid profile"
+		mockServer.EXPECT().UserInfo(gomock.Any(), userId, "openid profile").
+			Return(&dto.UserInfo{Subject: userId.String(), UserClaims: utils.UserClaims{Name: "John Doe", PreferredUsername: "john"}}, nil)
+
+		w := userInfo()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"sub":"`+userId.String()+`","name":"John Doe","preferred_username":"john"}`, w.Body.String())
+	})
+
+	t.Run("it should show the user's own session all of their claims", func(t *testing.T) {
+		scope = nil
+		mockServer.EXPECT().UserInfo(gomock.Any(), userId, "openid profile email").Return(&dto.UserInfo{Subject: userId.String()}, nil)
+		assert.Equal(t, http.StatusOK, userInfo().Code)
+	})
+
+	t.Run("it should answer 401 invalid_token for users that no longer exist", func(t *testing.T) {
+		scope = "openid"
+		mockServer.EXPECT().UserInfo(gomock.Any(), userId, "openid").Return(nil, &services.OAuthError{Code: "invalid_token", Description: "the user of the token no longer exists"})
+
+		w := userInfo()
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	})
+}
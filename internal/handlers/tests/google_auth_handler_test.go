@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"fmt"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoogleLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGoogleAuthService := mock_services.NewMockIGoogleAuthService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	googleAuthHandler := handlers.NewGoogleAuthHandler(mockGoogleAuthService, mockAuthService, mockMfaService)
+
+	router := gin.Default()
+	router.POST("/google", func(c *gin.Context) {
+		c.Set("validatedBody", dto.GoogleLogin{IdToken: "id-token"})
+		googleAuthHandler.Login(c)
+	})
+
+	t.Run("should return 401 if the id token is invalid", func(t *testing.T) {
+		mockGoogleAuthService.EXPECT().Authenticate(gomock.Any(), "id-token").Return(nil, fmt.Errorf("%w: expired", utils.ErrInvalidIDToken))
+
+		req, _ := http.NewRequest(http.MethodPost, "/google", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "invalid google id token"}`, w.Body.String())
+	})
+
+	t.Run("should return 403 if google has not verified the email", func(t *testing.T) {
+		mockGoogleAuthService.EXPECT().Authenticate(gomock.Any(), "id-token").Return(nil, services.ErrExternalEmailNotVerified)
+
+		req, _ := http.NewRequest(http.MethodPost, "/google", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should return 409 if an unverified account already uses the email", func(t *testing.T) {
+		mockGoogleAuthService.EXPECT().Authenticate(gomock.Any(), "id-token").Return(nil, services.ErrExternalAccountUnverified)
+
+		req, _ := http.NewRequest(http.MethodPost, "/google", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should return 200 with the same tokens and cookies as a password login", func(t *testing.T) {
+		user := &models.User{ID: uuid.New(), Email: "john@example.com", Provider: "google"}
+		mockGoogleAuthService.EXPECT().Authenticate(gomock.Any(), "id-token").Return(user, nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), user.ID).Return(false, nil)
+		mockAuthService.EXPECT().GenerateToken(user.ID, gomock.Any()).Return("test_token", nil)
+		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), user.ID, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/google", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+		assert.Len(t, w.Result().Cookies(), 3)
+	})
+}
@@ -0,0 +1,109 @@
+package handlers_test
+
+import (
+	"errors"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_services.NewMockIImpersonationService(ctrl)
+	handler := handlers.NewImpersonationHandler(mockService)
+
+	adminId := uuid.New()
+	userId := uuid.New()
+	var impersonation *services.TokenPayload
+	router := gin.Default()
+	router.POST("/users/:userId/impersonate", func(c *gin.Context) {
+		c.Set("authenticatedUserId", adminId)
+		c.Set("validatedBody", dto.StartImpersonation{Reason: "ticket #42"})
+		c.Next()
+	}, handler.Start)
+	router.POST("/impersonation/stop", func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		if impersonation != nil {
+			c.Set("impersonation", impersonation)
+		}
+		c.Next()
+	}, handler.Stop)
+	router.GET("/audit-log", handler.AuditLog)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should answer with a token for the user", func(t *testing.T) {
+		expiresAt := time.Date(2026, 3, 2, 9, 15, 0, 0, time.UTC)
+		mockService.EXPECT().Start(gomock.Any(), adminId, userId, "ticket #42", gomock.Any()).
+			Return(&services.Impersonation{AccessToken: "impersonation-token", User: &models.User{ID: userId}, ExpiresAt: expiresAt}, nil)
+
+		w := request(http.MethodPost, "/users/"+userId.String()+"/impersonate")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"token":"Bearer impersonation-token"`)
+		assert.Contains(t, w.Body.String(), `"expires_at":"2026-03-02T09:15:00Z"`)
+	})
+
+	t.Run("it should answer the status of each impersonation error", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{services.ErrUserNotFound, http.StatusNotFound},
+			{services.ErrImpersonateSelf, http.StatusBadRequest},
+			{services.ErrImpersonateAdmin, http.StatusForbidden},
+			{services.ErrImpersonateAbove, http.StatusForbidden},
+			{errors.New("db down"), http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			mockService.EXPECT().Start(gomock.Any(), adminId, userId, "ticket #42", gomock.Any()).Return(nil, tt.err)
+			assert.Equal(t, tt.status, request(http.MethodPost, "/users/"+userId.String()+"/impersonate").Code)
+		}
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/users/nobody/impersonate").Code)
+	})
+
+	t.Run("it should stop the impersonation of the request", func(t *testing.T) {
+		impersonation = &services.TokenPayload{UserId: userId, ActorId: adminId, Jti: uuid.New()}
+		mockService.EXPECT().Stop(gomock.Any(), impersonation, gomock.Any()).Return(nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodPost, "/impersonation/stop").Code)
+
+		impersonation = nil
+		mockService.EXPECT().Stop(gomock.Any(), nil, gomock.Any()).Return(services.ErrNotImpersonating)
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/impersonation/stop").Code)
+	})
+
+	t.Run("it should list the audit log", func(t *testing.T) {
+		mockService.EXPECT().AuditLog(gomock.Any(), 10).Return([]models.AuditLog{{
+			ID:       1,
+			ActorId:  adminId,
+			Action:   services.AuditImpersonationStart,
+			TargetId: &userId,
+			Details:  map[string]any{"reason": "ticket #42"},
+		}}, nil)
+
+		w := request(http.MethodGet, "/audit-log?limit=10")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"action":"impersonation.start","target_id":"`+userId.String()+`","details":{"reason":"ticket #42"}`)
+
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodGet, "/audit-log?limit=1000").Code)
+	})
+}
@@ -0,0 +1,42 @@
+package handlers_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := utils.NewKeyring("test-key", key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.Default()
+	router.GET("/.well-known/jwks.json", handlers.NewJWKSHandler(keyring).Get)
+
+	t.Run("it should publish the public keys without the private parts", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Cache-Control"), "max-age")
+		keys, err := utils.ParseJWKS(w.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, key.Public(), keys["test-key"])
+		assert.NotContains(t, w.Body.String(), `"d"`)
+	})
+}
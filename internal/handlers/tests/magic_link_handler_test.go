@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagicLinkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMagicLinkService := mock_services.NewMockIMagicLinkService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	magicLinkHandler := handlers.NewMagicLinkHandler(mockMagicLinkService, mockAuthService, mockMfaService, 15*time.Minute)
+
+	router := gin.Default()
+	router.POST("/magic-link", func(c *gin.Context) {
+		c.Set("validatedBody", dto.MagicLink{Email: "john@example.com"})
+		c.Next()
+	}, magicLinkHandler.Request)
+	router.GET("/magic-link/consume", magicLinkHandler.Consume)
+
+	cookie := func(w *httptest.ResponseRecorder, name string) *http.Cookie {
+		for _, c := range w.Result().Cookies() {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+
+	t.Run("should bind the link to the browser with a cookie", func(t *testing.T) {
+		mockMagicLinkService.EXPECT().RequestLink(gomock.Any(), "john@example.com").Return("binding", nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "/magic-link", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		binding := cookie(w, constants.COOKIE_MAGIC_LINK)
+		if assert.NotNil(t, binding) {
+			assert.Equal(t, "binding", binding.Value)
+			assert.True(t, binding.HttpOnly)
+			assert.Equal(t, 900, binding.MaxAge)
+		}
+	})
+
+	t.Run("should return 429 when requested too often", func(t *testing.T) {
+		mockMagicLinkService.EXPECT().RequestLink(gomock.Any(), "john@example.com").Return("", services.ErrTooManyRequests)
+
+		req, _ := http.NewRequest(http.MethodPost, "/magic-link", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("should exchange the link for a session", func(t *testing.T) {
+		userId := uuid.New()
+		user := &models.User{ID: userId, Email: "john@example.com"}
+		mockMagicLinkService.EXPECT().ConsumeLink(gomock.Any(), "token", "binding").Return(user, nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), userId).Return(false, nil)
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("test_token", nil)
+		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), userId, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/magic-link/consume?token=token", nil)
+		req.AddCookie(&http.Cookie{Name: constants.COOKIE_MAGIC_LINK, Value: "binding"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+		assert.NotNil(t, cookie(w, constants.COOKIE_REFRESH_TOKEN))
+		assert.NotNil(t, cookie(w, constants.COOKIE_DEVICE_ID))
+	})
+
+	t.Run("should return 401 for an invalid link", func(t *testing.T) {
+		mockMagicLinkService.EXPECT().ConsumeLink(gomock.Any(), "token", "").Return(nil, services.ErrInvalidMagicLink)
+
+		req, _ := http.NewRequest(http.MethodGet, "/magic-link/consume?token=token", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Nil(t, cookie(w, constants.COOKIE_REFRESH_TOKEN))
+	})
+}
@@ -0,0 +1,124 @@
+package handlers_test
+
+import (
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMfaHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	mfaHandler := handlers.NewMfaHandler(mockMfaService, mockAuthService, mockUserService)
+
+	userId := uuid.New()
+	user := &models.User{ID: userId, Email: "john@example.com"}
+
+	router := gin.Default()
+	authenticated := func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}
+	router.POST("/mfa/totp", authenticated, mfaHandler.Enroll)
+	router.POST("/mfa/totp/confirm", authenticated, func(c *gin.Context) {
+		c.Set("validatedBody", dto.MfaCode{Code: "123456"})
+		c.Next()
+	}, mfaHandler.ConfirmEnrollment)
+	router.POST("/mfa/totp/disable", authenticated, func(c *gin.Context) {
+		c.Set("validatedBody", dto.MfaCode{Code: "123456"})
+		c.Next()
+	}, mfaHandler.Disable)
+	router.POST("/mfa/verify", func(c *gin.Context) {
+		c.Set("validatedBody", dto.VerifyMfa{Ticket: "ticket", Code: "123456"})
+		c.Next()
+	}, mfaHandler.Verify)
+
+	request := func(url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should return the secret and otpauth uri of a new enrollment", func(t *testing.T) {
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(user, nil)
+		mockMfaService.EXPECT().StartEnrollment(gomock.Any(), user).Return(&services.MfaEnrollment{Secret: "SECRET", URI: "otpauth://totp/x"}, nil)
+
+		w := request("/mfa/totp")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"secret": "SECRET", "otpauth_uri": "otpauth://totp/x"}`, w.Body.String())
+	})
+
+	t.Run("should return 409 when two-factor is already enabled", func(t *testing.T) {
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(user, nil)
+		mockMfaService.EXPECT().StartEnrollment(gomock.Any(), user).Return(nil, services.ErrMfaAlreadyEnabled)
+
+		w := request("/mfa/totp")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should return the recovery codes once enrollment is confirmed", func(t *testing.T) {
+		mockMfaService.EXPECT().ConfirmEnrollment(gomock.Any(), userId, "123456").Return([]string{"aaaaa-bbbbb"}, nil)
+
+		w := request("/mfa/totp/confirm")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"recovery_codes": ["aaaaa-bbbbb"]}`, w.Body.String())
+	})
+
+	t.Run("should return 400 for a wrong confirmation code", func(t *testing.T) {
+		mockMfaService.EXPECT().ConfirmEnrollment(gomock.Any(), userId, "123456").Return(nil, services.ErrInvalidMfaCode)
+
+		w := request("/mfa/totp/confirm")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 429 once too many codes were tried", func(t *testing.T) {
+		mockMfaService.EXPECT().Disable(gomock.Any(), userId, "123456").Return(services.ErrTooManyMfaCodes)
+
+		w := request("/mfa/totp/disable")
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("should start the session after a valid second factor", func(t *testing.T) {
+		mockMfaService.EXPECT().RedeemTicket(gomock.Any(), "ticket", "123456").Return(userId, nil)
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(user, nil)
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("test_token", nil)
+		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), userId, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
+
+		w := request("/mfa/verify")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+	})
+
+	t.Run("should return 401 for an invalid ticket or code", func(t *testing.T) {
+		mockMfaService.EXPECT().RedeemTicket(gomock.Any(), "ticket", "123456").Return(uuid.Nil, services.ErrInvalidMfaCode)
+
+		w := request("/mfa/verify")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+}
@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"fmt"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthStart(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOAuthService := mock_services.NewMockIOAuthService(ctrl)
+	oauthHandler := handlers.NewOAuthHandler(mockOAuthService, mock_services.NewMockIAuthService(ctrl), nil, 10*time.Minute)
+
+	router := gin.Default()
+	router.GET("/oauth/:provider/start", oauthHandler.Start)
+
+	t.Run("should redirect to the provider authorization url", func(t *testing.T) {
+		mockOAuthService.EXPECT().Start(gomock.Any(), "okta").Return("https://idp.example.com/authorize?state=abc", "binding", nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://idp.example.com/authorize?state=abc", w.Header().Get("Location"))
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, constants.COOKIE_OAUTH_STATE, cookies[0].Name)
+		assert.Equal(t, "binding", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+		assert.Equal(t, 600, cookies[0].MaxAge)
+	})
+
+	t.Run("should return 404 for an unknown provider", func(t *testing.T) {
+		mockOAuthService.EXPECT().Start(gomock.Any(), "nope").Return("", "", utils.ErrUnknownOAuthProvider)
+
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/nope/start", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestOAuthCallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOAuthService := mock_services.NewMockIOAuthService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	oauthHandler := handlers.NewOAuthHandler(mockOAuthService, mockAuthService, mockMfaService, 10*time.Minute)
+
+	router := gin.Default()
+	router.GET("/oauth/:provider/callback", oauthHandler.Callback)
+
+	t.Run("should return 400 if the provider reported an error", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/callback?error=access_denied", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "access_denied")
+	})
+
+	t.Run("should return 400 if code or state is missing", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/callback?code=abc", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error": "code and state are required"}`, w.Body.String())
+	})
+
+	t.Run("should return 400 if the state is invalid", func(t *testing.T) {
+		mockOAuthService.EXPECT().Callback(gomock.Any(), "okta", "abc", "xyz", "").Return(nil, services.ErrInvalidOAuthState)
+
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/callback?code=abc&state=xyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 401 if the id token is rejected", func(t *testing.T) {
+		mockOAuthService.EXPECT().Callback(gomock.Any(), "okta", "abc", "xyz", "").Return(nil, fmt.Errorf("%w: nonce mismatch", utils.ErrInvalidIDToken))
+
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/callback?code=abc&state=xyz", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "Authentication with the provider failed"}`, w.Body.String())
+	})
+
+	t.Run("should return 200 with tokens and cookies on success", func(t *testing.T) {
+		user := &models.User{ID: uuid.New(), Email: "jane@example.com", Provider: "oidc"}
+		mockOAuthService.EXPECT().Callback(gomock.Any(), "okta", "abc", "xyz", "binding").Return(user, nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), user.ID).Return(false, nil)
+		mockAuthService.EXPECT().GenerateToken(user.ID, gomock.Any()).Return("test_token", nil)
+		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), user.ID, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/oauth/okta/callback?code=abc&state=xyz", nil)
+		req.AddCookie(&http.Cookie{Name: constants.COOKIE_OAUTH_STATE, Value: "binding"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+		cookies := w.Result().Cookies()
+		assert.Len(t, cookies, 4)
+		assert.Equal(t, constants.COOKIE_OAUTH_STATE, cookies[0].Name)
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	})
+}
@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/policy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyHandlerEvaluate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPolicyService := mock_services.NewMockIPolicyService(ctrl)
+	policyHandler := handlers.NewPolicyHandler(mockPolicyService)
+
+	var body any
+	router := gin.Default()
+	router.POST("/policies/evaluate", func(c *gin.Context) {
+		c.Set("validatedBody", body)
+		c.Next()
+	}, policyHandler.Evaluate)
+
+	request := func() *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/policies/evaluate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should answer the decision for the attributes given", func(t *testing.T) {
+		userId := uuid.New()
+		at := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+		body = dto.EvaluatePolicy{
+			Subject:  dto.PolicySubject{UserId: userId, Role: "support", Org: "acme"},
+			Action:   "user:read",
+			Resource: dto.PolicyResource{Type: "user", Owner: uuid.New()},
+			Context:  dto.PolicyContext{IP: "10.0.0.1", Time: &at, MfaLevel: 2},
+		}
+		mockPolicyService.EXPECT().Evaluate(gomock.Any()).DoAndReturn(func(req policy.Request) policy.Decision {
+			assert.Equal(t, policy.Subject{UserId: userId, Role: "support", Org: "acme"}, req.Subject)
+			assert.Equal(t, policy.ReadUser, req.Action)
+			assert.Equal(t, policy.Context{IP: "10.0.0.1", Time: at, MfaLevel: 2}, req.Context)
+			return policy.Decision{Reason: "Support does not work after 18:00 UTC", Policy: "office-hours"}
+		})
+
+		w := request()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"allowed":false,"reason":"Support does not work after 18:00 UTC","policy":"office-hours"}`, w.Body.String())
+	})
+
+	t.Run("it should evaluate requests without a time for now", func(t *testing.T) {
+		body = dto.EvaluatePolicy{Action: "user:read", Resource: dto.PolicyResource{Type: "user"}}
+		mockPolicyService.EXPECT().Evaluate(gomock.Any()).DoAndReturn(func(req policy.Request) policy.Decision {
+			assert.WithinDuration(t, time.Now(), req.Context.Time, time.Second)
+			return policy.Decision{Allowed: true, Policy: "baseline"}
+		})
+
+		w := request()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"allowed":true,"policy":"baseline"}`, w.Body.String())
+	})
+
+	t.Run("it should return 400 without a validated body", func(t *testing.T) {
+		body = nil
+		router := gin.Default()
+		router.POST("/policies/evaluate", policyHandler.Evaluate)
+		req, _ := http.NewRequest(http.MethodPost, "/policies/evaluate", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
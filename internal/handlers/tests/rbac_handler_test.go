@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"errors"
+	"fmt"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRBACHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	rbacHandler := handlers.NewRBACHandler(mockRBACService)
+
+	var body any
+	withBody := func(c *gin.Context) {
+		c.Set("validatedBody", body)
+		c.Next()
+	}
+	router := gin.Default()
+	router.GET("/roles", rbacHandler.ListRoles)
+	router.POST("/roles", withBody, rbacHandler.CreateRole)
+	router.PUT("/roles/:role/permissions", withBody, rbacHandler.SetRolePermissions)
+	router.DELETE("/roles/:role", rbacHandler.DeleteRole)
+	router.PUT("/users/:userId/role", withBody, rbacHandler.AssignRole)
+
+	request := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should list roles with their permissions", func(t *testing.T) {
+		mockRBACService.EXPECT().ListRoles(gomock.Any()).Return([]models.Role{
+			{Name: "user"},
+			{Name: "admin", Permissions: []string{"users:read", "users:write"}},
+		}, nil)
+
+		w := request(http.MethodGet, "/roles")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `{"name":"user","description":"","permissions":[]`)
+		assert.Contains(t, w.Body.String(), `"permissions":["users:read","users:write"]`)
+	})
+
+	t.Run("it should create a role", func(t *testing.T) {
+		body = dto.CreateRole{Name: "support", Permissions: []string{"users:read"}}
+		mockRBACService.EXPECT().CreateRole(gomock.Any(), body).Return(&models.Role{Name: "support", Permissions: []string{"users:read"}}, nil)
+
+		w := request(http.MethodPost, "/roles")
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"support"`)
+	})
+
+	t.Run("it should answer the status of each RBAC error", func(t *testing.T) {
+		tests := []struct {
+			err    error
+			status int
+		}{
+			{services.ErrRoleNotFound, http.StatusNotFound},
+			{services.ErrRoleInUse, http.StatusConflict},
+			{services.ErrBuiltInRole, http.StatusForbidden},
+			{errors.New("db down"), http.StatusInternalServerError},
+		}
+		for _, tt := range tests {
+			mockRBACService.EXPECT().DeleteRole(gomock.Any(), "support").Return(tt.err)
+			assert.Equal(t, tt.status, request(http.MethodDelete, "/roles/support").Code, tt.err.Error())
+		}
+
+		body = dto.SetRolePermissions{Permissions: []string{"everything:write"}}
+		mockRBACService.EXPECT().SetRolePermissions(gomock.Any(), "user", []string{"everything:write"}).
+			Return(nil, fmt.Errorf("%w: everything:write", services.ErrUnknownPermission))
+		w := request(http.MethodPut, "/roles/user/permissions")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"unknown permission: everything:write"}`, w.Body.String())
+	})
+
+	t.Run("it should assign a role to a user", func(t *testing.T) {
+		userId := uuid.New()
+		body = dto.AssignRole{Role: "support"}
+		mockRBACService.EXPECT().AssignRole(gomock.Any(), userId, "support").Return(nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodPut, "/users/"+userId.String()+"/role").Code)
+
+		mockRBACService.EXPECT().AssignRole(gomock.Any(), userId, "support").Return(services.ErrLastAdmin)
+		assert.Equal(t, http.StatusConflict, request(http.MethodPut, "/users/"+userId.String()+"/role").Code)
+
+		assert.Equal(t, http.StatusBadRequest, request(http.MethodPut, "/users/not-a-uuid/role").Code)
+	})
+}
@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessionService := mock_services.NewMockISessionService(ctrl)
+	sessionHandler := handlers.NewSessionHandler(mockSessionService)
+
+	userId := uuid.New()
+	currentDevice := uuid.New()
+	otherDevice := uuid.New()
+
+	router := gin.Default()
+	authenticated := func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}
+	router.GET("/sessions", authenticated, sessionHandler.List)
+	router.DELETE("/sessions", authenticated, sessionHandler.RevokeAll)
+	router.DELETE("/sessions/:deviceId", authenticated, sessionHandler.Revoke)
+
+	request := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		req.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: currentDevice.String()})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should list the sessions and flag the current one", func(t *testing.T) {
+		now := time.Now().Truncate(time.Second)
+		mockSessionService.EXPECT().ListSessions(gomock.Any(), userId).Return([]models.Token{
+			{
+				Hash:            "secret-hash",
+				DeviceId:        currentDevice,
+				SessionMetadata: models.SessionMetadata{DeviceLabel: "Firefox on Linux", UserAgent: "Mozilla/5.0", IP: "203.0.113.7"},
+				CreatedAt:       now,
+				LastUsedAt:      now,
+			},
+			{DeviceId: otherDevice, SessionMetadata: models.SessionMetadata{DeviceLabel: "Safari on iOS"}},
+		}, nil)
+
+		w := request(http.MethodGet, "/sessions")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+		var body struct {
+			Sessions []dto.Session `json:"sessions"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		if assert.Len(t, body.Sessions, 2) {
+			assert.True(t, body.Sessions[0].Current)
+			assert.Equal(t, "Firefox on Linux", body.Sessions[0].DeviceLabel)
+			assert.Equal(t, "203.0.113.7", body.Sessions[0].IP)
+			assert.False(t, body.Sessions[1].Current)
+		}
+	})
+
+	t.Run("should return 400 for an invalid device id", func(t *testing.T) {
+		w := request(http.MethodDelete, "/sessions/not-a-uuid")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 404 when the device has no session", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeSession(gomock.Any(), userId, otherDevice).Return(services.ErrSessionNotFound)
+		w := request(http.MethodDelete, "/sessions/"+otherDevice.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should revoke another device without touching the cookies", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeSession(gomock.Any(), userId, otherDevice).Return(nil)
+		w := request(http.MethodDelete, "/sessions/"+otherDevice.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("should clear the cookies when revoking the current device", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeSession(gomock.Any(), userId, currentDevice).Return(nil)
+		w := request(http.MethodDelete, "/sessions/"+currentDevice.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, w.Result().Cookies(), 3)
+	})
+
+	t.Run("should revoke every other session with except=current", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeOtherSessions(gomock.Any(), userId, currentDevice).Return(nil)
+		w := request(http.MethodDelete, "/sessions?except=current")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("should revoke every session and clear the cookies without except", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeAllSessions(gomock.Any(), userId).Return(nil)
+		w := request(http.MethodDelete, "/sessions")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, w.Result().Cookies(), 3)
+	})
+
+	t.Run("should return 400 for an unsupported except value", func(t *testing.T) {
+		w := request(http.MethodDelete, "/sessions?except=mobile")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 500 when revoking fails", func(t *testing.T) {
+		mockSessionService.EXPECT().RevokeOtherSessions(gomock.Any(), userId, currentDevice).Return(errors.New("some errors"))
+		w := request(http.MethodDelete, "/sessions?except=current")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
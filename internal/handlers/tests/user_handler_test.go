@@ -2,12 +2,15 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"my-go-api/internal/constants"
 	"my-go-api/internal/handlers"
 	"my-go-api/internal/mocks/mock_services"
 	"my-go-api/internal/models"
+	"my-go-api/internal/services"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,20 +26,25 @@ func TestUpdateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_services.NewMockIUserService(ctrl)
-	handler := handlers.NewUserHandler(mockService)
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	mockRBACService.EXPECT().Permissions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	handler := handlers.NewUserHandler(mockService, mockRBACService, mockAuthService, nil)
+
+	validUserID := uuid.New()
 
 	router := gin.Default()
 	router.PUT("/user/:id", func(c *gin.Context) {
 		// Simulating middleware setting "validatedBody" before handler is called
+		c.Set("authenticatedUserId", validUserID)
 		c.Set("validatedBody", map[string]interface{}{
-			"name":  "Updated Name",
-			"email": "updated@example.com",
+			"name":             "Updated Name",
+			"email":            "updated@example.com",
+			"current_password": "0ldPassword",
 		})
 		handler.Update(c)
 	})
 
-	validUserID := uuid.New()
-
 	t.Run("should return 500 for invalid user ID", func(t *testing.T) {
 		req, _ := http.NewRequest(http.MethodPut, "/user/invalid-uuid", nil)
 		w := httptest.NewRecorder()
@@ -94,7 +102,11 @@ func TestUpdateUser(t *testing.T) {
 		}
 
 		mockService.EXPECT().GetUserById(gomock.Any(), validUserID).Return(existingUser, nil)
+		mockAuthService.EXPECT().VerifyPassword("", "0ldPassword").Return(true)
+		mockService.EXPECT().CheckEmailAvailable(gomock.Any(), validUserID, "updated@example.com").Return(nil)
 		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.AssignableToTypeOf(&models.User{}))
+		mockAuthService.EXPECT().CreateEmailVerificationToken(gomock.Any(), validUserID).Return("verification-token", nil)
+		mockAuthService.EXPECT().SendVerificationEmail("Updated Name", "updated@example.com", "verification-token").Return(nil)
 
 		reqBody, _ := json.Marshal(map[string]interface{}{
 			"name":  "Updated Name",
@@ -130,12 +142,18 @@ func TestGetUserById(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_services.NewMockIUserService(ctrl)
-	handler := handlers.NewUserHandler(mockService)
-
-	router := gin.Default()
-	router.GET("/user/:id", handler.GetUserById)
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	mockRBACService.EXPECT().Permissions(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	handler := handlers.NewUserHandler(mockService, mockRBACService, nil, nil)
 
 	userId := uuid.New()
+	actorId := userId
+
+	router := gin.Default()
+	router.GET("/user/:id", func(c *gin.Context) {
+		c.Set("authenticatedUserId", actorId)
+		c.Next()
+	}, handler.GetUserById)
 	mockUser := &models.User{ID: userId, Name: "John Doe", Email: "john@example.com"}
 
 	t.Run("should return 200 with user data", func(t *testing.T) {
@@ -166,6 +184,19 @@ func TestGetUserById(t *testing.T) {
 
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
+
+	t.Run("should return 403 for the account of someone else", func(t *testing.T) {
+		actorId = uuid.New()
+		defer func() { actorId = userId }()
+		mockService.EXPECT().GetUserById(gomock.Any(), userId).Return(mockUser, nil)
+
+		req, _ := http.NewRequest(http.MethodGet, "/user/"+userId.String(), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "you may only view your own account"}`, w.Body.String())
+	})
 }
 
 func TestGetAllUsers(t *testing.T) {
@@ -173,7 +204,7 @@ func TestGetAllUsers(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockService := mock_services.NewMockIUserService(ctrl)
-	handler := handlers.NewUserHandler(mockService)
+	handler := handlers.NewUserHandler(mockService, mock_services.NewMockIRBACService(ctrl), nil, nil)
 
 	router := gin.Default()
 	router.GET("/users", handler.GetAll)
@@ -193,3 +224,213 @@ func TestGetAllUsers(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
+
+func TestUpdateUserPolicies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_services.NewMockIUserService(ctrl)
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockSessionService := mock_services.NewMockISessionService(ctrl)
+	handler := handlers.NewUserHandler(mockService, mockRBACService, mockAuthService, mockSessionService)
+
+	owner := uuid.New()
+	admin := uuid.New()
+	stranger := uuid.New()
+	mockRBACService.EXPECT().Permissions(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userId uuid.UUID) ([]string, error) {
+		if userId == admin {
+			return []string{"users:read", "users:write", "roles:read", "roles:write"}, nil
+		}
+		return nil, nil
+	}).AnyTimes()
+
+	jti := uuid.New()
+	updateWith := func(actorId uuid.UUID, body map[string]any, context map[string]any) *httptest.ResponseRecorder {
+		router := gin.Default()
+		router.PUT("/user/:id", func(c *gin.Context) {
+			c.Set("authenticatedUserId", actorId)
+			c.Set("validatedBody", body)
+			for key, value := range context {
+				c.Set(key, value)
+			}
+			c.Next()
+		}, handler.Update)
+		req, _ := http.NewRequest(http.MethodPut, "/user/"+owner.String(), nil)
+		// the session to keep comes from the access token, never from a cookie
+		req.AddCookie(&http.Cookie{Name: constants.COOKIE_DEVICE_ID, Value: uuid.New().String()})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	update := func(actorId uuid.UUID, body map[string]any) *httptest.ResponseRecorder {
+		return updateWith(actorId, body, map[string]any{"accessTokenJti": jti})
+	}
+	verifiedAt := "2026-01-01T00:00:00Z"
+	existing := func() *models.User {
+		return &models.User{ID: owner, Name: "Owner", Email: "owner@example.com", Role: "user", Password: "stored-hash", EmailVerifiedAt: &verifiedAt}
+	}
+
+	t.Run("it should deny editing someone else with the reason", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		w := update(stranger, map[string]any{"name": "Taken Over"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "you may only edit your own account"}`, w.Body.String())
+	})
+
+	t.Run("it should not let users change their own role", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		w := update(owner, map[string]any{"name": "Owner Name", "role": "admin"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "only admins may change roles"}`, w.Body.String())
+	})
+
+	t.Run("it should let admins change the role through the role assignment", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockRBACService.EXPECT().AssignRole(gomock.Any(), owner, "admin").Return(nil)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			assert.Equal(t, "admin", user.Role)
+			return user, nil
+		})
+		assert.Equal(t, http.StatusOK, update(admin, map[string]any{"role": "admin"}).Code)
+	})
+
+	t.Run("it should hash the new password of the owner and keep only their session", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockAuthService.EXPECT().VerifyPassword("stored-hash", "0ldPassword").Return(true)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			assert.Equal(t, "stored-hash", user.Password)
+			return user, nil
+		})
+		mockService.EXPECT().ChangePassword(gomock.Any(), owner, "N3wPassword").Return(nil)
+		mockSessionService.EXPECT().RevokeSessionsExceptToken(gomock.Any(), owner, jti).Return(nil)
+		assert.Equal(t, http.StatusOK, update(owner, map[string]any{"password": "N3wPassword", "current_password": "0ldPassword"}).Code)
+	})
+
+	t.Run("it should fail when the other sessions cannot be signed out after a new password", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockAuthService.EXPECT().VerifyPassword("stored-hash", "0ldPassword").Return(true)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			return user, nil
+		})
+		mockService.EXPECT().ChangePassword(gomock.Any(), owner, "N3wPassword").Return(nil)
+		mockSessionService.EXPECT().RevokeSessionsExceptToken(gomock.Any(), owner, jti).Return(errors.New("redis is down"))
+		assert.Equal(t, http.StatusInternalServerError, update(owner, map[string]any{"password": "N3wPassword", "current_password": "0ldPassword"}).Code)
+	})
+
+	t.Run("it should require the current password for a new password or email", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"password": "N3wPassword"},
+			{"email": "new@example.com"},
+		} {
+			mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+			w := update(owner, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"errors": {"current_password": "your current password is required"}}`, w.Body.String())
+
+			body["current_password"] = "Wr0ngPassword"
+			mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+			mockAuthService.EXPECT().VerifyPassword("stored-hash", "Wr0ngPassword").Return(false)
+			w = update(owner, body)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.JSONEq(t, `{"error": "current password is incorrect"}`, w.Body.String())
+		}
+	})
+
+	t.Run("it should not let api keys or oauth clients change the password or email", func(t *testing.T) {
+		for _, context := range []map[string]any{
+			{"apiKeyId": uuid.New()},
+			{"oauthClientId": uuid.New(), "accessTokenJti": jti},
+		} {
+			mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+			w := updateWith(owner, map[string]any{"password": "N3wPassword", "current_password": "0ldPassword"}, context)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.JSONEq(t, `{"error": "passwords cannot be changed with an api key or an oauth client"}`, w.Body.String())
+
+			mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+			w = updateWith(owner, map[string]any{"email": "new@example.com", "current_password": "0ldPassword"}, context)
+			assert.Equal(t, http.StatusForbidden, w.Code)
+			assert.JSONEq(t, `{"error": "emails cannot be changed with an api key or an oauth client"}`, w.Body.String())
+		}
+	})
+
+	t.Run("it should refuse an email another account uses", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockAuthService.EXPECT().VerifyPassword("stored-hash", "0ldPassword").Return(true)
+		mockService.EXPECT().CheckEmailAvailable(gomock.Any(), owner, "taken@example.com").Return(services.ErrEmailInUse)
+		w := update(owner, map[string]any{"email": "taken@example.com", "current_password": "0ldPassword"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error": "this email is already used by another account"}`, w.Body.String())
+	})
+
+	t.Run("it should ask to verify a new email again", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockAuthService.EXPECT().VerifyPassword("stored-hash", "0ldPassword").Return(true)
+		mockService.EXPECT().CheckEmailAvailable(gomock.Any(), owner, "new@example.com").Return(nil)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			assert.Equal(t, "new@example.com", user.Email)
+			assert.Nil(t, user.EmailVerifiedAt)
+			return user, nil
+		})
+		mockAuthService.EXPECT().CreateEmailVerificationToken(gomock.Any(), owner).Return("verification-token", nil)
+		mockAuthService.EXPECT().SendVerificationEmail("Owner", "new@example.com", "verification-token").Return(nil)
+		w := update(owner, map[string]any{"email": "new@example.com", "current_password": "0ldPassword"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "email_verified_at")
+	})
+
+	t.Run("it should ask an admin changing the email of a user for their own password", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockService.EXPECT().GetUserById(gomock.Any(), admin).Return(&models.User{ID: admin, Password: "admin-hash"}, nil)
+		mockAuthService.EXPECT().VerifyPassword("admin-hash", "Adm1nPassword").Return(false)
+		w := update(admin, map[string]any{"email": "new@example.com", "current_password": "Adm1nPassword"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("it should keep the email verified when it does not change", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			assert.Equal(t, &verifiedAt, user.EmailVerifiedAt)
+			return user, nil
+		})
+		assert.Equal(t, http.StatusOK, update(owner, map[string]any{"name": "Owner Name", "email": "owner@example.com"}).Code)
+	})
+
+	t.Run("it should not let admins set the password of others", func(t *testing.T) {
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		w := update(admin, map[string]any{"name": "Owner Name", "password": "N3wPassword"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "only the owner of an account may change its password"}`, w.Body.String())
+	})
+	t.Run("it should not let impersonators change the password or email", func(t *testing.T) {
+		impersonate := func(body map[string]any) *httptest.ResponseRecorder {
+			router := gin.Default()
+			router.PUT("/user/:id", func(c *gin.Context) {
+				c.Set("authenticatedUserId", owner)
+				c.Set("impersonation", &services.TokenPayload{UserId: owner, ActorId: admin})
+				c.Set("validatedBody", body)
+				c.Next()
+			}, handler.Update)
+			req, _ := http.NewRequest(http.MethodPut, "/user/"+owner.String(), nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		w := impersonate(map[string]any{"password": "N3wPassword"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "passwords cannot be changed while impersonating"}`, w.Body.String())
+
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		w = impersonate(map[string]any{"email": "new@example.com"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "emails cannot be changed while impersonating"}`, w.Body.String())
+
+		mockService.EXPECT().GetUserById(gomock.Any(), owner).Return(existing(), nil)
+		mockService.EXPECT().UpdateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user *models.User) (*models.User, error) {
+			return user, nil
+		})
+		assert.Equal(t, http.StatusOK, impersonate(map[string]any{"name": "Owner Name"}).Code)
+	})
+}
@@ -0,0 +1,144 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/handlers"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebAuthnHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebAuthnService := mock_services.NewMockIWebAuthnService(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	webAuthnHandler := handlers.NewWebAuthnHandler(mockWebAuthnService, mockMfaService, mockAuthService, mockUserService)
+
+	userId := uuid.New()
+	user := &models.User{ID: userId, Email: "john@example.com"}
+	credential := json.RawMessage(`{"id":"abc"}`)
+
+	router := gin.Default()
+	authenticated := func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}
+	router.GET("/webauthn/credentials", authenticated, webAuthnHandler.ListCredentials)
+	router.DELETE("/webauthn/credentials/:credentialId", authenticated, webAuthnHandler.DeleteCredential)
+	router.POST("/webauthn/login/finish", func(c *gin.Context) {
+		c.Set("validatedBody", dto.WebAuthnLogin{Session: "session", Credential: credential})
+		c.Next()
+	}, webAuthnHandler.FinishLogin)
+	router.POST("/mfa/webauthn/verify", func(c *gin.Context) {
+		c.Set("validatedBody", dto.WebAuthnMfa{Ticket: "ticket", Session: "session", Credential: credential})
+		c.Next()
+	}, webAuthnHandler.FinishMfa)
+
+	request := func(method, url string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	expectSession := func() {
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(user, nil)
+		mockAuthService.EXPECT().GenerateToken(userId, gomock.Any()).Return("test_token", nil)
+		mockAuthService.EXPECT().GenerateRefreshToken().Return("refresh_token", "hashed_refresh_token", nil)
+		mockAuthService.EXPECT().StoreRefreshToken(gomock.Any(), gomock.Any(), userId, gomock.Any(), "hashed_refresh_token", gomock.Any()).Return(nil)
+	}
+
+	t.Run("should list passkeys with base64url ids and without their keys", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().ListCredentials(gomock.Any(), userId).Return([]models.WebAuthnCredential{
+			{ID: []byte{0xfb, 0xff}, Name: "Laptop", PublicKey: []byte("public-key"), BackupState: true, CreatedAt: time.Now()},
+		}, nil)
+
+		w := request(http.MethodGet, "/webauthn/credentials")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, bytes.Contains(w.Body.Bytes(), []byte("public")))
+		var body struct {
+			Passkeys []dto.Passkey `json:"passkeys"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		if assert.Len(t, body.Passkeys, 1) {
+			assert.Equal(t, "-_8", body.Passkeys[0].ID)
+			assert.True(t, body.Passkeys[0].Synced)
+		}
+	})
+
+	t.Run("should return 404 when deleting an unknown passkey", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().DeleteCredential(gomock.Any(), userId, []byte{0xfb, 0xff}).Return(services.ErrWebAuthnCredentialNotFound)
+
+		w := request(http.MethodDelete, "/webauthn/credentials/-_8")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("should sign in with a passkey", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().FinishLogin(gomock.Any(), "session", []byte(credential)).Return(userId, nil)
+		mockAuthService.EXPECT().EnsureEmailVerified(user).Return(nil)
+		expectSession()
+
+		w := request(http.MethodPost, "/webauthn/login/finish")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+	})
+
+	t.Run("should return 403 for a passkey of an unverified email", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().FinishLogin(gomock.Any(), "session", []byte(credential)).Return(userId, nil)
+		mockUserService.EXPECT().GetUserById(gomock.Any(), userId).Return(user, nil)
+		mockAuthService.EXPECT().EnsureEmailVerified(user).Return(services.ErrEmailNotVerified)
+
+		w := request(http.MethodPost, "/webauthn/login/finish")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("should return 401 for a rejected passkey", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().FinishLogin(gomock.Any(), "session", []byte(credential)).Return(uuid.Nil, services.ErrWebAuthnVerificationFailed)
+
+		w := request(http.MethodPost, "/webauthn/login/finish")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("should complete a password login with a passkey", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().FinishLogin(gomock.Any(), "session", []byte(credential)).Return(userId, nil)
+		mockMfaService.EXPECT().ConsumeTicket(gomock.Any(), "ticket", userId).Return(nil)
+		expectSession()
+
+		w := request(http.MethodPost, "/mfa/webauthn/verify")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Bearer test_token")
+	})
+
+	t.Run("should return 401 when the passkey belongs to someone else than the ticket", func(t *testing.T) {
+		mockWebAuthnService.EXPECT().FinishLogin(gomock.Any(), "session", []byte(credential)).Return(uuid.New(), nil)
+		mockMfaService.EXPECT().ConsumeTicket(gomock.Any(), "ticket", gomock.Any()).Return(services.ErrInvalidMfaTicket)
+
+		w := request(http.MethodPost, "/mfa/webauthn/verify")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -3,6 +3,9 @@ package handlers
 import (
 	"database/sql"
 	"errors"
+	"log"
+	"my-go-api/internal/models"
+	"my-go-api/internal/policy"
 	"my-go-api/internal/services"
 	"net/http"
 
@@ -10,10 +13,46 @@ import (
 	"github.com/google/uuid"
 )
 
-type UserHandler struct{ service services.IUserService }
+type UserHandler struct {
+	service        services.IUserService
+	rbacService    services.IRBACService
+	authService    services.IAuthService
+	sessionService services.ISessionService
+}
 
-func NewUserHandler(service services.IUserService) *UserHandler {
-	return &UserHandler{service: service}
+func NewUserHandler(
+	service services.IUserService,
+	rbacService services.IRBACService,
+	authService services.IAuthService,
+	sessionService services.ISessionService,
+) *UserHandler {
+	return &UserHandler{service: service, rbacService: rbacService, authService: authService, sessionService: sessionService}
+}
+
+// actor resolves who makes the request for the policies, answering the request
+// itself when that fails.
+func (h *UserHandler) actor(c *gin.Context) (policy.Actor, bool) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return policy.Actor{}, false
+	}
+	permissions, err := h.rbacService.Permissions(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return policy.Actor{}, false
+	}
+	actor := policy.Actor{UserId: userId, Permissions: permissions}
+	_, viaAPIKey := c.Get("apiKeyId")
+	_, viaOAuthClient := c.Get("oauthClientId")
+	actor.Delegated = viaAPIKey || viaOAuthClient
+	if value, exist := c.Get("impersonation"); exist {
+		if impersonation, ok := value.(*services.TokenPayload); ok {
+			actor.ImpersonatorId = impersonation.ActorId
+		}
+	}
+	return actor, true
 }
 
 func (h *UserHandler) GetUserById(c *gin.Context) {
@@ -22,6 +61,10 @@ func (h *UserHandler) GetUserById(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user id"})
 		return
 	}
+	actor, ok := h.actor(c)
+	if !ok {
+		return
+	}
 	user, err := h.service.GetUserById(c.Request.Context(), userId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -32,6 +75,10 @@ func (h *UserHandler) GetUserById(c *gin.Context) {
 			return
 		}
 	}
+	if decision := policy.Can(actor, policy.ReadUser, user); !decision.Allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
@@ -44,6 +91,11 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"users": users})
 }
 
+// Update asks the policies about every part of the change before making any of
+// it: editing the account, and changing its role, password or email when the
+// body holds them. Both a new password and a new email need the current password
+// of whoever makes the change in current_password. A new password signs out
+// every other session of the user, and a new email has to be verified again.
 func (h *UserHandler) Update(c *gin.Context) {
 	userId, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -55,6 +107,11 @@ func (h *UserHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Error"})
 		return
 	}
+	v, _ := value.(map[string]any)
+	actor, ok := h.actor(c)
+	if !ok {
+		return
+	}
 	existingUser, err := h.service.GetUserById(c.Request.Context(), userId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -65,23 +122,124 @@ func (h *UserHandler) Update(c *gin.Context) {
 			return
 		}
 	}
-	if v, ok := value.(map[string]any); ok {
-		if username, exists := v["username"].(string); exists {
-			existingUser.Username = username
+	role, changesRole := v["role"].(string)
+	password, changesPassword := v["password"].(string)
+	email, hasEmail := v["email"].(string)
+	actions := []policy.Action{policy.UpdateUser}
+	if changesRole {
+		actions = append(actions, policy.ChangeRole)
+	}
+	if changesPassword {
+		actions = append(actions, policy.ChangePassword)
+	}
+	changesEmail := hasEmail && email != existingUser.Email
+	if changesEmail {
+		actions = append(actions, policy.ChangeEmail)
+	}
+	for _, action := range actions {
+		if decision := policy.Can(actor, action, existingUser); !decision.Allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+			return
+		}
+	}
+	if changesPassword || changesEmail {
+		if !h.confirmPassword(c, actor.UserId, existingUser, v["current_password"]) {
+			return
+		}
+	}
+	if changesEmail {
+		if err := h.service.CheckEmailAvailable(c.Request.Context(), userId, email); err != nil {
+			if errors.Is(err, services.ErrEmailInUse) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
+		}
+	}
+
+	if username, exists := v["username"].(string); exists {
+		existingUser.Username = username
+	}
+	if name, exists := v["name"].(string); exists {
+		existingUser.Name = name
+	}
+	if hasEmail {
+		existingUser.Email = email
+	}
+	// the role goes through the role assignment, which knows the roles and
+	// keeps the last admin, before the update writes it back
+	if changesRole && role != existingUser.Role {
+		if err := h.rbacService.AssignRole(c.Request.Context(), userId, role); err != nil {
+			rbacErrorResponse(c, err)
+			return
 		}
-		if name, exists := v["name"].(string); exists {
-			existingUser.Name = name
+		existingUser.Role = role
+	}
+	if changesEmail {
+		existingUser.EmailVerifiedAt = nil
+	}
+	if _, err := h.service.UpdateUser(c.Request.Context(), existingUser); err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	// the update writes back the stored hash, so the new password comes after it
+	if changesPassword {
+		if err := h.service.ChangePassword(c.Request.Context(), userId, password); err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
 		}
-		if email, exists := v["email"].(string); exists {
-			existingUser.Email = email
+		// only the owner changes a password, whoever knew the old one is signed out
+		// but the session of the access token that changed it is kept
+		jti, _ := c.Get("accessTokenJti")
+		tokenJti, _ := jti.(uuid.UUID)
+		if err := h.sessionService.RevokeSessionsExceptToken(c.Request.Context(), userId, tokenJti); err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
 		}
-		if password, exists := v["password"].(string); exists {
-			existingUser.Password = password
+	}
+	if changesEmail {
+		token, err := h.authService.CreateEmailVerificationToken(c.Request.Context(), userId)
+		if err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
 		}
-		if role, exists := v["role"].(string); exists {
-			existingUser.Role = role
+		if err := h.authService.SendVerificationEmail(existingUser.Name, existingUser.Email, token); err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return
 		}
 	}
-	h.service.UpdateUser(c.Request.Context(), existingUser)
 	c.JSON(http.StatusOK, gin.H{"user": existingUser})
 }
+
+// confirmPassword checks the current password the actor sent, which is that of
+// the user unless an admin edits them, answering the request when it is missing
+// or wrong.
+func (h *UserHandler) confirmPassword(c *gin.Context, actorId uuid.UUID, user *models.User, value any) bool {
+	currentPassword, _ := value.(string)
+	if currentPassword == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": gin.H{"current_password": "your current password is required"}})
+		return false
+	}
+	hashedPassword := user.Password
+	if actorId != user.ID {
+		actor, err := h.service.GetUserById(c.Request.Context(), actorId)
+		if err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			return false
+		}
+		hashedPassword = actor.Password
+	}
+	if !h.authService.VerifyPassword(hashedPassword, currentPassword) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "current password is incorrect"})
+		return false
+	}
+	return true
+}
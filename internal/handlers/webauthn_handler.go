@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IWebAuthnHandler interface {
+	BeginRegistration(c *gin.Context)
+	FinishRegistration(c *gin.Context)
+	ListCredentials(c *gin.Context)
+	DeleteCredential(c *gin.Context)
+	BeginLogin(c *gin.Context)
+	FinishLogin(c *gin.Context)
+	BeginMfa(c *gin.Context)
+	FinishMfa(c *gin.Context)
+}
+
+type webAuthnHandler struct {
+	ws services.IWebAuthnService
+	ms services.IMfaService
+	as services.IAuthService
+	us services.IUserService
+}
+
+func NewWebAuthnHandler(ws services.IWebAuthnService, ms services.IMfaService, as services.IAuthService, us services.IUserService) IWebAuthnHandler {
+	return &webAuthnHandler{ws: ws, ms: ms, as: as, us: us}
+}
+
+// webAuthnFailure returns the message to show when err means the browser's answer
+// was rejected, as opposed to a failure on our side.
+func webAuthnFailure(err error) (string, bool) {
+	for _, failure := range []error{services.ErrWebAuthnCeremonyNotFound, services.ErrWebAuthnVerificationFailed} {
+		if errors.Is(err, failure) {
+			return failure.Error(), true
+		}
+	}
+	return "", false
+}
+
+func (h *webAuthnHandler) BeginRegistration(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	creation, err := h.ws.BeginRegistration(c.Request.Context(), user)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, creation)
+}
+
+func (h *webAuthnHandler) FinishRegistration(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.WebAuthnRegistration)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	credential, err := h.ws.FinishRegistration(c.Request.Context(), user, body.Name, body.Credential)
+	if err != nil {
+		if message, ok := webAuthnFailure(err); ok {
+			log.Println(err.Error())
+			c.JSON(http.StatusBadRequest, gin.H{"error": message})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"passkey": dto.Passkey{
+		ID:         base64.RawURLEncoding.EncodeToString(credential.ID),
+		Name:       credential.Name,
+		Transports: credential.Transports,
+		Synced:     credential.BackupState,
+		CreatedAt:  credential.CreatedAt,
+		LastUsedAt: credential.LastUsedAt,
+	}})
+}
+
+func (h *webAuthnHandler) ListCredentials(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	credentials, err := h.ws.ListCredentials(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	passkeys := make([]dto.Passkey, 0, len(credentials))
+	for _, credential := range credentials {
+		passkeys = append(passkeys, dto.Passkey{
+			ID:         base64.RawURLEncoding.EncodeToString(credential.ID),
+			Name:       credential.Name,
+			Transports: credential.Transports,
+			Synced:     credential.BackupState,
+			CreatedAt:  credential.CreatedAt,
+			LastUsedAt: credential.LastUsedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"passkeys": passkeys})
+}
+
+func (h *webAuthnHandler) DeleteCredential(c *gin.Context) {
+	userId, ok := authenticatedUserId(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	credentialId, err := base64.RawURLEncoding.DecodeString(c.Param("credentialId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid passkey id"})
+		return
+	}
+	if err := h.ws.DeleteCredential(c.Request.Context(), userId, credentialId); err != nil {
+		if errors.Is(err, services.ErrWebAuthnCredentialNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey removed"})
+}
+
+// BeginLogin starts a passwordless login with a passkey.
+func (h *webAuthnHandler) BeginLogin(c *gin.Context) {
+	login, err := h.ws.BeginLogin(c.Request.Context())
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, login)
+}
+
+// FinishLogin signs the owner of the passkey in. The passkey is verified with
+// user verification, so no second factor is asked for, but like a password
+// login it needs a verified email.
+func (h *webAuthnHandler) FinishLogin(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.WebAuthnLogin)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	userId, err := h.ws.FinishLogin(c.Request.Context(), body.Session, body.Credential)
+	if err != nil {
+		if message, ok := webAuthnFailure(err); ok {
+			log.Println(err.Error())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	if err := h.as.EnsureEmailVerified(user); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email before logging in"})
+		return
+	}
+	issueSession(c, h.as, user)
+}
+
+// BeginMfa challenges the passkeys of the user an MFA ticket was issued for.
+func (h *webAuthnHandler) BeginMfa(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.MfaTicket)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	userId, err := h.ms.TicketUserId(c.Request.Context(), body.Ticket)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMfaTicket) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	login, err := h.ws.BeginSecondFactor(c.Request.Context(), userId)
+	if err != nil {
+		if errors.Is(err, services.ErrWebAuthnCredentialNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No passkey is registered for this account"})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	c.JSON(http.StatusOK, login)
+}
+
+// FinishMfa redeems an MFA ticket with a passkey of the same user and starts the
+// session the password login was holding back.
+func (h *webAuthnHandler) FinishMfa(c *gin.Context) {
+	value, exist := c.Get("validatedBody")
+	if !exist {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validated body not exists"})
+		return
+	}
+	body, ok := value.(dto.WebAuthnMfa)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid type for validated body"})
+		return
+	}
+	userId, err := h.ws.FinishLogin(c.Request.Context(), body.Session, body.Credential)
+	if err != nil {
+		if message, ok := webAuthnFailure(err); ok {
+			log.Println(err.Error())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	if err := h.ms.ConsumeTicket(c.Request.Context(), body.Ticket, userId); err != nil {
+		if errors.Is(err, services.ErrInvalidMfaTicket) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	user, err := h.us.GetUserById(c.Request.Context(), userId)
+	if err != nil {
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		return
+	}
+	issueSession(c, h.as, user)
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"my-go-api/internal/config"
+	"my-go-api/internal/repositories"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RateLimitMiddleware struct {
+	limiter  repositories.IRateLimiter
+	policies map[string]config.RateLimitPolicy
+}
+
+func RegisterRateLimitMiddleware(limiter repositories.IRateLimiter, policies map[string]config.RateLimitPolicy) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter:  limiter,
+		policies: policies,
+	}
+}
+
+// Limit enforces the named policy and reports it in the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers. A policy
+// that is not configured is not enforced. Requests are let through when the
+// limit cannot be checked, an outage should not take the API down with it.
+func (m RateLimitMiddleware) Limit(name string) gin.HandlerFunc {
+	policy, ok := m.policies[name]
+	if !ok {
+		log.Printf("rate limit policy %q is not configured, requests are not limited by it", name)
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+	limit := repositories.RateLimit{Algorithm: policy.Algorithm, Limit: policy.Limit, Window: policy.Window}
+	return func(c *gin.Context) {
+		key := fmt.Sprintf("rate-limit:%s:%s", name, rateLimitClient(c, policy.KeyBy))
+		result, err := m.limiter.Allow(c.Request.Context(), key, limit)
+		if err != nil {
+			log.Println(err.Error())
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(max(result.Remaining, 0)))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitClient names who a request is counted for. Requests without a user
+// or API key are counted by IP, so routes can be limited before RequireAuth.
+func rateLimitClient(c *gin.Context, keyBy string) string {
+	switch keyBy {
+	case "user":
+		if value, exist := c.Get("authenticatedUserId"); exist {
+			if userId, ok := value.(uuid.UUID); ok {
+				return "user:" + userId.String()
+			}
+		}
+	case "api_key":
+		if apiKey := apiKeyFromRequest(c); apiKey != "" {
+			hash := sha256.Sum256([]byte(apiKey))
+			return "api-key:" + hex.EncodeToString(hash[:])
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
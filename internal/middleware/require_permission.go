@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"log"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PermissionMiddleware struct {
+	rbacService services.IRBACService
+}
+
+func RegisterPermissionMiddleware(rbacService services.IRBACService) *PermissionMiddleware {
+	return &PermissionMiddleware{
+		rbacService: rbacService,
+	}
+}
+
+// RequirePermission only lets users whose role grants the permission through.
+// It runs after RequireAuth and resolves the permissions of the authenticated
+// user from the database, so a change of role applies at once.
+func (m PermissionMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exist := c.Get("authenticatedUserId")
+		userId, ok := value.(uuid.UUID)
+		if !exist || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		allowed, err := m.rbacService.HasPermission(c.Request.Context(), userId, permission)
+		if err != nil {
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
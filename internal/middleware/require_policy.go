@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"my-go-api/internal/policy"
+	"my-go-api/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PolicyMiddleware struct {
+	policyService services.IPolicyService
+}
+
+func RegisterPolicyMiddleware(policyService services.IPolicyService) *PolicyMiddleware {
+	return &PolicyMiddleware{
+		policyService: policyService,
+	}
+}
+
+// ResourceFunc tells Authorize which resource a request acts on.
+type ResourceFunc func(c *gin.Context) policy.Resource
+
+// UserParam is the user account named by a path parameter, which its user owns.
+func UserParam(param string) ResourceFunc {
+	return func(c *gin.Context) policy.Resource {
+		owner, _ := uuid.Parse(c.Param(param))
+		return policy.Resource{Type: policy.ResourceUser, Id: c.Param(param), Owner: owner}
+	}
+}
+
+// Authorize lets the request through when the access policies allow the
+// authenticated user the action on the resource. It runs after RequireAuth;
+// requests made with an API key count as single factor whatever the account has.
+func (m PolicyMiddleware) Authorize(action policy.Action, resource ResourceFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exist := c.Get("authenticatedUserId")
+		userId, ok := value.(uuid.UUID)
+		if !exist || !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+		env := policy.Context{IP: c.ClientIP()}
+		if _, exist := c.Get("apiKeyId"); exist {
+			env.MfaLevel = policy.MfaLevelSingle
+		}
+		decision, err := m.policyService.Authorize(c.Request.Context(), userId, action, resource(c), env)
+		if err != nil {
+			if errors.Is(err, services.ErrUserNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				c.Abort()
+				return
+			}
+			log.Println(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+			c.Abort()
+			return
+		}
+		if !decision.Allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": decision.Reason})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"my-go-api/internal/config"
+	"my-go-api/internal/middleware"
+	"my-go-api/internal/repositories"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRateLimiter struct{}
+
+func (failingRateLimiter) Allow(ctx context.Context, key string, limit repositories.RateLimit) (*repositories.RateLimitResult, error) {
+	return nil, errors.New("redis down")
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policies := map[string]config.RateLimitPolicy{
+		"ip":   {Algorithm: "sliding_window", Limit: 2, Window: time.Minute, KeyBy: "ip"},
+		"user": {Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "user"},
+		"key":  {Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "api_key"},
+	}
+	rl := middleware.RegisterRateLimitMiddleware(repositories.NewMemoryRateLimiter(), policies)
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	}
+
+	router := gin.Default()
+	router.GET("/ip", rl.Limit("ip"), ok)
+	router.GET("/user/:id", func(c *gin.Context) {
+		c.Set("authenticatedUserId", uuid.MustParse(c.Param("id")))
+		c.Next()
+	}, rl.Limit("user"), ok)
+	router.GET("/key", rl.Limit("key"), ok)
+	router.GET("/unconfigured", rl.Limit("missing"), ok)
+
+	request := func(path, ip string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should send the RateLimit headers and reject requests over the limit", func(t *testing.T) {
+		w := request("/ip", "10.0.0.1", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+
+		assert.Equal(t, http.StatusOK, request("/ip", "10.0.0.1", nil).Code)
+		w = request("/ip", "10.0.0.1", nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, request("/ip", "10.0.0.2", nil).Code)
+	})
+
+	t.Run("it should count users separately", func(t *testing.T) {
+		first, second := uuid.NewString(), uuid.NewString()
+		assert.Equal(t, http.StatusOK, request("/user/"+first, "10.0.0.1", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request("/user/"+first, "10.0.0.3", nil).Code)
+		assert.Equal(t, http.StatusOK, request("/user/"+second, "10.0.0.1", nil).Code)
+	})
+
+	t.Run("it should count API keys separately and fall back to the IP without one", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request("/key", "10.0.0.1", map[string]string{"X-API-Key": "first"}).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request("/key", "10.0.0.1", map[string]string{"X-API-Key": "first"}).Code)
+		assert.Equal(t, http.StatusOK, request("/key", "10.0.0.1", map[string]string{"X-API-Key": "second"}).Code)
+		assert.Equal(t, http.StatusOK, request("/key", "10.0.0.1", nil).Code)
+		assert.Equal(t, http.StatusTooManyRequests, request("/key", "10.0.0.1", nil).Code)
+	})
+
+	t.Run("it should not limit by a policy that is not configured", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			w := request("/unconfigured", "10.0.0.1", nil)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("it should let requests through when the limit cannot be checked", func(t *testing.T) {
+		failing := middleware.RegisterRateLimitMiddleware(failingRateLimiter{}, policies)
+		router := gin.Default()
+		router.GET("/ip", failing.Limit("ip"), ok)
+		req, _ := http.NewRequest(http.MethodGet, "/ip", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"errors"
+	"my-go-api/internal/middleware"
+	"my-go-api/internal/mocks/mock_services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	mdP := middleware.RegisterPermissionMiddleware(mockRBACService)
+	userId := uuid.New()
+
+	router := gin.Default()
+	router.PUT("/users/:id", func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}, mdP.RequirePermission("users:write"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	})
+	router.GET("/anonymous", mdP.RequirePermission("users:read"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should let users with the permission through", func(t *testing.T) {
+		mockRBACService.EXPECT().HasPermission(gomock.Any(), userId, "users:write").Return(true, nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodPut, "/users/1").Code)
+	})
+
+	t.Run("it should return 403 without the permission", func(t *testing.T) {
+		mockRBACService.EXPECT().HasPermission(gomock.Any(), userId, "users:write").Return(false, nil)
+		assert.Equal(t, http.StatusForbidden, request(http.MethodPut, "/users/1").Code)
+	})
+
+	t.Run("it should return 401 when nobody is authenticated", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/anonymous").Code)
+	})
+
+	t.Run("it should return 500 when the permissions cannot be loaded", func(t *testing.T) {
+		mockRBACService.EXPECT().HasPermission(gomock.Any(), userId, "users:write").Return(false, errors.New("db down"))
+		assert.Equal(t, http.StatusInternalServerError, request(http.MethodPut, "/users/1").Code)
+	})
+}
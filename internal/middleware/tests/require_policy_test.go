@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"errors"
+	"my-go-api/internal/middleware"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/policy"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPolicyService := mock_services.NewMockIPolicyService(ctrl)
+	mdA := middleware.RegisterPolicyMiddleware(mockPolicyService)
+	userId := uuid.New()
+	owner := uuid.New()
+	account := policy.Resource{Type: policy.ResourceUser, Id: owner.String(), Owner: owner}
+
+	authenticated := func(c *gin.Context) {
+		c.Set("authenticatedUserId", userId)
+		c.Next()
+	}
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	}
+	router := gin.Default()
+	router.PUT("/users/:id", authenticated, mdA.Authorize(policy.UpdateUser, middleware.UserParam("id")), ok)
+	router.PUT("/api-key/users/:id", authenticated, func(c *gin.Context) {
+		c.Set("apiKeyId", uuid.New())
+		c.Next()
+	}, mdA.Authorize(policy.UpdateUser, middleware.UserParam("id")), ok)
+	router.GET("/anonymous/:id", mdA.Authorize(policy.ReadUser, middleware.UserParam("id")), ok)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.RemoteAddr = "203.0.113.9:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should let allowed requests through", func(t *testing.T) {
+		mockPolicyService.EXPECT().Authorize(gomock.Any(), userId, policy.UpdateUser, account, policy.Context{IP: "203.0.113.9"}).
+			Return(policy.Decision{Allowed: true, Policy: "own-account"}, nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodPut, "/users/"+owner.String()).Code)
+	})
+
+	t.Run("it should return 403 with the reason of a denial", func(t *testing.T) {
+		mockPolicyService.EXPECT().Authorize(gomock.Any(), userId, policy.UpdateUser, account, gomock.Any()).
+			Return(policy.Decision{Reason: "Editing another user's account requires a second factor", Policy: "edit-others-with-mfa"}, nil)
+		w := request(http.MethodPut, "/users/"+owner.String())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"Editing another user's account requires a second factor"}`, w.Body.String())
+	})
+
+	t.Run("it should count API keys as a single factor", func(t *testing.T) {
+		mockPolicyService.EXPECT().Authorize(gomock.Any(), userId, policy.UpdateUser, account, policy.Context{IP: "203.0.113.9", MfaLevel: policy.MfaLevelSingle}).
+			Return(policy.Decision{Allowed: true}, nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodPut, "/api-key/users/"+owner.String()).Code)
+	})
+
+	t.Run("it should return 401 when nobody is authenticated", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/anonymous/"+owner.String()).Code)
+	})
+
+	t.Run("it should return 401 when the user no longer exists", func(t *testing.T) {
+		mockPolicyService.EXPECT().Authorize(gomock.Any(), userId, policy.UpdateUser, account, gomock.Any()).Return(policy.Decision{}, services.ErrUserNotFound)
+		assert.Equal(t, http.StatusUnauthorized, request(http.MethodPut, "/users/"+owner.String()).Code)
+	})
+
+	t.Run("it should return 500 when the attributes cannot be loaded", func(t *testing.T) {
+		mockPolicyService.EXPECT().Authorize(gomock.Any(), userId, policy.UpdateUser, account, gomock.Any()).Return(policy.Decision{}, errors.New("db down"))
+		assert.Equal(t, http.StatusInternalServerError, request(http.MethodPut, "/users/"+owner.String()).Code)
+	})
+}
@@ -0,0 +1,254 @@
+package middleware_test
+
+import (
+	"errors"
+	"fmt"
+	"my-go-api/internal/middleware"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mdT := middleware.RegisterTokenVerificationMiddleware(mockAuthService, nil)
+
+	router := gin.Default()
+	router.GET("/me", mdT.RequireAuth, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userId": c.MustGet("authenticatedUserId")})
+	})
+
+	request := func(authorization string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/me", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should return 401 without a bearer token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request("").Code)
+		assert.Equal(t, http.StatusUnauthorized, request("Basic abc").Code)
+	})
+
+	t.Run("it should tell why the token was rejected", func(t *testing.T) {
+		tests := []struct {
+			err     error
+			message string
+		}{
+			{fmt.Errorf("%w: token is expired", utils.ErrAccessTokenExpired), "token expired"},
+			{fmt.Errorf("%w: token is malformed", utils.ErrAccessTokenMalformed), "malformed token"},
+			{fmt.Errorf("%w: token has invalid audience", utils.ErrAccessTokenInvalidAudience), "invalid token audience"},
+			{services.ErrTokenRevoked, "token has been revoked"},
+		}
+		for _, tt := range tests {
+			mockAuthService.EXPECT().ValidateToken("token").Return(nil, tt.err)
+			w := request("Bearer token")
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, tt.message), w.Body.String())
+			assert.Equal(t, fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, tt.message), w.Header().Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("it should return 500 when the token cannot be checked", func(t *testing.T) {
+		mockAuthService.EXPECT().ValidateToken("token").Return(nil, errors.New("redis is down"))
+		w := request("Bearer token")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("it should set the authenticated user", func(t *testing.T) {
+		userId := uuid.New()
+		mockAuthService.EXPECT().ValidateToken("token").Return(&services.TokenPayload{UserId: userId, Jti: uuid.New()}, nil)
+		w := request("Bearer token")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), userId.String())
+	})
+}
+
+func TestRequireAuthWithAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockAPIKeyService := mock_services.NewMockIAPIKeyService(ctrl)
+	mdT := middleware.RegisterTokenVerificationMiddleware(mockAuthService, mockAPIKeyService)
+
+	handler := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userId": c.MustGet("authenticatedUserId")})
+	}
+	router := gin.Default()
+	router.GET("/me", mdT.RequireAuth, handler)
+	router.POST("/me", mdT.RequireAuth, handler)
+	router.GET("/api-keys", mdT.RequireAuth, mdT.RequireSession, handler)
+
+	request := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	userId := uuid.New()
+	readKey := &models.APIKey{ID: uuid.New(), UserId: userId, Scopes: []string{"read"}}
+
+	t.Run("it should accept a key as a bearer token or in X-API-Key", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "mga_key").Return(readKey, nil).Times(2)
+
+		w := request(http.MethodGet, "/me", map[string]string{"Authorization": "Bearer mga_key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), userId.String())
+
+		w = request(http.MethodGet, "/me", map[string]string{"X-API-Key": "mga_key"})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("it should return 401 for an invalid key", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "mga_key").Return(nil, services.ErrInvalidAPIKey)
+
+		w := request(http.MethodGet, "/me", map[string]string{"X-API-Key": "mga_key"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error": "invalid or expired api key"}`, w.Body.String())
+	})
+
+	t.Run("it should require the write scope for unsafe methods", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "mga_key").Return(readKey, nil)
+
+		w := request(http.MethodPost, "/me", map[string]string{"X-API-Key": "mga_key"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error": "api key lacks the write scope"}`, w.Body.String())
+	})
+
+	t.Run("it should keep keys away from routes that need a session", func(t *testing.T) {
+		mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "mga_key").Return(readKey, nil)
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api-keys", map[string]string{"X-API-Key": "mga_key"}).Code)
+
+		mockAuthService.EXPECT().ValidateToken("token").Return(&services.TokenPayload{UserId: userId, Jti: uuid.New()}, nil)
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api-keys", map[string]string{"Authorization": "Bearer token"}).Code)
+	})
+}
+
+func TestRequireAuthWithOAuthClientToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mdT := middleware.RegisterTokenVerificationMiddleware(mockAuthService, nil)
+
+	router := gin.Default()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/items", mdT.RequireAuth, ok)
+	router.POST("/items", mdT.RequireAuth, ok)
+	router.GET("/api-keys", mdT.RequireAuth, mdT.RequireSession, ok)
+	router.GET("/userinfo", mdT.RequireScope("openid"), func(c *gin.Context) {
+		scope, _ := c.Get("oauthScope")
+		c.JSON(http.StatusOK, gin.H{"scope": scope})
+	})
+
+	userId := uuid.New()
+	clientId := uuid.New()
+	request := func(method, path string, payload *services.TokenPayload) *httptest.ResponseRecorder {
+		mockAuthService.EXPECT().ValidateToken("token").Return(payload, nil)
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	readOnly := &services.TokenPayload{UserId: userId, Jti: uuid.New(), ClientId: clientId, Scope: "openid read"}
+
+	t.Run("it should limit a client to its scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/items", readOnly).Code)
+
+		w := request(http.MethodPost, "/items", readOnly)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="write"`, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("it should turn a client away from routes that need a session", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request(http.MethodGet, "/api-keys", readOnly).Code)
+	})
+
+	t.Run("it should require the scope a route asks for instead of read or write", func(t *testing.T) {
+		w := request(http.MethodGet, "/userinfo", readOnly)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"scope":"openid read"}`, w.Body.String())
+
+		w = request(http.MethodGet, "/userinfo", &services.TokenPayload{UserId: userId, Jti: uuid.New(), ClientId: clientId, Scope: "read"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer error="insufficient_scope", scope="openid"`, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("it should reject tokens a client got for itself", func(t *testing.T) {
+		w := request(http.MethodGet, "/items", &services.TokenPayload{Jti: uuid.New(), ClientId: clientId, Scope: "read"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestRequireAuthWithImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mdT := middleware.RegisterTokenVerificationMiddleware(mockAuthService, nil)
+
+	router := gin.Default()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/me", mdT.RequireAuth, func(c *gin.Context) {
+		_, impersonated := c.Get("impersonation")
+		c.JSON(http.StatusOK, gin.H{"userId": c.MustGet("authenticatedUserId"), "impersonated": impersonated})
+	})
+	router.GET("/api-keys", mdT.RequireAuth, mdT.RequireSession, ok)
+
+	userId := uuid.New()
+	request := func(method, path string, payload *services.TokenPayload) *httptest.ResponseRecorder {
+		mockAuthService.EXPECT().ValidateToken("token").Return(payload, nil)
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	impersonation := &services.TokenPayload{UserId: userId, Jti: uuid.New(), ActorId: uuid.New()}
+	own := &services.TokenPayload{UserId: userId, Jti: uuid.New()}
+
+	t.Run("it should authenticate the impersonated user and flag the request", func(t *testing.T) {
+		w := request(http.MethodGet, "/me", impersonation)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, fmt.Sprintf(`{"userId":%q,"impersonated":true}`, userId), w.Body.String())
+
+		w = request(http.MethodGet, "/me", own)
+		assert.JSONEq(t, fmt.Sprintf(`{"userId":%q,"impersonated":false}`, userId), w.Body.String())
+	})
+
+	t.Run("it should keep impersonators away from sensitive routes", func(t *testing.T) {
+		w := request(http.MethodGet, "/api-keys", impersonation)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"This action is not available while impersonating"}`, w.Body.String())
+
+		assert.Equal(t, http.StatusOK, request(http.MethodGet, "/api-keys", own).Code)
+	})
+}
@@ -87,10 +87,12 @@ func (m *middleware) UpdateUser(c *gin.Context) {
 	if password, exists := input["password"].(string); exists {
 		if err := m.validate.Var(password, "required,strongPassword"); err != nil {
 			valErrors["password"] = "a minimum of 5 characters including an uppercase letter, a lowercase letter, and a number is required"
+		} else if err := m.validate.Var(password, "notBreached"); err != nil {
+			valErrors["password"] = "this password has appeared in a data breach, please choose a different one"
 		}
 	}
 	if role, exists := input["role"].(string); exists {
-		if err := m.validate.Var(role, "required,oneof=user admin"); err != nil {
+		if err := m.validate.Var(role, "required,max=50,roleName"); err != nil {
 			valErrors["role"] = "unrecognized role"
 		}
 	}
@@ -102,3 +104,150 @@ func (m *middleware) UpdateUser(c *gin.Context) {
 	c.Set("validatedBody", input)
 	c.Next()
 }
+
+func (m *middleware) VerifyEmail(c *gin.Context) {
+	var input dto.VerifyEmail
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) UnlockAccount(c *gin.Context) {
+	var input dto.UnlockAccount
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) ResendVerificationEmail(c *gin.Context) {
+	var input dto.ResendVerificationEmail
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) ForgotPassword(c *gin.Context) {
+	var input dto.ForgotPassword
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) ResetPassword(c *gin.Context) {
+	var input dto.ResetPassword
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) GoogleLogin(c *gin.Context) {
+	var input dto.GoogleLogin
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) MagicLink(c *gin.Context) {
+	var input dto.MagicLink
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) MfaCode(c *gin.Context) {
+	var input dto.MfaCode
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) VerifyMfa(c *gin.Context) {
+	var input dto.VerifyMfa
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) MfaTicket(c *gin.Context) {
+	var input dto.MfaTicket
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) WebAuthnRegistration(c *gin.Context) {
+	var input dto.WebAuthnRegistration
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) WebAuthnLogin(c *gin.Context) {
+	var input dto.WebAuthnLogin
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) WebAuthnMfa(c *gin.Context) {
+	var input dto.WebAuthnMfa
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) CreateAPIKey(c *gin.Context) {
+	var input dto.CreateAPIKey
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) CreateOAuthClient(c *gin.Context) {
+	var input dto.CreateOAuthClient
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) OAuthAuthorize(c *gin.Context) {
+	var input dto.OAuthAuthorize
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) CreateRole(c *gin.Context) {
+	var input dto.CreateRole
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) SetRolePermissions(c *gin.Context) {
+	var input dto.SetRolePermissions
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) AssignRole(c *gin.Context) {
+	var input dto.AssignRole
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) EvaluatePolicy(c *gin.Context) {
+	var input dto.EvaluatePolicy
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
+
+func (m *middleware) StartImpersonation(c *gin.Context) {
+	var input dto.StartImpersonation
+	m.runValidation(c, &input)
+	c.Set("validatedBody", input)
+	c.Next()
+}
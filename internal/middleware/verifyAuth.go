@@ -1,24 +1,54 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/constants"
 	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type VerificationAuthTokenMiddleware struct {
-	authService services.IAuthService
+	authService   services.IAuthService
+	apiKeyService services.IAPIKeyService
 }
 
-func RegisterTokenVerificationMiddleware(authService services.IAuthService) *VerificationAuthTokenMiddleware {
+func RegisterTokenVerificationMiddleware(authService services.IAuthService, apiKeyService services.IAPIKeyService) *VerificationAuthTokenMiddleware {
 	return &VerificationAuthTokenMiddleware{
-		authService: authService,
+		authService:   authService,
+		apiKeyService: apiKeyService,
 	}
 }
 
+// RequireAuth accepts an access token or an API key, sent as a bearer token or
+// in the X-API-Key header, and sets authenticatedUserId either way, and for an
+// access token accessTokenJti. Tokens an
+// OAuth2 client got for a user are limited by their scope like API keys, and
+// tokens a client got for itself are not accepted as they have no user.
 func (m VerificationAuthTokenMiddleware) RequireAuth(c *gin.Context) {
+	m.authenticate(c, requiredScope(c))
+}
+
+// RequireScope is RequireAuth for routes API keys and tokens of OAuth2 clients
+// need the given scope for, rather than read or write.
+func (m VerificationAuthTokenMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.authenticate(c, scope)
+	}
+}
+
+func (m VerificationAuthTokenMiddleware) authenticate(c *gin.Context, scope string) {
+	if apiKey := apiKeyFromRequest(c); apiKey != "" {
+		m.requireAPIKey(c, apiKey, scope)
+		return
+	}
 	authorization := c.GetHeader("Authorization")
 	if authorization == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -34,11 +64,127 @@ func (m VerificationAuthTokenMiddleware) RequireAuth(c *gin.Context) {
 	tokenStr := strings.TrimSpace(strings.TrimPrefix(authorization, bearerPrefix))
 	payload, err := m.authService.ValidateToken(tokenStr)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		if reason := invalidTokenReason(err); reason != nil {
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, reason.Error()))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": reason.Error()})
+			c.Abort()
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
 		c.Abort()
 		return
 	}
+	if payload.ClientId != uuid.Nil {
+		if payload.UserId == uuid.Nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token was not issued for a user"})
+			c.Abort()
+			return
+		}
+		if !slices.Contains(strings.Fields(payload.Scope), scope) {
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scope))
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("token lacks the %s scope", scope)})
+			c.Abort()
+			return
+		}
+		c.Set("oauthClientId", payload.ClientId)
+		c.Set("oauthScope", payload.Scope)
+	}
+	if payload.ActorId != uuid.Nil {
+		c.Set("impersonation", payload)
+	}
+	c.Set("accessTokenJti", payload.Jti)
 	c.Set("authenticatedUserId", payload.UserId)
 	c.Next()
+}
+
+// requiredScope is the scope an API key or a client's token needs for the
+// request: read for safe requests and write for all others.
+func requiredScope(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return constants.API_KEY_SCOPE_READ
+	}
+	return constants.API_KEY_SCOPE_WRITE
+}
+
+// requireAPIKey authenticates with an API key that has the scope.
+func (m VerificationAuthTokenMiddleware) requireAPIKey(c *gin.Context, raw, scope string) {
+	key, err := m.apiKeyService.Authenticate(c.Request.Context(), raw)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		log.Println(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Something went wrong"})
+		c.Abort()
+		return
+	}
+	if !slices.Contains(key.Scopes, scope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("api key lacks the %s scope", scope)})
+		c.Abort()
+		return
+	}
+	c.Set("authenticatedUserId", key.UserId)
+	c.Set("apiKeyId", key.ID)
+	c.Next()
+}
 
+// RequireSession turns away requests authenticated with an API key, a token of
+// an OAuth2 client or the token of an admin impersonating the user, for routes
+// that need the user themselves such as managing sessions, API keys or MFA and
+// the admin routes. It runs after RequireAuth.
+func (m VerificationAuthTokenMiddleware) RequireSession(c *gin.Context) {
+	if _, exist := c.Get("impersonation"); exist {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action is not available while impersonating"})
+		c.Abort()
+		return
+	}
+	if _, exist := c.Get("apiKeyId"); exist {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action is not available to api keys"})
+		c.Abort()
+		return
+	}
+	if _, exist := c.Get("oauthClientId"); exist {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This action is not available to oauth clients"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// apiKeyFromRequest returns the API key the request carries, "" when it has none.
+func apiKeyFromRequest(c *gin.Context) string {
+	if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if found && strings.HasPrefix(strings.TrimSpace(token), constants.API_KEY_PREFIX) {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+var invalidTokenReasons = []error{
+	utils.ErrAccessTokenMalformed,
+	utils.ErrAccessTokenInvalidSignature,
+	utils.ErrAccessTokenExpired,
+	utils.ErrAccessTokenNotYetValid,
+	utils.ErrAccessTokenInvalidIssuer,
+	utils.ErrAccessTokenInvalidAudience,
+	utils.ErrAccessTokenInvalidClaims,
+	services.ErrTokenRevoked,
+}
+
+// invalidTokenReason returns which check the token failed, or nil when the
+// error is not about the token itself.
+func invalidTokenReason(err error) error {
+	for _, reason := range invalidTokenReasons {
+		if errors.Is(err, reason) {
+			return reason
+		}
+	}
+	return nil
 }
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/audit_log_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIAuditLogRepository is a mock of IAuditLogRepository interface.
+type MockIAuditLogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuditLogRepositoryMockRecorder
+}
+
+// MockIAuditLogRepositoryMockRecorder is the mock recorder for MockIAuditLogRepository.
+type MockIAuditLogRepositoryMockRecorder struct {
+	mock *MockIAuditLogRepository
+}
+
+// NewMockIAuditLogRepository creates a new mock instance.
+func NewMockIAuditLogRepository(ctrl *gomock.Controller) *MockIAuditLogRepository {
+	mock := &MockIAuditLogRepository{ctrl: ctrl}
+	mock.recorder = &MockIAuditLogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuditLogRepository) EXPECT() *MockIAuditLogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIAuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIAuditLogRepositoryMockRecorder) Create(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIAuditLogRepository)(nil).Create), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockIAuditLogRepository) List(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, limit)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIAuditLogRepositoryMockRecorder) List(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIAuditLogRepository)(nil).List), ctx, limit)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/utils/id_token.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	utils "my-go-api/internal/utils"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIIDTokenVerifier is a mock of IIDTokenVerifier interface.
+type MockIIDTokenVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockIIDTokenVerifierMockRecorder
+}
+
+// MockIIDTokenVerifierMockRecorder is the mock recorder for MockIIDTokenVerifier.
+type MockIIDTokenVerifierMockRecorder struct {
+	mock *MockIIDTokenVerifier
+}
+
+// NewMockIIDTokenVerifier creates a new mock instance.
+func NewMockIIDTokenVerifier(ctrl *gomock.Controller) *MockIIDTokenVerifier {
+	mock := &MockIIDTokenVerifier{ctrl: ctrl}
+	mock.recorder = &MockIIDTokenVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIIDTokenVerifier) EXPECT() *MockIIDTokenVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockIIDTokenVerifier) Verify(ctx context.Context, rawToken string) (*utils.IDTokenClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, rawToken)
+	ret0, _ := ret[0].(*utils.IDTokenClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockIIDTokenVerifierMockRecorder) Verify(ctx, rawToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockIIDTokenVerifier)(nil).Verify), ctx, rawToken)
+}
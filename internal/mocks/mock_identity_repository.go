@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/identity_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIIdentityRepository is a mock of IIdentityRepository interface.
+type MockIIdentityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIIdentityRepositoryMockRecorder
+}
+
+// MockIIdentityRepositoryMockRecorder is the mock recorder for MockIIdentityRepository.
+type MockIIdentityRepositoryMockRecorder struct {
+	mock *MockIIdentityRepository
+}
+
+// NewMockIIdentityRepository creates a new mock instance.
+func NewMockIIdentityRepository(ctrl *gomock.Controller) *MockIIdentityRepository {
+	mock := &MockIIdentityRepository{ctrl: ctrl}
+	mock.recorder = &MockIIdentityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIIdentityRepository) EXPECT() *MockIIdentityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIIdentityRepository) Create(ctx context.Context, userId uuid.UUID, provider, subject, email string) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userId, provider, subject, email)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIIdentityRepositoryMockRecorder) Create(ctx, userId, provider, subject, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIIdentityRepository)(nil).Create), ctx, userId, provider, subject, email)
+}
+
+// GetByProviderSubject mocks base method.
+func (m *MockIIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByProviderSubject", ctx, provider, subject)
+	ret0, _ := ret[0].(*models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByProviderSubject indicates an expected call of GetByProviderSubject.
+func (mr *MockIIdentityRepositoryMockRecorder) GetByProviderSubject(ctx, provider, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByProviderSubject", reflect.TypeOf((*MockIIdentityRepository)(nil).GetByProviderSubject), ctx, provider, subject)
+}
+
+// GetByUser mocks base method.
+func (m *MockIIdentityRepository) GetByUser(ctx context.Context, userId uuid.UUID) ([]models.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUser", ctx, userId)
+	ret0, _ := ret[0].([]models.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUser indicates an expected call of GetByUser.
+func (mr *MockIIdentityRepositoryMockRecorder) GetByUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUser", reflect.TypeOf((*MockIIdentityRepository)(nil).GetByUser), ctx, userId)
+}
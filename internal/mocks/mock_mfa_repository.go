@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/mfa_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIMfaRepository is a mock of IMfaRepository interface.
+type MockIMfaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMfaRepositoryMockRecorder
+}
+
+// MockIMfaRepositoryMockRecorder is the mock recorder for MockIMfaRepository.
+type MockIMfaRepositoryMockRecorder struct {
+	mock *MockIMfaRepository
+}
+
+// NewMockIMfaRepository creates a new mock instance.
+func NewMockIMfaRepository(ctrl *gomock.Controller) *MockIMfaRepository {
+	mock := &MockIMfaRepository{ctrl: ctrl}
+	mock.recorder = &MockIMfaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMfaRepository) EXPECT() *MockIMfaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimStep mocks base method.
+func (m *MockIMfaRepository) ClaimStep(ctx context.Context, userId uuid.UUID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimStep", ctx, userId, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimStep indicates an expected call of ClaimStep.
+func (mr *MockIMfaRepositoryMockRecorder) ClaimStep(ctx, userId, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimStep", reflect.TypeOf((*MockIMfaRepository)(nil).ClaimStep), ctx, userId, step)
+}
+
+// Delete mocks base method.
+func (m *MockIMfaRepository) Delete(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIMfaRepositoryMockRecorder) Delete(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIMfaRepository)(nil).Delete), ctx, userId)
+}
+
+// Enable mocks base method.
+func (m *MockIMfaRepository) Enable(ctx context.Context, userId uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, userId, step, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockIMfaRepositoryMockRecorder) Enable(ctx, userId, step, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockIMfaRepository)(nil).Enable), ctx, userId, step, recoveryCodeHashes)
+}
+
+// GetByUserId mocks base method.
+func (m *MockIMfaRepository) GetByUserId(ctx context.Context, userId uuid.UUID) (*models.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUserId", ctx, userId)
+	ret0, _ := ret[0].(*models.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUserId indicates an expected call of GetByUserId.
+func (mr *MockIMfaRepositoryMockRecorder) GetByUserId(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserId", reflect.TypeOf((*MockIMfaRepository)(nil).GetByUserId), ctx, userId)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockIMfaRepository) ReplaceRecoveryCodes(ctx context.Context, userId uuid.UUID, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userId, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockIMfaRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userId, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockIMfaRepository)(nil).ReplaceRecoveryCodes), ctx, userId, codeHashes)
+}
+
+// SaveSecret mocks base method.
+func (m *MockIMfaRepository) SaveSecret(ctx context.Context, userId uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecret", ctx, userId, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecret indicates an expected call of SaveSecret.
+func (mr *MockIMfaRepositoryMockRecorder) SaveSecret(ctx, userId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecret", reflect.TypeOf((*MockIMfaRepository)(nil).SaveSecret), ctx, userId, secret)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockIMfaRepository) UseRecoveryCode(ctx context.Context, userId uuid.UUID, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userId, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockIMfaRepositoryMockRecorder) UseRecoveryCode(ctx, userId, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockIMfaRepository)(nil).UseRecoveryCode), ctx, userId, codeHash)
+}
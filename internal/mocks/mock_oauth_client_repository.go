@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/oauth_client_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIOAuthClientRepository is a mock of IOAuthClientRepository interface.
+type MockIOAuthClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOAuthClientRepositoryMockRecorder
+}
+
+// MockIOAuthClientRepositoryMockRecorder is the mock recorder for MockIOAuthClientRepository.
+type MockIOAuthClientRepositoryMockRecorder struct {
+	mock *MockIOAuthClientRepository
+}
+
+// NewMockIOAuthClientRepository creates a new mock instance.
+func NewMockIOAuthClientRepository(ctrl *gomock.Controller) *MockIOAuthClientRepository {
+	mock := &MockIOAuthClientRepository{ctrl: ctrl}
+	mock.recorder = &MockIOAuthClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOAuthClientRepository) EXPECT() *MockIOAuthClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIOAuthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIOAuthClientRepositoryMockRecorder) Create(ctx, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIOAuthClientRepository)(nil).Create), ctx, client)
+}
+
+// CreateGrant mocks base method.
+func (m *MockIOAuthClientRepository) CreateGrant(ctx context.Context, grant *models.OAuthGrant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGrant", ctx, grant)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGrant indicates an expected call of CreateGrant.
+func (mr *MockIOAuthClientRepositoryMockRecorder) CreateGrant(ctx, grant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGrant", reflect.TypeOf((*MockIOAuthClientRepository)(nil).CreateGrant), ctx, grant)
+}
+
+// Delete mocks base method.
+func (m *MockIOAuthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIOAuthClientRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIOAuthClientRepository)(nil).Delete), ctx, id)
+}
+
+// GetById mocks base method.
+func (m *MockIOAuthClientRepository) GetById(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockIOAuthClientRepositoryMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockIOAuthClientRepository)(nil).GetById), ctx, id)
+}
+
+// GetGrant mocks base method.
+func (m *MockIOAuthClientRepository) GetGrant(ctx context.Context, deviceId uuid.UUID) (*models.OAuthGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGrant", ctx, deviceId)
+	ret0, _ := ret[0].(*models.OAuthGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGrant indicates an expected call of GetGrant.
+func (mr *MockIOAuthClientRepositoryMockRecorder) GetGrant(ctx, deviceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGrant", reflect.TypeOf((*MockIOAuthClientRepository)(nil).GetGrant), ctx, deviceId)
+}
+
+// List mocks base method.
+func (m *MockIOAuthClientRepository) List(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIOAuthClientRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIOAuthClientRepository)(nil).List), ctx)
+}
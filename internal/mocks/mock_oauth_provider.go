@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/utils/oauth_provider.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	utils "my-go-api/internal/utils"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIOAuthProviderRegistry is a mock of IOAuthProviderRegistry interface.
+type MockIOAuthProviderRegistry struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOAuthProviderRegistryMockRecorder
+}
+
+// MockIOAuthProviderRegistryMockRecorder is the mock recorder for MockIOAuthProviderRegistry.
+type MockIOAuthProviderRegistryMockRecorder struct {
+	mock *MockIOAuthProviderRegistry
+}
+
+// NewMockIOAuthProviderRegistry creates a new mock instance.
+func NewMockIOAuthProviderRegistry(ctrl *gomock.Controller) *MockIOAuthProviderRegistry {
+	mock := &MockIOAuthProviderRegistry{ctrl: ctrl}
+	mock.recorder = &MockIOAuthProviderRegistryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOAuthProviderRegistry) EXPECT() *MockIOAuthProviderRegistryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockIOAuthProviderRegistry) Get(ctx context.Context, name string) (*utils.OAuthProvider, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, name)
+	ret0, _ := ret[0].(*utils.OAuthProvider)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIOAuthProviderRegistryMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIOAuthProviderRegistry)(nil).Get), ctx, name)
+}
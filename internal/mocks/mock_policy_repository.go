@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/policy_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	policy "my-go-api/internal/policy"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIPolicyRepository is a mock of IPolicyRepository interface.
+type MockIPolicyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPolicyRepositoryMockRecorder
+}
+
+// MockIPolicyRepositoryMockRecorder is the mock recorder for MockIPolicyRepository.
+type MockIPolicyRepositoryMockRecorder struct {
+	mock *MockIPolicyRepository
+}
+
+// NewMockIPolicyRepository creates a new mock instance.
+func NewMockIPolicyRepository(ctrl *gomock.Controller) *MockIPolicyRepository {
+	mock := &MockIPolicyRepository{ctrl: ctrl}
+	mock.recorder = &MockIPolicyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPolicyRepository) EXPECT() *MockIPolicyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetSubject mocks base method.
+func (m *MockIPolicyRepository) GetSubject(ctx context.Context, userId uuid.UUID) (*policy.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubject", ctx, userId)
+	ret0, _ := ret[0].(*policy.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubject indicates an expected call of GetSubject.
+func (mr *MockIPolicyRepositoryMockRecorder) GetSubject(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubject", reflect.TypeOf((*MockIPolicyRepository)(nil).GetSubject), ctx, userId)
+}
+
+// ListPolicies mocks base method.
+func (m *MockIPolicyRepository) ListPolicies(ctx context.Context) ([]policy.Rule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPolicies", ctx)
+	ret0, _ := ret[0].([]policy.Rule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPolicies indicates an expected call of ListPolicies.
+func (mr *MockIPolicyRepositoryMockRecorder) ListPolicies(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPolicies", reflect.TypeOf((*MockIPolicyRepository)(nil).ListPolicies), ctx)
+}
@@ -0,0 +1,263 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/redis_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIRedisRepository is a mock of IRedisRepository interface.
+type MockIRedisRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRedisRepositoryMockRecorder
+}
+
+// MockIRedisRepositoryMockRecorder is the mock recorder for MockIRedisRepository.
+type MockIRedisRepositoryMockRecorder struct {
+	mock *MockIRedisRepository
+}
+
+// NewMockIRedisRepository creates a new mock instance.
+func NewMockIRedisRepository(ctrl *gomock.Controller) *MockIRedisRepository {
+	mock := &MockIRedisRepository{ctrl: ctrl}
+	mock.recorder = &MockIRedisRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRedisRepository) EXPECT() *MockIRedisRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockIRedisRepository) Consume(key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockIRedisRepositoryMockRecorder) Consume(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockIRedisRepository)(nil).Consume), key)
+}
+
+// Del mocks base method.
+func (m *MockIRedisRepository) Del(keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Del", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockIRedisRepositoryMockRecorder) Del(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockIRedisRepository)(nil).Del), keys...)
+}
+
+// Exists mocks base method.
+func (m *MockIRedisRepository) Exists(key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockIRedisRepositoryMockRecorder) Exists(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockIRedisRepository)(nil).Exists), key)
+}
+
+// GetDel mocks base method.
+func (m *MockIRedisRepository) GetDel(key string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDel", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDel indicates an expected call of GetDel.
+func (mr *MockIRedisRepositoryMockRecorder) GetDel(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDel", reflect.TypeOf((*MockIRedisRepository)(nil).GetDel), key)
+}
+
+// HGet mocks base method.
+func (m *MockIRedisRepository) HGet(key, field string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HGet", key, field)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HGet indicates an expected call of HGet.
+func (mr *MockIRedisRepositoryMockRecorder) HGet(key, field interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HGet", reflect.TypeOf((*MockIRedisRepository)(nil).HGet), key, field)
+}
+
+// HIncrBy mocks base method.
+func (m *MockIRedisRepository) HIncrBy(key, field string, incr int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HIncrBy", key, field, incr)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HIncrBy indicates an expected call of HIncrBy.
+func (mr *MockIRedisRepositoryMockRecorder) HIncrBy(key, field, incr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HIncrBy", reflect.TypeOf((*MockIRedisRepository)(nil).HIncrBy), key, field, incr)
+}
+
+// HSet mocks base method.
+func (m *MockIRedisRepository) HSet(key string, data map[string]any, expiry time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HSet", key, data, expiry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HSet indicates an expected call of HSet.
+func (mr *MockIRedisRepositoryMockRecorder) HSet(key, data, expiry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HSet", reflect.TypeOf((*MockIRedisRepository)(nil).HSet), key, data, expiry)
+}
+
+// Incr mocks base method.
+func (m *MockIRedisRepository) Incr(key string, window time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", key, window)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockIRedisRepositoryMockRecorder) Incr(key, window interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockIRedisRepository)(nil).Incr), key, window)
+}
+
+// Set mocks base method.
+func (m *MockIRedisRepository) Set(key string, value any, expiry time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", key, value, expiry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockIRedisRepositoryMockRecorder) Set(key, value, expiry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockIRedisRepository)(nil).Set), key, value, expiry)
+}
+
+// SetNX mocks base method.
+func (m *MockIRedisRepository) SetNX(key string, value any, expiry time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", key, value, expiry)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockIRedisRepositoryMockRecorder) SetNX(key, value, expiry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*MockIRedisRepository)(nil).SetNX), key, value, expiry)
+}
+
+// TTL mocks base method.
+func (m *MockIRedisRepository) TTL(key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TTL", key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TTL indicates an expected call of TTL.
+func (mr *MockIRedisRepositoryMockRecorder) TTL(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TTL", reflect.TypeOf((*MockIRedisRepository)(nil).TTL), key)
+}
+
+// ZAdd mocks base method.
+func (m *MockIRedisRepository) ZAdd(key string, score float64, member string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZAdd", key, score, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ZAdd indicates an expected call of ZAdd.
+func (mr *MockIRedisRepositoryMockRecorder) ZAdd(key, score, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZAdd", reflect.TypeOf((*MockIRedisRepository)(nil).ZAdd), key, score, member)
+}
+
+// ZRangeByScore mocks base method.
+func (m *MockIRedisRepository) ZRangeByScore(key, min, max string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRangeByScore", key, min, max)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ZRangeByScore indicates an expected call of ZRangeByScore.
+func (mr *MockIRedisRepositoryMockRecorder) ZRangeByScore(key, min, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRangeByScore", reflect.TypeOf((*MockIRedisRepository)(nil).ZRangeByScore), key, min, max)
+}
+
+// ZRem mocks base method.
+func (m *MockIRedisRepository) ZRem(key string, members ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{key}
+	for _, a := range members {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ZRem", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ZRem indicates an expected call of ZRem.
+func (mr *MockIRedisRepositoryMockRecorder) ZRem(key interface{}, members ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{key}, members...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRem", reflect.TypeOf((*MockIRedisRepository)(nil).ZRem), varargs...)
+}
+
+// ZRemRangeByScore mocks base method.
+func (m *MockIRedisRepository) ZRemRangeByScore(key, min, max string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ZRemRangeByScore", key, min, max)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ZRemRangeByScore indicates an expected call of ZRemRangeByScore.
+func (mr *MockIRedisRepositoryMockRecorder) ZRemRangeByScore(key, min, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ZRemRangeByScore", reflect.TypeOf((*MockIRedisRepository)(nil).ZRemRangeByScore), key, min, max)
+}
@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/role_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIRoleRepository is a mock of IRoleRepository interface.
+type MockIRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRoleRepositoryMockRecorder
+}
+
+// MockIRoleRepositoryMockRecorder is the mock recorder for MockIRoleRepository.
+type MockIRoleRepositoryMockRecorder struct {
+	mock *MockIRoleRepository
+}
+
+// NewMockIRoleRepository creates a new mock instance.
+func NewMockIRoleRepository(ctrl *gomock.Controller) *MockIRoleRepository {
+	mock := &MockIRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockIRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRoleRepository) EXPECT() *MockIRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AssignRole mocks base method.
+func (m *MockIRoleRepository) AssignRole(ctx context.Context, userId uuid.UUID, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockIRoleRepositoryMockRecorder) AssignRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockIRoleRepository)(nil).AssignRole), ctx, userId, role)
+}
+
+// CountUsers mocks base method.
+func (m *MockIRoleRepository) CountUsers(ctx context.Context, role string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsers", ctx, role)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsers indicates an expected call of CountUsers.
+func (mr *MockIRoleRepositoryMockRecorder) CountUsers(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsers", reflect.TypeOf((*MockIRoleRepository)(nil).CountUsers), ctx, role)
+}
+
+// CreateRole mocks base method.
+func (m *MockIRoleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", ctx, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockIRoleRepositoryMockRecorder) CreateRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockIRoleRepository)(nil).CreateRole), ctx, role)
+}
+
+// DeleteRole mocks base method.
+func (m *MockIRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockIRoleRepositoryMockRecorder) DeleteRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockIRoleRepository)(nil).DeleteRole), ctx, name)
+}
+
+// GetRole mocks base method.
+func (m *MockIRoleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", ctx, name)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockIRoleRepositoryMockRecorder) GetRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockIRoleRepository)(nil).GetRole), ctx, name)
+}
+
+// ListPermissions mocks base method.
+func (m *MockIRoleRepository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", ctx)
+	ret0, _ := ret[0].([]models.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) ListPermissions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).ListPermissions), ctx)
+}
+
+// ListRoles mocks base method.
+func (m *MockIRoleRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", ctx)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockIRoleRepositoryMockRecorder) ListRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockIRoleRepository)(nil).ListRoles), ctx)
+}
+
+// PermissionsOfUser mocks base method.
+func (m *MockIRoleRepository) PermissionsOfUser(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PermissionsOfUser", ctx, userId)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PermissionsOfUser indicates an expected call of PermissionsOfUser.
+func (mr *MockIRoleRepositoryMockRecorder) PermissionsOfUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PermissionsOfUser", reflect.TypeOf((*MockIRoleRepository)(nil).PermissionsOfUser), ctx, userId)
+}
+
+// SetPermissions mocks base method.
+func (m *MockIRoleRepository) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPermissions", ctx, role, permissions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPermissions indicates an expected call of SetPermissions.
+func (mr *MockIRoleRepositoryMockRecorder) SetPermissions(ctx, role, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPermissions", reflect.TypeOf((*MockIRoleRepository)(nil).SetPermissions), ctx, role, permissions)
+}
@@ -38,6 +38,21 @@ func (m *MockIAuthService) EXPECT() *MockIAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateEmailVerificationToken mocks base method.
+func (m *MockIAuthService) CreateEmailVerificationToken(ctx context.Context, userId uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailVerificationToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailVerificationToken indicates an expected call of CreateEmailVerificationToken.
+func (mr *MockIAuthServiceMockRecorder) CreateEmailVerificationToken(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailVerificationToken", reflect.TypeOf((*MockIAuthService)(nil).CreateEmailVerificationToken), ctx, userId)
+}
+
 // CreateUser mocks base method.
 func (m *MockIAuthService) CreateUser(ctx context.Context, req dto.CreateUser) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +68,20 @@ func (mr *MockIAuthServiceMockRecorder) CreateUser(ctx, req interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockIAuthService)(nil).CreateUser), ctx, req)
 }
 
+// DeleteAllRefreshTokens mocks base method.
+func (m *MockIAuthService) DeleteAllRefreshTokens(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllRefreshTokens", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllRefreshTokens indicates an expected call of DeleteAllRefreshTokens.
+func (mr *MockIAuthServiceMockRecorder) DeleteAllRefreshTokens(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllRefreshTokens", reflect.TypeOf((*MockIAuthService)(nil).DeleteAllRefreshTokens), ctx, userId)
+}
+
 // DeleteRefreshToken mocks base method.
 func (m *MockIAuthService) DeleteRefreshToken(ctx context.Context, userId, deviceId uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -67,6 +96,20 @@ func (mr *MockIAuthServiceMockRecorder) DeleteRefreshToken(ctx, userId, deviceId
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).DeleteRefreshToken), ctx, userId, deviceId)
 }
 
+// EnsureEmailVerified mocks base method.
+func (m *MockIAuthService) EnsureEmailVerified(user *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEmailVerified", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureEmailVerified indicates an expected call of EnsureEmailVerified.
+func (mr *MockIAuthServiceMockRecorder) EnsureEmailVerified(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEmailVerified", reflect.TypeOf((*MockIAuthService)(nil).EnsureEmailVerified), user)
+}
+
 // GenerateRefreshToken mocks base method.
 func (m *MockIAuthService) GenerateRefreshToken() (string, string, error) {
 	m.ctrl.T.Helper()
@@ -83,19 +126,34 @@ func (mr *MockIAuthServiceMockRecorder) GenerateRefreshToken() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).GenerateRefreshToken))
 }
 
+// GenerateScopedToken mocks base method.
+func (m *MockIAuthService) GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateScopedToken", subject, clientId, scope, jti)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateScopedToken indicates an expected call of GenerateScopedToken.
+func (mr *MockIAuthServiceMockRecorder) GenerateScopedToken(subject, clientId, scope, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateScopedToken", reflect.TypeOf((*MockIAuthService)(nil).GenerateScopedToken), subject, clientId, scope, jti)
+}
+
 // GenerateToken mocks base method.
-func (m *MockIAuthService) GenerateToken(userId uuid.UUID) (string, error) {
+func (m *MockIAuthService) GenerateToken(userId, jti uuid.UUID) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", userId)
+	ret := m.ctrl.Call(m, "GenerateToken", userId, jti)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockIAuthServiceMockRecorder) GenerateToken(userId interface{}) *gomock.Call {
+func (mr *MockIAuthServiceMockRecorder) GenerateToken(userId, jti interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockIAuthService)(nil).GenerateToken), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockIAuthService)(nil).GenerateToken), userId, jti)
 }
 
 // GetUserByIdentity mocks base method.
@@ -113,6 +171,78 @@ func (mr *MockIAuthServiceMockRecorder) GetUserByIdentity(ctx, identity interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByIdentity", reflect.TypeOf((*MockIAuthService)(nil).GetUserByIdentity), ctx, identity)
 }
 
+// RequestPasswordReset mocks base method.
+func (m *MockIAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockIAuthServiceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockIAuthService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResendVerificationEmail mocks base method.
+func (m *MockIAuthService) ResendVerificationEmail(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendVerificationEmail", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendVerificationEmail indicates an expected call of ResendVerificationEmail.
+func (mr *MockIAuthServiceMockRecorder) ResendVerificationEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendVerificationEmail", reflect.TypeOf((*MockIAuthService)(nil).ResendVerificationEmail), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockIAuthService) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockIAuthServiceMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockIAuthService)(nil).ResetPassword), ctx, token, password)
+}
+
+// RotateClientRefreshToken mocks base method.
+func (m *MockIAuthService) RotateClientRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, token string, meta models.SessionMetadata) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateClientRefreshToken", ctx, jti, userId, deviceId, clientId, token, meta)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateClientRefreshToken indicates an expected call of RotateClientRefreshToken.
+func (mr *MockIAuthServiceMockRecorder) RotateClientRefreshToken(ctx, jti, userId, deviceId, clientId, token, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateClientRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).RotateClientRefreshToken), ctx, jti, userId, deviceId, clientId, token, meta)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockIAuthService) RotateRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, token string, meta models.SessionMetadata) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, jti, userId, deviceId, token, meta)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockIAuthServiceMockRecorder) RotateRefreshToken(ctx, jti, userId, deviceId, token, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).RotateRefreshToken), ctx, jti, userId, deviceId, token, meta)
+}
+
 // SendVerificationEmail mocks base method.
 func (m *MockIAuthService) SendVerificationEmail(name, email, token string) error {
 	m.ctrl.T.Helper()
@@ -127,18 +257,46 @@ func (mr *MockIAuthServiceMockRecorder) SendVerificationEmail(name, email, token
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockIAuthService)(nil).SendVerificationEmail), name, email, token)
 }
 
+// StoreClientRefreshToken mocks base method.
+func (m *MockIAuthService) StoreClientRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, hash string, meta models.SessionMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreClientRefreshToken", ctx, jti, userId, deviceId, clientId, hash, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreClientRefreshToken indicates an expected call of StoreClientRefreshToken.
+func (mr *MockIAuthServiceMockRecorder) StoreClientRefreshToken(ctx, jti, userId, deviceId, clientId, hash, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreClientRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).StoreClientRefreshToken), ctx, jti, userId, deviceId, clientId, hash, meta)
+}
+
 // StoreRefreshToken mocks base method.
-func (m *MockIAuthService) StoreRefreshToken(ctx context.Context, userId, deviceId uuid.UUID, hash string) error {
+func (m *MockIAuthService) StoreRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, hash string, meta models.SessionMetadata) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreRefreshToken", ctx, userId, deviceId, hash)
+	ret := m.ctrl.Call(m, "StoreRefreshToken", ctx, jti, userId, deviceId, hash, meta)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreRefreshToken indicates an expected call of StoreRefreshToken.
-func (mr *MockIAuthServiceMockRecorder) StoreRefreshToken(ctx, userId, deviceId, hash interface{}) *gomock.Call {
+func (mr *MockIAuthServiceMockRecorder) StoreRefreshToken(ctx, jti, userId, deviceId, hash, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).StoreRefreshToken), ctx, jti, userId, deviceId, hash, meta)
+}
+
+// UpgradePasswordHash mocks base method.
+func (m *MockIAuthService) UpgradePasswordHash(ctx context.Context, user *models.User, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpgradePasswordHash", ctx, user, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpgradePasswordHash indicates an expected call of UpgradePasswordHash.
+func (mr *MockIAuthServiceMockRecorder) UpgradePasswordHash(ctx, user, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).StoreRefreshToken), ctx, userId, deviceId, hash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpgradePasswordHash", reflect.TypeOf((*MockIAuthService)(nil).UpgradePasswordHash), ctx, user, password)
 }
 
 // ValidateToken mocks base method.
@@ -156,6 +314,21 @@ func (mr *MockIAuthServiceMockRecorder) ValidateToken(tokenString interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockIAuthService)(nil).ValidateToken), tokenString)
 }
 
+// VerifyEmail mocks base method.
+func (m *MockIAuthService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockIAuthServiceMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockIAuthService)(nil).VerifyEmail), ctx, token)
+}
+
 // VerifyPassword mocks base method.
 func (m *MockIAuthService) VerifyPassword(hashedPassword, plainPassword string) bool {
 	m.ctrl.T.Helper()
@@ -169,17 +342,3 @@ func (mr *MockIAuthServiceMockRecorder) VerifyPassword(hashedPassword, plainPass
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyPassword", reflect.TypeOf((*MockIAuthService)(nil).VerifyPassword), hashedPassword, plainPassword)
 }
-
-// VerifyRefreshToken mocks base method.
-func (m *MockIAuthService) VerifyRefreshToken(ctx context.Context, userId, deviceId uuid.UUID, token string) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "VerifyRefreshToken", ctx, userId, deviceId, token)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// VerifyRefreshToken indicates an expected call of VerifyRefreshToken.
-func (mr *MockIAuthServiceMockRecorder) VerifyRefreshToken(ctx, userId, deviceId, token interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRefreshToken", reflect.TypeOf((*MockIAuthService)(nil).VerifyRefreshToken), ctx, userId, deviceId, token)
-}
@@ -0,0 +1,201 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/authorization_server.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	dto "my-go-api/internal/dto"
+	models "my-go-api/internal/models"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIAuthorizationServer is a mock of IAuthorizationServer interface.
+type MockIAuthorizationServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockIAuthorizationServerMockRecorder
+}
+
+// MockIAuthorizationServerMockRecorder is the mock recorder for MockIAuthorizationServer.
+type MockIAuthorizationServerMockRecorder struct {
+	mock *MockIAuthorizationServer
+}
+
+// NewMockIAuthorizationServer creates a new mock instance.
+func NewMockIAuthorizationServer(ctrl *gomock.Controller) *MockIAuthorizationServer {
+	mock := &MockIAuthorizationServer{ctrl: ctrl}
+	mock.recorder = &MockIAuthorizationServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIAuthorizationServer) EXPECT() *MockIAuthorizationServerMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateClient mocks base method.
+func (m *MockIAuthorizationServer) AuthenticateClient(ctx context.Context, clientId, secret string) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateClient", ctx, clientId, secret)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateClient indicates an expected call of AuthenticateClient.
+func (mr *MockIAuthorizationServerMockRecorder) AuthenticateClient(ctx, clientId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateClient", reflect.TypeOf((*MockIAuthorizationServer)(nil).AuthenticateClient), ctx, clientId, secret)
+}
+
+// AuthenticateInternalKey mocks base method.
+func (m *MockIAuthorizationServer) AuthenticateInternalKey(key string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateInternalKey", key)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// AuthenticateInternalKey indicates an expected call of AuthenticateInternalKey.
+func (mr *MockIAuthorizationServerMockRecorder) AuthenticateInternalKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateInternalKey", reflect.TypeOf((*MockIAuthorizationServer)(nil).AuthenticateInternalKey), key)
+}
+
+// Authorize mocks base method.
+func (m *MockIAuthorizationServer) Authorize(ctx context.Context, userId uuid.UUID, req dto.OAuthAuthorize) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userId, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockIAuthorizationServerMockRecorder) Authorize(ctx, userId, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockIAuthorizationServer)(nil).Authorize), ctx, userId, req)
+}
+
+// DeleteClient mocks base method.
+func (m *MockIAuthorizationServer) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClient", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteClient indicates an expected call of DeleteClient.
+func (mr *MockIAuthorizationServerMockRecorder) DeleteClient(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClient", reflect.TypeOf((*MockIAuthorizationServer)(nil).DeleteClient), ctx, id)
+}
+
+// Discovery mocks base method.
+func (m *MockIAuthorizationServer) Discovery() dto.OpenIDConfiguration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Discovery")
+	ret0, _ := ret[0].(dto.OpenIDConfiguration)
+	return ret0
+}
+
+// Discovery indicates an expected call of Discovery.
+func (mr *MockIAuthorizationServerMockRecorder) Discovery() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Discovery", reflect.TypeOf((*MockIAuthorizationServer)(nil).Discovery))
+}
+
+// Introspect mocks base method.
+func (m *MockIAuthorizationServer) Introspect(ctx context.Context, caller *models.OAuthClient, token, hint string) (*dto.OAuthIntrospection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Introspect", ctx, caller, token, hint)
+	ret0, _ := ret[0].(*dto.OAuthIntrospection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Introspect indicates an expected call of Introspect.
+func (mr *MockIAuthorizationServerMockRecorder) Introspect(ctx, caller, token, hint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Introspect", reflect.TypeOf((*MockIAuthorizationServer)(nil).Introspect), ctx, caller, token, hint)
+}
+
+// ListClients mocks base method.
+func (m *MockIAuthorizationServer) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClients", ctx)
+	ret0, _ := ret[0].([]models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClients indicates an expected call of ListClients.
+func (mr *MockIAuthorizationServerMockRecorder) ListClients(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClients", reflect.TypeOf((*MockIAuthorizationServer)(nil).ListClients), ctx)
+}
+
+// RegisterClient mocks base method.
+func (m *MockIAuthorizationServer) RegisterClient(ctx context.Context, req dto.CreateOAuthClient) (*models.OAuthClient, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterClient", ctx, req)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RegisterClient indicates an expected call of RegisterClient.
+func (mr *MockIAuthorizationServerMockRecorder) RegisterClient(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterClient", reflect.TypeOf((*MockIAuthorizationServer)(nil).RegisterClient), ctx, req)
+}
+
+// Revoke mocks base method.
+func (m *MockIAuthorizationServer) Revoke(ctx context.Context, caller *models.OAuthClient, token, hint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, caller, token, hint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockIAuthorizationServerMockRecorder) Revoke(ctx, caller, token, hint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockIAuthorizationServer)(nil).Revoke), ctx, caller, token, hint)
+}
+
+// Token mocks base method.
+func (m *MockIAuthorizationServer) Token(ctx context.Context, client *models.OAuthClient, req services.TokenRequest, meta models.SessionMetadata) (*dto.OAuthToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", ctx, client, req, meta)
+	ret0, _ := ret[0].(*dto.OAuthToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockIAuthorizationServerMockRecorder) Token(ctx, client, req, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockIAuthorizationServer)(nil).Token), ctx, client, req, meta)
+}
+
+// UserInfo mocks base method.
+func (m *MockIAuthorizationServer) UserInfo(ctx context.Context, userId uuid.UUID, scope string) (*dto.UserInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserInfo", ctx, userId, scope)
+	ret0, _ := ret[0].(*dto.UserInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserInfo indicates an expected call of UserInfo.
+func (mr *MockIAuthorizationServerMockRecorder) UserInfo(ctx, userId, scope interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserInfo", reflect.TypeOf((*MockIAuthorizationServer)(nil).UserInfo), ctx, userId, scope)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/google_auth_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIGoogleAuthService is a mock of IGoogleAuthService interface.
+type MockIGoogleAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIGoogleAuthServiceMockRecorder
+}
+
+// MockIGoogleAuthServiceMockRecorder is the mock recorder for MockIGoogleAuthService.
+type MockIGoogleAuthServiceMockRecorder struct {
+	mock *MockIGoogleAuthService
+}
+
+// NewMockIGoogleAuthService creates a new mock instance.
+func NewMockIGoogleAuthService(ctrl *gomock.Controller) *MockIGoogleAuthService {
+	mock := &MockIGoogleAuthService{ctrl: ctrl}
+	mock.recorder = &MockIGoogleAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIGoogleAuthService) EXPECT() *MockIGoogleAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockIGoogleAuthService) Authenticate(ctx context.Context, idToken string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, idToken)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockIGoogleAuthServiceMockRecorder) Authenticate(ctx, idToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockIGoogleAuthService)(nil).Authenticate), ctx, idToken)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/impersonation_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIImpersonationService is a mock of IImpersonationService interface.
+type MockIImpersonationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIImpersonationServiceMockRecorder
+}
+
+// MockIImpersonationServiceMockRecorder is the mock recorder for MockIImpersonationService.
+type MockIImpersonationServiceMockRecorder struct {
+	mock *MockIImpersonationService
+}
+
+// NewMockIImpersonationService creates a new mock instance.
+func NewMockIImpersonationService(ctrl *gomock.Controller) *MockIImpersonationService {
+	mock := &MockIImpersonationService{ctrl: ctrl}
+	mock.recorder = &MockIImpersonationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIImpersonationService) EXPECT() *MockIImpersonationServiceMockRecorder {
+	return m.recorder
+}
+
+// AuditLog mocks base method.
+func (m *MockIImpersonationService) AuditLog(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditLog", ctx, limit)
+	ret0, _ := ret[0].([]models.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditLog indicates an expected call of AuditLog.
+func (mr *MockIImpersonationServiceMockRecorder) AuditLog(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditLog", reflect.TypeOf((*MockIImpersonationService)(nil).AuditLog), ctx, limit)
+}
+
+// Start mocks base method.
+func (m *MockIImpersonationService) Start(ctx context.Context, actorId, userId uuid.UUID, reason string, meta models.SessionMetadata) (*services.Impersonation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, actorId, userId, reason, meta)
+	ret0, _ := ret[0].(*services.Impersonation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockIImpersonationServiceMockRecorder) Start(ctx, actorId, userId, reason, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockIImpersonationService)(nil).Start), ctx, actorId, userId, reason, meta)
+}
+
+// Stop mocks base method.
+func (m *MockIImpersonationService) Stop(ctx context.Context, token *services.TokenPayload, meta models.SessionMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx, token, meta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockIImpersonationServiceMockRecorder) Stop(ctx, token, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockIImpersonationService)(nil).Stop), ctx, token, meta)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/lockout_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockILockoutService is a mock of ILockoutService interface.
+type MockILockoutService struct {
+	ctrl     *gomock.Controller
+	recorder *MockILockoutServiceMockRecorder
+}
+
+// MockILockoutServiceMockRecorder is the mock recorder for MockILockoutService.
+type MockILockoutServiceMockRecorder struct {
+	mock *MockILockoutService
+}
+
+// NewMockILockoutService creates a new mock instance.
+func NewMockILockoutService(ctrl *gomock.Controller) *MockILockoutService {
+	mock := &MockILockoutService{ctrl: ctrl}
+	mock.recorder = &MockILockoutServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILockoutService) EXPECT() *MockILockoutServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockILockoutService) Check(ctx context.Context, user *models.User, identity, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, user, identity, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockILockoutServiceMockRecorder) Check(ctx, user, identity, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockILockoutService)(nil).Check), ctx, user, identity, ip)
+}
+
+// ListLocked mocks base method.
+func (m *MockILockoutService) ListLocked(ctx context.Context) ([]services.LockedAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLocked", ctx)
+	ret0, _ := ret[0].([]services.LockedAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLocked indicates an expected call of ListLocked.
+func (mr *MockILockoutServiceMockRecorder) ListLocked(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLocked", reflect.TypeOf((*MockILockoutService)(nil).ListLocked), ctx)
+}
+
+// RecordFailure mocks base method.
+func (m *MockILockoutService) RecordFailure(ctx context.Context, user *models.User, identity, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, user, identity, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailure indicates an expected call of RecordFailure.
+func (mr *MockILockoutServiceMockRecorder) RecordFailure(ctx, user, identity, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockILockoutService)(nil).RecordFailure), ctx, user, identity, ip)
+}
+
+// RecordSuccess mocks base method.
+func (m *MockILockoutService) RecordSuccess(ctx context.Context, user *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSuccess", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSuccess indicates an expected call of RecordSuccess.
+func (mr *MockILockoutServiceMockRecorder) RecordSuccess(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSuccess", reflect.TypeOf((*MockILockoutService)(nil).RecordSuccess), ctx, user)
+}
+
+// Unlock mocks base method.
+func (m *MockILockoutService) Unlock(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockILockoutServiceMockRecorder) Unlock(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockILockoutService)(nil).Unlock), ctx, token)
+}
+
+// UnlockUser mocks base method.
+func (m *MockILockoutService) UnlockUser(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockILockoutServiceMockRecorder) UnlockUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockILockoutService)(nil).UnlockUser), ctx, userId)
+}
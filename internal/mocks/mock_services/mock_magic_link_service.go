@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/magic_link_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIMagicLinkService is a mock of IMagicLinkService interface.
+type MockIMagicLinkService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMagicLinkServiceMockRecorder
+}
+
+// MockIMagicLinkServiceMockRecorder is the mock recorder for MockIMagicLinkService.
+type MockIMagicLinkServiceMockRecorder struct {
+	mock *MockIMagicLinkService
+}
+
+// NewMockIMagicLinkService creates a new mock instance.
+func NewMockIMagicLinkService(ctrl *gomock.Controller) *MockIMagicLinkService {
+	mock := &MockIMagicLinkService{ctrl: ctrl}
+	mock.recorder = &MockIMagicLinkServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMagicLinkService) EXPECT() *MockIMagicLinkServiceMockRecorder {
+	return m.recorder
+}
+
+// ConsumeLink mocks base method.
+func (m *MockIMagicLinkService) ConsumeLink(ctx context.Context, token, binding string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLink", ctx, token, binding)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeLink indicates an expected call of ConsumeLink.
+func (mr *MockIMagicLinkServiceMockRecorder) ConsumeLink(ctx, token, binding interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLink", reflect.TypeOf((*MockIMagicLinkService)(nil).ConsumeLink), ctx, token, binding)
+}
+
+// RequestLink mocks base method.
+func (m *MockIMagicLinkService) RequestLink(ctx context.Context, email string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestLink", ctx, email)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestLink indicates an expected call of RequestLink.
+func (mr *MockIMagicLinkServiceMockRecorder) RequestLink(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestLink", reflect.TypeOf((*MockIMagicLinkService)(nil).RequestLink), ctx, email)
+}
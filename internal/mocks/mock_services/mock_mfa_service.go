@@ -0,0 +1,171 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/mfa_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIMfaService is a mock of IMfaService interface.
+type MockIMfaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIMfaServiceMockRecorder
+}
+
+// MockIMfaServiceMockRecorder is the mock recorder for MockIMfaService.
+type MockIMfaServiceMockRecorder struct {
+	mock *MockIMfaService
+}
+
+// NewMockIMfaService creates a new mock instance.
+func NewMockIMfaService(ctrl *gomock.Controller) *MockIMfaService {
+	mock := &MockIMfaService{ctrl: ctrl}
+	mock.recorder = &MockIMfaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIMfaService) EXPECT() *MockIMfaServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEnrollment mocks base method.
+func (m *MockIMfaService) ConfirmEnrollment(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEnrollment", ctx, userId, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEnrollment indicates an expected call of ConfirmEnrollment.
+func (mr *MockIMfaServiceMockRecorder) ConfirmEnrollment(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEnrollment", reflect.TypeOf((*MockIMfaService)(nil).ConfirmEnrollment), ctx, userId, code)
+}
+
+// ConsumeTicket mocks base method.
+func (m *MockIMfaService) ConsumeTicket(ctx context.Context, ticket string, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeTicket", ctx, ticket, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeTicket indicates an expected call of ConsumeTicket.
+func (mr *MockIMfaServiceMockRecorder) ConsumeTicket(ctx, ticket, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeTicket", reflect.TypeOf((*MockIMfaService)(nil).ConsumeTicket), ctx, ticket, userId)
+}
+
+// CreateTicket mocks base method.
+func (m *MockIMfaService) CreateTicket(ctx context.Context, userId uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTicket", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTicket indicates an expected call of CreateTicket.
+func (mr *MockIMfaServiceMockRecorder) CreateTicket(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTicket", reflect.TypeOf((*MockIMfaService)(nil).CreateTicket), ctx, userId)
+}
+
+// Disable mocks base method.
+func (m *MockIMfaService) Disable(ctx context.Context, userId uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userId, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockIMfaServiceMockRecorder) Disable(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockIMfaService)(nil).Disable), ctx, userId, code)
+}
+
+// IsEnabled mocks base method.
+func (m *MockIMfaService) IsEnabled(ctx context.Context, userId uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEnabled indicates an expected call of IsEnabled.
+func (mr *MockIMfaServiceMockRecorder) IsEnabled(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockIMfaService)(nil).IsEnabled), ctx, userId)
+}
+
+// RedeemTicket mocks base method.
+func (m *MockIMfaService) RedeemTicket(ctx context.Context, ticket, code string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemTicket", ctx, ticket, code)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemTicket indicates an expected call of RedeemTicket.
+func (mr *MockIMfaServiceMockRecorder) RedeemTicket(ctx, ticket, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemTicket", reflect.TypeOf((*MockIMfaService)(nil).RedeemTicket), ctx, ticket, code)
+}
+
+// RegenerateRecoveryCodes mocks base method.
+func (m *MockIMfaService) RegenerateRecoveryCodes(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateRecoveryCodes", ctx, userId, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegenerateRecoveryCodes indicates an expected call of RegenerateRecoveryCodes.
+func (mr *MockIMfaServiceMockRecorder) RegenerateRecoveryCodes(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateRecoveryCodes", reflect.TypeOf((*MockIMfaService)(nil).RegenerateRecoveryCodes), ctx, userId, code)
+}
+
+// StartEnrollment mocks base method.
+func (m *MockIMfaService) StartEnrollment(ctx context.Context, user *models.User) (*services.MfaEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartEnrollment", ctx, user)
+	ret0, _ := ret[0].(*services.MfaEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartEnrollment indicates an expected call of StartEnrollment.
+func (mr *MockIMfaServiceMockRecorder) StartEnrollment(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartEnrollment", reflect.TypeOf((*MockIMfaService)(nil).StartEnrollment), ctx, user)
+}
+
+// TicketUserId mocks base method.
+func (m *MockIMfaService) TicketUserId(ctx context.Context, ticket string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TicketUserId", ctx, ticket)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TicketUserId indicates an expected call of TicketUserId.
+func (mr *MockIMfaServiceMockRecorder) TicketUserId(ctx, ticket interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TicketUserId", reflect.TypeOf((*MockIMfaService)(nil).TicketUserId), ctx, ticket)
+}
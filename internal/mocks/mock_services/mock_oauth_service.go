@@ -0,0 +1,67 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/oauth_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIOAuthService is a mock of IOAuthService interface.
+type MockIOAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIOAuthServiceMockRecorder
+}
+
+// MockIOAuthServiceMockRecorder is the mock recorder for MockIOAuthService.
+type MockIOAuthServiceMockRecorder struct {
+	mock *MockIOAuthService
+}
+
+// NewMockIOAuthService creates a new mock instance.
+func NewMockIOAuthService(ctrl *gomock.Controller) *MockIOAuthService {
+	mock := &MockIOAuthService{ctrl: ctrl}
+	mock.recorder = &MockIOAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIOAuthService) EXPECT() *MockIOAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// Callback mocks base method.
+func (m *MockIOAuthService) Callback(ctx context.Context, provider, code, state, binding string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Callback", ctx, provider, code, state, binding)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Callback indicates an expected call of Callback.
+func (mr *MockIOAuthServiceMockRecorder) Callback(ctx, provider, code, state, binding interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Callback", reflect.TypeOf((*MockIOAuthService)(nil).Callback), ctx, provider, code, state, binding)
+}
+
+// Start mocks base method.
+func (m *MockIOAuthService) Start(ctx context.Context, provider string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx, provider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockIOAuthServiceMockRecorder) Start(ctx, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockIOAuthService)(nil).Start), ctx, provider)
+}
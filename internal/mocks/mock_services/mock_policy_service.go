@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/policy_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	policy "my-go-api/internal/policy"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIPolicyService is a mock of IPolicyService interface.
+type MockIPolicyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIPolicyServiceMockRecorder
+}
+
+// MockIPolicyServiceMockRecorder is the mock recorder for MockIPolicyService.
+type MockIPolicyServiceMockRecorder struct {
+	mock *MockIPolicyService
+}
+
+// NewMockIPolicyService creates a new mock instance.
+func NewMockIPolicyService(ctrl *gomock.Controller) *MockIPolicyService {
+	mock := &MockIPolicyService{ctrl: ctrl}
+	mock.recorder = &MockIPolicyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIPolicyService) EXPECT() *MockIPolicyServiceMockRecorder {
+	return m.recorder
+}
+
+// Authorize mocks base method.
+func (m *MockIPolicyService) Authorize(ctx context.Context, userId uuid.UUID, action policy.Action, resource policy.Resource, env policy.Context) (policy.Decision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authorize", ctx, userId, action, resource, env)
+	ret0, _ := ret[0].(policy.Decision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockIPolicyServiceMockRecorder) Authorize(ctx, userId, action, resource, env interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockIPolicyService)(nil).Authorize), ctx, userId, action, resource, env)
+}
+
+// Evaluate mocks base method.
+func (m *MockIPolicyService) Evaluate(req policy.Request) policy.Decision {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Evaluate", req)
+	ret0, _ := ret[0].(policy.Decision)
+	return ret0
+}
+
+// Evaluate indicates an expected call of Evaluate.
+func (mr *MockIPolicyServiceMockRecorder) Evaluate(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Evaluate", reflect.TypeOf((*MockIPolicyService)(nil).Evaluate), req)
+}
+
+// Reload mocks base method.
+func (m *MockIPolicyService) Reload(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reload", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reload indicates an expected call of Reload.
+func (mr *MockIPolicyServiceMockRecorder) Reload(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reload", reflect.TypeOf((*MockIPolicyService)(nil).Reload), ctx)
+}
+
+// ReloadEvery mocks base method.
+func (m *MockIPolicyService) ReloadEvery(ctx context.Context, interval time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReloadEvery", ctx, interval)
+}
+
+// ReloadEvery indicates an expected call of ReloadEvery.
+func (mr *MockIPolicyServiceMockRecorder) ReloadEvery(ctx, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadEvery", reflect.TypeOf((*MockIPolicyService)(nil).ReloadEvery), ctx, interval)
+}
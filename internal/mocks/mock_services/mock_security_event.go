@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/security_event.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockISecurityEventPublisher is a mock of ISecurityEventPublisher interface.
+type MockISecurityEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockISecurityEventPublisherMockRecorder
+}
+
+// MockISecurityEventPublisherMockRecorder is the mock recorder for MockISecurityEventPublisher.
+type MockISecurityEventPublisherMockRecorder struct {
+	mock *MockISecurityEventPublisher
+}
+
+// NewMockISecurityEventPublisher creates a new mock instance.
+func NewMockISecurityEventPublisher(ctrl *gomock.Controller) *MockISecurityEventPublisher {
+	mock := &MockISecurityEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockISecurityEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISecurityEventPublisher) EXPECT() *MockISecurityEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockISecurityEventPublisher) Publish(ctx context.Context, event services.SecurityEvent) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Publish", ctx, event)
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockISecurityEventPublisherMockRecorder) Publish(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockISecurityEventPublisher)(nil).Publish), ctx, event)
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/session_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockISessionService is a mock of ISessionService interface.
+type MockISessionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionServiceMockRecorder
+}
+
+// MockISessionServiceMockRecorder is the mock recorder for MockISessionService.
+type MockISessionServiceMockRecorder struct {
+	mock *MockISessionService
+}
+
+// NewMockISessionService creates a new mock instance.
+func NewMockISessionService(ctrl *gomock.Controller) *MockISessionService {
+	mock := &MockISessionService{ctrl: ctrl}
+	mock.recorder = &MockISessionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionService) EXPECT() *MockISessionServiceMockRecorder {
+	return m.recorder
+}
+
+// ListSessions mocks base method.
+func (m *MockISessionService) ListSessions(ctx context.Context, userId uuid.UUID) ([]models.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessions", ctx, userId)
+	ret0, _ := ret[0].([]models.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSessions indicates an expected call of ListSessions.
+func (mr *MockISessionServiceMockRecorder) ListSessions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessions", reflect.TypeOf((*MockISessionService)(nil).ListSessions), ctx, userId)
+}
+
+// RevokeAllSessions mocks base method.
+func (m *MockISessionService) RevokeAllSessions(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockISessionServiceMockRecorder) RevokeAllSessions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockISessionService)(nil).RevokeAllSessions), ctx, userId)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockISessionService) RevokeOtherSessions(ctx context.Context, userId, currentDeviceId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, userId, currentDeviceId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockISessionServiceMockRecorder) RevokeOtherSessions(ctx, userId, currentDeviceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockISessionService)(nil).RevokeOtherSessions), ctx, userId, currentDeviceId)
+}
+
+// RevokeSession mocks base method.
+func (m *MockISessionService) RevokeSession(ctx context.Context, userId, deviceId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userId, deviceId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockISessionServiceMockRecorder) RevokeSession(ctx, userId, deviceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockISessionService)(nil).RevokeSession), ctx, userId, deviceId)
+}
+
+// RevokeSessionsExceptToken mocks base method.
+func (m *MockISessionService) RevokeSessionsExceptToken(ctx context.Context, userId, jti uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessionsExceptToken", ctx, userId, jti)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessionsExceptToken indicates an expected call of RevokeSessionsExceptToken.
+func (mr *MockISessionServiceMockRecorder) RevokeSessionsExceptToken(ctx, userId, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionsExceptToken", reflect.TypeOf((*MockISessionService)(nil).RevokeSessionsExceptToken), ctx, userId, jti)
+}
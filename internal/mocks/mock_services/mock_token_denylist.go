@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/token_denylist.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockITokenDenylist is a mock of ITokenDenylist interface.
+type MockITokenDenylist struct {
+	ctrl     *gomock.Controller
+	recorder *MockITokenDenylistMockRecorder
+}
+
+// MockITokenDenylistMockRecorder is the mock recorder for MockITokenDenylist.
+type MockITokenDenylistMockRecorder struct {
+	mock *MockITokenDenylist
+}
+
+// NewMockITokenDenylist creates a new mock instance.
+func NewMockITokenDenylist(ctrl *gomock.Controller) *MockITokenDenylist {
+	mock := &MockITokenDenylist{ctrl: ctrl}
+	mock.recorder = &MockITokenDenylistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITokenDenylist) EXPECT() *MockITokenDenylistMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockITokenDenylist) IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockITokenDenylistMockRecorder) IsRevoked(ctx, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockITokenDenylist)(nil).IsRevoked), ctx, jti)
+}
+
+// Revoke mocks base method.
+func (m *MockITokenDenylist) Revoke(ctx context.Context, jti uuid.UUID, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, jti, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockITokenDenylistMockRecorder) Revoke(ctx, jti, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockITokenDenylist)(nil).Revoke), ctx, jti, expiresAt)
+}
+
+// RevokeSession mocks base method.
+func (m *MockITokenDenylist) RevokeSession(ctx context.Context, token *models.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockITokenDenylistMockRecorder) RevokeSession(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockITokenDenylist)(nil).RevokeSession), ctx, token)
+}
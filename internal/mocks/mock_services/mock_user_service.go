@@ -36,6 +36,34 @@ func (m *MockIUserService) EXPECT() *MockIUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockIUserService) ChangePassword(ctx context.Context, userId uuid.UUID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockIUserServiceMockRecorder) ChangePassword(ctx, userId, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockIUserService)(nil).ChangePassword), ctx, userId, password)
+}
+
+// CheckEmailAvailable mocks base method.
+func (m *MockIUserService) CheckEmailAvailable(ctx context.Context, userId uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckEmailAvailable", ctx, userId, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckEmailAvailable indicates an expected call of CheckEmailAvailable.
+func (mr *MockIUserServiceMockRecorder) CheckEmailAvailable(ctx, userId, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckEmailAvailable", reflect.TypeOf((*MockIUserService)(nil).CheckEmailAvailable), ctx, userId, email)
+}
+
 // GetAllUsers mocks base method.
 func (m *MockIUserService) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,143 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/webauthn_service.go
+
+// Package mock_services is a generated GoMock package.
+package mock_services
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	services "my-go-api/internal/services"
+	reflect "reflect"
+
+	protocol "github.com/go-webauthn/webauthn/protocol"
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIWebAuthnService is a mock of IWebAuthnService interface.
+type MockIWebAuthnService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebAuthnServiceMockRecorder
+}
+
+// MockIWebAuthnServiceMockRecorder is the mock recorder for MockIWebAuthnService.
+type MockIWebAuthnServiceMockRecorder struct {
+	mock *MockIWebAuthnService
+}
+
+// NewMockIWebAuthnService creates a new mock instance.
+func NewMockIWebAuthnService(ctrl *gomock.Controller) *MockIWebAuthnService {
+	mock := &MockIWebAuthnService{ctrl: ctrl}
+	mock.recorder = &MockIWebAuthnServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebAuthnService) EXPECT() *MockIWebAuthnServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginLogin mocks base method.
+func (m *MockIWebAuthnService) BeginLogin(ctx context.Context) (*services.WebAuthnLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", ctx)
+	ret0, _ := ret[0].(*services.WebAuthnLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginLogin indicates an expected call of BeginLogin.
+func (mr *MockIWebAuthnServiceMockRecorder) BeginLogin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockIWebAuthnService)(nil).BeginLogin), ctx)
+}
+
+// BeginRegistration mocks base method.
+func (m *MockIWebAuthnService) BeginRegistration(ctx context.Context, user *models.User) (*protocol.CredentialCreation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", ctx, user)
+	ret0, _ := ret[0].(*protocol.CredentialCreation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration.
+func (mr *MockIWebAuthnServiceMockRecorder) BeginRegistration(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockIWebAuthnService)(nil).BeginRegistration), ctx, user)
+}
+
+// BeginSecondFactor mocks base method.
+func (m *MockIWebAuthnService) BeginSecondFactor(ctx context.Context, userId uuid.UUID) (*services.WebAuthnLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginSecondFactor", ctx, userId)
+	ret0, _ := ret[0].(*services.WebAuthnLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginSecondFactor indicates an expected call of BeginSecondFactor.
+func (mr *MockIWebAuthnServiceMockRecorder) BeginSecondFactor(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginSecondFactor", reflect.TypeOf((*MockIWebAuthnService)(nil).BeginSecondFactor), ctx, userId)
+}
+
+// DeleteCredential mocks base method.
+func (m *MockIWebAuthnService) DeleteCredential(ctx context.Context, userId uuid.UUID, credentialId []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCredential", ctx, userId, credentialId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCredential indicates an expected call of DeleteCredential.
+func (mr *MockIWebAuthnServiceMockRecorder) DeleteCredential(ctx, userId, credentialId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCredential", reflect.TypeOf((*MockIWebAuthnService)(nil).DeleteCredential), ctx, userId, credentialId)
+}
+
+// FinishLogin mocks base method.
+func (m *MockIWebAuthnService) FinishLogin(ctx context.Context, session string, response []byte) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", ctx, session, response)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin.
+func (mr *MockIWebAuthnServiceMockRecorder) FinishLogin(ctx, session, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockIWebAuthnService)(nil).FinishLogin), ctx, session, response)
+}
+
+// FinishRegistration mocks base method.
+func (m *MockIWebAuthnService) FinishRegistration(ctx context.Context, user *models.User, name string, response []byte) (*models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", ctx, user, name, response)
+	ret0, _ := ret[0].(*models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration.
+func (mr *MockIWebAuthnServiceMockRecorder) FinishRegistration(ctx, user, name, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockIWebAuthnService)(nil).FinishRegistration), ctx, user, name, response)
+}
+
+// ListCredentials mocks base method.
+func (m *MockIWebAuthnService) ListCredentials(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCredentials", ctx, userId)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCredentials indicates an expected call of ListCredentials.
+func (mr *MockIWebAuthnServiceMockRecorder) ListCredentials(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCredentials", reflect.TypeOf((*MockIWebAuthnService)(nil).ListCredentials), ctx, userId)
+}
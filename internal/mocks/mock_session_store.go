@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/session_store.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockISessionStore is a mock of ISessionStore interface.
+type MockISessionStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockISessionStoreMockRecorder
+}
+
+// MockISessionStoreMockRecorder is the mock recorder for MockISessionStore.
+type MockISessionStoreMockRecorder struct {
+	mock *MockISessionStore
+}
+
+// NewMockISessionStore creates a new mock instance.
+func NewMockISessionStore(ctrl *gomock.Controller) *MockISessionStore {
+	mock := &MockISessionStore{ctrl: ctrl}
+	mock.recorder = &MockISessionStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISessionStore) EXPECT() *MockISessionStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockISessionStore) Get(ctx context.Context, userId, deviceId uuid.UUID) (*models.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userId, deviceId)
+	ret0, _ := ret[0].(*models.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockISessionStoreMockRecorder) Get(ctx, userId, deviceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockISessionStore)(nil).Get), ctx, userId, deviceId)
+}
+
+// GetByHash mocks base method.
+func (m *MockISessionStore) GetByHash(ctx context.Context, hash string) (*models.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(*models.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockISessionStoreMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockISessionStore)(nil).GetByHash), ctx, hash)
+}
+
+// ListByUser mocks base method.
+func (m *MockISessionStore) ListByUser(ctx context.Context, userId uuid.UUID) ([]models.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userId)
+	ret0, _ := ret[0].([]models.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockISessionStoreMockRecorder) ListByUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockISessionStore)(nil).ListByUser), ctx, userId)
+}
+
+// ListFamily mocks base method.
+func (m *MockISessionStore) ListFamily(ctx context.Context, familyId uuid.UUID) ([]models.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFamily", ctx, familyId)
+	ret0, _ := ret[0].([]models.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFamily indicates an expected call of ListFamily.
+func (mr *MockISessionStoreMockRecorder) ListFamily(ctx, familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFamily", reflect.TypeOf((*MockISessionStore)(nil).ListFamily), ctx, familyId)
+}
+
+// Remove mocks base method.
+func (m *MockISessionStore) Remove(ctx context.Context, userId, deviceId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, userId, deviceId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockISessionStoreMockRecorder) Remove(ctx, userId, deviceId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockISessionStore)(nil).Remove), ctx, userId, deviceId)
+}
+
+// RemoveAllByUser mocks base method.
+func (m *MockISessionStore) RemoveAllByUser(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAllByUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAllByUser indicates an expected call of RemoveAllByUser.
+func (mr *MockISessionStoreMockRecorder) RemoveAllByUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAllByUser", reflect.TypeOf((*MockISessionStore)(nil).RemoveAllByUser), ctx, userId)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockISessionStore) RevokeFamily(ctx context.Context, familyId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, familyId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockISessionStoreMockRecorder) RevokeFamily(ctx, familyId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockISessionStore)(nil).RevokeFamily), ctx, familyId)
+}
+
+// Rotate mocks base method.
+func (m *MockISessionStore) Rotate(ctx context.Context, current, next *models.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rotate", ctx, current, next)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rotate indicates an expected call of Rotate.
+func (mr *MockISessionStoreMockRecorder) Rotate(ctx, current, next interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rotate", reflect.TypeOf((*MockISessionStore)(nil).Rotate), ctx, current, next)
+}
+
+// Save mocks base method.
+func (m *MockISessionStore) Save(ctx context.Context, token *models.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockISessionStoreMockRecorder) Save(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockISessionStore)(nil).Save), ctx, token)
+}
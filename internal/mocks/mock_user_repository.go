@@ -51,6 +51,21 @@ func (mr *MockIUserRepositoryMockRecorder) Create(ctx, name, username, email, pa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIUserRepository)(nil).Create), ctx, name, username, email, password)
 }
 
+// CreateWithProvider mocks base method.
+func (m *MockIUserRepository) CreateWithProvider(ctx context.Context, name, username, email, provider string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithProvider", ctx, name, username, email, provider)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWithProvider indicates an expected call of CreateWithProvider.
+func (mr *MockIUserRepositoryMockRecorder) CreateWithProvider(ctx, name, username, email, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithProvider", reflect.TypeOf((*MockIUserRepository)(nil).CreateWithProvider), ctx, name, username, email, provider)
+}
+
 // GetAll mocks base method.
 func (m *MockIUserRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	m.ctrl.T.Helper()
@@ -125,3 +140,32 @@ func (mr *MockIUserRepositoryMockRecorder) Update(ctx, user interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockIUserRepository)(nil).Update), ctx, user)
 }
+
+// UpdatePassword mocks base method.
+func (m *MockIUserRepository) UpdatePassword(ctx context.Context, userId uuid.UUID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockIUserRepositoryMockRecorder) UpdatePassword(ctx, userId, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockIUserRepository)(nil).UpdatePassword), ctx, userId, password)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockIUserRepository) VerifyEmail(ctx context.Context, userId uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, userId)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockIUserRepositoryMockRecorder) VerifyEmail(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockIUserRepository)(nil).VerifyEmail), ctx, userId)
+}
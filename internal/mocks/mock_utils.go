@@ -5,9 +5,10 @@
 package mocks
 
 import (
+	utils "my-go-api/internal/utils"
 	reflect "reflect"
+	time "time"
 
-	jwt "github.com/golang-jwt/jwt/v5"
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	oauth2 "golang.org/x/oauth2"
@@ -50,6 +51,36 @@ func (mr *MockIUtilsMockRecorder) CreateGoogleOauth2Config() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGoogleOauth2Config", reflect.TypeOf((*MockIUtils)(nil).CreateGoogleOauth2Config))
 }
 
+// GenerateIDToken mocks base method.
+func (m *MockIUtils) GenerateIDToken(userId, clientId uuid.UUID, nonce string, claims utils.UserClaims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateIDToken", userId, clientId, nonce, claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateIDToken indicates an expected call of GenerateIDToken.
+func (mr *MockIUtilsMockRecorder) GenerateIDToken(userId, clientId, nonce, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateIDToken", reflect.TypeOf((*MockIUtils)(nil).GenerateIDToken), userId, clientId, nonce, claims)
+}
+
+// GenerateImpersonationToken mocks base method.
+func (m *MockIUtils) GenerateImpersonationToken(userId, actorId, jti uuid.UUID, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateImpersonationToken", userId, actorId, jti, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateImpersonationToken indicates an expected call of GenerateImpersonationToken.
+func (mr *MockIUtilsMockRecorder) GenerateImpersonationToken(userId, actorId, jti, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateImpersonationToken", reflect.TypeOf((*MockIUtils)(nil).GenerateImpersonationToken), userId, actorId, jti, ttl)
+}
+
 // GenerateRandomBytes mocks base method.
 func (m *MockIUtils) GenerateRandomBytes(size int) (string, error) {
 	m.ctrl.T.Helper()
@@ -65,19 +96,34 @@ func (mr *MockIUtilsMockRecorder) GenerateRandomBytes(size interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRandomBytes", reflect.TypeOf((*MockIUtils)(nil).GenerateRandomBytes), size)
 }
 
+// GenerateScopedToken mocks base method.
+func (m *MockIUtils) GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateScopedToken", subject, clientId, scope, jti)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateScopedToken indicates an expected call of GenerateScopedToken.
+func (mr *MockIUtilsMockRecorder) GenerateScopedToken(subject, clientId, scope, jti interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateScopedToken", reflect.TypeOf((*MockIUtils)(nil).GenerateScopedToken), subject, clientId, scope, jti)
+}
+
 // GenerateToken mocks base method.
-func (m *MockIUtils) GenerateToken(userId uuid.UUID) (string, error) {
+func (m *MockIUtils) GenerateToken(userId, jti uuid.UUID) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateToken", userId)
+	ret := m.ctrl.Call(m, "GenerateToken", userId, jti)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GenerateToken indicates an expected call of GenerateToken.
-func (mr *MockIUtilsMockRecorder) GenerateToken(userId interface{}) *gomock.Call {
+func (mr *MockIUtilsMockRecorder) GenerateToken(userId, jti interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockIUtils)(nil).GenerateToken), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockIUtils)(nil).GenerateToken), userId, jti)
 }
 
 // GetTokenFromRefreshToken mocks base method.
@@ -123,6 +169,20 @@ func (mr *MockIUtilsMockRecorder) HashWithSHA256(randomStr interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashWithSHA256", reflect.TypeOf((*MockIUtils)(nil).HashWithSHA256), randomStr)
 }
 
+// PasswordNeedsRehash mocks base method.
+func (m *MockIUtils) PasswordNeedsRehash(hashedPassword string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordNeedsRehash", hashedPassword)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// PasswordNeedsRehash indicates an expected call of PasswordNeedsRehash.
+func (mr *MockIUtilsMockRecorder) PasswordNeedsRehash(hashedPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordNeedsRehash", reflect.TypeOf((*MockIUtils)(nil).PasswordNeedsRehash), hashedPassword)
+}
+
 // SendEmailWithGmail mocks base method.
 func (m *MockIUtils) SendEmailWithGmail(subject, body, address string) error {
 	m.ctrl.T.Helper()
@@ -137,11 +197,25 @@ func (mr *MockIUtilsMockRecorder) SendEmailWithGmail(subject, body, address inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailWithGmail", reflect.TypeOf((*MockIUtils)(nil).SendEmailWithGmail), subject, body, address)
 }
 
+// SigningAlgorithms mocks base method.
+func (m *MockIUtils) SigningAlgorithms() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SigningAlgorithms")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// SigningAlgorithms indicates an expected call of SigningAlgorithms.
+func (mr *MockIUtilsMockRecorder) SigningAlgorithms() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SigningAlgorithms", reflect.TypeOf((*MockIUtils)(nil).SigningAlgorithms))
+}
+
 // ValidateToken mocks base method.
-func (m *MockIUtils) ValidateToken(tokenString string) (*jwt.MapClaims, error) {
+func (m *MockIUtils) ValidateToken(tokenString string) (*utils.AccessTokenClaims, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ValidateToken", tokenString)
-	ret0, _ := ret[0].(*jwt.MapClaims)
+	ret0, _ := ret[0].(*utils.AccessTokenClaims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repositories/webauthn_repository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	models "my-go-api/internal/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockIWebAuthnRepository is a mock of IWebAuthnRepository interface.
+type MockIWebAuthnRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebAuthnRepositoryMockRecorder
+}
+
+// MockIWebAuthnRepositoryMockRecorder is the mock recorder for MockIWebAuthnRepository.
+type MockIWebAuthnRepositoryMockRecorder struct {
+	mock *MockIWebAuthnRepository
+}
+
+// NewMockIWebAuthnRepository creates a new mock instance.
+func NewMockIWebAuthnRepository(ctrl *gomock.Controller) *MockIWebAuthnRepository {
+	mock := &MockIWebAuthnRepository{ctrl: ctrl}
+	mock.recorder = &MockIWebAuthnRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebAuthnRepository) EXPECT() *MockIWebAuthnRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIWebAuthnRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIWebAuthnRepositoryMockRecorder) Create(ctx, credential interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIWebAuthnRepository)(nil).Create), ctx, credential)
+}
+
+// Delete mocks base method.
+func (m *MockIWebAuthnRepository) Delete(ctx context.Context, userId uuid.UUID, credentialId []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userId, credentialId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIWebAuthnRepositoryMockRecorder) Delete(ctx, userId, credentialId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIWebAuthnRepository)(nil).Delete), ctx, userId, credentialId)
+}
+
+// GetByUser mocks base method.
+func (m *MockIWebAuthnRepository) GetByUser(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByUser", ctx, userId)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByUser indicates an expected call of GetByUser.
+func (mr *MockIWebAuthnRepositoryMockRecorder) GetByUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUser", reflect.TypeOf((*MockIWebAuthnRepository)(nil).GetByUser), ctx, userId)
+}
+
+// UpdateAfterLogin mocks base method.
+func (m *MockIWebAuthnRepository) UpdateAfterLogin(ctx context.Context, credentialId []byte, signCount uint32, backupState bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAfterLogin", ctx, credentialId, signCount, backupState)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAfterLogin indicates an expected call of UpdateAfterLogin.
+func (mr *MockIWebAuthnRepositoryMockRecorder) UpdateAfterLogin(ctx, credentialId, signCount, backupState interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAfterLogin", reflect.TypeOf((*MockIWebAuthnRepository)(nil).UpdateAfterLogin), ctx, credentialId, signCount, backupState)
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a personal access token a user created for scripts and integrations.
+// Only the SHA-256 of the key is kept, Prefix is its first characters.
+type APIKey struct {
+	ID         uuid.UUID
+	UserId     uuid.UUID
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
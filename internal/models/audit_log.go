@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog records an action an admin took, TargetId is the user it was taken
+// on when there is one.
+type AuditLog struct {
+	ID        int64
+	ActorId   uuid.UUID
+	Action    string
+	TargetId  *uuid.UUID
+	Details   map[string]any
+	IP        string
+	UserAgent string
+	CreatedAt time.Time
+}
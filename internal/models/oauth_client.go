@@ -0,0 +1,42 @@
+package models
+
+import (
+	"my-go-api/internal/constants"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OAuthClient is an application registered to obtain tokens from our OAuth2
+// authorization server. Only the bcrypt hash of a confidential client's secret
+// is kept, public clients have none.
+type OAuthClient struct {
+	ID           uuid.UUID
+	Name         string
+	Type         string
+	SecretHash   string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	CreatedAt    time.Time
+}
+
+func (c *OAuthClient) IsPublic() bool {
+	return c.Type == constants.OAUTH_CLIENT_PUBLIC
+}
+
+// Allows reports whether the client was registered for the grant type.
+func (c *OAuthClient) Allows(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+// OAuthGrant records which client, and with which scope, a user authorized for
+// the refresh token session of DeviceId.
+type OAuthGrant struct {
+	DeviceId  uuid.UUID
+	UserId    uuid.UUID
+	ClientId  uuid.UUID
+	Scope     string
+	CreatedAt time.Time
+}
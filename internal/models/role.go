@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Role is a named set of permissions. Every user has exactly one role, the
+// user role unless an admin assigned another.
+type Role struct {
+	Name        string
+	Description string
+	Permissions []string
+	CreatedAt   time.Time
+}
+
+// Permission allows an action on a resource, named resource:action such as
+// users:write.
+type Permission struct {
+	Name        string
+	Description string
+}
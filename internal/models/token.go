@@ -1,12 +1,34 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// SessionMetadata describes the client a refresh token was issued to.
+type SessionMetadata struct {
+	DeviceLabel string `json:"device_label"`
+	UserAgent   string `json:"user_agent"`
+	IP          string `json:"ip"`
+}
+
+// Token is a refresh token of one device. Every rotation revokes the token and
+// issues its successor in the same family, the family starts at login.
 type Token struct {
 	ID        int       `json:"id"`
 	Hash      string    `json:"hash"`
 	IsRevoked bool      `json:"is_revoked"`
 	DeviceId  uuid.UUID `json:"device_id"`
 	UserId    uuid.UUID `json:"user_id"`
-	ExpiredAt string    `json:"expired_at"`
+	Jti       uuid.UUID `json:"jti"`
+	FamilyId  uuid.UUID `json:"family_id"`
+	// ClientId is the OAuth2 client the session was issued to, uuid.Nil for
+	// sessions of our own apps.
+	ClientId  uuid.UUID `json:"client_id"`
+	ExpiredAt time.Time `json:"expired_at"`
+	SessionMetadata
+	// CreatedAt is when the device signed in, LastUsedAt when it last refreshed.
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
 }
@@ -5,13 +5,14 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
-	Provider  string    `json:"provider"`
-	Role      string    `json:"role"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
+	ID              uuid.UUID `json:"id"`
+	Username        string    `json:"username"`
+	Name            string    `json:"name"`
+	Email           string    `json:"email"`
+	Password        string    `json:"-"`
+	Provider        string    `json:"provider"`
+	Role            string    `json:"role"`
+	EmailVerifiedAt *string   `json:"email_verified_at,omitempty"`
+	CreatedAt       string    `json:"created_at"`
+	UpdatedAt       string    `json:"updated_at"`
 }
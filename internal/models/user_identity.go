@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// UserIdentity links a user to an account at an external identity provider.
+type UserIdentity struct {
+	ID        int       `json:"id"`
+	UserId    uuid.UUID `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt string    `json:"created_at"`
+}
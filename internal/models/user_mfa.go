@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserMFA is the TOTP second factor of a user. EnabledAt stays nil while the
+// enrollment has not been confirmed with a first code.
+type UserMFA struct {
+	UserId       uuid.UUID
+	TOTPSecret   string
+	EnabledAt    *time.Time
+	LastUsedStep int64
+	CreatedAt    time.Time
+}
@@ -0,0 +1,365 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectDeny  Effect = "deny"
+)
+
+// MFA levels of a request. A session of an account with a second factor has
+// passed it at login, every login path enforces it, while API keys skip it.
+const (
+	MfaLevelUnknown = 0
+	MfaLevelSingle  = 1
+	MfaLevelMulti   = 2
+)
+
+// ResourceUser is the type of user accounts as resources.
+const ResourceUser = "user"
+
+// Subject is who makes a request.
+type Subject struct {
+	UserId uuid.UUID `json:"user_id"`
+	Role   string    `json:"role"`
+	Org    string    `json:"org"`
+}
+
+// Resource is what a request acts on, Owner is the user it belongs to.
+type Resource struct {
+	Type  string    `json:"type"`
+	Id    string    `json:"id"`
+	Owner uuid.UUID `json:"owner"`
+}
+
+// Context is where and when a request is made.
+type Context struct {
+	IP       string    `json:"ip"`
+	Time     time.Time `json:"time"`
+	MfaLevel int       `json:"mfa_level"`
+}
+
+// Request is everything a rule can look at.
+type Request struct {
+	Subject  Subject  `json:"subject"`
+	Action   Action   `json:"action"`
+	Resource Resource `json:"resource"`
+	Context  Context  `json:"context"`
+}
+
+// Condition compares an attribute of the request with Value, or with another
+// attribute named by ValueFrom, e.g. resource.owner eq subject.user_id.
+type Condition struct {
+	Attribute string `yaml:"attribute" json:"attribute"`
+	Operator  string `yaml:"operator" json:"operator"`
+	Value     any    `yaml:"value,omitempty" json:"value,omitempty"`
+	ValueFrom string `yaml:"value_from,omitempty" json:"value_from,omitempty"`
+}
+
+// Rule applies to the actions and resource types it lists when all of its
+// conditions hold. "*" matches anything and "user:*" every action on users.
+type Rule struct {
+	ID          string      `yaml:"id" json:"id"`
+	Description string      `yaml:"description" json:"description"`
+	Effect      Effect      `yaml:"effect" json:"effect"`
+	Actions     []string    `yaml:"actions" json:"actions"`
+	Resources   []string    `yaml:"resources" json:"resources"`
+	Conditions  []Condition `yaml:"conditions" json:"conditions"`
+}
+
+var attributes = []string{
+	"subject.user_id", "subject.role", "subject.org",
+	"action",
+	"resource.type", "resource.id", "resource.owner",
+	"context.ip", "context.hour", "context.weekday", "context.mfa_level",
+}
+
+var operators = []string{"eq", "ne", "in", "not_in", "gt", "gte", "lt", "lte", "cidr"}
+
+// attribute returns the value of a named attribute, "" when the request does
+// not carry it. Times are looked at in UTC.
+func (r Request) attribute(name string) string {
+	id := func(id uuid.UUID) string {
+		if id == uuid.Nil {
+			return ""
+		}
+		return id.String()
+	}
+	switch name {
+	case "subject.user_id":
+		return id(r.Subject.UserId)
+	case "subject.role":
+		return r.Subject.Role
+	case "subject.org":
+		return r.Subject.Org
+	case "action":
+		return string(r.Action)
+	case "resource.type":
+		return r.Resource.Type
+	case "resource.id":
+		return r.Resource.Id
+	case "resource.owner":
+		return id(r.Resource.Owner)
+	case "context.ip":
+		return r.Context.IP
+	case "context.hour":
+		if r.Context.Time.IsZero() {
+			return ""
+		}
+		return strconv.Itoa(r.Context.Time.UTC().Hour())
+	case "context.weekday":
+		if r.Context.Time.IsZero() {
+			return ""
+		}
+		return strings.ToLower(r.Context.Time.UTC().Weekday().String())
+	case "context.mfa_level":
+		return strconv.Itoa(r.Context.MfaLevel)
+	}
+	return ""
+}
+
+// condition is a Condition checked and parsed once when the rules are loaded.
+type condition struct {
+	Condition
+	values   []string
+	number   float64
+	networks []*net.IPNet
+}
+
+type rule struct {
+	Rule
+	conditions []condition
+}
+
+// Engine evaluates requests against a fixed set of rules, load a new engine to
+// change them.
+type Engine struct {
+	rules []rule
+}
+
+// NewEngine checks the rules, so a typo fails when they are loaded rather than
+// denying requests.
+func NewEngine(rules []Rule) (*Engine, error) {
+	engine := &Engine{rules: make([]rule, 0, len(rules))}
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.ID == "" {
+			return nil, errors.New("policy without id")
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("policy %q is defined twice", r.ID)
+		}
+		seen[r.ID] = true
+		if r.Effect != EffectAllow && r.Effect != EffectDeny {
+			return nil, fmt.Errorf("policy %q: effect must be allow or deny", r.ID)
+		}
+		if len(r.Actions) == 0 || len(r.Resources) == 0 {
+			return nil, fmt.Errorf("policy %q: actions and resources are required", r.ID)
+		}
+		compiled := rule{Rule: r}
+		for _, c := range r.Conditions {
+			cond, err := compileCondition(c)
+			if err != nil {
+				return nil, fmt.Errorf("policy %q: %w", r.ID, err)
+			}
+			compiled.conditions = append(compiled.conditions, cond)
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+func compileCondition(c Condition) (condition, error) {
+	cond := condition{Condition: c}
+	if !slices.Contains(attributes, c.Attribute) {
+		return cond, fmt.Errorf("unknown attribute %q", c.Attribute)
+	}
+	if !slices.Contains(operators, c.Operator) {
+		return cond, fmt.Errorf("unknown operator %q", c.Operator)
+	}
+	if c.ValueFrom != "" {
+		if !slices.Contains(attributes, c.ValueFrom) {
+			return cond, fmt.Errorf("unknown attribute %q", c.ValueFrom)
+		}
+		if c.Operator != "eq" && c.Operator != "ne" {
+			return cond, errors.New("value_from only works with eq and ne")
+		}
+		return cond, nil
+	}
+	if c.Value == nil {
+		return cond, fmt.Errorf("condition on %s needs a value or value_from", c.Attribute)
+	}
+	if list, ok := c.Value.([]any); ok {
+		for _, item := range list {
+			cond.values = append(cond.values, fmt.Sprint(item))
+		}
+	} else {
+		cond.values = []string{fmt.Sprint(c.Value)}
+	}
+	switch c.Operator {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		if len(cond.values) != 1 {
+			return cond, fmt.Errorf("%s on %s needs a single value", c.Operator, c.Attribute)
+		}
+	}
+	switch c.Operator {
+	case "gt", "gte", "lt", "lte":
+		number, err := strconv.ParseFloat(cond.values[0], 64)
+		if err != nil {
+			return cond, fmt.Errorf("%s on %s needs a number", c.Operator, c.Attribute)
+		}
+		cond.number = number
+	case "cidr":
+		for _, value := range cond.values {
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return cond, fmt.Errorf("invalid cidr %q", value)
+			}
+			cond.networks = append(cond.networks, network)
+		}
+	}
+	return cond, nil
+}
+
+// holds reports whether the condition is true for the request. A condition on
+// an attribute the request does not carry never holds.
+func (c condition) holds(req Request) bool {
+	actual := req.attribute(c.Attribute)
+	if actual == "" {
+		return false
+	}
+	if c.ValueFrom != "" {
+		other := req.attribute(c.ValueFrom)
+		if other == "" {
+			return false
+		}
+		return (actual == other) == (c.Operator == "eq")
+	}
+	switch c.Operator {
+	case "eq":
+		return actual == c.values[0]
+	case "ne":
+		return actual != c.values[0]
+	case "in":
+		return slices.Contains(c.values, actual)
+	case "not_in":
+		return !slices.Contains(c.values, actual)
+	case "cidr":
+		ip := net.ParseIP(actual)
+		if ip == nil {
+			return false
+		}
+		return slices.ContainsFunc(c.networks, func(network *net.IPNet) bool { return network.Contains(ip) })
+	}
+	number, err := strconv.ParseFloat(actual, 64)
+	if err != nil {
+		return false
+	}
+	switch c.Operator {
+	case "gt":
+		return number > c.number
+	case "gte":
+		return number >= c.number
+	case "lt":
+		return number < c.number
+	case "lte":
+		return number <= c.number
+	}
+	return false
+}
+
+func matches(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == value {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r rule) applies(req Request) bool {
+	if !matches(r.Actions, string(req.Action)) || !matches(r.Resources, req.Resource.Type) {
+		return false
+	}
+	for _, c := range r.conditions {
+		if !c.holds(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate allows the request when a rule allows it and none denies it, a deny
+// always wins. Decision.Policy names the rule that decided.
+func (e *Engine) Evaluate(req Request) Decision {
+	var allowedBy *rule
+	for i := range e.rules {
+		r := &e.rules[i]
+		if !r.applies(req) {
+			continue
+		}
+		if r.Effect == EffectDeny {
+			return Decision{Reason: denyReason(r.Rule), Policy: r.ID}
+		}
+		if allowedBy == nil {
+			allowedBy = r
+		}
+	}
+	if allowedBy == nil {
+		return deny(fmt.Sprintf("no policy allows %s on %s", req.Action, req.Resource.Type))
+	}
+	return Decision{Allowed: true, Policy: allowedBy.ID}
+}
+
+func denyReason(r Rule) string {
+	if r.Description != "" {
+		return r.Description
+	}
+	return "denied by policy " + r.ID
+}
+
+// Rules returns the rules the engine was built from.
+func (e *Engine) Rules() []Rule {
+	rules := make([]Rule, 0, len(e.rules))
+	for _, r := range e.rules {
+		rules = append(rules, r.Rule)
+	}
+	return rules
+}
+
+// ParseYAML reads rules from a document with a top level policies list.
+func ParseYAML(data []byte) ([]Rule, error) {
+	var document struct {
+		Policies []Rule `yaml:"policies"`
+	}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document.Policies, nil
+}
+
+// LoadFile reads the rules of a YAML file.
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseYAML(data)
+}
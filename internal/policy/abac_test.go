@@ -0,0 +1,138 @@
+package policy_test
+
+import (
+	"my-go-api/internal/policy"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testPolicies = `
+policies:
+  - id: own-account
+    effect: allow
+    actions: ["user:*"]
+    resources: ["user"]
+    conditions:
+      - attribute: resource.owner
+        operator: eq
+        value_from: subject.user_id
+  - id: support
+    effect: allow
+    actions: ["user:read"]
+    resources: ["*"]
+    conditions:
+      - attribute: subject.role
+        operator: in
+        value: [support, admin]
+      - attribute: subject.org
+        operator: eq
+        value: acme
+  - id: office-hours
+    description: Support does not work after 18:00 UTC
+    effect: deny
+    actions: ["*"]
+    resources: ["*"]
+    conditions:
+      - attribute: subject.role
+        operator: eq
+        value: support
+      - attribute: context.hour
+        operator: gte
+        value: 18
+  - id: edit-with-mfa
+    description: Changing a role requires a second factor
+    effect: deny
+    actions: ["user:change_role"]
+    resources: ["user"]
+    conditions:
+      - attribute: context.mfa_level
+        operator: lt
+        value: 2
+  - id: admins
+    effect: allow
+    actions: ["user:change_role"]
+    resources: ["user"]
+    conditions:
+      - attribute: subject.role
+        operator: eq
+        value: admin
+      - attribute: context.ip
+        operator: cidr
+        value: ["10.0.0.0/8", "192.168.1.0/24"]
+`
+
+func TestEngine(t *testing.T) {
+	rules, err := policy.ParseYAML([]byte(testPolicies))
+	assert.NoError(t, err)
+	engine, err := policy.NewEngine(rules)
+	assert.NoError(t, err)
+	assert.Len(t, engine.Rules(), 5)
+
+	owner := uuid.New()
+	user := policy.Subject{UserId: owner, Role: "user"}
+	support := policy.Subject{UserId: uuid.New(), Role: "support", Org: "acme"}
+	admin := policy.Subject{UserId: uuid.New(), Role: "admin", Org: "acme"}
+	account := policy.Resource{Type: policy.ResourceUser, Id: owner.String(), Owner: owner}
+	morning := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		subject  policy.Subject
+		action   policy.Action
+		resource policy.Resource
+		env      policy.Context
+		decision policy.Decision
+	}{
+		{"owners read their account", user, policy.ReadUser, account, policy.Context{Time: morning}, policy.Decision{Allowed: true, Policy: "own-account"}},
+		{"owners update their account", user, policy.UpdateUser, account, policy.Context{Time: evening}, policy.Decision{Allowed: true, Policy: "own-account"}},
+		{"others are denied by default", policy.Subject{UserId: uuid.New(), Role: "user"}, policy.ReadUser, account, policy.Context{Time: morning}, policy.Decision{Reason: "no policy allows user:read on user"}},
+		{"support reads accounts of any org member", support, policy.ReadUser, account, policy.Context{Time: morning}, policy.Decision{Allowed: true, Policy: "support"}},
+		{"support of another org is denied", policy.Subject{UserId: uuid.New(), Role: "support", Org: "globex"}, policy.ReadUser, account, policy.Context{Time: morning}, policy.Decision{Reason: "no policy allows user:read on user"}},
+		{"a deny wins over an allow", support, policy.ReadUser, account, policy.Context{Time: evening}, policy.Decision{Reason: "Support does not work after 18:00 UTC", Policy: "office-hours"}},
+		{"support does not update accounts", support, policy.UpdateUser, account, policy.Context{Time: morning}, policy.Decision{Reason: "no policy allows user:update on user"}},
+		{"admins change roles from the office with mfa", admin, policy.ChangeRole, account, policy.Context{IP: "10.1.2.3", MfaLevel: policy.MfaLevelMulti}, policy.Decision{Allowed: true, Policy: "admins"}},
+		{"admins need a second factor to change roles", admin, policy.ChangeRole, account, policy.Context{IP: "10.1.2.3", MfaLevel: policy.MfaLevelSingle}, policy.Decision{Reason: "Changing a role requires a second factor", Policy: "edit-with-mfa"}},
+		{"admins change roles only from the office", admin, policy.ChangeRole, account, policy.Context{IP: "203.0.113.9", MfaLevel: policy.MfaLevelMulti}, policy.Decision{Reason: "no policy allows user:change_role on user"}},
+		{"a missing attribute does not match", admin, policy.ChangeRole, account, policy.Context{MfaLevel: policy.MfaLevelMulti}, policy.Decision{Reason: "no policy allows user:change_role on user"}},
+		{"nobody owns an account without an owner", policy.Subject{Role: "user"}, policy.ReadUser, policy.Resource{Type: policy.ResourceUser}, policy.Context{}, policy.Decision{Reason: "no policy allows user:read on user"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(policy.Request{Subject: tt.subject, Action: tt.action, Resource: tt.resource, Context: tt.env})
+			assert.Equal(t, tt.decision, decision)
+		})
+	}
+}
+
+func TestNewEngineRejectsBrokenRules(t *testing.T) {
+	allow := func(conditions ...policy.Condition) policy.Rule {
+		return policy.Rule{ID: "rule", Effect: policy.EffectAllow, Actions: []string{"*"}, Resources: []string{"*"}, Conditions: conditions}
+	}
+	tests := []struct {
+		name  string
+		rules []policy.Rule
+		err   string
+	}{
+		{"missing id", []policy.Rule{{Effect: policy.EffectAllow}}, "policy without id"},
+		{"duplicate id", []policy.Rule{allow(), allow()}, `policy "rule" is defined twice`},
+		{"unknown effect", []policy.Rule{{ID: "rule", Effect: "maybe", Actions: []string{"*"}, Resources: []string{"*"}}}, `policy "rule": effect must be allow or deny`},
+		{"no actions", []policy.Rule{{ID: "rule", Effect: policy.EffectDeny, Resources: []string{"*"}}}, `policy "rule": actions and resources are required`},
+		{"unknown attribute", []policy.Rule{allow(policy.Condition{Attribute: "subject.email", Operator: "eq", Value: "x"})}, `policy "rule": unknown attribute "subject.email"`},
+		{"unknown operator", []policy.Rule{allow(policy.Condition{Attribute: "subject.role", Operator: "like", Value: "x"})}, `policy "rule": unknown operator "like"`},
+		{"no value", []policy.Rule{allow(policy.Condition{Attribute: "subject.role", Operator: "eq"})}, `policy "rule": condition on subject.role needs a value or value_from`},
+		{"value_from with in", []policy.Rule{allow(policy.Condition{Attribute: "subject.role", Operator: "in", ValueFrom: "subject.org"})}, `policy "rule": value_from only works with eq and ne`},
+		{"comparison with text", []policy.Rule{allow(policy.Condition{Attribute: "context.hour", Operator: "gt", Value: "noon"})}, `policy "rule": gt on context.hour needs a number`},
+		{"list for eq", []policy.Rule{allow(policy.Condition{Attribute: "subject.role", Operator: "eq", Value: []any{"a", "b"}})}, `policy "rule": eq on subject.role needs a single value`},
+		{"invalid cidr", []policy.Rule{allow(policy.Condition{Attribute: "context.ip", Operator: "cidr", Value: "10.0.0.1"})}, `policy "rule": invalid cidr "10.0.0.1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := policy.NewEngine(tt.rules)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
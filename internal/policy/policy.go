@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"my-go-api/internal/constants"
+	"my-go-api/internal/models"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Actor is the authenticated user a request is made by, together with the
+// permissions their role grants. ImpersonatorId is the admin acting as them,
+// uuid.Nil when the user acts themselves. Delegated is set when the request
+// comes with an API key or a token of an OAuth2 client rather than a session
+// of the user.
+type Actor struct {
+	UserId         uuid.UUID
+	Permissions    []string
+	ImpersonatorId uuid.UUID
+	Delegated      bool
+}
+
+func (a Actor) Has(permission string) bool {
+	return slices.Contains(a.Permissions, permission)
+}
+
+type Action string
+
+const (
+	ReadUser       Action = "user:read"
+	UpdateUser     Action = "user:update"
+	ChangeRole     Action = "user:change_role"
+	ChangePassword Action = "user:change_password"
+	ChangeEmail    Action = "user:change_email"
+)
+
+// Decision is the answer of Can and Engine.Evaluate. Reason tells the caller
+// why they were denied, Policy names the rule of an engine that decided.
+type Decision struct {
+	Allowed bool
+	Reason  string
+	Policy  string
+}
+
+func allow() Decision {
+	return Decision{Allowed: true}
+}
+
+func deny(reason string) Decision {
+	return Decision{Reason: reason}
+}
+
+// Can decides whether the actor may take the action on the resource. Anything
+// the policies do not know of is denied.
+func Can(actor Actor, action Action, resource any) Decision {
+	switch r := resource.(type) {
+	case *models.User:
+		return canOnUser(actor, action, r)
+	}
+	return deny("unknown resource")
+}
+
+// canOnUser lets users see and edit their own account, and those with the users
+// permissions any account. Roles are only changed by whoever may manage roles,
+// and passwords only by the owner of the account. Admins impersonating a user,
+// API keys and OAuth2 clients change neither the password nor the email, which
+// would let them take the account over.
+func canOnUser(actor Actor, action Action, user *models.User) Decision {
+	self := actor.UserId != uuid.Nil && actor.UserId == user.ID
+	impersonated := actor.ImpersonatorId != uuid.Nil
+	switch action {
+	case ReadUser:
+		if self || actor.Has(constants.PERMISSION_USERS_READ) {
+			return allow()
+		}
+		return deny("you may only view your own account")
+	case UpdateUser:
+		if self || actor.Has(constants.PERMISSION_USERS_WRITE) {
+			return allow()
+		}
+		return deny("you may only edit your own account")
+	case ChangeRole:
+		if actor.Has(constants.PERMISSION_ROLES_WRITE) {
+			return allow()
+		}
+		return deny("only admins may change roles")
+	case ChangePassword:
+		if impersonated {
+			return deny("passwords cannot be changed while impersonating")
+		}
+		if actor.Delegated {
+			return deny("passwords cannot be changed with an api key or an oauth client")
+		}
+		if self {
+			return allow()
+		}
+		return deny("only the owner of an account may change its password")
+	case ChangeEmail:
+		if impersonated {
+			return deny("emails cannot be changed while impersonating")
+		}
+		if actor.Delegated {
+			return deny("emails cannot be changed with an api key or an oauth client")
+		}
+		if self || actor.Has(constants.PERMISSION_USERS_WRITE) {
+			return allow()
+		}
+		return deny("you may only edit your own account")
+	}
+	return deny("unknown action")
+}
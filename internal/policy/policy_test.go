@@ -0,0 +1,70 @@
+package policy_test
+
+import (
+	"my-go-api/internal/models"
+	"my-go-api/internal/policy"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanOnUser(t *testing.T) {
+	owner := &models.User{ID: uuid.New(), Role: "user"}
+	self := policy.Actor{UserId: owner.ID}
+	stranger := policy.Actor{UserId: uuid.New()}
+	support := policy.Actor{UserId: uuid.New(), Permissions: []string{"users:read", "users:write"}}
+	admin := policy.Actor{UserId: uuid.New(), Permissions: []string{"users:read", "users:write", "roles:read", "roles:write"}}
+	adminSelf := policy.Actor{UserId: owner.ID, Permissions: admin.Permissions}
+	impersonated := policy.Actor{UserId: owner.ID, ImpersonatorId: uuid.New()}
+	delegated := policy.Actor{UserId: owner.ID, Delegated: true}
+
+	tests := []struct {
+		name    string
+		actor   policy.Actor
+		action  policy.Action
+		allowed bool
+		reason  string
+	}{
+		{"users view themselves", self, policy.ReadUser, true, ""},
+		{"users do not view others", stranger, policy.ReadUser, false, "you may only view your own account"},
+		{"users:read views anyone", support, policy.ReadUser, true, ""},
+		{"users edit themselves", self, policy.UpdateUser, true, ""},
+		{"users do not edit others", stranger, policy.UpdateUser, false, "you may only edit your own account"},
+		{"users:write edits anyone", support, policy.UpdateUser, true, ""},
+		{"users do not change their own role", self, policy.ChangeRole, false, "only admins may change roles"},
+		{"users:write does not change roles", support, policy.ChangeRole, false, "only admins may change roles"},
+		{"admins change roles", admin, policy.ChangeRole, true, ""},
+		{"admins change their own role", adminSelf, policy.ChangeRole, true, ""},
+		{"users change their own password", self, policy.ChangePassword, true, ""},
+		{"admins do not change the password of others", admin, policy.ChangePassword, false, "only the owner of an account may change its password"},
+		{"unknown actions are denied", admin, policy.Action("user:delete"), false, "unknown action"},
+		{"nobody is nobody's owner", policy.Actor{}, policy.UpdateUser, false, "you may only edit your own account"},
+		{"users change their own email", self, policy.ChangeEmail, true, ""},
+		{"users:write changes any email", support, policy.ChangeEmail, true, ""},
+		{"users do not change the email of others", stranger, policy.ChangeEmail, false, "you may only edit your own account"},
+		{"impersonators edit the account", impersonated, policy.UpdateUser, true, ""},
+		{"impersonators do not change the password", impersonated, policy.ChangePassword, false, "passwords cannot be changed while impersonating"},
+		{"impersonators do not change the email", impersonated, policy.ChangeEmail, false, "emails cannot be changed while impersonating"},
+		{"api keys and clients edit the account", delegated, policy.UpdateUser, true, ""},
+		{"api keys and clients do not change the password", delegated, policy.ChangePassword, false, "passwords cannot be changed with an api key or an oauth client"},
+		{"api keys and clients do not change the email", delegated, policy.ChangeEmail, false, "emails cannot be changed with an api key or an oauth client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := owner
+			if tt.actor.UserId == uuid.Nil {
+				target = &models.User{}
+			}
+			decision := policy.Can(tt.actor, tt.action, target)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+			assert.Equal(t, tt.reason, decision.Reason)
+		})
+	}
+}
+
+func TestCanOnUnknownResource(t *testing.T) {
+	decision := policy.Can(policy.Actor{UserId: uuid.New(), Permissions: []string{"users:write"}}, policy.UpdateUser, "user")
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "unknown resource", decision.Reason)
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type IAPIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByHash(ctx context.Context, hash string) (*models.APIKey, error)
+	GetByUser(ctx context.Context, userId uuid.UUID) ([]models.APIKey, error)
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	Delete(ctx context.Context, userId, id uuid.UUID) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) IAPIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at"
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+	if err := row.Scan(
+		&key.ID,
+		&key.UserId,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&scopes,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	return &key, nil
+}
+
+func (s *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return s.db.QueryRowContext(ctx, query,
+		key.UserId,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		strings.Join(key.Scopes, ","),
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (s *apiKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash=$1"
+	return scanAPIKey(s.db.QueryRowContext(ctx, query, hash))
+}
+
+func (s *apiKeyRepository) GetByUser(ctx context.Context, userId uuid.UUID) ([]models.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE user_id=$1 ORDER BY created_at"
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+func (s *apiKeyRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at=$2 WHERE id=$1", id, usedAt)
+	return err
+}
+
+// Delete revokes a key of the user, returning sql.ErrNoRows when the user has no such key.
+func (s *apiKeyRepository) Delete(ctx context.Context, userId, id uuid.UUID) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM api_keys WHERE id=$1 AND user_id=$2", id, userId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"my-go-api/internal/models"
+)
+
+type IAuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	List(ctx context.Context, limit int) ([]models.AuditLog, error)
+}
+
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+func NewAuditLogRepository(db *sql.DB) IAuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return err
+	}
+	if entry.Details == nil {
+		details = []byte("{}")
+	}
+	query := `
+		INSERT INTO audit_logs (actor_id, action, target_id, details, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		entry.ActorId,
+		entry.Action,
+		entry.TargetId,
+		details,
+		entry.IP,
+		entry.UserAgent,
+	).Scan(&entry.ID, &entry.CreatedAt)
+}
+
+// List returns the latest entries first.
+func (r *auditLogRepository) List(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, actor_id, action, target_id, details, ip, user_agent, created_at
+		FROM audit_logs ORDER BY created_at DESC, id DESC LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.ActorId, &entry.Action, &entry.TargetId, &details, &entry.IP, &entry.UserAgent, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type IIdentityRepository interface {
+	Create(ctx context.Context, userId uuid.UUID, provider, subject, email string) (*models.UserIdentity, error)
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	GetByUser(ctx context.Context, userId uuid.UUID) ([]models.UserIdentity, error)
+}
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) IIdentityRepository {
+	return &identityRepository{db: db}
+}
+
+func (s *identityRepository) Create(ctx context.Context, userId uuid.UUID, provider, subject, email string) (*models.UserIdentity, error) {
+	identity := &models.UserIdentity{}
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, provider, subject, COALESCE(email, ''), created_at
+	`
+	if err := s.db.QueryRowContext(ctx, query, userId, provider, subject, email).Scan(
+		&identity.ID, &identity.UserId, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *identityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	identity := &models.UserIdentity{}
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE provider=$1 AND subject=$2
+	`
+	if err := s.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&identity.ID, &identity.UserId, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (s *identityRepository) GetByUser(ctx context.Context, userId uuid.UUID) ([]models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE user_id=$1
+		ORDER BY created_at
+	`
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := rows.Scan(&identity.ID, &identity.UserId, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
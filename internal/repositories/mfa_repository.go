@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type IMfaRepository interface {
+	GetByUserId(ctx context.Context, userId uuid.UUID) (*models.UserMFA, error)
+	SaveSecret(ctx context.Context, userId uuid.UUID, secret string) error
+	Enable(ctx context.Context, userId uuid.UUID, step int64, recoveryCodeHashes []string) error
+	ClaimStep(ctx context.Context, userId uuid.UUID, step int64) (bool, error)
+	Delete(ctx context.Context, userId uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userId uuid.UUID, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userId uuid.UUID, codeHash string) (bool, error)
+}
+
+type mfaRepository struct {
+	db *sql.DB
+}
+
+func NewMfaRepository(db *sql.DB) IMfaRepository {
+	return &mfaRepository{db: db}
+}
+
+func (s *mfaRepository) GetByUserId(ctx context.Context, userId uuid.UUID) (*models.UserMFA, error) {
+	mfa := &models.UserMFA{}
+	query := `
+		SELECT user_id, totp_secret, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id=$1
+	`
+	if err := s.db.QueryRowContext(ctx, query, userId).Scan(
+		&mfa.UserId, &mfa.TOTPSecret, &mfa.EnabledAt, &mfa.LastUsedStep, &mfa.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return mfa, nil
+}
+
+// SaveSecret starts or restarts an enrollment, the factor stays disabled until Enable.
+func (s *mfaRepository) SaveSecret(ctx context.Context, userId uuid.UUID, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, totp_secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET totp_secret=$2, enabled_at=NULL, last_used_step=0, created_at=NOW()
+	`
+	_, err := s.db.ExecContext(ctx, query, userId, secret)
+	return err
+}
+
+// Enable confirms the enrollment, consuming the time step of the confirming code,
+// and stores the first set of recovery codes.
+func (s *mfaRepository) Enable(ctx context.Context, userId uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_mfa SET enabled_at=NOW(), last_used_step=$2
+		WHERE user_id=$1 AND enabled_at IS NULL
+	`, userId, step)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return sql.ErrNoRows
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userId, recoveryCodeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClaimStep records step as used and reports false if it, or a later one, was
+// already used, so a code cannot be replayed even by concurrent requests.
+func (s *mfaRepository) ClaimStep(ctx context.Context, userId uuid.UUID, step int64) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE user_mfa SET last_used_step=$2
+		WHERE user_id=$1 AND last_used_step < $2
+	`, userId, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *mfaRepository) Delete(ctx context.Context, userId uuid.UUID) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_mfa WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userId uuid.UUID, codeHashes []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := replaceRecoveryCodes(ctx, tx, userId, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userId uuid.UUID, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id=$1", userId); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)", userId, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused code as used and reports whether there was one.
+func (s *mfaRepository) UseRecoveryCode(ctx context.Context, userId uuid.UUID, codeHash string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at=NOW()
+		WHERE user_id=$1 AND code_hash=$2 AND used_at IS NULL
+	`, userId, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
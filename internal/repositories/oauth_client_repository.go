@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type IOAuthClientRepository interface {
+	Create(ctx context.Context, client *models.OAuthClient) error
+	GetById(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error)
+	List(ctx context.Context) ([]models.OAuthClient, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	CreateGrant(ctx context.Context, grant *models.OAuthGrant) error
+	GetGrant(ctx context.Context, deviceId uuid.UUID) (*models.OAuthGrant, error)
+}
+
+type oauthClientRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthClientRepository(db *sql.DB) IOAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+const oauthClientColumns = "id, name, type, secret_hash, redirect_uris, scopes, grant_types, created_at"
+
+func scanOAuthClient(row rowScanner) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	var redirectURIs, scopes, grantTypes string
+	if err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&client.Type,
+		&client.SecretHash,
+		&redirectURIs,
+		&scopes,
+		&grantTypes,
+		&client.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.Scopes = strings.Fields(scopes)
+	client.GrantTypes = strings.Fields(grantTypes)
+	return &client, nil
+}
+
+func (r *oauthClientRepository) Create(ctx context.Context, client *models.OAuthClient) error {
+	query := `
+		INSERT INTO oauth_clients (name, type, secret_hash, redirect_uris, scopes, grant_types)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		client.Name,
+		client.Type,
+		client.SecretHash,
+		strings.Join(client.RedirectURIs, " "),
+		strings.Join(client.Scopes, " "),
+		strings.Join(client.GrantTypes, " "),
+	).Scan(&client.ID, &client.CreatedAt)
+}
+
+func (r *oauthClientRepository) GetById(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error) {
+	query := "SELECT " + oauthClientColumns + " FROM oauth_clients WHERE id=$1"
+	return scanOAuthClient(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *oauthClientRepository) List(ctx context.Context) ([]models.OAuthClient, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+oauthClientColumns+" FROM oauth_clients ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []models.OAuthClient
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, *client)
+	}
+	return clients, rows.Err()
+}
+
+// Delete removes the client together with its grants, returning sql.ErrNoRows
+// when there is no such client.
+func (r *oauthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM oauth_clients WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *oauthClientRepository) CreateGrant(ctx context.Context, grant *models.OAuthGrant) error {
+	query := `
+		INSERT INTO oauth_grants (device_id, user_id, client_id, scope)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	return r.db.QueryRowContext(ctx, query, grant.DeviceId, grant.UserId, grant.ClientId, grant.Scope).Scan(&grant.CreatedAt)
+}
+
+func (r *oauthClientRepository) GetGrant(ctx context.Context, deviceId uuid.UUID) (*models.OAuthGrant, error) {
+	var grant models.OAuthGrant
+	err := r.db.QueryRowContext(ctx,
+		"SELECT device_id, user_id, client_id, scope, created_at FROM oauth_grants WHERE device_id=$1",
+		deviceId,
+	).Scan(&grant.DeviceId, &grant.UserId, &grant.ClientId, &grant.Scope, &grant.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"my-go-api/internal/policy"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type IPolicyRepository interface {
+	ListPolicies(ctx context.Context) ([]policy.Rule, error)
+	GetSubject(ctx context.Context, userId uuid.UUID) (*policy.Subject, error)
+}
+
+type policyRepository struct {
+	db *sql.DB
+}
+
+func NewPolicyRepository(db *sql.DB) IPolicyRepository {
+	return &policyRepository{db: db}
+}
+
+func (r *policyRepository) ListPolicies(ctx context.Context) ([]policy.Rule, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, description, effect, actions, resources, conditions FROM policies ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []policy.Rule
+	for rows.Next() {
+		var rule policy.Rule
+		var actions, resources string
+		var conditions []byte
+		if err := rows.Scan(&rule.ID, &rule.Description, &rule.Effect, &actions, &resources, &conditions); err != nil {
+			return nil, err
+		}
+		rule.Actions = strings.Fields(actions)
+		rule.Resources = strings.Fields(resources)
+		if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+			return nil, fmt.Errorf("policy %q: %w", rule.ID, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// GetSubject returns the attributes policies know a user by, sql.ErrNoRows when
+// there is no such user.
+func (r *policyRepository) GetSubject(ctx context.Context, userId uuid.UUID) (*policy.Subject, error) {
+	subject := &policy.Subject{UserId: userId}
+	if err := r.db.QueryRowContext(ctx,
+		"SELECT role, COALESCE(org, '') FROM users WHERE id=$1", userId,
+	).Scan(&subject.Role, &subject.Org); err != nil {
+		return nil, err
+	}
+	return subject, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RateLimitStoreRedis  = "redis"
+	RateLimitStoreMemory = "memory"
+
+	// RateLimitTokenBucket allows bursts of up to Limit requests and refills the
+	// bucket evenly over Window.
+	RateLimitTokenBucket = "token_bucket"
+	// RateLimitSlidingWindow allows at most Limit requests in any Window.
+	RateLimitSlidingWindow = "sliding_window"
+)
+
+// RateLimit is how many requests a key may make and how they are counted.
+type RateLimit struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the decision for one request. Reset is how long until the
+// key has its full limit again and RetryAfter, for a rejected request, how long
+// until the next one would be allowed.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// IRateLimiter counts requests per key. Every call to Allow counts as a request
+// when it is allowed.
+type IRateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
+
+// NewRateLimiter returns the implementation selected by store, see RateLimitConfig.Store.
+func NewRateLimiter(store string, rdb *redis.Client) IRateLimiter {
+	if store == RateLimitStoreMemory {
+		return NewMemoryRateLimiter()
+	}
+	return NewRedisRateLimiter(rdb)
+}
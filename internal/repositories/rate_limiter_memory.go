@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// memoryRateLimiter counts in the process, for a single instance or development
+// and as the fallback of the Redis limiter. Idle keys are dropped once a minute.
+type memoryRateLimiter struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	buckets   map[string]*tokenBucket
+	windows   map[string]*requestLog
+	lastSweep time.Time
+}
+
+type requestLog struct {
+	times  []time.Time
+	window time.Duration
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+func NewMemoryRateLimiter() IRateLimiter {
+	return newMemoryRateLimiter(time.Now)
+}
+
+func newMemoryRateLimiter(now func() time.Time) *memoryRateLimiter {
+	return &memoryRateLimiter{
+		now:       now,
+		buckets:   make(map[string]*tokenBucket),
+		windows:   make(map[string]*requestLog),
+		lastSweep: now(),
+	}
+}
+
+func (l *memoryRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+	switch limit.Algorithm {
+	case RateLimitTokenBucket:
+		return l.takeToken(now, key, limit), nil
+	case RateLimitSlidingWindow:
+		return l.logRequest(now, key, limit), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+}
+
+func (l *memoryRateLimiter) takeToken(now time.Time, key string, limit RateLimit) *RateLimitResult {
+	capacity := float64(limit.Limit)
+	// tokens per nanosecond
+	rate := capacity / float64(limit.Window)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+	bucket.window = limit.Window
+	bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updated))*rate)
+	bucket.updated = now
+
+	result := &RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) / rate))
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - bucket.tokens) / rate))
+	return result
+}
+
+func (l *memoryRateLimiter) logRequest(now time.Time, key string, limit RateLimit) *RateLimitResult {
+	entry, ok := l.windows[key]
+	if !ok {
+		entry = &requestLog{}
+		l.windows[key] = entry
+	}
+	entry.window = limit.Window
+	requests := entry.times
+	for len(requests) > 0 && !requests[0].After(now.Add(-limit.Window)) {
+		requests = requests[1:]
+	}
+	result := &RateLimitResult{}
+	if len(requests) < limit.Limit {
+		requests = append(requests, now)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = requests[0].Add(limit.Window).Sub(now)
+	}
+	entry.times = requests
+	result.Remaining = limit.Limit - len(requests)
+	if len(requests) > 0 {
+		result.Reset = requests[len(requests)-1].Add(limit.Window).Sub(now)
+	}
+	return result
+}
+
+// sweep drops keys that have been idle for longer than their window.
+func (l *memoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.updated) > bucket.window {
+			delete(l.buckets, key)
+		}
+	}
+	for key, entry := range l.windows {
+		if len(entry.times) == 0 || now.Sub(entry.times[len(entry.times)-1]) > entry.window {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Both scripts read the clock of Redis so that every instance shares one clock,
+// and answer {allowed, remaining, retry after ms, reset ms}.
+
+// tokenBucketScript keeps the tokens left and when they were counted in a hash.
+// ARGV: capacity, window in ms to refill an empty bucket.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local rate = capacity / window
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+// slidingWindowScript keeps a sorted set of the allowed requests scored by time.
+// ARGV: limit, window in ms, a unique member for this request.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[3])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+local retry = 0
+if allowed == 0 then
+  retry = tonumber(oldest[2]) + window - now
+end
+local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+return {allowed, limit - count, retry, tonumber(newest[2]) + window - now}
+`)
+
+// redisRateLimiter counts in Redis with atomic scripts so all instances share
+// the limits. While Redis cannot be reached it counts in memory instead, which
+// keeps every instance limited on its own.
+type redisRateLimiter struct {
+	rdb      *redis.Client
+	fallback IRateLimiter
+}
+
+func NewRedisRateLimiter(rdb *redis.Client) IRateLimiter {
+	return &redisRateLimiter{rdb: rdb, fallback: NewMemoryRateLimiter()}
+}
+
+func (l *redisRateLimiter) Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error) {
+	var result []int64
+	var err error
+	window := limit.Window.Milliseconds()
+	switch limit.Algorithm {
+	case RateLimitTokenBucket:
+		result, err = tokenBucketScript.Run(ctx, l.rdb, []string{key}, limit.Limit, window).Int64Slice()
+	case RateLimitSlidingWindow:
+		result, err = slidingWindowScript.Run(ctx, l.rdb, []string{key}, limit.Limit, window, uuid.NewString()).Int64Slice()
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm %q", limit.Algorithm)
+	}
+	if err != nil {
+		log.Printf("rate limiter: redis unavailable, counting in memory: %v", err)
+		return l.fallback.Allow(ctx, key, limit)
+	}
+	if len(result) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", result)
+	}
+	return &RateLimitResult{
+		Allowed:    result[0] == 1,
+		Remaining:  int(result[1]),
+		RetryAfter: time.Duration(result[2]) * time.Millisecond,
+		Reset:      time.Duration(result[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+// RateLimiterTestSuite is the conformance suite every IRateLimiter implementation
+// has to pass, setup provides a fresh limiter and a way to move its clock.
+type RateLimiterTestSuite struct {
+	suite.Suite
+	setup   func(t *testing.T) (IRateLimiter, func(time.Duration))
+	limiter IRateLimiter
+	advance func(time.Duration)
+}
+
+func (suite *RateLimiterTestSuite) SetupTest() {
+	suite.limiter, suite.advance = suite.setup(suite.T())
+}
+
+func (suite *RateLimiterTestSuite) allow(key string, limit RateLimit) *RateLimitResult {
+	result, err := suite.limiter.Allow(context.Background(), key, limit)
+	suite.Require().NoError(err)
+	return result
+}
+
+func (suite *RateLimiterTestSuite) TestTokenBucket() {
+	limit := RateLimit{Algorithm: RateLimitTokenBucket, Limit: 3, Window: 3 * time.Second}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := suite.allow("bucket", limit)
+		suite.True(result.Allowed)
+		suite.Equal(remaining, result.Remaining)
+	}
+	result := suite.allow("bucket", limit)
+	suite.False(result.Allowed)
+	suite.Equal(time.Second, result.RetryAfter)
+	suite.Equal(3*time.Second, result.Reset)
+
+	suite.True(suite.allow("other", limit).Allowed, "keys are limited separately")
+
+	suite.advance(time.Second)
+	suite.True(suite.allow("bucket", limit).Allowed)
+	suite.False(suite.allow("bucket", limit).Allowed)
+
+	suite.advance(time.Minute)
+	result = suite.allow("bucket", limit)
+	suite.True(result.Allowed)
+	suite.Equal(2, result.Remaining, "the bucket never holds more than its capacity")
+}
+
+func (suite *RateLimiterTestSuite) TestSlidingWindow() {
+	limit := RateLimit{Algorithm: RateLimitSlidingWindow, Limit: 2, Window: 10 * time.Second}
+
+	suite.True(suite.allow("window", limit).Allowed)
+	suite.advance(4 * time.Second)
+	result := suite.allow("window", limit)
+	suite.True(result.Allowed)
+	suite.Equal(0, result.Remaining)
+	suite.Equal(10*time.Second, result.Reset)
+
+	result = suite.allow("window", limit)
+	suite.False(result.Allowed)
+	suite.Equal(6*time.Second, result.RetryAfter)
+
+	suite.advance(6 * time.Second)
+	result = suite.allow("window", limit)
+	suite.True(result.Allowed, "the first request left the window")
+	suite.False(suite.allow("window", limit).Allowed, "rejected requests are not counted")
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	suite.Run(t, &RateLimiterTestSuite{setup: func(t *testing.T) (IRateLimiter, func(time.Duration)) {
+		now := time.Now()
+		limiter := newMemoryRateLimiter(func() time.Time { return now })
+		return limiter, func(d time.Duration) { now = now.Add(d) }
+	}})
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	suite.Run(t, &RateLimiterTestSuite{setup: func(t *testing.T) (IRateLimiter, func(time.Duration)) {
+		mr := miniredis.RunT(t)
+		now := time.Now().Truncate(time.Millisecond)
+		mr.SetTime(now)
+		rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { rdb.Close() })
+		return NewRedisRateLimiter(rdb), func(d time.Duration) {
+			now = now.Add(d)
+			mr.SetTime(now)
+			mr.FastForward(d)
+		}
+	}})
+}
+
+func TestRedisRateLimiterFallback(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	defer rdb.Close()
+	limiter := NewRedisRateLimiter(rdb)
+	mr.Close()
+
+	limit := RateLimit{Algorithm: RateLimitSlidingWindow, Limit: 1, Window: time.Minute}
+	result, err := limiter.Allow(context.Background(), "key", limit)
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected the first request to be allowed in memory, got %v, %v", result, err)
+	}
+	result, err = limiter.Allow(context.Background(), "key", limit)
+	if err != nil || result.Allowed {
+		t.Fatalf("expected the second request to be limited in memory, got %v, %v", result, err)
+	}
+}
@@ -11,6 +11,19 @@ import (
 type IRedisRepository interface {
 	HSet(key string, data map[string]any, expiry time.Duration) error
 	HGet(key string, field string) (string, error)
+	HIncrBy(key string, field string, incr int64) (int64, error)
+	Set(key string, value any, expiry time.Duration) error
+	SetNX(key string, value any, expiry time.Duration) (bool, error)
+	GetDel(key string) (string, error)
+	Del(keys ...string) error
+	Consume(key string) (bool, error)
+	Exists(key string) (bool, error)
+	Incr(key string, window time.Duration) (int64, error)
+	TTL(key string) (time.Duration, error)
+	ZAdd(key string, score float64, member string) error
+	ZRangeByScore(key string, min, max string) ([]string, error)
+	ZRem(key string, members ...string) error
+	ZRemRangeByScore(key string, min, max string) error
 }
 
 type redisRepository struct {
@@ -50,3 +63,141 @@ func (s *redisRepository) HGet(key string, field string) (string, error) {
 	}
 	return result, nil
 }
+
+func (s *redisRepository) HIncrBy(key string, field string, incr int64) (int64, error) {
+	ctx := context.Background()
+	result, err := s.rdb.HIncrBy(ctx, key, field, incr).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis HIncrBy failed: %w", err)
+	}
+	return result, nil
+}
+
+func (s *redisRepository) Set(key string, value any, expiration time.Duration) error {
+	ctx := context.Background()
+	if err := s.rdb.Set(ctx, key, value, expiration).Err(); err != nil {
+		return fmt.Errorf("failed to set key in Redis: %w", err)
+	}
+	return nil
+}
+
+// SetNX sets the key only when it does not exist yet and reports whether it was set.
+func (s *redisRepository) SetNX(key string, value any, expiration time.Duration) (bool, error) {
+	ctx := context.Background()
+	ok, err := s.rdb.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis SetNX failed: %w", err)
+	}
+	return ok, nil
+}
+
+// GetDel atomically reads and removes the key, so the value can be consumed only once.
+func (s *redisRepository) GetDel(key string) (string, error) {
+	ctx := context.Background()
+	result, err := s.rdb.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return "", fmt.Errorf("key %s not found: %w", key, err)
+		}
+		return "", fmt.Errorf("redis GetDel failed: %w", err)
+	}
+	return result, nil
+}
+
+func (s *redisRepository) Del(keys ...string) error {
+	ctx := context.Background()
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis Del failed: %w", err)
+	}
+	return nil
+}
+
+// Consume deletes the key and reports whether this call is the one that removed
+// it, so of concurrent callers only one gets true.
+func (s *redisRepository) Consume(key string) (bool, error) {
+	ctx := context.Background()
+	n, err := s.rdb.Del(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis Del failed: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (s *redisRepository) Exists(key string) (bool, error) {
+	ctx := context.Background()
+	n, err := s.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis Exists failed: %w", err)
+	}
+	return n > 0, nil
+}
+
+// incrScript increments KEYS[1] and, on its first increment, expires it after
+// ARGV[1] ms, in one step so a counter never outlives its window.
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 and tonumber(ARGV[1]) > 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// Incr increments a counter that lives for window from its first increment, so
+// it counts events in fixed windows. The increment and the expiry are a single
+// script, a failure between them cannot leave a counter that never expires.
+func (s *redisRepository) Incr(key string, window time.Duration) (int64, error) {
+	ctx := context.Background()
+	n, err := incrScript.Run(ctx, s.rdb, []string{key}, window.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("redis Incr failed: %w", err)
+	}
+	return n, nil
+}
+
+// TTL returns how long the key lives on, zero or less when it does not exist or never expires.
+func (s *redisRepository) TTL(key string) (time.Duration, error) {
+	ctx := context.Background()
+	ttl, err := s.rdb.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis TTL failed: %w", err)
+	}
+	return ttl, nil
+}
+
+func (s *redisRepository) ZAdd(key string, score float64, member string) error {
+	ctx := context.Background()
+	if err := s.rdb.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err(); err != nil {
+		return fmt.Errorf("redis ZAdd failed: %w", err)
+	}
+	return nil
+}
+
+// ZRangeByScore returns the members scored between min and max, which may be "-inf" or "+inf".
+func (s *redisRepository) ZRangeByScore(key string, min, max string) ([]string, error) {
+	ctx := context.Background()
+	members, err := s.rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis ZRangeByScore failed: %w", err)
+	}
+	return members, nil
+}
+
+func (s *redisRepository) ZRem(key string, members ...string) error {
+	ctx := context.Background()
+	values := make([]any, 0, len(members))
+	for _, member := range members {
+		values = append(values, member)
+	}
+	if err := s.rdb.ZRem(ctx, key, values...).Err(); err != nil {
+		return fmt.Errorf("redis ZRem failed: %w", err)
+	}
+	return nil
+}
+
+func (s *redisRepository) ZRemRangeByScore(key string, min, max string) error {
+	ctx := context.Background()
+	if err := s.rdb.ZRemRangeByScore(ctx, key, min, max).Err(); err != nil {
+		return fmt.Errorf("redis ZRemRangeByScore failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMiniRedisRepository(t *testing.T) (IRedisRepository, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewRedisRepository(rdb), mr
+}
+
+func TestRedisIncr(t *testing.T) {
+	repo, mr := newMiniRedisRepository(t)
+
+	for want := int64(1); want <= 3; want++ {
+		n, err := repo.Incr("counter", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+	assert.Equal(t, time.Minute, mr.TTL("counter"), "the window starts at the first increment")
+
+	mr.FastForward(time.Minute)
+	n, err := repo.Incr("counter", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n, "a new window starts once the last one expired")
+
+	_, err = repo.Incr("forever", 0)
+	assert.NoError(t, err)
+	assert.Zero(t, mr.TTL("forever"))
+}
+
+func TestRedisConsume(t *testing.T) {
+	repo, mr := newMiniRedisRepository(t)
+	mr.HSet("ticket", "user_id", "user")
+
+	consumed, err := repo.Consume("ticket")
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = repo.Consume("ticket")
+	assert.NoError(t, err)
+	assert.False(t, consumed, "only one caller consumes a key")
+}
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type IRoleRepository interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	GetRole(ctx context.Context, name string) (*models.Role, error)
+	CreateRole(ctx context.Context, role *models.Role) error
+	SetPermissions(ctx context.Context, role string, permissions []string) error
+	DeleteRole(ctx context.Context, name string) error
+	CountUsers(ctx context.Context, role string) (int, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	PermissionsOfUser(ctx context.Context, userId uuid.UUID) ([]string, error)
+	AssignRole(ctx context.Context, userId uuid.UUID, role string) error
+}
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) IRoleRepository {
+	return &roleRepository{db: db}
+}
+
+// roleQuery selects roles with their permissions as one space separated column.
+const roleQuery = `
+	SELECT r.name, r.description, COALESCE(string_agg(rp.permission, ' ' ORDER BY rp.permission), ''), r.created_at
+	FROM roles r
+	LEFT JOIN role_permissions rp ON rp.role = r.name
+`
+
+func scanRole(row rowScanner) (*models.Role, error) {
+	var role models.Role
+	var permissions string
+	if err := row.Scan(&role.Name, &role.Description, &permissions, &role.CreatedAt); err != nil {
+		return nil, err
+	}
+	role.Permissions = strings.Fields(permissions)
+	return &role, nil
+}
+
+func (r *roleRepository) ListRoles(ctx context.Context) ([]models.Role, error) {
+	rows, err := r.db.QueryContext(ctx, roleQuery+" GROUP BY r.name ORDER BY r.created_at, r.name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+// GetRole returns sql.ErrNoRows when there is no such role.
+func (r *roleRepository) GetRole(ctx context.Context, name string) (*models.Role, error) {
+	return scanRole(r.db.QueryRowContext(ctx, roleQuery+" WHERE r.name=$1 GROUP BY r.name", name))
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role *models.Role) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := tx.QueryRowContext(ctx,
+		"INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING created_at",
+		role.Name, role.Description,
+	).Scan(&role.CreatedAt); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, role.Name, role.Permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetPermissions replaces the permissions of a role.
+func (r *roleRepository) SetPermissions(ctx context.Context, role string, permissions []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM role_permissions WHERE role=$1", role); err != nil {
+		return err
+	}
+	if err := insertRolePermissions(ctx, tx, role, permissions); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertRolePermissions(ctx context.Context, tx *sql.Tx, role string, permissions []string) error {
+	for _, permission := range permissions {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO role_permissions (role, permission) VALUES ($1, $2)",
+			role, permission,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteRole returns sql.ErrNoRows when there is no such role. Roles still
+// assigned to users cannot be deleted.
+func (r *roleRepository) DeleteRole(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM roles WHERE name=$1", name)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *roleRepository) CountUsers(ctx context.Context, role string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users WHERE role=$1", role).Scan(&count)
+	return count, err
+}
+
+func (r *roleRepository) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT name, description FROM permissions ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.Name, &permission.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+// PermissionsOfUser returns the permissions the role of the user grants, none
+// for unknown users.
+func (r *roleRepository) PermissionsOfUser(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rp.permission
+		FROM users u
+		JOIN role_permissions rp ON rp.role = u.role
+		WHERE u.id=$1
+		ORDER BY rp.permission
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+// AssignRole returns sql.ErrNoRows when there is no such user.
+func (r *roleRepository) AssignRole(ctx context.Context, userId uuid.UUID, role string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE users SET role=$2, updated_at=NOW() WHERE id=$1", userId, role)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
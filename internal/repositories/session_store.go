@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"my-go-api/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SessionStorePostgres = "postgres"
+	SessionStoreRedis    = "redis"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has already been rotated")
+)
+
+// ISessionStore keeps the refresh tokens of every signed in device.
+// A device has one active token at a time, rotated tokens are kept as revoked
+// until they expire so that replaying them can be detected. Saving a session
+// for a device replaces everything it had before. Expired tokens are never returned.
+type ISessionStore interface {
+	Save(ctx context.Context, token *models.Token) error
+	Get(ctx context.Context, userId, deviceId uuid.UUID) (*models.Token, error)
+	GetByHash(ctx context.Context, hash string) (*models.Token, error)
+	// Rotate revokes current and stores next in its place. It fails with
+	// ErrSessionRevoked when current has been revoked in the meantime.
+	Rotate(ctx context.Context, current, next *models.Token) error
+	RevokeFamily(ctx context.Context, familyId uuid.UUID) error
+	// ListFamily returns every token of a family, the rotated ones included,
+	// oldest first.
+	ListFamily(ctx context.Context, familyId uuid.UUID) ([]models.Token, error)
+	// ListByUser returns the active token of every signed in device, most recently used first.
+	ListByUser(ctx context.Context, userId uuid.UUID) ([]models.Token, error)
+	Remove(ctx context.Context, userId, deviceId uuid.UUID) error
+	RemoveAllByUser(ctx context.Context, userId uuid.UUID) error
+}
+
+// NewSessionStore returns the implementation selected by driver, see AuthConfig.SessionStore.
+func NewSessionStore(driver string, db *sql.DB, rdb *redis.Client) ISessionStore {
+	if driver == SessionStoreRedis {
+		return NewRedisSessionStore(rdb)
+	}
+	return NewPostgresSessionStore(db)
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"my-go-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type postgresSessionStore struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionStore(db *sql.DB) ISessionStore {
+	return &postgresSessionStore{db: db}
+}
+
+const tokenColumns = `id, hash, is_revoked, device_id, user_id, jti, family_id, client_id, expired_at, device_label, user_agent, ip, created_at, last_used_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row rowScanner) (*models.Token, error) {
+	token := &models.Token{}
+	// sessions created before the jti column existed have none
+	var jti, clientId uuid.NullUUID
+	if err := row.Scan(
+		&token.ID, &token.Hash, &token.IsRevoked, &token.DeviceId, &token.UserId, &jti, &token.FamilyId, &clientId, &token.ExpiredAt,
+		&token.DeviceLabel, &token.UserAgent, &token.IP, &token.CreatedAt, &token.LastUsedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	token.Jti = jti.UUID
+	token.ClientId = clientId.UUID
+	return token, nil
+}
+
+func insertToken(ctx context.Context, tx *sql.Tx, token *models.Token) error {
+	query := `
+		INSERT INTO tokens (device_id, user_id, jti, family_id, client_id, hash, is_revoked, expired_at, device_label, user_agent, ip, created_at, last_used_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+	clientId := uuid.NullUUID{UUID: token.ClientId, Valid: token.ClientId != uuid.Nil}
+	return tx.QueryRowContext(ctx, query,
+		token.DeviceId, token.UserId, token.Jti, token.FamilyId, clientId, token.Hash, token.IsRevoked, token.ExpiredAt,
+		token.DeviceLabel, token.UserAgent, token.IP, token.CreatedAt, token.LastUsedAt,
+	).Scan(&token.ID)
+}
+
+func (s *postgresSessionStore) Save(ctx context.Context, token *models.Token) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tokens WHERE user_id=$1 AND device_id=$2`, token.UserId, token.DeviceId); err != nil {
+		return err
+	}
+	if err := insertToken(ctx, tx, token); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *postgresSessionStore) Get(ctx context.Context, userId, deviceId uuid.UUID) (*models.Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE user_id=$1 AND device_id=$2 AND is_revoked=false AND expired_at > NOW()
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	return scanToken(s.db.QueryRowContext(ctx, query, userId, deviceId))
+}
+
+func (s *postgresSessionStore) GetByHash(ctx context.Context, hash string) (*models.Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE hash=$1 AND expired_at > NOW()
+	`
+	return scanToken(s.db.QueryRowContext(ctx, query, hash))
+}
+
+func (s *postgresSessionStore) Rotate(ctx context.Context, current, next *models.Token) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	result, err := tx.ExecContext(ctx, `UPDATE tokens SET is_revoked=true WHERE hash=$1 AND is_revoked=false`, current.Hash)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrSessionRevoked
+	}
+	if err := insertToken(ctx, tx, next); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *postgresSessionStore) RevokeFamily(ctx context.Context, familyId uuid.UUID) error {
+	query := `DELETE FROM tokens WHERE family_id=$1`
+	if _, err := s.db.ExecContext(ctx, query, familyId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *postgresSessionStore) ListFamily(ctx context.Context, familyId uuid.UUID) ([]models.Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE family_id=$1 AND expired_at > NOW()
+		ORDER BY id
+	`
+	return s.queryTokens(ctx, query, familyId)
+}
+
+func (s *postgresSessionStore) ListByUser(ctx context.Context, userId uuid.UUID) ([]models.Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM tokens
+		WHERE user_id=$1 AND is_revoked=false AND expired_at > NOW()
+		ORDER BY last_used_at DESC, id DESC
+	`
+	return s.queryTokens(ctx, query, userId)
+}
+
+func (s *postgresSessionStore) queryTokens(ctx context.Context, query string, args ...any) ([]models.Token, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tokens := []models.Token{}
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *postgresSessionStore) Remove(ctx context.Context, userId, deviceId uuid.UUID) error {
+	query := `DELETE FROM tokens WHERE user_id=$1 AND device_id=$2`
+	if _, err := s.db.ExecContext(ctx, query, userId, deviceId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *postgresSessionStore) RemoveAllByUser(ctx context.Context, userId uuid.UUID) error {
+	query := `DELETE FROM tokens WHERE user_id=$1`
+	if _, err := s.db.ExecContext(ctx, query, userId); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"my-go-api/internal/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisSessionStore keeps each refresh token in a hash at refresh-token:<hash>.
+// session:<userId>:<deviceId> points at the active token of a device, the set
+// session-family:<familyId> holds every token of a family and sessions:<userId>
+// the devices of a user.
+type redisSessionStore struct {
+	rdb *redis.Client
+}
+
+func NewRedisSessionStore(rdb *redis.Client) ISessionStore {
+	return &redisSessionStore{rdb: rdb}
+}
+
+func tokenKey(hash string) string {
+	return fmt.Sprintf("refresh-token:%s", hash)
+}
+
+func sessionKey(userId, deviceId uuid.UUID) string {
+	return fmt.Sprintf("session:%s:%s", userId, deviceId)
+}
+
+func familyKey(familyId uuid.UUID) string {
+	return fmt.Sprintf("session-family:%s", familyId)
+}
+
+func userSessionsKey(userId uuid.UUID) string {
+	return fmt.Sprintf("sessions:%s", userId)
+}
+
+func writeToken(ctx context.Context, pipe redis.Pipeliner, token *models.Token) {
+	key := tokenKey(token.Hash)
+	pipe.HSet(ctx, key, map[string]any{
+		"user_id":      token.UserId.String(),
+		"device_id":    token.DeviceId.String(),
+		"jti":          token.Jti.String(),
+		"family_id":    token.FamilyId.String(),
+		"client_id":    clientIdField(token.ClientId),
+		"is_revoked":   token.IsRevoked,
+		"expired_at":   token.ExpiredAt.Unix(),
+		"device_label": token.DeviceLabel,
+		"user_agent":   token.UserAgent,
+		"ip":           token.IP,
+		"created_at":   token.CreatedAt.Unix(),
+		"last_used_at": token.LastUsedAt.Unix(),
+	})
+	pipe.ExpireAt(ctx, key, token.ExpiredAt)
+	pipe.SetArgs(ctx, sessionKey(token.UserId, token.DeviceId), token.Hash, redis.SetArgs{ExpireAt: token.ExpiredAt})
+	pipe.SAdd(ctx, familyKey(token.FamilyId), token.Hash)
+	pipe.ExpireAt(ctx, familyKey(token.FamilyId), token.ExpiredAt)
+	// the device index is shared by all sessions of the user so it does not expire,
+	// devices whose session has ended are pruned by ListByUser
+	pipe.SAdd(ctx, userSessionsKey(token.UserId), token.DeviceId.String())
+}
+
+// clientIdField is how the client of a session is stored, empty for sessions of
+// our own apps.
+func clientIdField(clientId uuid.UUID) string {
+	if clientId == uuid.Nil {
+		return ""
+	}
+	return clientId.String()
+}
+
+func (s *redisSessionStore) loadToken(ctx context.Context, hash string) (*models.Token, error) {
+	values, err := s.rdb.HGetAll(ctx, tokenKey(hash)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis HGetAll failed: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	timestamps := make(map[string]time.Time, 3)
+	for _, field := range []string{"expired_at", "created_at", "last_used_at"} {
+		unix, err := strconv.ParseInt(values[field], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session %s: %w", field, err)
+		}
+		timestamps[field] = time.Unix(unix, 0)
+	}
+	token := &models.Token{
+		Hash:      hash,
+		IsRevoked: values["is_revoked"] == "1",
+		ExpiredAt: timestamps["expired_at"],
+		SessionMetadata: models.SessionMetadata{
+			DeviceLabel: values["device_label"],
+			UserAgent:   values["user_agent"],
+			IP:          values["ip"],
+		},
+		CreatedAt:  timestamps["created_at"],
+		LastUsedAt: timestamps["last_used_at"],
+	}
+	if !token.ExpiredAt.After(time.Now()) {
+		return nil, ErrSessionNotFound
+	}
+	for field, target := range map[string]*uuid.UUID{
+		"user_id":   &token.UserId,
+		"device_id": &token.DeviceId,
+		"jti":       &token.Jti,
+		"family_id": &token.FamilyId,
+	} {
+		if *target, err = uuid.Parse(values[field]); err != nil {
+			return nil, fmt.Errorf("invalid session %s: %w", field, err)
+		}
+	}
+	if values["client_id"] != "" {
+		if token.ClientId, err = uuid.Parse(values["client_id"]); err != nil {
+			return nil, fmt.Errorf("invalid session client_id: %w", err)
+		}
+	}
+	return token, nil
+}
+
+func (s *redisSessionStore) Save(ctx context.Context, token *models.Token) error {
+	if err := s.Remove(ctx, token.UserId, token.DeviceId); err != nil {
+		return err
+	}
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		writeToken(ctx, pipe, token)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save session in Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *redisSessionStore) Get(ctx context.Context, userId, deviceId uuid.UUID) (*models.Token, error) {
+	hash, err := s.rdb.Get(ctx, sessionKey(userId, deviceId)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("redis Get failed: %w", err)
+	}
+	token, err := s.loadToken(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if token.IsRevoked {
+		return nil, ErrSessionNotFound
+	}
+	return token, nil
+}
+
+func (s *redisSessionStore) GetByHash(ctx context.Context, hash string) (*models.Token, error) {
+	return s.loadToken(ctx, hash)
+}
+
+func (s *redisSessionStore) Rotate(ctx context.Context, current, next *models.Token) error {
+	key := tokenKey(current.Hash)
+	err := s.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		revoked, err := tx.HGet(ctx, key, "is_revoked").Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		if revoked != "0" {
+			return ErrSessionRevoked
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "is_revoked", true)
+			writeToken(ctx, pipe, next)
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// another request rotated the same token first
+		return ErrSessionRevoked
+	}
+	return err
+}
+
+func (s *redisSessionStore) RevokeFamily(ctx context.Context, familyId uuid.UUID) error {
+	hashes, err := s.rdb.SMembers(ctx, familyKey(familyId)).Result()
+	if err != nil {
+		return fmt.Errorf("redis SMembers failed: %w", err)
+	}
+	keys := []string{familyKey(familyId)}
+	var devices [][2]string
+	for _, hash := range hashes {
+		keys = append(keys, tokenKey(hash))
+		owner, err := s.rdb.HMGet(ctx, tokenKey(hash), "user_id", "device_id").Result()
+		if err != nil {
+			return fmt.Errorf("redis HMGet failed: %w", err)
+		}
+		userId, _ := owner[0].(string)
+		deviceId, _ := owner[1].(string)
+		if userId == "" || deviceId == "" {
+			continue
+		}
+		pointer := fmt.Sprintf("session:%s:%s", userId, deviceId)
+		active, err := s.rdb.Get(ctx, pointer).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("redis Get failed: %w", err)
+		}
+		// the device may have signed in again since, its new session is not part of this family
+		if active == hash {
+			devices = append(devices, [2]string{userId, deviceId})
+		}
+	}
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, owner := range devices {
+			pipe.Del(ctx, fmt.Sprintf("session:%s:%s", owner[0], owner[1]))
+			pipe.SRem(ctx, fmt.Sprintf("sessions:%s", owner[0]), owner[1])
+		}
+		pipe.Del(ctx, keys...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session family in Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *redisSessionStore) ListFamily(ctx context.Context, familyId uuid.UUID) ([]models.Token, error) {
+	hashes, err := s.rdb.SMembers(ctx, familyKey(familyId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis SMembers failed: %w", err)
+	}
+	tokens := []models.Token{}
+	for _, hash := range hashes {
+		token, err := s.loadToken(ctx, hash)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].LastUsedAt.Before(tokens[j].LastUsedAt)
+	})
+	return tokens, nil
+}
+
+func (s *redisSessionStore) ListByUser(ctx context.Context, userId uuid.UUID) ([]models.Token, error) {
+	devices, err := s.rdb.SMembers(ctx, userSessionsKey(userId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis SMembers failed: %w", err)
+	}
+	tokens := []models.Token{}
+	for _, device := range devices {
+		deviceId, err := uuid.Parse(device)
+		if err != nil {
+			continue
+		}
+		token, err := s.Get(ctx, userId, deviceId)
+		if err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				s.rdb.SRem(ctx, userSessionsKey(userId), device)
+				continue
+			}
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].LastUsedAt.After(tokens[j].LastUsedAt)
+	})
+	return tokens, nil
+}
+
+func (s *redisSessionStore) Remove(ctx context.Context, userId, deviceId uuid.UUID) error {
+	hash, err := s.rdb.Get(ctx, sessionKey(userId, deviceId)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("redis Get failed: %w", err)
+	}
+	if hash != "" {
+		familyId, err := s.rdb.HGet(ctx, tokenKey(hash), "family_id").Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("redis HGet failed: %w", err)
+		}
+		if id, err := uuid.Parse(familyId); err == nil {
+			if err := s.RevokeFamily(ctx, id); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(userId, deviceId))
+		pipe.SRem(ctx, userSessionsKey(userId), deviceId.String())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove session from Redis: %w", err)
+	}
+	return nil
+}
+
+func (s *redisSessionStore) RemoveAllByUser(ctx context.Context, userId uuid.UUID) error {
+	devices, err := s.rdb.SMembers(ctx, userSessionsKey(userId)).Result()
+	if err != nil {
+		return fmt.Errorf("redis SMembers failed: %w", err)
+	}
+	for _, device := range devices {
+		deviceId, err := uuid.Parse(device)
+		if err != nil {
+			continue
+		}
+		if err := s.Remove(ctx, userId, deviceId); err != nil {
+			return err
+		}
+	}
+	if err := s.rdb.Del(ctx, userSessionsKey(userId)).Err(); err != nil {
+		return fmt.Errorf("redis Del failed: %w", err)
+	}
+	return nil
+}
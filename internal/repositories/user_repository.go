@@ -16,6 +16,9 @@ type IUserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) (*models.User, error)
+	VerifyEmail(ctx context.Context, userId uuid.UUID) (*models.User, error)
+	UpdatePassword(ctx context.Context, userId uuid.UUID, password string) error
+	CreateWithProvider(ctx context.Context, name, username, email, provider string) (*models.User, error)
 }
 
 type userRepository struct {
@@ -28,7 +31,7 @@ func NewUserRepository(db *sql.DB) IUserRepository {
 
 func (s *userRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	query := `
-		SELECT id, name, username, email, provider, role, created_at, updated_at
+		SELECT id, name, username, email, provider, role, email_verified_at, created_at, updated_at
 		FROM users
 	`
 	rows, err := s.db.QueryContext(ctx, query)
@@ -41,7 +44,7 @@ func (s *userRepository) GetAll(ctx context.Context) ([]models.User, error) {
 	for rows.Next() {
 		var user models.User
 		// Scan the row into the User struct
-		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Provider, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+		err := rows.Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
 			log.Printf("Failed to scan user: %v", err) // Log the error
 			return nil, err
@@ -57,9 +60,24 @@ func (s *userRepository) Create(ctx context.Context, name, username, email, pass
 	query := `
 						INSERT INTO users (name, username, email, password)
 						VALUES ($1, $2, $3, $4)
-						RETURNING id, name, email, password, username, provider, role, updated_at, created_at
+						RETURNING id, name, email, COALESCE(password, ''), username, provider, role, email_verified_at, updated_at, created_at
 					`
-	if err := s.db.QueryRowContext(ctx, query, name, username, email, password).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Username, &user.Provider, &user.Role, &user.UpdatedAt, &user.CreatedAt); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, name, username, email, password).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Username, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.UpdatedAt, &user.CreatedAt); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateWithProvider inserts a password-less account for an external identity provider.
+// The provider has already confirmed the address, so the email is marked as verified.
+func (s *userRepository) CreateWithProvider(ctx context.Context, name, username, email, provider string) (*models.User, error) {
+	user := &models.User{}
+	query := `
+		INSERT INTO users (name, username, email, provider, email_verified_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, name, email, COALESCE(password, ''), username, provider, role, email_verified_at, updated_at, created_at
+	`
+	if err := s.db.QueryRowContext(ctx, query, name, username, email, provider).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.Username, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.UpdatedAt, &user.CreatedAt); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -68,9 +86,9 @@ func (s *userRepository) Create(ctx context.Context, name, username, email, pass
 func (s *userRepository) GetById(ctx context.Context, userId uuid.UUID) (*models.User, error) {
 	user := &models.User{}
 	query := `SELECT 
-						id, name, username, email, password, provider, role, created_at, updated_at 
+						id, name, username, email, COALESCE(password, ''), provider, role, email_verified_at, created_at, updated_at 
 						FROM users WHERE id = $1`
-	if err := s.db.QueryRowContext(ctx, query, userId).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, userId).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -79,9 +97,9 @@ func (s *userRepository) GetById(ctx context.Context, userId uuid.UUID) (*models
 func (s *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	user := &models.User{}
 	query := `SELECT 
-						id, name, username, email, password, provider, role, created_at, updated_at 
+						id, name, username, email, COALESCE(password, ''), provider, role, email_verified_at, created_at, updated_at 
 						FROM users WHERE username = $1`
-	if err := s.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, username).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return user, nil
@@ -90,23 +108,56 @@ func (s *userRepository) GetByUsername(ctx context.Context, username string) (*m
 func (s *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	query := `SELECT 
-						id, name, username, email, password, provider, role, created_at, updated_at 
+						id, name, username, email, COALESCE(password, ''), provider, role, email_verified_at, created_at, updated_at 
 						FROM users WHERE email = $1`
-	if err := s.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
 
+// Update writes the user back. A new email is not verified yet, so changing it
+// clears email_verified_at.
 func (s *userRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
 		UPDATE users
-		SET username=$1, email=$2, name=$3, password=$4, role=$5, updated_at=NOW()
+		SET username=$1, email=$2, name=$3, password=NULLIF($4, ''), role=$5, updated_at=NOW(),
+			email_verified_at=CASE WHEN email=$2 THEN email_verified_at END
 		WHERE id=$6 
-		RETURNING id, name, username, email, password, provider, role, created_at, updated_at
+		RETURNING id, name, username, email, COALESCE(password, ''), provider, role, email_verified_at, created_at, updated_at
+	`
+	if err := s.db.QueryRowContext(ctx, query, user.Username, user.Email, user.Name, user.Password, user.Role, user.ID).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *userRepository) VerifyEmail(ctx context.Context, userId uuid.UUID) (*models.User, error) {
+	user := &models.User{}
+	query := `
+		UPDATE users
+		SET email_verified_at=COALESCE(email_verified_at, NOW()), updated_at=NOW()
+		WHERE id=$1
+		RETURNING id, name, username, email, COALESCE(password, ''), provider, role, email_verified_at, created_at, updated_at
 	`
-	if err := s.db.QueryRowContext(ctx, query, user.Username, user.Email, user.Name, user.Password, user.Role, user.ID).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, userId).Scan(&user.ID, &user.Name, &user.Username, &user.Email, &user.Password, &user.Provider, &user.Role, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
+
+func (s *userRepository) UpdatePassword(ctx context.Context, userId uuid.UUID, password string) error {
+	query := `UPDATE users SET password=$1, updated_at=NOW() WHERE id=$2`
+	result, err := s.db.ExecContext(ctx, query, password, userId)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -41,6 +42,9 @@ func (suite *UserRepositoryTestSuite) SetupTest() {
 				password TEXT,
 				provider providers DEFAULT 'credentials',
 				role user_roles DEFAULT 'user',
+				email_verified_at TIMESTAMP(0)
+				WITH
+					TIME ZONE,
 				created_at TIMESTAMP(0)
 				WITH
 					TIME ZONE NOT NULL DEFAULT NOW (),
@@ -173,6 +177,65 @@ func (suite *UserRepositoryTestSuite) TestUpdate() {
 	assert.Greater(suite.T(), u.UnixMilli(), c.UnixMilli())
 }
 
+func (suite *UserRepositoryTestSuite) TestUpdateEmailClearsVerification() {
+	testUser := suite.localInsert()
+	verified, err := suite.repo.VerifyEmail(context.Background(), testUser.ID)
+	assert.NoError(suite.T(), err)
+
+	verified.Name = "Fufufafa"
+	user, err := suite.repo.Update(context.Background(), verified)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), user.EmailVerifiedAt, "keeping the email keeps it verified")
+
+	user.Email = "changed@example.com"
+	user, err = suite.repo.Update(context.Background(), user)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), user.EmailVerifiedAt)
+}
+
+func (suite *UserRepositoryTestSuite) TestVerifyEmail() {
+	testUser := suite.localInsert()
+	assert.Nil(suite.T(), testUser.EmailVerifiedAt)
+
+	user, err := suite.repo.VerifyEmail(context.Background(), testUser.ID)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), user.EmailVerifiedAt)
+
+	// verifying twice keeps the original timestamp
+	time.Sleep(1 * time.Second)
+	again, err := suite.repo.VerifyEmail(context.Background(), testUser.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *user.EmailVerifiedAt, *again.EmailVerifiedAt)
+}
+
+func (suite *UserRepositoryTestSuite) TestUpdatePassword() {
+	testUser := suite.localInsert()
+
+	err := suite.repo.UpdatePassword(context.Background(), testUser.ID, "new-hash")
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.repo.GetById(context.Background(), testUser.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new-hash", user.Password)
+
+	err = suite.repo.UpdatePassword(context.Background(), uuid.New(), "new-hash")
+	assert.ErrorIs(suite.T(), err, sql.ErrNoRows)
+}
+
+func (suite *UserRepositoryTestSuite) TestCreateWithProvider() {
+	newUser, err := suite.repo.CreateWithProvider(context.Background(), "John Doe", "johndoe", "john@gmail.com", "google")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "google", newUser.Provider)
+	assert.Equal(suite.T(), "", newUser.Password)
+	assert.NotNil(suite.T(), newUser.EmailVerifiedAt)
+
+	// password-less users can be read back
+	user, err := suite.repo.GetByEmail(context.Background(), "john@gmail.com")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), newUser.ID, user.ID)
+	assert.Equal(suite.T(), "", user.Password)
+}
+
 func TestUserRepositoryTestSuite(t *testing.T) {
 	suite.Run(t, new(UserRepositoryTestSuite))
 }
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"my-go-api/internal/models"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type IWebAuthnRepository interface {
+	Create(ctx context.Context, credential *models.WebAuthnCredential) error
+	GetByUser(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error)
+	UpdateAfterLogin(ctx context.Context, credentialId []byte, signCount uint32, backupState bool) error
+	Delete(ctx context.Context, userId uuid.UUID, credentialId []byte) error
+}
+
+type webAuthnRepository struct {
+	db *sql.DB
+}
+
+func NewWebAuthnRepository(db *sql.DB) IWebAuthnRepository {
+	return &webAuthnRepository{db: db}
+}
+
+func (s *webAuthnRepository) Create(ctx context.Context, credential *models.WebAuthnCredential) error {
+	query := `
+		INSERT INTO webauthn_credentials (id, user_id, name, public_key, attestation_type, transports, aaguid, sign_count, backup_eligible, backup_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+	return s.db.QueryRowContext(ctx, query,
+		credential.ID,
+		credential.UserId,
+		credential.Name,
+		credential.PublicKey,
+		credential.AttestationType,
+		strings.Join(credential.Transports, ","),
+		credential.AAGUID,
+		int64(credential.SignCount),
+		credential.BackupEligible,
+		credential.BackupState,
+	).Scan(&credential.CreatedAt)
+}
+
+func (s *webAuthnRepository) GetByUser(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error) {
+	query := `
+		SELECT id, user_id, name, public_key, attestation_type, transports, aaguid, sign_count, backup_eligible, backup_state, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id=$1
+		ORDER BY created_at
+	`
+	rows, err := s.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var credentials []models.WebAuthnCredential
+	for rows.Next() {
+		var credential models.WebAuthnCredential
+		var transports string
+		var signCount int64
+		if err := rows.Scan(
+			&credential.ID,
+			&credential.UserId,
+			&credential.Name,
+			&credential.PublicKey,
+			&credential.AttestationType,
+			&transports,
+			&credential.AAGUID,
+			&signCount,
+			&credential.BackupEligible,
+			&credential.BackupState,
+			&credential.CreatedAt,
+			&credential.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		if transports != "" {
+			credential.Transports = strings.Split(transports, ",")
+		}
+		credential.SignCount = uint32(signCount)
+		credentials = append(credentials, credential)
+	}
+	return credentials, rows.Err()
+}
+
+// UpdateAfterLogin stores the signature counter and backup state reported by the
+// authenticator in its latest assertion.
+func (s *webAuthnRepository) UpdateAfterLogin(ctx context.Context, credentialId []byte, signCount uint32, backupState bool) error {
+	query := `
+		UPDATE webauthn_credentials SET sign_count=$2, backup_state=$3, last_used_at=NOW()
+		WHERE id=$1
+	`
+	_, err := s.db.ExecContext(ctx, query, credentialId, int64(signCount), backupState)
+	return err
+}
+
+// Delete removes a credential of the user, returning sql.ErrNoRows when the user has no such credential.
+func (s *webAuthnRepository) Delete(ctx context.Context, userId uuid.UUID, credentialId []byte) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM webauthn_credentials WHERE id=$1 AND user_id=$2", credentialId, userId)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
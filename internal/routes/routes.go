@@ -1,18 +1,24 @@
 package routes
 
 import (
+	"context"
 	"database/sql"
+	"log"
 	"my-go-api/internal/config"
+	"my-go-api/internal/constants"
 	"my-go-api/internal/handlers"
 	"my-go-api/internal/middleware"
+	"my-go-api/internal/policy"
 	"my-go-api/internal/utils"
 
 	"my-go-api/internal/repositories"
 	"my-go-api/internal/services"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -21,51 +27,253 @@ func RegisterRoutes(
 	rdb *redis.Client,
 	validate *validator.Validate,
 	config *config.Config,
+	keyring *utils.Keyring,
+	relyingParty *webauthn.WebAuthn,
 ) *gin.Engine {
-	router := gin.Default()
-
 	userRepo := repositories.NewUserRepository(db)
-	userService := services.NewUserService(userRepo)
-	userHandler := handlers.NewUserHandler(userService)
-
 	redisRepo := repositories.NewRedisRepository(rdb)
 
-	utilities := utils.NewUtilities(config.JWtSecretKey, config.AppUri, config.GoogleOAuth2)
-	tokenRepo := repositories.NewTokenRepository(db)
+	utilities := utils.NewUtilities(keyring, config.JWT, config.AppUri, config.GoogleOAuth2, utils.NewPasswordHasher(config.Password))
+
+	rbacService := services.NewRBACService(repositories.NewRoleRepository(db), userRepo)
+
+	userService := services.NewUserService(userRepo, utilities)
+	sessionStore := repositories.NewSessionStore(config.Auth.SessionStore, db, rdb)
+	securityEvents := services.NewLogSecurityEventPublisher()
+	tokenDenylist := services.NewTokenDenylist(redisRepo, sessionStore, config.JWT.AccessTokenTTL, config.Auth.DenylistCacheTTL)
 
 	authService := services.NewAuthService(
 		userRepo,
 		utilities,
-		tokenRepo,
+		sessionStore,
 		redisRepo,
+		securityEvents,
+		tokenDenylist,
 		config.AppUri,
+		config.Auth,
 	)
 
-	authHandler := handlers.NewAuthHandler(authService, userService)
+	webAuthnRepo := repositories.NewWebAuthnRepository(db)
+	mfaService := services.NewMfaService(repositories.NewMfaRepository(db), webAuthnRepo, redisRepo, utilities, config.Auth)
+
+	policyService := services.NewPolicyService(repositories.NewPolicyRepository(db), mfaService, config.Policy)
+	if err := policyService.Reload(context.Background()); err != nil {
+		log.Fatalf("Could not load access policies: %v", err)
+	}
+	go policyService.ReloadEvery(context.Background(), config.Policy.ReloadInterval)
+
+	magicLinkService := services.NewMagicLinkService(userRepo, redisRepo, utilities, config.AppUri, config.Auth)
+
+	webAuthnService := services.NewWebAuthnService(relyingParty, webAuthnRepo, redisRepo, utilities, config.WebAuthn.ChallengeTTL)
+
+	lockoutService := services.NewLockoutService(
+		userRepo,
+		redisRepo,
+		securityEvents,
+		utilities,
+		config.AppUri,
+		config.Auth,
+	)
+
+	sessionService := services.NewSessionService(sessionStore, tokenDenylist)
+
+	googleVerifier := utils.NewIDTokenVerifier(
+		utils.NewJWKS(config.GoogleSignIn.JWKSSource, time.Hour),
+		[]string{"accounts.google.com", "https://accounts.google.com"},
+		config.GoogleSignIn.ClientIds,
+	)
+	identityRepo := repositories.NewIdentityRepository(db)
+	googleAuthService := services.NewGoogleAuthService(userRepo, identityRepo, googleVerifier)
+
+	oauthService := services.NewOAuthService(
+		utils.NewOAuthProviderRegistry(config.OAuthProviders),
+		userRepo,
+		identityRepo,
+		redisRepo,
+		utilities,
+		config.Auth.OAuthStateTTL,
+	)
+
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(db), utilities)
+
+	authorizationServer := services.NewAuthorizationServer(
+		repositories.NewOAuthClientRepository(db),
+		userRepo,
+		sessionStore,
+		redisRepo,
+		tokenDenylist,
+		authService,
+		utilities,
+		config.JWT.Issuer,
+		config.Auth.OAuthConsentUri,
+		config.Auth.OAuthCodeTTL,
+		config.JWT.AccessTokenTTL,
+		config.Auth.InternalAPIKeys,
+	)
+
+	impersonationService := services.NewImpersonationService(
+		userRepo,
+		rbacService,
+		repositories.NewAuditLogRepository(db),
+		tokenDenylist,
+		utilities,
+		config.Auth.ImpersonationTTL,
+	)
+
+	return NewRouter(Services{
+		Auth:                authService,
+		User:                userService,
+		RBAC:                rbacService,
+		Mfa:                 mfaService,
+		Policy:              policyService,
+		MagicLink:           magicLinkService,
+		WebAuthn:            webAuthnService,
+		Lockout:             lockoutService,
+		Session:             sessionService,
+		GoogleAuth:          googleAuthService,
+		OAuth:               oauthService,
+		APIKey:              apiKeyService,
+		AuthorizationServer: authorizationServer,
+		Impersonation:       impersonationService,
+	}, validate, config, keyring, repositories.NewRateLimiter(config.RateLimit.Store, rdb))
+}
+
+// Services are what the routes are served by.
+type Services struct {
+	Auth                services.IAuthService
+	User                services.IUserService
+	RBAC                services.IRBACService
+	Mfa                 services.IMfaService
+	Policy              services.IPolicyService
+	MagicLink           services.IMagicLinkService
+	WebAuthn            services.IWebAuthnService
+	Lockout             services.ILockoutService
+	Session             services.ISessionService
+	GoogleAuth          services.IGoogleAuthService
+	OAuth               services.IOAuthService
+	APIKey              services.IAPIKeyService
+	AuthorizationServer services.IAuthorizationServer
+	Impersonation       services.IImpersonationService
+}
+
+// NewRouter registers the handlers of the services on their routes, with the
+// authentication, authorization, validation and rate limit they need.
+func NewRouter(
+	s Services,
+	validate *validator.Validate,
+	config *config.Config,
+	keyring *utils.Keyring,
+	limiter repositories.IRateLimiter,
+) *gin.Engine {
+	router := gin.Default()
+
+	userHandler := handlers.NewUserHandler(s.User, s.RBAC, s.Auth, s.Session)
+	authHandler := handlers.NewAuthHandler(s.Auth, s.User, s.Mfa, s.Lockout)
+	rbacHandler := handlers.NewRBACHandler(s.RBAC)
+	mfaHandler := handlers.NewMfaHandler(s.Mfa, s.Auth, s.User)
+	policyHandler := handlers.NewPolicyHandler(s.Policy)
+	magicLinkHandler := handlers.NewMagicLinkHandler(s.MagicLink, s.Auth, s.Mfa, config.Auth.MagicLinkTTL)
+	webAuthnHandler := handlers.NewWebAuthnHandler(s.WebAuthn, s.Mfa, s.Auth, s.User)
+	lockoutHandler := handlers.NewLockoutHandler(s.Lockout)
+	sessionHandler := handlers.NewSessionHandler(s.Session)
+	googleAuthHandler := handlers.NewGoogleAuthHandler(s.GoogleAuth, s.Auth, s.Mfa)
+	oauthHandler := handlers.NewOAuthHandler(s.OAuth, s.Auth, s.Mfa, config.Auth.OAuthStateTTL)
+	apiKeyHandler := handlers.NewAPIKeyHandler(s.APIKey)
+	authorizationServerHandler := handlers.NewAuthorizationServerHandler(s.AuthorizationServer)
+	impersonationHandler := handlers.NewImpersonationHandler(s.Impersonation)
+	jwksHandler := handlers.NewJWKSHandler(keyring)
 
 	md := middleware.RegisterValidationMiddleware(validate)
-	mdT := middleware.RegisterTokenVerificationMiddleware(authService)
+	mdT := middleware.RegisterTokenVerificationMiddleware(s.Auth, s.APIKey)
+	mdP := middleware.RegisterPermissionMiddleware(s.RBAC)
+	mdA := middleware.RegisterPolicyMiddleware(s.Policy)
+	rl := middleware.RegisterRateLimitMiddleware(limiter, config.RateLimit.Policies)
+	limitAuth := rl.Limit("auth")
+	// the user is only known once RequireAuth ran, so user keyed limits follow it
+	limitUser := rl.Limit("user")
 
 	router.SetTrustedProxies([]string{"127.0.0.1"})
 
-	v1 := router.Group("/api/v1")
+	router.GET("/.well-known/jwks.json", jwksHandler.Get)
+	router.GET("/.well-known/openid-configuration", authorizationServerHandler.Discovery)
+	router.GET("/userinfo", mdT.RequireScope(constants.OAUTH_SCOPE_OPENID), limitUser, authorizationServerHandler.UserInfo)
+	router.POST("/userinfo", mdT.RequireScope(constants.OAUTH_SCOPE_OPENID), limitUser, authorizationServerHandler.UserInfo)
+
+	oauth := router.Group("/oauth")
+	{
+		oauth.POST("/authorize", mdT.RequireAuth, mdT.RequireSession, limitUser, md.OAuthAuthorize, authorizationServerHandler.Authorize)
+		oauth.POST("/token", limitAuth, authorizationServerHandler.Token)
+		oauth.POST("/introspect", limitAuth, authorizationServerHandler.Introspect)
+		oauth.POST("/revoke", limitAuth, authorizationServerHandler.Revoke)
+	}
+
+	v1 := router.Group("/api/v1", rl.Limit("global"))
 	{
 		v1.GET("", func(ctx *gin.Context) {
 			ctx.JSON(http.StatusOK, gin.H{"message": "Welcome to V1"})
 		})
 		v1Users := v1.Group("/users")
 		{
-			v1Users.GET("", userHandler.GetAll)
-			v1Users.GET("/:id", userHandler.GetUserById)
-			v1Users.PUT("/:id", md.UpdateUser, userHandler.Update)
+			v1Users.GET("", mdT.RequireAuth, limitUser, mdP.RequirePermission(constants.PERMISSION_USERS_READ), userHandler.GetAll)
+			v1Users.GET("/:id", mdT.RequireAuth, limitUser, mdA.Authorize(policy.ReadUser, middleware.UserParam("id")), userHandler.GetUserById)
+			v1Users.PUT("/:id", mdT.RequireAuth, limitUser, mdA.Authorize(policy.UpdateUser, middleware.UserParam("id")), md.UpdateUser, userHandler.Update)
 		}
 		v1Auth := v1.Group("/auth")
 		{
-			v1Auth.GET("", mdT.RequireAuth, authHandler.GetAuth)
-			v1Auth.POST("", md.Login, authHandler.Login)
+			v1Auth.GET("", mdT.RequireAuth, limitUser, authHandler.GetAuth)
+			v1Auth.POST("", limitAuth, md.Login, authHandler.Login)
 			v1Auth.POST("/refresh-token", authHandler.RefreshToken)
 			v1Auth.POST("/logout", authHandler.Logout)
-			v1Auth.POST("/register", md.CreateUser, authHandler.Register)
+			v1Auth.POST("/register", limitAuth, md.CreateUser, authHandler.Register)
+			v1Auth.GET("/email-verification", authHandler.VerifyEmail)
+			v1Auth.POST("/email-verification", md.VerifyEmail, authHandler.VerifyEmail)
+			v1Auth.POST("/email-verification/resend", limitAuth, md.ResendVerificationEmail, authHandler.ResendVerificationEmail)
+			v1Auth.POST("/password/forgot", limitAuth, md.ForgotPassword, authHandler.ForgotPassword)
+			v1Auth.POST("/password/reset", limitAuth, md.ResetPassword, authHandler.ResetPassword)
+			v1Auth.GET("/unlock", authHandler.UnlockAccount)
+			v1Auth.POST("/unlock", limitAuth, md.UnlockAccount, authHandler.UnlockAccount)
+			v1Auth.POST("/magic-link", limitAuth, md.MagicLink, magicLinkHandler.Request)
+			v1Auth.GET("/magic-link/consume", magicLinkHandler.Consume)
+			v1Auth.POST("/google", limitAuth, md.GoogleLogin, googleAuthHandler.Login)
+			v1Auth.GET("/oauth/:provider/start", oauthHandler.Start)
+			v1Auth.GET("/oauth/:provider/callback", oauthHandler.Callback)
+			v1Auth.GET("/sessions", mdT.RequireAuth, mdT.RequireSession, limitUser, sessionHandler.List)
+			v1Auth.DELETE("/sessions", mdT.RequireAuth, mdT.RequireSession, limitUser, sessionHandler.RevokeAll)
+			v1Auth.DELETE("/sessions/:deviceId", mdT.RequireAuth, mdT.RequireSession, limitUser, sessionHandler.Revoke)
+			v1Auth.GET("/api-keys", mdT.RequireAuth, mdT.RequireSession, limitUser, apiKeyHandler.List)
+			v1Auth.POST("/api-keys", mdT.RequireAuth, mdT.RequireSession, limitUser, md.CreateAPIKey, apiKeyHandler.Create)
+			v1Auth.DELETE("/api-keys/:keyId", mdT.RequireAuth, mdT.RequireSession, limitUser, apiKeyHandler.Revoke)
+			v1Auth.POST("/mfa/verify", limitAuth, md.VerifyMfa, mfaHandler.Verify)
+			v1Auth.POST("/mfa/totp", mdT.RequireAuth, mdT.RequireSession, limitUser, mfaHandler.Enroll)
+			v1Auth.POST("/mfa/totp/confirm", mdT.RequireAuth, mdT.RequireSession, limitUser, md.MfaCode, mfaHandler.ConfirmEnrollment)
+			v1Auth.POST("/mfa/totp/disable", mdT.RequireAuth, mdT.RequireSession, limitUser, md.MfaCode, mfaHandler.Disable)
+			v1Auth.POST("/mfa/recovery-codes", mdT.RequireAuth, mdT.RequireSession, limitUser, md.MfaCode, mfaHandler.RegenerateRecoveryCodes)
+			v1Auth.POST("/mfa/webauthn/start", md.MfaTicket, webAuthnHandler.BeginMfa)
+			v1Auth.POST("/mfa/webauthn/verify", limitAuth, md.WebAuthnMfa, webAuthnHandler.FinishMfa)
+			v1Auth.POST("/webauthn/register/start", mdT.RequireAuth, mdT.RequireSession, limitUser, webAuthnHandler.BeginRegistration)
+			v1Auth.POST("/webauthn/register/finish", mdT.RequireAuth, mdT.RequireSession, limitUser, md.WebAuthnRegistration, webAuthnHandler.FinishRegistration)
+			v1Auth.GET("/webauthn/credentials", mdT.RequireAuth, mdT.RequireSession, limitUser, webAuthnHandler.ListCredentials)
+			v1Auth.DELETE("/webauthn/credentials/:credentialId", mdT.RequireAuth, mdT.RequireSession, limitUser, webAuthnHandler.DeleteCredential)
+			v1Auth.POST("/webauthn/login/start", webAuthnHandler.BeginLogin)
+			v1Auth.POST("/webauthn/login/finish", limitAuth, md.WebAuthnLogin, webAuthnHandler.FinishLogin)
+			v1Auth.POST("/impersonation/stop", mdT.RequireAuth, limitUser, impersonationHandler.Stop)
+		}
+		v1Admin := v1.Group("/admin", mdT.RequireAuth, mdT.RequireSession, limitUser)
+		{
+			v1Admin.GET("/locked-accounts", mdP.RequirePermission(constants.PERMISSION_LOCKOUTS_READ), lockoutHandler.ListLocked)
+			v1Admin.DELETE("/locked-accounts/:userId", mdP.RequirePermission(constants.PERMISSION_LOCKOUTS_WRITE), lockoutHandler.Unlock)
+			v1Admin.GET("/oauth/clients", mdP.RequirePermission(constants.PERMISSION_OAUTH_CLIENTS_READ), authorizationServerHandler.ListClients)
+			v1Admin.POST("/oauth/clients", mdP.RequirePermission(constants.PERMISSION_OAUTH_CLIENTS_WRITE), md.CreateOAuthClient, authorizationServerHandler.RegisterClient)
+			v1Admin.DELETE("/oauth/clients/:clientId", mdP.RequirePermission(constants.PERMISSION_OAUTH_CLIENTS_WRITE), authorizationServerHandler.DeleteClient)
+			v1Admin.GET("/roles", mdP.RequirePermission(constants.PERMISSION_ROLES_READ), rbacHandler.ListRoles)
+			v1Admin.POST("/roles", mdP.RequirePermission(constants.PERMISSION_ROLES_WRITE), md.CreateRole, rbacHandler.CreateRole)
+			v1Admin.PUT("/roles/:role/permissions", mdP.RequirePermission(constants.PERMISSION_ROLES_WRITE), md.SetRolePermissions, rbacHandler.SetRolePermissions)
+			v1Admin.DELETE("/roles/:role", mdP.RequirePermission(constants.PERMISSION_ROLES_WRITE), rbacHandler.DeleteRole)
+			v1Admin.GET("/permissions", mdP.RequirePermission(constants.PERMISSION_ROLES_READ), rbacHandler.ListPermissions)
+			v1Admin.PUT("/users/:userId/role", mdP.RequirePermission(constants.PERMISSION_ROLES_WRITE), md.AssignRole, rbacHandler.AssignRole)
+			v1Admin.POST("/policies/evaluate", mdP.RequirePermission(constants.PERMISSION_POLICIES_READ), md.EvaluatePolicy, policyHandler.Evaluate)
+			v1Admin.POST("/users/:userId/impersonate", mdP.RequirePermission(constants.PERMISSION_USERS_IMPERSONATE), md.StartImpersonation, impersonationHandler.Start)
+			v1Admin.GET("/audit-log", mdP.RequirePermission(constants.PERMISSION_AUDIT_LOG_READ), impersonationHandler.AuditLog)
 		}
 	}
 
@@ -0,0 +1,183 @@
+package routes_test
+
+import (
+	"context"
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/routes"
+	"my-go-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionRoutes are the routes only the user themselves may use, never with an
+// API key, a token of an OAuth2 client or while impersonated.
+var sessionRoutes = []struct {
+	method string
+	path   string
+}{
+	{http.MethodGet, "/api/v1/auth/sessions"},
+	{http.MethodDelete, "/api/v1/auth/sessions"},
+	{http.MethodDelete, "/api/v1/auth/sessions/device"},
+	{http.MethodGet, "/api/v1/auth/api-keys"},
+	{http.MethodPost, "/api/v1/auth/api-keys"},
+	{http.MethodDelete, "/api/v1/auth/api-keys/key"},
+	{http.MethodPost, "/api/v1/auth/mfa/totp"},
+	{http.MethodPost, "/api/v1/auth/mfa/totp/confirm"},
+	{http.MethodPost, "/api/v1/auth/mfa/totp/disable"},
+	{http.MethodPost, "/api/v1/auth/mfa/recovery-codes"},
+	{http.MethodPost, "/api/v1/auth/webauthn/register/start"},
+	{http.MethodPost, "/api/v1/auth/webauthn/register/finish"},
+	{http.MethodGet, "/api/v1/auth/webauthn/credentials"},
+	{http.MethodDelete, "/api/v1/auth/webauthn/credentials/credential"},
+	{http.MethodGet, "/api/v1/admin/locked-accounts"},
+	{http.MethodDelete, "/api/v1/admin/locked-accounts/user"},
+	{http.MethodGet, "/api/v1/admin/oauth/clients"},
+	{http.MethodPost, "/api/v1/admin/oauth/clients"},
+	{http.MethodDelete, "/api/v1/admin/oauth/clients/client"},
+	{http.MethodGet, "/api/v1/admin/roles"},
+	{http.MethodPost, "/api/v1/admin/roles"},
+	{http.MethodPut, "/api/v1/admin/roles/role/permissions"},
+	{http.MethodDelete, "/api/v1/admin/roles/role"},
+	{http.MethodGet, "/api/v1/admin/permissions"},
+	{http.MethodPut, "/api/v1/admin/users/user/role"},
+	{http.MethodPost, "/api/v1/admin/policies/evaluate"},
+	{http.MethodPost, "/api/v1/admin/users/user/impersonate"},
+	{http.MethodGet, "/api/v1/admin/audit-log"},
+}
+
+func TestSessionRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userId := uuid.New()
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockAPIKeyService := mock_services.NewMockIAPIKeyService(ctrl)
+	router := routes.NewRouter(
+		routes.Services{Auth: mockAuthService, APIKey: mockAPIKeyService},
+		validator.New(),
+		&config.Config{},
+		nil,
+		repositories.NewMemoryRateLimiter(),
+	)
+
+	mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "mga_key").Return(&models.APIKey{
+		ID:     uuid.New(),
+		UserId: userId,
+		Scopes: []string{"read", "write"},
+	}, nil).AnyTimes()
+	mockAuthService.EXPECT().ValidateToken("client-token").Return(&services.TokenPayload{
+		UserId:   userId,
+		ClientId: uuid.New(),
+		Scope:    "read write",
+		Jti:      uuid.New(),
+	}, nil).AnyTimes()
+	mockAuthService.EXPECT().ValidateToken("impersonation-token").Return(&services.TokenPayload{
+		UserId:  userId,
+		ActorId: uuid.New(),
+		Jti:     uuid.New(),
+	}, nil).AnyTimes()
+
+	request := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header = header
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	credentials := []struct {
+		name   string
+		header http.Header
+	}{
+		{"an api key", http.Header{"X-Api-Key": {"mga_key"}}},
+		{"a token of an oauth client", http.Header{"Authorization": {"Bearer client-token"}}},
+		{"an impersonation token", http.Header{"Authorization": {"Bearer impersonation-token"}}},
+	}
+	for _, credential := range credentials {
+		t.Run("it should return 403 for "+credential.name, func(t *testing.T) {
+			for _, route := range sessionRoutes {
+				w := request(route.method, route.path, credential.header)
+				assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route.method, route.path)
+			}
+		})
+	}
+}
+
+func TestOAuthRoutesRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limited := &config.Config{RateLimit: config.RateLimitConfig{Policies: map[string]config.RateLimitPolicy{
+		"auth": {Algorithm: "sliding_window", Limit: 1, Window: time.Minute, KeyBy: "ip"},
+	}}}
+	for _, path := range []string{"/oauth/token", "/oauth/introspect", "/oauth/revoke"} {
+		t.Run("it should limit "+path+" like the other auth routes", func(t *testing.T) {
+			router := routes.NewRouter(routes.Services{}, validator.New(), limited, nil, repositories.NewMemoryRateLimiter())
+			codes := make([]int, 2)
+			for i := range codes {
+				req, _ := http.NewRequest(http.MethodPost, path, nil)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				codes[i] = w.Code
+			}
+			assert.NotEqual(t, http.StatusTooManyRequests, codes[0])
+			assert.Equal(t, http.StatusTooManyRequests, codes[1])
+		})
+	}
+}
+
+func TestUserRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := mock_services.NewMockIAuthService(ctrl)
+	mockUserService := mock_services.NewMockIUserService(ctrl)
+	limited := &config.Config{RateLimit: config.RateLimitConfig{Policies: map[string]config.RateLimitPolicy{
+		"global": {Algorithm: "token_bucket", Limit: 100, Window: time.Minute, KeyBy: "ip"},
+		"user":   {Algorithm: "token_bucket", Limit: 1, Window: time.Minute, KeyBy: "user"},
+	}}}
+	router := routes.NewRouter(
+		routes.Services{Auth: mockAuthService, User: mockUserService},
+		validator.New(),
+		limited,
+		nil,
+		repositories.NewMemoryRateLimiter(),
+	)
+
+	first, second := uuid.New(), uuid.New()
+	for token, userId := range map[string]uuid.UUID{"first-token": first, "second-token": second} {
+		mockAuthService.EXPECT().ValidateToken(token).Return(&services.TokenPayload{UserId: userId, Jti: uuid.New()}, nil).AnyTimes()
+	}
+	mockUserService.EXPECT().GetUserById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id uuid.UUID) (*models.User, error) {
+		return &models.User{ID: id}, nil
+	}).AnyTimes()
+
+	request := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("it should count users behind the same IP separately", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request("first-token").Code)
+		assert.Equal(t, http.StatusTooManyRequests, request("first-token").Code)
+		assert.Equal(t, http.StatusOK, request("second-token").Code)
+	})
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid or expired api key")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// apiKeyLastUsedInterval limits how often using a key is written to the database.
+const apiKeyLastUsedInterval = time.Minute
+
+type IAPIKeyService interface {
+	Create(ctx context.Context, userId uuid.UUID, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error)
+	List(ctx context.Context, userId uuid.UUID) ([]models.APIKey, error)
+	Revoke(ctx context.Context, userId, id uuid.UUID) error
+	Authenticate(ctx context.Context, raw string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo repositories.IAPIKeyRepository
+	utility    utils.IUtils
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.IAPIKeyRepository, utility utils.IUtils) IAPIKeyService {
+	return &apiKeyService{apiKeyRepo: apiKeyRepo, utility: utility}
+}
+
+// Create returns a new key together with its stored record. The key is only
+// available here, afterwards the user sees its prefix.
+func (s *apiKeyService) Create(ctx context.Context, userId uuid.UUID, name string, scopes []string, expiresAt *time.Time) (string, *models.APIKey, error) {
+	secret, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", nil, errors.New("failed to generate api key")
+	}
+	raw := constants.API_KEY_PREFIX + secret
+	key := &models.APIKey{
+		UserId:    userId,
+		Name:      name,
+		Prefix:    raw[:len(constants.API_KEY_PREFIX)+8],
+		Hash:      s.utility.HashWithSHA256(raw),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return raw, key, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, userId uuid.UUID) ([]models.APIKey, error) {
+	return s.apiKeyRepo.GetByUser(ctx, userId)
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userId, id uuid.UUID) error {
+	err := s.apiKeyRepo.Delete(ctx, userId, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// Authenticate returns the key raw belongs to, failing with ErrInvalidAPIKey for
+// unknown and expired keys.
+func (s *apiKeyService) Authenticate(ctx context.Context, raw string) (*models.APIKey, error) {
+	if !strings.HasPrefix(raw, constants.API_KEY_PREFIX) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := s.apiKeyRepo.GetByHash(ctx, s.utility.HashWithSHA256(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, ErrInvalidAPIKey
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(ctx, key.ID, now); err != nil {
+			log.Println(err.Error())
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
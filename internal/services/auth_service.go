@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"my-go-api/internal/config"
+	"my-go-api/internal/constants"
 	"my-go-api/internal/dto"
 	"my-go-api/internal/models"
 	"my-go-api/internal/repositories"
@@ -16,14 +18,36 @@ import (
 	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified         = errors.New("email is not verified")
+	ErrTooManyRequests          = errors.New("too many requests")
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+	ErrInvalidRefreshToken      = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused       = errors.New("refresh token reuse detected")
+	ErrTokenRevoked             = errors.New("token has been revoked")
+	ErrUserNotFound             = errors.New("user not found")
+)
+
 type IAuthService interface {
 	SendVerificationEmail(name, email, token string) error
-	StoreRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, hash string) error
+	CreateEmailVerificationToken(ctx context.Context, userId uuid.UUID) (string, error)
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	ResendVerificationEmail(ctx context.Context, email string) error
+	EnsureEmailVerified(user *models.User) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	DeleteAllRefreshTokens(ctx context.Context, userId uuid.UUID) error
+	StoreRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, hash string, meta models.SessionMetadata) error
+	StoreClientRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, hash string, meta models.SessionMetadata) error
 	DeleteRefreshToken(ctx context.Context, userId, deviceId uuid.UUID) error
-	VerifyRefreshToken(ctx context.Context, userId, deviceId uuid.UUID, token string) error
+	RotateRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, token string, meta models.SessionMetadata) (string, error)
+	RotateClientRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, token string, meta models.SessionMetadata) (string, error)
 	GenerateRefreshToken() (string, string, error)
 	GenerateToken(userId, jti uuid.UUID) (string, error)
+	GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error)
 	VerifyPassword(hashedPassword string, plainPassword string) bool
+	UpgradePasswordHash(ctx context.Context, user *models.User, password string) error
 	GetUserByIdentity(ctx context.Context, identity string) (*models.User, error)
 	ValidateToken(tokenString string) (*TokenPayload, error)
 	CreateUser(ctx context.Context, req dto.CreateUser) (*models.User, error)
@@ -31,26 +55,35 @@ type IAuthService interface {
 
 type authService struct {
 	appUri    string
+	authCfg   config.AuthConfig
 	userRepo  repositories.IUserRepository
-	tokenRepo repositories.ITokenRepository
+	sessions  repositories.ISessionStore
 	redisRepo repositories.IRedisRepository
+	events    ISecurityEventPublisher
+	denylist  ITokenDenylist
 	utility   utils.IUtils
 }
 
 func NewAuthService(
 	userRepo repositories.IUserRepository,
 	utility utils.IUtils,
-	tokenRepo repositories.ITokenRepository,
+	sessions repositories.ISessionStore,
 	redisRepo repositories.IRedisRepository,
+	events ISecurityEventPublisher,
+	denylist ITokenDenylist,
 	appUri string,
+	authCfg config.AuthConfig,
 ) IAuthService {
 
 	return &authService{
 		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
+		sessions:  sessions,
 		appUri:    appUri,
+		authCfg:   authCfg,
 		utility:   utility,
 		redisRepo: redisRepo,
+		events:    events,
+		denylist:  denylist,
 	}
 
 }
@@ -66,56 +99,301 @@ func (s *authService) SendVerificationEmail(name, email, token string) error {
 	return nil
 }
 
-func (s *authService) StoreRefreshToken(
-	ctx context.Context,
-	jti, userId, deviceId uuid.UUID,
-	hash string) error {
-	key := fmt.Sprintf("refresh-token:%s", hash)
-	if err := s.redisRepo.HSet(key, map[string]any{
-		"userId":   userId,
-		"deviceId": deviceId,
-		"jti":      jti,
-	}, time.Hour*24*7); err != nil {
+// CreateEmailVerificationToken issues a single-use token for the verification link.
+// Only its hash is kept in Redis and it expires after AuthConfig.EmailVerificationTTL.
+func (s *authService) CreateEmailVerificationToken(ctx context.Context, userId uuid.UUID) (string, error) {
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", errors.New("failed to generate verification token")
+	}
+	key := fmt.Sprintf("email-verification:%s", s.utility.HashWithSHA256(raw))
+	if err := s.redisRepo.Set(key, userId.String(), s.authCfg.EmailVerificationTTL); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+func (s *authService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	key := fmt.Sprintf("email-verification:%s", s.utility.HashWithSHA256(token))
+	value, err := s.redisRepo.GetDel(key)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, ErrInvalidVerificationToken
+	}
+	userId, err := uuid.Parse(value)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+	user, err := s.userRepo.VerifyEmail(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidVerificationToken
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// ResendVerificationEmail is throttled per address and silently does nothing for
+// unknown or already verified accounts so the response cannot be used to probe emails.
+func (s *authService) ResendVerificationEmail(ctx context.Context, email string) error {
+	throttleKey := fmt.Sprintf("email-verification-resend:%s", strings.ToLower(email))
+	ok, err := s.redisRepo.SetNX(throttleKey, 1, s.authCfg.EmailVerificationResendInterval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTooManyRequests
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil || user.Provider != constants.PROVIDER_CREDENTIALS {
+		return nil
+	}
+	token, err := s.CreateEmailVerificationToken(ctx, user.ID)
+	if err != nil {
 		return err
 	}
+	return s.SendVerificationEmail(user.Name, user.Email, token)
+}
+
+func (s *authService) EnsureEmailVerified(user *models.User) error {
+	if !s.authCfg.RequireEmailVerification || user.Provider != constants.PROVIDER_CREDENTIALS {
+		return nil
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
 	return nil
 }
 
-// func (s *authService) StoreRefreshToken(ctx context.Context, userId, deviceId uuid.UUID, hash string) error {
-// 	_, err := s.tokenRepo.Insert(ctx, userId, deviceId, hash)
-// 	if err != nil {
-// 		return err
-// 	}
-// 	return nil
-// }
+// RequestPasswordReset emails a single-use reset link to credentials accounts.
+// Like ResendVerificationEmail it never reports whether the address is registered.
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	throttleKey := fmt.Sprintf("password-reset-request:%s", strings.ToLower(email))
+	ok, err := s.redisRepo.SetNX(throttleKey, 1, s.authCfg.PasswordResetRequestInterval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTooManyRequests
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if user.Provider != constants.PROVIDER_CREDENTIALS {
+		return nil
+	}
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return errors.New("failed to generate password reset token")
+	}
+	hash := s.utility.HashWithSHA256(raw)
+	// only the most recently requested link stays usable
+	userKey := fmt.Sprintf("password-reset-user:%s", user.ID)
+	if previous, err := s.redisRepo.GetDel(userKey); err == nil {
+		if err := s.redisRepo.Del(fmt.Sprintf("password-reset:%s", previous)); err != nil {
+			return err
+		}
+	}
+	if err := s.redisRepo.Set(fmt.Sprintf("password-reset:%s", hash), user.ID.String(), s.authCfg.PasswordResetTTL); err != nil {
+		return err
+	}
+	if err := s.redisRepo.Set(userKey, hash, s.authCfg.PasswordResetTTL); err != nil {
+		return err
+	}
+	var link = s.appUri + fmt.Sprintf("/password-reset?token=%s", raw)
+	var subject = "Password reset"
+	var emailBody = fmt.Sprintf("Hello %s.\n\n Please follow this link to reset your password. The link expires in %s.\n\n%s\n\nIf you did not request a password reset you can ignore this email.", user.Name, s.authCfg.PasswordResetTTL, link)
+	return s.utility.SendEmailWithGmail(subject, emailBody, user.Email)
+}
+
+// ResetPassword consumes the reset token, stores the new password and signs the
+// user out everywhere by removing all of their refresh tokens.
+func (s *authService) ResetPassword(ctx context.Context, token, password string) error {
+	hash := s.utility.HashWithSHA256(token)
+	value, err := s.redisRepo.GetDel(fmt.Sprintf("password-reset:%s", hash))
+	if err != nil {
+		log.Println(err.Error())
+		return ErrInvalidResetToken
+	}
+	userId, err := uuid.Parse(value)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	if err := s.redisRepo.Del(fmt.Sprintf("password-reset-user:%s", userId)); err != nil {
+		log.Println(err.Error())
+	}
+	hashedPassword, err := s.utility.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userId, hashedPassword); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	return s.DeleteAllRefreshTokens(ctx, userId)
+}
+
+// DeleteAllRefreshTokens signs the user out of every device, including the
+// access tokens those devices still hold.
+func (s *authService) DeleteAllRefreshTokens(ctx context.Context, userId uuid.UUID) error {
+	tokens, err := s.sessions.ListByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for i := range tokens {
+		if err := s.denylist.RevokeSession(ctx, &tokens[i]); err != nil {
+			return err
+		}
+	}
+	return s.sessions.RemoveAllByUser(ctx, userId)
+}
+
+// StoreRefreshToken starts a new token family for a device that just signed in,
+// replacing the session it had before.
+func (s *authService) StoreRefreshToken(
+	ctx context.Context,
+	jti, userId, deviceId uuid.UUID,
+	hash string,
+	meta models.SessionMetadata) error {
+	return s.storeRefreshToken(ctx, jti, userId, deviceId, uuid.Nil, hash, meta)
+}
+
+// StoreClientRefreshToken is StoreRefreshToken for a session an OAuth2 client
+// was granted, which only that client can rotate.
+func (s *authService) StoreClientRefreshToken(
+	ctx context.Context,
+	jti, userId, deviceId, clientId uuid.UUID,
+	hash string,
+	meta models.SessionMetadata) error {
+	return s.storeRefreshToken(ctx, jti, userId, deviceId, clientId, hash, meta)
+}
+
+func (s *authService) storeRefreshToken(
+	ctx context.Context,
+	jti, userId, deviceId, clientId uuid.UUID,
+	hash string,
+	meta models.SessionMetadata) error {
+	now := time.Now()
+	return s.sessions.Save(ctx, &models.Token{
+		Hash:            hash,
+		DeviceId:        deviceId,
+		UserId:          userId,
+		Jti:             jti,
+		FamilyId:        uuid.New(),
+		ClientId:        clientId,
+		ExpiredAt:       now.Add(s.authCfg.RefreshTokenTTL),
+		SessionMetadata: meta,
+		CreatedAt:       now,
+		LastUsedAt:      now,
+	})
+}
 
 func (s *authService) DeleteRefreshToken(ctx context.Context, userId, deviceId uuid.UUID) error {
-	err := s.tokenRepo.Remove(ctx, userId, deviceId)
+	token, err := s.sessions.Get(ctx, userId, deviceId)
+	if err != nil && !errors.Is(err, repositories.ErrSessionNotFound) {
+		return err
+	}
+	if token != nil {
+		if err := s.denylist.RevokeSession(ctx, token); err != nil {
+			return err
+		}
+	}
+	err = s.sessions.Remove(ctx, userId, deviceId)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *authService) VerifyRefreshToken(ctx context.Context, userId, deviceId uuid.UUID, token string) error {
-	existingToken, err := s.tokenRepo.GetToken(ctx, userId, deviceId)
+// RotateRefreshToken exchanges a refresh token for its successor in the same
+// family and returns the new raw token. A token can be exchanged only once;
+// presenting one that has already been rotated means it was copied, so the whole
+// family is revoked and a security event is published. Sessions of OAuth2
+// clients are refused, their tokens must not become tokens of our own apps.
+func (s *authService) RotateRefreshToken(ctx context.Context, jti, userId, deviceId uuid.UUID, token string, meta models.SessionMetadata) (string, error) {
+	return s.rotateRefreshToken(ctx, jti, userId, deviceId, uuid.Nil, token, meta)
+}
+
+// RotateClientRefreshToken is RotateRefreshToken for the sessions of clientId,
+// any other session is refused.
+func (s *authService) RotateClientRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, token string, meta models.SessionMetadata) (string, error) {
+	return s.rotateRefreshToken(ctx, jti, userId, deviceId, clientId, token, meta)
+}
+
+func (s *authService) rotateRefreshToken(ctx context.Context, jti, userId, deviceId, clientId uuid.UUID, token string, meta models.SessionMetadata) (string, error) {
+	current, err := s.sessions.GetByHash(ctx, s.utility.HashWithSHA256(token))
 	if err != nil {
-		return errors.New("stored token not found")
+		if errors.Is(err, repositories.ErrSessionNotFound) {
+			return "", ErrInvalidRefreshToken
+		}
+		return "", err
+	}
+	if current.UserId != userId || current.DeviceId != deviceId || current.ClientId != clientId {
+		return "", ErrInvalidRefreshToken
 	}
-	if existingToken.IsRevoked {
-		return errors.New("reuse token detected")
+	if current.IsRevoked {
+		return "", s.revokeReusedFamily(ctx, current)
 	}
-	t, err := time.Parse(time.RFC3339, existingToken.ExpiredAt)
+	raw, hash, err := s.GenerateRefreshToken()
 	if err != nil {
-		return errors.New("failed to parsed string expiredAt to time")
+		return "", err
 	}
-	if t.UnixMilli() < time.Now().UnixMilli() {
-		return errors.New("refresh token is expired")
+	now := time.Now()
+	next := &models.Token{
+		Hash:            hash,
+		DeviceId:        deviceId,
+		UserId:          userId,
+		Jti:             jti,
+		FamilyId:        current.FamilyId,
+		ClientId:        current.ClientId,
+		ExpiredAt:       now.Add(s.authCfg.RefreshTokenTTL),
+		SessionMetadata: meta,
+		CreatedAt:       current.CreatedAt,
+		LastUsedAt:      now,
 	}
-	if existingToken.Hash != s.utility.HashWithSHA256(token) {
-		return errors.New("unrecognized token")
+	if err := s.sessions.Rotate(ctx, current, next); err != nil {
+		if errors.Is(err, repositories.ErrSessionRevoked) {
+			return "", s.revokeReusedFamily(ctx, current)
+		}
+		return "", err
 	}
-	return nil
+	return raw, nil
+}
+
+func (s *authService) revokeReusedFamily(ctx context.Context, token *models.Token) error {
+	// the access token issued with the family's newest refresh token may be in the attacker's hands too
+	active, err := s.sessions.Get(ctx, token.UserId, token.DeviceId)
+	if err != nil && !errors.Is(err, repositories.ErrSessionNotFound) {
+		return err
+	}
+	if active != nil && active.FamilyId == token.FamilyId {
+		if err := s.denylist.RevokeSession(ctx, active); err != nil {
+			return err
+		}
+	}
+	if err := s.sessions.RevokeFamily(ctx, token.FamilyId); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, SecurityEvent{
+		Type:     SecurityEventRefreshTokenReuse,
+		UserId:   token.UserId,
+		DeviceId: token.DeviceId,
+		Details:  map[string]any{"family_id": token.FamilyId},
+	})
+	return ErrRefreshTokenReused
 }
 
 func (s *authService) GenerateRefreshToken() (string, string, error) {
@@ -135,6 +413,14 @@ func (s *authService) GenerateToken(userId, jti uuid.UUID) (string, error) {
 	return token, nil
 }
 
+func (s *authService) GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error) {
+	token, err := s.utility.GenerateScopedToken(subject, clientId, scope, jti)
+	if err != nil {
+		return "", errors.New("failed to generate token")
+	}
+	return token, nil
+}
+
 func (s *authService) VerifyPassword(hashedPassword string, plainPassword string) bool {
 	if err := s.utility.VerifyPassword(hashedPassword, plainPassword); err != nil {
 		return false
@@ -142,13 +428,31 @@ func (s *authService) VerifyPassword(hashedPassword string, plainPassword string
 	return true
 }
 
+// UpgradePasswordHash rehashes the password the user just logged in with when
+// the stored hash is bcrypt or was made with other argon2id parameters or pepper.
+func (s *authService) UpgradePasswordHash(ctx context.Context, user *models.User, password string) error {
+	if !s.utility.PasswordNeedsRehash(user.Password) {
+		return nil
+	}
+	hashedPassword, err := s.utility.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	user.Password = hashedPassword
+	return nil
+}
+
 func (s *authService) GetUserByIdentity(ctx context.Context, identity string) (*models.User, error) {
+	identity = strings.TrimSpace(identity)
 	var user *models.User
 	if strings.Contains(identity, "@") {
 		existingUser, err := s.userRepo.GetByEmail(ctx, identity)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				return nil, errors.New("user not found")
+				return nil, ErrUserNotFound
 			}
 			return nil, err
 		}
@@ -157,7 +461,7 @@ func (s *authService) GetUserByIdentity(ctx context.Context, identity string) (*
 		existingUser, err := s.userRepo.GetByUsername(ctx, identity)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				return nil, errors.New("user not found")
+				return nil, ErrUserNotFound
 			}
 			return nil, err
 		}
@@ -166,34 +470,47 @@ func (s *authService) GetUserByIdentity(ctx context.Context, identity string) (*
 	return user, nil
 }
 
+// TokenPayload is what an access token tells about its bearer. ClientId and
+// Scope are only set for tokens issued to OAuth2 clients, and UserId is
+// uuid.Nil when such a client acts on its own behalf.
 type TokenPayload struct {
-	UserId uuid.UUID
+	UserId   uuid.UUID
+	Jti      uuid.UUID
+	ClientId uuid.UUID
+	Scope    string
+	// ActorId is the admin impersonating the user, uuid.Nil for the user's own tokens.
+	ActorId   uuid.UUID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
+// ValidateToken verifies an access token and checks it was not revoked. Token
+// problems come back as the utils.ErrAccessToken errors or ErrTokenRevoked,
+// anything else means the check itself could not be made.
 func (s *authService) ValidateToken(tokenString string) (*TokenPayload, error) {
 	claims, err := s.utility.ValidateToken(tokenString)
-	log.Println(claims)
 	if err != nil {
-		return nil, errors.New("invalid token")
+		return nil, err
 	}
-	expireTime, ok := (*claims)["exp"].(float64)
-	if !ok {
-		return nil, errors.New("failed to covert to int64")
+	revoked, err := s.denylist.IsRevoked(context.Background(), claims.Jti())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
 	}
-	expirationTime := time.UnixMilli(int64(expireTime))
-	if expirationTime.Before(time.Now()) {
-		return nil, errors.New("token expired")
+	if revoked {
+		return nil, ErrTokenRevoked
 	}
-	userId, ok := (*claims)["userId"].(string)
-	if !ok {
-		return nil, errors.New("failed to covert to uuid")
+	payload := &TokenPayload{
+		UserId:   claims.UserId(),
+		Jti:      claims.Jti(),
+		ClientId: claims.OAuthClientId(),
+		Scope:    claims.Scope,
+		ActorId:  claims.ActorId(),
 	}
-	userIdUUD, err := uuid.Parse(userId)
-	if err != nil {
-		return nil, err
+	if claims.IssuedAt != nil {
+		payload.IssuedAt = claims.IssuedAt.Time
 	}
-	payload := &TokenPayload{
-		UserId: userIdUUD,
+	if claims.ExpiresAt != nil {
+		payload.ExpiresAt = claims.ExpiresAt.Time
 	}
 	return payload, nil
 }
@@ -0,0 +1,786 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrOAuthClientNotFound = errors.New("oauth client not found")
+
+// OAuthError is an error response of the authorization server, Code is one of
+// the error codes of RFC 6749 such as invalid_grant.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// userScopes can only be granted when a user authorizes the client.
+var userScopes = []string{constants.OAUTH_SCOPE_OPENID, constants.OAUTH_SCOPE_PROFILE, constants.OAUTH_SCOPE_EMAIL}
+
+// TokenRequest holds the parameters of a token request, which of them are used
+// depends on GrantType.
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// authorizationCode is kept in Redis under the hash of the code until the
+// client exchanges it.
+type authorizationCode struct {
+	ClientId      uuid.UUID `json:"client_id"`
+	UserId        uuid.UUID `json:"user_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scope         string    `json:"scope"`
+	CodeChallenge string    `json:"code_challenge,omitempty"`
+	Nonce         string    `json:"nonce,omitempty"`
+}
+
+type IAuthorizationServer interface {
+	RegisterClient(ctx context.Context, req dto.CreateOAuthClient) (*models.OAuthClient, string, error)
+	ListClients(ctx context.Context) ([]models.OAuthClient, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+	Authorize(ctx context.Context, userId uuid.UUID, req dto.OAuthAuthorize) (string, error)
+	AuthenticateClient(ctx context.Context, clientId, secret string) (*models.OAuthClient, error)
+	Token(ctx context.Context, client *models.OAuthClient, req TokenRequest, meta models.SessionMetadata) (*dto.OAuthToken, error)
+	AuthenticateInternalKey(key string) bool
+	Introspect(ctx context.Context, caller *models.OAuthClient, token, hint string) (*dto.OAuthIntrospection, error)
+	Revoke(ctx context.Context, caller *models.OAuthClient, token, hint string) error
+	Discovery() dto.OpenIDConfiguration
+	UserInfo(ctx context.Context, userId uuid.UUID, scope string) (*dto.UserInfo, error)
+}
+
+type authorizationServer struct {
+	clientRepo     repositories.IOAuthClientRepository
+	userRepo       repositories.IUserRepository
+	sessions       repositories.ISessionStore
+	redisRepo      repositories.IRedisRepository
+	denylist       ITokenDenylist
+	authService    IAuthService
+	utility        utils.IUtils
+	issuer         string
+	consentUri     string
+	codeTTL        time.Duration
+	accessTokenTTL time.Duration
+	// internalAPIKeys let our own services introspect and revoke any token
+	// without being registered as a client.
+	internalAPIKeys []string
+}
+
+func NewAuthorizationServer(
+	clientRepo repositories.IOAuthClientRepository,
+	userRepo repositories.IUserRepository,
+	sessions repositories.ISessionStore,
+	redisRepo repositories.IRedisRepository,
+	denylist ITokenDenylist,
+	authService IAuthService,
+	utility utils.IUtils,
+	issuer string,
+	consentUri string,
+	codeTTL time.Duration,
+	accessTokenTTL time.Duration,
+	internalAPIKeys []string,
+) IAuthorizationServer {
+	return &authorizationServer{
+		clientRepo:      clientRepo,
+		userRepo:        userRepo,
+		sessions:        sessions,
+		redisRepo:       redisRepo,
+		denylist:        denylist,
+		authService:     authService,
+		utility:         utility,
+		issuer:          strings.TrimSuffix(issuer, "/"),
+		consentUri:      consentUri,
+		codeTTL:         codeTTL,
+		accessTokenTTL:  accessTokenTTL,
+		internalAPIKeys: internalAPIKeys,
+	}
+}
+
+// RegisterClient returns the new client together with its secret, which is
+// only available here and empty for public clients. Secrets are random, so like
+// api keys a SHA-256 of them is enough to store.
+func (s *authorizationServer) RegisterClient(ctx context.Context, req dto.CreateOAuthClient) (*models.OAuthClient, string, error) {
+	if req.Type == constants.OAUTH_CLIENT_PUBLIC && slices.Contains(req.GrantTypes, constants.OAUTH_GRANT_CLIENT_CREDENTIALS) {
+		return nil, "", oauthError("invalid_client_metadata", "public clients cannot use the client_credentials grant")
+	}
+	if slices.Contains(req.GrantTypes, constants.OAUTH_GRANT_AUTHORIZATION_CODE) && len(req.RedirectURIs) == 0 {
+		return nil, "", oauthError("invalid_redirect_uri", "the authorization_code grant needs at least one redirect uri")
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		if !validRedirectURI(redirectURI) {
+			return nil, "", oauthError("invalid_redirect_uri", fmt.Sprintf("%s is not a valid redirect uri", redirectURI))
+		}
+	}
+	client := &models.OAuthClient{
+		Name:         req.Name,
+		Type:         req.Type,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       slices.Compact(slices.Sorted(slices.Values(req.Scopes))),
+		GrantTypes:   slices.Compact(slices.Sorted(slices.Values(req.GrantTypes))),
+	}
+	var secret string
+	if !client.IsPublic() {
+		raw, err := s.utility.GenerateRandomBytes(32)
+		if err != nil {
+			return nil, "", errors.New("failed to generate client secret")
+		}
+		secret, client.SecretHash = raw, s.utility.HashWithSHA256(raw)
+	}
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+// validRedirectURI accepts https uris with a host and no fragment, and http
+// only for loopback hosts, where native apps receive the code. Requiring a host
+// also rules out javascript: and data: uris.
+func validRedirectURI(redirectURI string) bool {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || parsed.Host == "" || strings.ContainsAny(redirectURI, " #") {
+		return false
+	}
+	switch parsed.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || net.ParseIP(host).IsLoopback()
+	default:
+		return false
+	}
+}
+
+func (s *authorizationServer) ListClients(ctx context.Context) ([]models.OAuthClient, error) {
+	return s.clientRepo.List(ctx)
+}
+
+// DeleteClient removes the client and its grants, so its refresh tokens stop
+// working. Access tokens already issued stay valid until they expire.
+func (s *authorizationServer) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	err := s.clientRepo.Delete(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOAuthClientNotFound
+	}
+	return err
+}
+
+// Authorize issues an authorization code for the signed in user and returns
+// where to send the browser next. An unknown client or redirect uri fails with
+// an *OAuthError, as the browser must not be sent there; every other problem is
+// reported to the client in the returned redirect.
+func (s *authorizationServer) Authorize(ctx context.Context, userId uuid.UUID, req dto.OAuthAuthorize) (string, error) {
+	client, err := s.findClient(ctx, req.ClientId)
+	if err != nil {
+		var oauthErr *OAuthError
+		if errors.As(err, &oauthErr) {
+			return "", oauthError("invalid_request", "unknown client")
+		}
+		return "", err
+	}
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return "", oauthError("invalid_request", "redirect_uri is not registered for the client")
+	}
+	redirect := func(params url.Values) string {
+		if req.State != "" {
+			params.Set("state", req.State)
+		}
+		target, _ := url.Parse(req.RedirectURI)
+		query := target.Query()
+		for key, values := range params {
+			query[key] = values
+		}
+		target.RawQuery = query.Encode()
+		return target.String()
+	}
+	fail := func(code, description string) string {
+		return redirect(url.Values{"error": {code}, "error_description": {description}})
+	}
+
+	if req.ResponseType != "code" {
+		return fail("unsupported_response_type", "only the code response type is supported"), nil
+	}
+	if !client.Allows(constants.OAUTH_GRANT_AUTHORIZATION_CODE) {
+		return fail("unauthorized_client", "the client may not use the authorization_code grant"), nil
+	}
+	scope, scopeErr := grantScope(client.Scopes, req.Scope)
+	if scopeErr != nil {
+		return fail(scopeErr.Code, scopeErr.Description), nil
+	}
+	if req.CodeChallenge == "" {
+		if client.IsPublic() {
+			return fail("invalid_request", "public clients must send a PKCE code_challenge"), nil
+		}
+	} else if req.CodeChallengeMethod != "S256" {
+		return fail("invalid_request", "code_challenge_method must be S256"), nil
+	}
+
+	code, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", errors.New("failed to generate authorization code")
+	}
+	value, err := json.Marshal(authorizationCode{
+		ClientId:      client.ID,
+		UserId:        userId,
+		RedirectURI:   req.RedirectURI,
+		Scope:         scope,
+		CodeChallenge: req.CodeChallenge,
+		Nonce:         req.Nonce,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := s.redisRepo.Set(fmt.Sprintf("oauth-code:%s", s.utility.HashWithSHA256(code)), value, s.codeTTL); err != nil {
+		return "", err
+	}
+	return redirect(url.Values{"code": {code}}), nil
+}
+
+// AuthenticateClient checks the credentials a client sent to the token
+// endpoint. Public clients only identify themselves, confidential ones also
+// need their secret.
+func (s *authorizationServer) AuthenticateClient(ctx context.Context, clientId, secret string) (*models.OAuthClient, error) {
+	client, err := s.findClient(ctx, clientId)
+	if err != nil {
+		return nil, err
+	}
+	if client.IsPublic() {
+		if secret != "" {
+			return nil, oauthError("invalid_client", "public clients have no secret")
+		}
+		return client, nil
+	}
+	if secret == "" || subtle.ConstantTimeCompare([]byte(s.utility.HashWithSHA256(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, oauthError("invalid_client", "client authentication failed")
+	}
+	return client, nil
+}
+
+// findClient fails with an invalid_client *OAuthError for unknown clients.
+func (s *authorizationServer) findClient(ctx context.Context, clientId string) (*models.OAuthClient, error) {
+	id, err := uuid.Parse(clientId)
+	if err != nil {
+		return nil, oauthError("invalid_client", "client authentication failed")
+	}
+	client, err := s.clientRepo.GetById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauthError("invalid_client", "client authentication failed")
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+// Token runs a grant for an authenticated client. Failures of the grant are
+// returned as an *OAuthError.
+func (s *authorizationServer) Token(ctx context.Context, client *models.OAuthClient, req TokenRequest, meta models.SessionMetadata) (*dto.OAuthToken, error) {
+	if req.GrantType == "" {
+		return nil, oauthError("invalid_request", "grant_type is required")
+	}
+	if !slices.Contains([]string{
+		constants.OAUTH_GRANT_AUTHORIZATION_CODE,
+		constants.OAUTH_GRANT_REFRESH_TOKEN,
+		constants.OAUTH_GRANT_CLIENT_CREDENTIALS,
+	}, req.GrantType) {
+		return nil, oauthError("unsupported_grant_type", fmt.Sprintf("%s is not supported", req.GrantType))
+	}
+	if !client.Allows(req.GrantType) {
+		return nil, oauthError("unauthorized_client", fmt.Sprintf("the client may not use the %s grant", req.GrantType))
+	}
+	meta.DeviceLabel = client.Name
+	switch req.GrantType {
+	case constants.OAUTH_GRANT_AUTHORIZATION_CODE:
+		return s.exchangeCode(ctx, client, req, meta)
+	case constants.OAUTH_GRANT_REFRESH_TOKEN:
+		return s.refresh(ctx, client, req, meta)
+	default:
+		return s.clientCredentials(client, req)
+	}
+}
+
+// exchangeCode redeems an authorization code. A code is removed on the first
+// attempt, so it can never be redeemed twice.
+func (s *authorizationServer) exchangeCode(ctx context.Context, client *models.OAuthClient, req TokenRequest, meta models.SessionMetadata) (*dto.OAuthToken, error) {
+	if req.Code == "" {
+		return nil, oauthError("invalid_request", "code is required")
+	}
+	value, err := s.redisRepo.GetDel(fmt.Sprintf("oauth-code:%s", s.utility.HashWithSHA256(req.Code)))
+	if err != nil {
+		log.Println(err.Error())
+		return nil, oauthError("invalid_grant", "invalid or expired authorization code")
+	}
+	var code authorizationCode
+	if err := json.Unmarshal([]byte(value), &code); err != nil {
+		return nil, oauthError("invalid_grant", "invalid or expired authorization code")
+	}
+	if code.ClientId != client.ID {
+		return nil, oauthError("invalid_grant", "invalid or expired authorization code")
+	}
+	if code.RedirectURI != req.RedirectURI {
+		return nil, oauthError("invalid_grant", "redirect_uri does not match the authorization request")
+	}
+	if code.CodeChallenge == "" {
+		if req.CodeVerifier != "" {
+			return nil, oauthError("invalid_grant", "the authorization request had no code_challenge")
+		}
+	} else if !verifyCodeChallenge(code.CodeChallenge, req.CodeVerifier) {
+		return nil, oauthError("invalid_grant", "code_verifier does not match the code_challenge")
+	}
+	return s.issue(ctx, client, code.UserId, code.Scope, code.Nonce, meta)
+}
+
+// verifyCodeChallenge checks a PKCE verifier against its S256 challenge, RFC 7636.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// issue returns an access token for the user the client acts for, an ID token
+// when the openid scope was granted, and when the client may refresh it a
+// refresh token. Refresh tokens are sessions of their own device in the session
+// store, so users see authorized clients among their sessions and can sign them
+// out there. The sessions record the client, only it can refresh them.
+func (s *authorizationServer) issue(ctx context.Context, client *models.OAuthClient, userId uuid.UUID, scope, nonce string, meta models.SessionMetadata) (*dto.OAuthToken, error) {
+	jti := uuid.New()
+	accessToken, err := s.authService.GenerateScopedToken(userId, client.ID, scope, jti)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := s.idToken(ctx, client.ID, userId, scope, nonce)
+	if err != nil {
+		return nil, err
+	}
+	token := &dto.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.accessTokenTTL.Seconds()),
+		Scope:       scope,
+		IDToken:     idToken,
+	}
+	if !client.Allows(constants.OAUTH_GRANT_REFRESH_TOKEN) {
+		return token, nil
+	}
+	deviceId := uuid.New()
+	refreshToken, hash, err := s.authService.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.clientRepo.CreateGrant(ctx, &models.OAuthGrant{
+		DeviceId: deviceId,
+		UserId:   userId,
+		ClientId: client.ID,
+		Scope:    scope,
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.authService.StoreClientRefreshToken(ctx, jti, userId, deviceId, client.ID, hash, meta); err != nil {
+		return nil, err
+	}
+	token.RefreshToken = refreshToken
+	return token, nil
+}
+
+// refresh rotates a refresh token the client got from this server. The scope
+// may be narrowed but never widened beyond what the user granted.
+func (s *authorizationServer) refresh(ctx context.Context, client *models.OAuthClient, req TokenRequest, meta models.SessionMetadata) (*dto.OAuthToken, error) {
+	if req.RefreshToken == "" {
+		return nil, oauthError("invalid_request", "refresh_token is required")
+	}
+	current, err := s.sessions.GetByHash(ctx, s.utility.HashWithSHA256(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repositories.ErrSessionNotFound) {
+			return nil, oauthError("invalid_grant", "invalid or expired refresh token")
+		}
+		return nil, err
+	}
+	if current.ClientId != client.ID {
+		return nil, oauthError("invalid_grant", "invalid or expired refresh token")
+	}
+	grant, err := s.clientRepo.GetGrant(ctx, current.DeviceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauthError("invalid_grant", "invalid or expired refresh token")
+		}
+		return nil, err
+	}
+	if grant.ClientId != client.ID || grant.UserId != current.UserId {
+		return nil, oauthError("invalid_grant", "invalid or expired refresh token")
+	}
+	scope := grant.Scope
+	if req.Scope != "" {
+		narrowed, scopeErr := grantScope(strings.Fields(grant.Scope), req.Scope)
+		if scopeErr != nil {
+			return nil, scopeErr
+		}
+		scope = narrowed
+	}
+	jti := uuid.New()
+	refreshToken, err := s.authService.RotateClientRefreshToken(ctx, jti, current.UserId, current.DeviceId, client.ID, req.RefreshToken, meta)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			return nil, oauthError("invalid_grant", err.Error())
+		}
+		return nil, err
+	}
+	accessToken, err := s.authService.GenerateScopedToken(current.UserId, client.ID, scope, jti)
+	if err != nil {
+		return nil, err
+	}
+	// a refreshed ID token has no nonce, OpenID Connect Core section 12.2
+	idToken, err := s.idToken(ctx, client.ID, current.UserId, scope, "")
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OAuthToken{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
+		IDToken:      idToken,
+	}, nil
+}
+
+// clientCredentials issues a token to a confidential client acting on its own
+// behalf, with the client as subject and without a refresh token.
+func (s *authorizationServer) clientCredentials(client *models.OAuthClient, req TokenRequest) (*dto.OAuthToken, error) {
+	allowed := slices.DeleteFunc(slices.Clone(client.Scopes), func(scope string) bool {
+		return slices.Contains(userScopes, scope)
+	})
+	scope, scopeErr := grantScope(allowed, req.Scope)
+	if scopeErr != nil {
+		return nil, scopeErr
+	}
+	accessToken, err := s.authService.GenerateScopedToken(client.ID, client.ID, scope, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OAuthToken{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.accessTokenTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+// grantScope returns the space separated scope to grant for a request, all of
+// allowed when nothing was requested, failing with invalid_scope when the
+// request asks for more.
+func grantScope(allowed []string, requested string) (string, *OAuthError) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		scopes = allowed
+	}
+	var granted []string
+	for _, scope := range scopes {
+		if !slices.Contains(allowed, scope) {
+			return "", oauthError("invalid_scope", fmt.Sprintf("the scope %s is not allowed", scope))
+		}
+		if !slices.Contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	return strings.Join(granted, " "), nil
+}
+
+// AuthenticateInternalKey reports whether key is one of the internal API keys.
+func (s *authorizationServer) AuthenticateInternalKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, internalKey := range s.internalAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(internalKey), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenTypes is the order a token is tried in, starting with the type the
+// caller hinted at. A hint is only an optimization, RFC 7662 section 2.1, so an
+// unknown one is ignored.
+func tokenTypes(hint string) []string {
+	if hint == "refresh_token" {
+		return []string{"refresh_token", "access_token"}
+	}
+	return []string{"access_token", "refresh_token"}
+}
+
+// invalidAccessToken reports whether ValidateToken failed because of the token
+// itself rather than because it could not be checked.
+func invalidAccessToken(err error) bool {
+	for _, reason := range []error{
+		utils.ErrAccessTokenMalformed,
+		utils.ErrAccessTokenInvalidSignature,
+		utils.ErrAccessTokenExpired,
+		utils.ErrAccessTokenNotYetValid,
+		utils.ErrAccessTokenInvalidIssuer,
+		utils.ErrAccessTokenInvalidAudience,
+		utils.ErrAccessTokenInvalidClaims,
+		ErrTokenRevoked,
+	} {
+		if errors.Is(err, reason) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *authorizationServer) validAccessToken(ctx context.Context, token string) (*TokenPayload, error) {
+	payload, err := s.authService.ValidateToken(token)
+	if err != nil {
+		if invalidAccessToken(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// refreshTokenGrant is an active refresh token together with the grant of the
+// client it was issued to, nil for our own sessions.
+type refreshTokenGrant struct {
+	session *models.Token
+	grant   *models.OAuthGrant
+}
+
+func (s *authorizationServer) validRefreshToken(ctx context.Context, token string) (*refreshTokenGrant, error) {
+	session, err := s.sessions.GetByHash(ctx, s.utility.HashWithSHA256(token))
+	if err != nil {
+		if errors.Is(err, repositories.ErrSessionNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if session.IsRevoked {
+		return nil, nil
+	}
+	grant, err := s.clientRepo.GetGrant(ctx, session.DeviceId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &refreshTokenGrant{session: session}, nil
+		}
+		return nil, err
+	}
+	return &refreshTokenGrant{session: session, grant: grant}, nil
+}
+
+// issuedTo reports whether a refresh token belongs to the caller. Internal
+// callers, passed as nil, may see every token.
+func (r *refreshTokenGrant) issuedTo(caller *models.OAuthClient) bool {
+	return caller == nil || (r.grant != nil && r.grant.ClientId == caller.ID)
+}
+
+// Introspect tells whether a token is active and what it grants, RFC 7662.
+// Unknown, expired and revoked tokens are reported as inactive, and so are
+// refresh tokens of other clients, which a client has no business knowing of.
+func (s *authorizationServer) Introspect(ctx context.Context, caller *models.OAuthClient, token, hint string) (*dto.OAuthIntrospection, error) {
+	for _, tokenType := range tokenTypes(hint) {
+		var introspection *dto.OAuthIntrospection
+		var err error
+		if tokenType == "access_token" {
+			introspection, err = s.introspectAccessToken(ctx, token)
+		} else {
+			introspection, err = s.introspectRefreshToken(ctx, caller, token)
+		}
+		if err != nil || introspection != nil {
+			return introspection, err
+		}
+	}
+	return &dto.OAuthIntrospection{Active: false}, nil
+}
+
+func (s *authorizationServer) introspectAccessToken(ctx context.Context, token string) (*dto.OAuthIntrospection, error) {
+	payload, err := s.validAccessToken(ctx, token)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	introspection := &dto.OAuthIntrospection{
+		Active:    true,
+		Scope:     payload.Scope,
+		Subject:   payload.UserId.String(),
+		TokenType: "access_token",
+		ExpiresAt: payload.ExpiresAt.Unix(),
+		IssuedAt:  payload.IssuedAt.Unix(),
+		Jti:       payload.Jti.String(),
+	}
+	if payload.ClientId != uuid.Nil {
+		introspection.ClientId = payload.ClientId.String()
+		if payload.UserId == uuid.Nil {
+			introspection.Subject = payload.ClientId.String()
+		}
+	}
+	return introspection, nil
+}
+
+func (s *authorizationServer) introspectRefreshToken(ctx context.Context, caller *models.OAuthClient, token string) (*dto.OAuthIntrospection, error) {
+	found, err := s.validRefreshToken(ctx, token)
+	if err != nil || found == nil || !found.issuedTo(caller) {
+		return nil, err
+	}
+	introspection := &dto.OAuthIntrospection{
+		Active:    true,
+		Subject:   found.session.UserId.String(),
+		TokenType: "refresh_token",
+		ExpiresAt: found.session.ExpiredAt.Unix(),
+	}
+	if found.grant != nil {
+		introspection.ClientId = found.grant.ClientId.String()
+		introspection.Scope = found.grant.Scope
+	}
+	return introspection, nil
+}
+
+// Revoke invalidates a token, RFC 7009. Revoking a refresh token signs its
+// session out, including the access token issued with it. Tokens that are
+// already invalid are ignored, while a client revoking a token of another
+// client fails with unauthorized_client.
+func (s *authorizationServer) Revoke(ctx context.Context, caller *models.OAuthClient, token, hint string) error {
+	for _, tokenType := range tokenTypes(hint) {
+		var found bool
+		var err error
+		if tokenType == "access_token" {
+			found, err = s.revokeAccessToken(ctx, caller, token)
+		} else {
+			found, err = s.revokeRefreshToken(ctx, caller, token)
+		}
+		if err != nil || found {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *authorizationServer) revokeAccessToken(ctx context.Context, caller *models.OAuthClient, token string) (bool, error) {
+	payload, err := s.validAccessToken(ctx, token)
+	if err != nil || payload == nil {
+		return false, err
+	}
+	if caller != nil && payload.ClientId != caller.ID {
+		return true, oauthError("unauthorized_client", "the token was not issued to the client")
+	}
+	return true, s.denylist.Revoke(ctx, payload.Jti, payload.ExpiresAt)
+}
+
+func (s *authorizationServer) revokeRefreshToken(ctx context.Context, caller *models.OAuthClient, token string) (bool, error) {
+	found, err := s.validRefreshToken(ctx, token)
+	if err != nil || found == nil {
+		return false, err
+	}
+	if !found.issuedTo(caller) {
+		return true, oauthError("unauthorized_client", "the token was not issued to the client")
+	}
+	return true, s.authService.DeleteRefreshToken(ctx, found.session.UserId, found.session.DeviceId)
+}
+
+// idToken returns an ID token about the user for the client, "" unless the
+// openid scope was granted.
+func (s *authorizationServer) idToken(ctx context.Context, clientId, userId uuid.UUID, scope, nonce string) (string, error) {
+	if !slices.Contains(strings.Fields(scope), constants.OAUTH_SCOPE_OPENID) {
+		return "", nil
+	}
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+	return s.utility.GenerateIDToken(userId, clientId, nonce, userClaims(user, scope))
+}
+
+// userClaims returns the claims about the user the scope covers: profile
+// releases the names and email the address.
+func userClaims(user *models.User, scope string) utils.UserClaims {
+	scopes := strings.Fields(scope)
+	var claims utils.UserClaims
+	if slices.Contains(scopes, constants.OAUTH_SCOPE_PROFILE) {
+		claims.Name = user.Name
+		claims.PreferredUsername = user.Username
+	}
+	if slices.Contains(scopes, constants.OAUTH_SCOPE_EMAIL) {
+		verified := user.EmailVerifiedAt != nil
+		claims.Email = user.Email
+		claims.EmailVerified = &verified
+	}
+	return claims
+}
+
+// Discovery describes us as an OpenID provider. The endpoints are served by
+// this API under the issuer, except for authorization, which is the consent
+// page of our frontend.
+func (s *authorizationServer) Discovery() dto.OpenIDConfiguration {
+	return dto.OpenIDConfiguration{
+		Issuer:                s.issuer,
+		AuthorizationEndpoint: s.consentUri,
+		TokenEndpoint:         s.issuer + "/oauth/token",
+		UserInfoEndpoint:      s.issuer + "/userinfo",
+		JWKSURI:               s.issuer + "/.well-known/jwks.json",
+		IntrospectionEndpoint: s.issuer + "/oauth/introspect",
+		RevocationEndpoint:    s.issuer + "/oauth/revoke",
+		ScopesSupported: []string{
+			constants.OAUTH_SCOPE_OPENID,
+			constants.OAUTH_SCOPE_PROFILE,
+			constants.OAUTH_SCOPE_EMAIL,
+			constants.API_KEY_SCOPE_READ,
+			constants.API_KEY_SCOPE_WRITE,
+		},
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: []string{
+			constants.OAUTH_GRANT_AUTHORIZATION_CODE,
+			constants.OAUTH_GRANT_REFRESH_TOKEN,
+			constants.OAUTH_GRANT_CLIENT_CREDENTIALS,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  s.utility.SigningAlgorithms(),
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "preferred_username", "email", "email_verified"},
+	}
+}
+
+// UserInfo returns the claims about the user the scope of their access token
+// covers. A user who no longer exists fails with invalid_token.
+func (s *authorizationServer) UserInfo(ctx context.Context, userId uuid.UUID, scope string) (*dto.UserInfo, error) {
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, oauthError("invalid_token", "the user of the token no longer exists")
+		}
+		return nil, err
+	}
+	return &dto.UserInfo{Subject: user.ID.String(), UserClaims: userClaims(user, scope)}, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrExternalEmailNotVerified  = errors.New("the identity provider has not verified this email")
+	ErrExternalAccountUnverified = errors.New("an unverified account already uses this email, verify it before signing in with a provider")
+	ErrExternalEmailInUse        = errors.New("an account already uses this email, sign in to it the way it was created")
+)
+
+// resolveExternalUser maps a verified ID token to a local user. A known
+// (provider, subject) identity wins; otherwise the identity is linked to the
+// account with the same verified email when trustEmail is set, or a new
+// password-less account is created with accountProvider as its users.provider
+// value.
+//
+// Accounts whose email was never verified are not linked: anyone can register
+// an email they do not own, and linking would hand the owner an account whose
+// password the registrant still knows.
+func resolveExternalUser(
+	ctx context.Context,
+	userRepo repositories.IUserRepository,
+	identityRepo repositories.IIdentityRepository,
+	provider string,
+	claims *utils.IDTokenClaims,
+	accountProvider string,
+	trustEmail bool,
+) (*models.User, error) {
+	identity, err := identityRepo.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		return userRepo.GetById(ctx, identity.UserId)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrExternalEmailNotVerified
+	}
+	user, err := userRepo.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if !trustEmail {
+			return nil, ErrExternalEmailInUse
+		}
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrExternalAccountUnverified
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		username, err := availableUsername(ctx, userRepo, claims.Email)
+		if err != nil {
+			return nil, err
+		}
+		name := claims.Name
+		if name == "" {
+			name = username
+		}
+		user, err = userRepo.CreateWithProvider(ctx, name, username, claims.Email, accountProvider)
+		if err != nil {
+			return nil, fmt.Errorf("create user failed: %w", err)
+		}
+	default:
+		return nil, err
+	}
+	if _, err := identityRepo.Create(ctx, user.ID, provider, claims.Subject, claims.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+var usernameDisallowed = regexp.MustCompile(`[^a-z0-9_.]`)
+
+// availableUsername derives a free username from the local part of an email,
+// appending a random number when the plain version is already taken.
+func availableUsername(ctx context.Context, userRepo repositories.IUserRepository, email string) (string, error) {
+	base := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	base = usernameDisallowed.ReplaceAllString(base, "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	for len(base) < 5 {
+		base += "_"
+	}
+	candidate := base
+	for range 5 {
+		_, err := userRepo.GetByUsername(ctx, candidate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%06d", base, n.Int64())
+	}
+	return "", errors.New("failed to find an available username")
+}
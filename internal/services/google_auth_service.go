@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+)
+
+type IGoogleAuthService interface {
+	Authenticate(ctx context.Context, idToken string) (*models.User, error)
+}
+
+type googleAuthService struct {
+	userRepo     repositories.IUserRepository
+	identityRepo repositories.IIdentityRepository
+	verifier     utils.IIDTokenVerifier
+}
+
+func NewGoogleAuthService(
+	userRepo repositories.IUserRepository,
+	identityRepo repositories.IIdentityRepository,
+	verifier utils.IIDTokenVerifier,
+) IGoogleAuthService {
+	return &googleAuthService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		verifier:     verifier,
+	}
+}
+
+// Authenticate verifies a Google ID token and returns the matching user, creating
+// a password-less google account on first sign in. An existing account with the
+// same address is linked because Google has confirmed the caller owns it.
+func (s *googleAuthService) Authenticate(ctx context.Context, idToken string) (*models.User, error) {
+	claims, err := s.verifier.Verify(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	return resolveExternalUser(ctx, s.userRepo, s.identityRepo, constants.PROVIDER_GOOGLE, claims, constants.PROVIDER_GOOGLE, true)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImpersonateSelf  = errors.New("you cannot impersonate yourself")
+	ErrImpersonateAdmin = errors.New("admins cannot be impersonated")
+	ErrImpersonateAbove = errors.New("you cannot impersonate a user with permissions you do not have")
+	ErrNotImpersonating = errors.New("this session is not an impersonation")
+)
+
+const (
+	AuditImpersonationStart = "impersonation.start"
+	AuditImpersonationStop  = "impersonation.stop"
+)
+
+// Impersonation is an access token an admin got to act as a user.
+type Impersonation struct {
+	AccessToken string
+	User        *models.User
+	ExpiresAt   time.Time
+}
+
+// IImpersonationService lets admins act as another user for a short time, for
+// support. Every start and stop is written to the audit log.
+type IImpersonationService interface {
+	Start(ctx context.Context, actorId, userId uuid.UUID, reason string, meta models.SessionMetadata) (*Impersonation, error)
+	Stop(ctx context.Context, token *TokenPayload, meta models.SessionMetadata) error
+	AuditLog(ctx context.Context, limit int) ([]models.AuditLog, error)
+}
+
+type impersonationService struct {
+	userRepo  repositories.IUserRepository
+	rbac      IRBACService
+	auditRepo repositories.IAuditLogRepository
+	denylist  ITokenDenylist
+	utility   utils.IUtils
+	ttl       time.Duration
+}
+
+func NewImpersonationService(
+	userRepo repositories.IUserRepository,
+	rbac IRBACService,
+	auditRepo repositories.IAuditLogRepository,
+	denylist ITokenDenylist,
+	utility utils.IUtils,
+	ttl time.Duration,
+) IImpersonationService {
+	return &impersonationService{
+		userRepo:  userRepo,
+		rbac:      rbac,
+		auditRepo: auditRepo,
+		denylist:  denylist,
+		utility:   utility,
+		ttl:       ttl,
+	}
+}
+
+// Start issues an access token for the user naming the admin as its actor. The
+// token is only handed out once the start is in the audit log. Admins and users
+// with a permission the actor lacks cannot be impersonated, so impersonation
+// never gains the actor anything.
+func (s *impersonationService) Start(ctx context.Context, actorId, userId uuid.UUID, reason string, meta models.SessionMetadata) (*Impersonation, error) {
+	if actorId == userId {
+		return nil, ErrImpersonateSelf
+	}
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if user.Role == constants.ROLE_ADMIN {
+		return nil, ErrImpersonateAdmin
+	}
+	actorPermissions, err := s.rbac.Permissions(ctx, actorId)
+	if err != nil {
+		return nil, err
+	}
+	userPermissions, err := s.rbac.Permissions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range userPermissions {
+		if !slices.Contains(actorPermissions, permission) {
+			return nil, ErrImpersonateAbove
+		}
+	}
+	jti := uuid.New()
+	expiresAt := time.Now().Add(s.ttl)
+	token, err := s.utility.GenerateImpersonationToken(userId, actorId, jti, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditRepo.Create(ctx, &models.AuditLog{
+		ActorId:   actorId,
+		Action:    AuditImpersonationStart,
+		TargetId:  &userId,
+		Details:   map[string]any{"reason": reason, "jti": jti.String(), "expires_at": expiresAt.UTC().Format(time.RFC3339)},
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+	}); err != nil {
+		return nil, err
+	}
+	return &Impersonation{AccessToken: token, User: user, ExpiresAt: expiresAt}, nil
+}
+
+// Stop revokes the access token of an impersonation before it expires.
+func (s *impersonationService) Stop(ctx context.Context, token *TokenPayload, meta models.SessionMetadata) error {
+	if token == nil || token.ActorId == uuid.Nil {
+		return ErrNotImpersonating
+	}
+	if err := s.denylist.Revoke(ctx, token.Jti, token.ExpiresAt); err != nil {
+		return err
+	}
+	userId := token.UserId
+	return s.auditRepo.Create(ctx, &models.AuditLog{
+		ActorId:   token.ActorId,
+		Action:    AuditImpersonationStop,
+		TargetId:  &userId,
+		Details:   map[string]any{"jti": token.Jti.String()},
+		IP:        meta.IP,
+		UserAgent: meta.UserAgent,
+	})
+}
+
+// AuditLog returns the latest entries first.
+func (s *impersonationService) AuditLog(ctx context.Context, limit int) ([]models.AuditLog, error) {
+	return s.auditRepo.List(ctx, limit)
+}
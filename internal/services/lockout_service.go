@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/config"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidUnlockToken = errors.New("invalid or expired unlock link")
+
+const (
+	// failures an account or a client IP gets before attempts are slowed down
+	accountFreeAttempts = 3
+	ipFreeAttempts      = 20
+	loginBackoffBase    = time.Second
+	// lockedAccountsKey is a sorted set of locked user ids scored by when the lock ends.
+	lockedAccountsKey = "login-locked"
+)
+
+// LoginThrottledError is returned while a login may not be attempted. Locked is
+// set when the account is locked, as opposed to waiting out a backoff.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("account locked, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("too many failed logins, retry after %s", e.RetryAfter)
+}
+
+// LockedAccount is an account that is locked after too many failed logins.
+type LockedAccount struct {
+	User        *models.User
+	LockedUntil time.Time
+}
+
+type ILockoutService interface {
+	Check(ctx context.Context, user *models.User, identity, ip string) error
+	RecordFailure(ctx context.Context, user *models.User, identity, ip string) error
+	RecordSuccess(ctx context.Context, user *models.User) error
+	Unlock(ctx context.Context, token string) error
+	UnlockUser(ctx context.Context, userId uuid.UUID) error
+	ListLocked(ctx context.Context) ([]LockedAccount, error)
+}
+
+type lockoutService struct {
+	appUri    string
+	authCfg   config.AuthConfig
+	userRepo  repositories.IUserRepository
+	redisRepo repositories.IRedisRepository
+	events    ISecurityEventPublisher
+	utility   utils.IUtils
+}
+
+func NewLockoutService(
+	userRepo repositories.IUserRepository,
+	redisRepo repositories.IRedisRepository,
+	events ISecurityEventPublisher,
+	utility utils.IUtils,
+	appUri string,
+	authCfg config.AuthConfig,
+) ILockoutService {
+	return &lockoutService{
+		appUri:    appUri,
+		authCfg:   authCfg,
+		userRepo:  userRepo,
+		redisRepo: redisRepo,
+		events:    events,
+		utility:   utility,
+	}
+}
+
+// accountKey names the account a login is for. Identities that match no user
+// are counted and locked like real accounts so the two cannot be told apart,
+// normalized so that spelling one differently does not start a fresh count.
+func accountKey(user *models.User, identity string) string {
+	if user != nil {
+		return "user:" + user.ID.String()
+	}
+	return "identity:" + strings.ToLower(strings.TrimSpace(identity))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns a *LoginThrottledError while the account is locked or the account
+// or the client IP has to wait before the next attempt.
+func (s *lockoutService) Check(ctx context.Context, user *models.User, identity, ip string) error {
+	account := accountKey(user, identity)
+	ttl, err := s.redisRepo.TTL(fmt.Sprintf("login-lock:%s", account))
+	if err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return &LoginThrottledError{RetryAfter: ttl, Locked: true}
+	}
+	var wait time.Duration
+	for _, key := range []string{account, ipKey(ip)} {
+		ttl, err := s.redisRepo.TTL(fmt.Sprintf("login-backoff:%s", key))
+		if err != nil {
+			return err
+		}
+		wait = max(wait, ttl)
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login against the account and the client IP.
+// Past the free attempts every failure doubles the wait before the next one,
+// and reaching the lockout threshold locks the account.
+func (s *lockoutService) RecordFailure(ctx context.Context, user *models.User, identity, ip string) error {
+	account := accountKey(user, identity)
+	failures, err := s.redisRepo.Incr(fmt.Sprintf("login-failures:%s", account), s.authCfg.LoginFailureWindow)
+	if err != nil {
+		return err
+	}
+	ipFailures, err := s.redisRepo.Incr(fmt.Sprintf("login-failures:%s", ipKey(ip)), s.authCfg.LoginFailureWindow)
+	if err != nil {
+		return err
+	}
+	if wait := s.backoff(ipFailures, ipFreeAttempts); wait > 0 {
+		if err := s.redisRepo.Set(fmt.Sprintf("login-backoff:%s", ipKey(ip)), 1, wait); err != nil {
+			return err
+		}
+	}
+	if failures >= int64(s.authCfg.LoginLockoutThreshold) {
+		return s.lock(ctx, user, account, failures)
+	}
+	if wait := s.backoff(failures, accountFreeAttempts); wait > 0 {
+		return s.redisRepo.Set(fmt.Sprintf("login-backoff:%s", account), 1, wait)
+	}
+	return nil
+}
+
+// backoff returns the wait after the given number of failures: nothing for the
+// free attempts, then one second doubling with every failure up to the maximum.
+func (s *lockoutService) backoff(failures int64, free int64) time.Duration {
+	if failures <= free {
+		return 0
+	}
+	exponent := failures - free - 1
+	if exponent >= 30 {
+		return s.authCfg.LoginMaxBackoff
+	}
+	return min(loginBackoffBase<<exponent, s.authCfg.LoginMaxBackoff)
+}
+
+// lock locks the account and starts counting afresh once the lock ends. The
+// owner of a real account is told by email and can unlock it from there.
+func (s *lockoutService) lock(ctx context.Context, user *models.User, account string, failures int64) error {
+	lockedUntil := time.Now().Add(s.authCfg.LoginLockoutDuration)
+	if err := s.redisRepo.Set(fmt.Sprintf("login-lock:%s", account), 1, s.authCfg.LoginLockoutDuration); err != nil {
+		return err
+	}
+	if err := s.redisRepo.Del(fmt.Sprintf("login-failures:%s", account), fmt.Sprintf("login-backoff:%s", account)); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+	if err := s.redisRepo.ZAdd(lockedAccountsKey, float64(lockedUntil.Unix()), user.ID.String()); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, SecurityEvent{
+		Type:   SecurityEventAccountLocked,
+		UserId: user.ID,
+		Details: map[string]any{
+			"failed_attempts": failures,
+			"locked_until":    lockedUntil,
+		},
+	})
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return errors.New("failed to generate unlock token")
+	}
+	if err := s.redisRepo.Set(fmt.Sprintf("login-unlock:%s", s.utility.HashWithSHA256(raw)), user.ID.String(), s.authCfg.LoginLockoutDuration); err != nil {
+		return err
+	}
+	var link = s.appUri + fmt.Sprintf("/unlock-account?token=%s", raw)
+	var subject = "Your account has been locked"
+	var emailBody = fmt.Sprintf("Hello %s.\n\n Your account was locked for %s after too many failed login attempts. If that was you, follow this link to unlock it now.\n\n%s\n\nIf it was not you, somebody may be guessing your password and you should reset it.", user.Name, s.authCfg.LoginLockoutDuration, link)
+	return s.utility.SendEmailWithGmail(subject, emailBody, user.Email)
+}
+
+// RecordSuccess forgets the failures of the account. Failures of the client IP
+// are kept, a valid login to one account does not excuse guesses at others.
+func (s *lockoutService) RecordSuccess(ctx context.Context, user *models.User) error {
+	account := accountKey(user, "")
+	return s.redisRepo.Del(fmt.Sprintf("login-failures:%s", account), fmt.Sprintf("login-backoff:%s", account))
+}
+
+// Unlock consumes the link emailed when the account was locked.
+func (s *lockoutService) Unlock(ctx context.Context, token string) error {
+	value, err := s.redisRepo.GetDel(fmt.Sprintf("login-unlock:%s", s.utility.HashWithSHA256(token)))
+	if err != nil {
+		log.Println(err.Error())
+		return ErrInvalidUnlockToken
+	}
+	userId, err := uuid.Parse(value)
+	if err != nil {
+		return ErrInvalidUnlockToken
+	}
+	return s.UnlockUser(ctx, userId)
+}
+
+// UnlockUser lifts the lock of the user and clears their failed logins.
+func (s *lockoutService) UnlockUser(ctx context.Context, userId uuid.UUID) error {
+	account := "user:" + userId.String()
+	if err := s.redisRepo.Del(
+		fmt.Sprintf("login-lock:%s", account),
+		fmt.Sprintf("login-failures:%s", account),
+		fmt.Sprintf("login-backoff:%s", account),
+	); err != nil {
+		return err
+	}
+	return s.redisRepo.ZRem(lockedAccountsKey, userId.String())
+}
+
+// ListLocked returns the accounts that are locked right now, dropping the ones
+// whose lock has ended or was lifted.
+func (s *lockoutService) ListLocked(ctx context.Context) ([]LockedAccount, error) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := s.redisRepo.ZRemRangeByScore(lockedAccountsKey, "-inf", "("+now); err != nil {
+		return nil, err
+	}
+	ids, err := s.redisRepo.ZRangeByScore(lockedAccountsKey, now, "+inf")
+	if err != nil {
+		return nil, err
+	}
+	locked := make([]LockedAccount, 0, len(ids))
+	for _, id := range ids {
+		ttl, err := s.redisRepo.TTL(fmt.Sprintf("login-lock:user:%s", id))
+		if err != nil {
+			return nil, err
+		}
+		userId, parseErr := uuid.Parse(id)
+		if ttl <= 0 || parseErr != nil {
+			if err := s.redisRepo.ZRem(lockedAccountsKey, id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		user, err := s.userRepo.GetById(ctx, userId)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+		locked = append(locked, LockedAccount{User: user, LockedUntil: time.Now().Add(ttl)})
+	}
+	return locked, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/config"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidMagicLink = errors.New("invalid or expired login link")
+
+type IMagicLinkService interface {
+	RequestLink(ctx context.Context, email string) (string, error)
+	ConsumeLink(ctx context.Context, token, binding string) (*models.User, error)
+}
+
+type magicLinkService struct {
+	appUri    string
+	authCfg   config.AuthConfig
+	userRepo  repositories.IUserRepository
+	redisRepo repositories.IRedisRepository
+	utility   utils.IUtils
+}
+
+func NewMagicLinkService(
+	userRepo repositories.IUserRepository,
+	redisRepo repositories.IRedisRepository,
+	utility utils.IUtils,
+	appUri string,
+	authCfg config.AuthConfig,
+) IMagicLinkService {
+	return &magicLinkService{
+		appUri:    appUri,
+		authCfg:   authCfg,
+		userRepo:  userRepo,
+		redisRepo: redisRepo,
+		utility:   utility,
+	}
+}
+
+// RequestLink emails a single-use login link and returns the device binding the
+// caller keeps in a cookie; the link only works together with that binding.
+// Unknown addresses get a binding too and no email, so the response does not
+// tell whether the address is registered.
+func (s *magicLinkService) RequestLink(ctx context.Context, email string) (string, error) {
+	throttleKey := fmt.Sprintf("magic-link-request:%s", strings.ToLower(email))
+	ok, err := s.redisRepo.SetNX(throttleKey, 1, s.authCfg.MagicLinkRequestInterval)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrTooManyRequests
+	}
+	binding, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", errors.New("failed to generate magic link binding")
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return binding, nil
+		}
+		return "", err
+	}
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", errors.New("failed to generate magic link token")
+	}
+	key := fmt.Sprintf("magic-link:%s", s.utility.HashWithSHA256(raw))
+	value := user.ID.String() + ":" + s.utility.HashWithSHA256(binding)
+	if err := s.redisRepo.Set(key, value, s.authCfg.MagicLinkTTL); err != nil {
+		return "", err
+	}
+	var link = s.appUri + fmt.Sprintf("/magic-link?token=%s", raw)
+	var subject = "Your login link"
+	var emailBody = fmt.Sprintf("Hello %s.\n\n Please follow this link to log in. The link expires in %s and can only be used once, from the browser you requested it in.\n\n%s\n\nIf you did not try to log in you can ignore this email.", user.Name, s.authCfg.MagicLinkTTL, link)
+	if err := s.utility.SendEmailWithGmail(subject, emailBody, user.Email); err != nil {
+		return "", err
+	}
+	return binding, nil
+}
+
+// ConsumeLink exchanges a login link for its user. The link is removed on the
+// first attempt, even one from the wrong device, so it can never be used twice.
+// Following the link proves the user owns the address, so it also verifies it.
+func (s *magicLinkService) ConsumeLink(ctx context.Context, token, binding string) (*models.User, error) {
+	value, err := s.redisRepo.GetDel(fmt.Sprintf("magic-link:%s", s.utility.HashWithSHA256(token)))
+	if err != nil {
+		log.Println(err.Error())
+		return nil, ErrInvalidMagicLink
+	}
+	id, bindingHash, found := strings.Cut(value, ":")
+	if !found || subtle.ConstantTimeCompare([]byte(bindingHash), []byte(s.utility.HashWithSHA256(binding))) != 1 {
+		return nil, ErrInvalidMagicLink
+	}
+	userId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidMagicLink
+	}
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return s.userRepo.VerifyEmail(ctx, userId)
+	}
+	return user, nil
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/config"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMfaAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMfaNotEnrolled    = errors.New("two-factor authentication has not been set up")
+	ErrMfaNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMfaCode    = errors.New("invalid authentication code")
+	ErrInvalidMfaTicket  = errors.New("invalid or expired mfa ticket")
+	ErrTooManyMfaCodes   = errors.New("too many authentication codes were tried, try again later")
+)
+
+const (
+	totpCodeLength    = 6
+	recoveryCodeCount = 10
+	// maxMfaTicketAttempts is how many codes can be tried with one ticket before
+	// the user has to enter their password again.
+	maxMfaTicketAttempts = 5
+	// maxMfaCodeAttempts is how many codes a signed in user can try within
+	// mfaCodeAttemptWindow to turn two-factor off or get new recovery codes.
+	maxMfaCodeAttempts   = 5
+	mfaCodeAttemptWindow = 15 * time.Minute
+)
+
+// MfaEnrollment is what an authenticator app needs to add the account.
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type IMfaService interface {
+	IsEnabled(ctx context.Context, userId uuid.UUID) (bool, error)
+	StartEnrollment(ctx context.Context, user *models.User) (*MfaEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userId uuid.UUID, code string) ([]string, error)
+	Disable(ctx context.Context, userId uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userId uuid.UUID, code string) ([]string, error)
+	CreateTicket(ctx context.Context, userId uuid.UUID) (string, error)
+	RedeemTicket(ctx context.Context, ticket, code string) (uuid.UUID, error)
+	TicketUserId(ctx context.Context, ticket string) (uuid.UUID, error)
+	ConsumeTicket(ctx context.Context, ticket string, userId uuid.UUID) error
+}
+
+type mfaService struct {
+	mfaRepo      repositories.IMfaRepository
+	webAuthnRepo repositories.IWebAuthnRepository
+	redisRepo    repositories.IRedisRepository
+	utility      utils.IUtils
+	authCfg      config.AuthConfig
+}
+
+func NewMfaService(
+	mfaRepo repositories.IMfaRepository,
+	webAuthnRepo repositories.IWebAuthnRepository,
+	redisRepo repositories.IRedisRepository,
+	utility utils.IUtils,
+	authCfg config.AuthConfig,
+) IMfaService {
+	return &mfaService{
+		mfaRepo:      mfaRepo,
+		webAuthnRepo: webAuthnRepo,
+		redisRepo:    redisRepo,
+		utility:      utility,
+		authCfg:      authCfg,
+	}
+}
+
+// IsEnabled reports whether a login has to be completed with a second factor,
+// which is the case once the user confirmed TOTP or registered a passkey.
+func (s *mfaService) IsEnabled(ctx context.Context, userId uuid.UUID) (bool, error) {
+	enabled, err := s.totpEnabled(ctx, userId)
+	if err != nil || enabled {
+		return enabled, err
+	}
+	credentials, err := s.webAuthnRepo.GetByUser(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	return len(credentials) > 0, nil
+}
+
+func (s *mfaService) totpEnabled(ctx context.Context, userId uuid.UUID) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.EnabledAt != nil, nil
+}
+
+// StartEnrollment generates a new secret, replacing an unconfirmed one. Two-factor
+// stays off until ConfirmEnrollment proves the authenticator app has the secret.
+func (s *mfaService) StartEnrollment(ctx context.Context, user *models.User) (*MfaEnrollment, error) {
+	enabled, err := s.totpEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	secret, uri, err := utils.GenerateTOTPKey(s.authCfg.MfaIssuer, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+	return &MfaEnrollment{Secret: secret, URI: uri}, nil
+}
+
+// ConfirmEnrollment enables two-factor with the first code from the app and
+// returns the recovery codes, which are only ever shown here and on regeneration.
+func (s *mfaService) ConfirmEnrollment(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMfaNotEnrolled
+		}
+		return nil, err
+	}
+	if mfa.EnabledAt != nil {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	step, ok := utils.MatchTOTP(mfa.TOTPSecret, normalizeMfaCode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidMfaCode
+	}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Enable(ctx, userId, step, hashes); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMfaAlreadyEnabled
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *mfaService) Disable(ctx context.Context, userId uuid.UUID, code string) error {
+	if err := s.verifyCodeLimited(ctx, userId, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, userId)
+}
+
+// RegenerateRecoveryCodes invalidates every previous recovery code.
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userId uuid.UUID, code string) ([]string, error) {
+	if err := s.verifyCodeLimited(ctx, userId, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CreateTicket is handed out instead of tokens once the password was verified
+// for a user with two-factor enabled. Only its hash is kept in Redis.
+func (s *mfaService) CreateTicket(ctx context.Context, userId uuid.UUID) (string, error) {
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", errors.New("failed to generate mfa ticket")
+	}
+	key := mfaTicketKey(s.utility.HashWithSHA256(raw))
+	if err := s.redisRepo.HSet(key, map[string]any{"user_id": userId.String(), "attempts": 0}, s.authCfg.MfaTicketTTL); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// RedeemTicket completes a login with a TOTP or recovery code and returns the
+// user it was issued for. A ticket is single use and burns after too many wrong
+// codes; when requests redeem it at once only the one that deletes it wins.
+func (s *mfaService) RedeemTicket(ctx context.Context, ticket, code string) (uuid.UUID, error) {
+	key := mfaTicketKey(s.utility.HashWithSHA256(ticket))
+	userId, err := s.TicketUserId(ctx, ticket)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	attempts, err := s.redisRepo.HIncrBy(key, "attempts", 1)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if attempts > maxMfaTicketAttempts {
+		if err := s.redisRepo.Del(key); err != nil {
+			log.Println(err.Error())
+		}
+		return uuid.Nil, ErrInvalidMfaTicket
+	}
+	if err := s.verifyCode(ctx, userId, code); err != nil {
+		return uuid.Nil, err
+	}
+	consumed, err := s.redisRepo.Consume(key)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !consumed {
+		return uuid.Nil, ErrInvalidMfaTicket
+	}
+	return userId, nil
+}
+
+// TicketUserId returns the user a pending ticket was issued for, for second
+// factors that are verified outside this service such as passkeys.
+func (s *mfaService) TicketUserId(ctx context.Context, ticket string) (uuid.UUID, error) {
+	value, err := s.redisRepo.HGet(mfaTicketKey(s.utility.HashWithSHA256(ticket)), "user_id")
+	if err != nil {
+		log.Println(err.Error())
+		return uuid.Nil, ErrInvalidMfaTicket
+	}
+	userId, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, ErrInvalidMfaTicket
+	}
+	return userId, nil
+}
+
+// ConsumeTicket ends a ticket once userId proved the second factor elsewhere.
+func (s *mfaService) ConsumeTicket(ctx context.Context, ticket string, userId uuid.UUID) error {
+	owner, err := s.TicketUserId(ctx, ticket)
+	if err != nil {
+		return err
+	}
+	if owner != userId {
+		return ErrInvalidMfaTicket
+	}
+	consumed, err := s.redisRepo.Consume(mfaTicketKey(s.utility.HashWithSHA256(ticket)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidMfaTicket
+	}
+	return nil
+}
+
+// verifyCodeLimited is verifyCode for codes a signed in user submits, who has
+// no ticket to burn, so their attempts are counted per user instead and reset
+// by a valid code.
+func (s *mfaService) verifyCodeLimited(ctx context.Context, userId uuid.UUID, code string) error {
+	key := fmt.Sprintf("mfa-code-attempts:%s", userId)
+	attempts, err := s.redisRepo.Incr(key, mfaCodeAttemptWindow)
+	if err != nil {
+		return err
+	}
+	if attempts > maxMfaCodeAttempts {
+		return ErrTooManyMfaCodes
+	}
+	if err := s.verifyCode(ctx, userId, code); err != nil {
+		return err
+	}
+	if err := s.redisRepo.Del(key); err != nil {
+		log.Println(err.Error())
+	}
+	return nil
+}
+
+// verifyCode accepts a current TOTP code, each time step only once, or an unused
+// recovery code, which is consumed.
+func (s *mfaService) verifyCode(ctx context.Context, userId uuid.UUID, code string) error {
+	mfa, err := s.mfaRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMfaNotEnabled
+		}
+		return err
+	}
+	if mfa.EnabledAt == nil {
+		return ErrMfaNotEnabled
+	}
+	code = normalizeMfaCode(code)
+	if len(code) == totpCodeLength {
+		step, ok := utils.MatchTOTP(mfa.TOTPSecret, code, time.Now())
+		if !ok {
+			return ErrInvalidMfaCode
+		}
+		claimed, err := s.mfaRepo.ClaimStep(ctx, userId, step)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return ErrInvalidMfaCode
+		}
+		return nil
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userId, s.utility.HashWithSHA256(code))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns codes formatted as xxxxx-xxxxx and the hashes
+// of their normalized form to store.
+func (s *mfaService) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw, err := s.utility.GenerateRandomBytes(5)
+		if err != nil {
+			return nil, nil, errors.New("failed to generate recovery codes")
+		}
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, s.utility.HashWithSHA256(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeMfaCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
+
+func mfaTicketKey(ticketHash string) string {
+	return fmt.Sprintf("mfa-ticket:%s", ticketHash)
+}
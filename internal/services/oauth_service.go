@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrInvalidOAuthState   = errors.New("invalid or expired oauth state")
+	ErrOAuthExchangeFailed = errors.New("failed to exchange authorization code")
+)
+
+type IOAuthService interface {
+	Start(ctx context.Context, provider string) (string, string, error)
+	Callback(ctx context.Context, provider, code, state, binding string) (*models.User, error)
+}
+
+type oauthService struct {
+	providers    utils.IOAuthProviderRegistry
+	userRepo     repositories.IUserRepository
+	identityRepo repositories.IIdentityRepository
+	redisRepo    repositories.IRedisRepository
+	utility      utils.IUtils
+	stateTTL     time.Duration
+}
+
+func NewOAuthService(
+	providers utils.IOAuthProviderRegistry,
+	userRepo repositories.IUserRepository,
+	identityRepo repositories.IIdentityRepository,
+	redisRepo repositories.IRedisRepository,
+	utility utils.IUtils,
+	stateTTL time.Duration,
+) IOAuthService {
+	return &oauthService{
+		providers:    providers,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		redisRepo:    redisRepo,
+		utility:      utility,
+		stateTTL:     stateTTL,
+	}
+}
+
+// oauthState is what we remember between redirecting to the provider and its callback.
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	BindingHash  string `json:"binding_hash"`
+}
+
+// Start begins an authorization code flow with PKCE and returns the provider URL
+// the browser should be redirected to, and the binding the browser keeps in a
+// cookie. state, nonce, the code verifier and the hash of the binding are kept
+// in Redis until the callback consumes them.
+func (s *oauthService) Start(ctx context.Context, provider string) (string, string, error) {
+	p, err := s.providers.Get(ctx, provider)
+	if err != nil {
+		return "", "", err
+	}
+	state, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", "", err
+	}
+	binding, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+	value, err := json.Marshal(oauthState{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		BindingHash:  s.utility.HashWithSHA256(binding),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.redisRepo.Set(fmt.Sprintf("oauth-state:%s", state), value, s.stateTTL); err != nil {
+		return "", "", err
+	}
+	return p.OAuth2.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	), binding, nil
+}
+
+// Callback finishes a flow started by the same browser: the binding from its
+// cookie must match the state, or a callback URL of someone else's flow could
+// sign the browser in to their account.
+func (s *oauthService) Callback(ctx context.Context, provider, code, state, binding string) (*models.User, error) {
+	value, err := s.redisRepo.GetDel(fmt.Sprintf("oauth-state:%s", state))
+	if err != nil {
+		log.Println(err.Error())
+		return nil, ErrInvalidOAuthState
+	}
+	var stored oauthState
+	if err := json.Unmarshal([]byte(value), &stored); err != nil || stored.Provider != provider {
+		return nil, ErrInvalidOAuthState
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(stored.BindingHash), []byte(s.utility.HashWithSHA256(binding))) != 1 {
+		return nil, ErrInvalidOAuthState
+	}
+	p, err := s.providers.Get(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	token, err := p.OAuth2.Exchange(ctx, code, oauth2.VerifierOption(stored.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrOAuthExchangeFailed)
+	}
+	claims, err := p.Verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != stored.Nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", utils.ErrInvalidIDToken)
+	}
+	return resolveExternalUser(ctx, s.userRepo, s.identityRepo, provider, claims, constants.PROVIDER_OIDC, p.TrustEmail)
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"my-go-api/internal/config"
+	"my-go-api/internal/policy"
+	"my-go-api/internal/repositories"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IPolicyService decides requests with the attribute based access policies.
+// Authorize is what routes and services call, Evaluate is a dry run over
+// attributes given by the caller.
+type IPolicyService interface {
+	Authorize(ctx context.Context, userId uuid.UUID, action policy.Action, resource policy.Resource, env policy.Context) (policy.Decision, error)
+	Evaluate(req policy.Request) policy.Decision
+	Reload(ctx context.Context) error
+	ReloadEvery(ctx context.Context, interval time.Duration)
+}
+
+type policyService struct {
+	policyRepo repositories.IPolicyRepository
+	mfaService IMfaService
+	policyCfg  config.PolicyConfig
+
+	mu     sync.RWMutex
+	engine *policy.Engine
+}
+
+func NewPolicyService(policyRepo repositories.IPolicyRepository, mfaService IMfaService, policyCfg config.PolicyConfig) IPolicyService {
+	return &policyService{
+		policyRepo: policyRepo,
+		mfaService: mfaService,
+		policyCfg:  policyCfg,
+	}
+}
+
+// Reload loads the policies again from the configured source. Requests keep
+// being decided with the previous policies when the new ones do not load.
+func (s *policyService) Reload(ctx context.Context) error {
+	var rules []policy.Rule
+	var err error
+	if s.policyCfg.Source == "database" {
+		rules, err = s.policyRepo.ListPolicies(ctx)
+	} else {
+		rules, err = policy.LoadFile(s.policyCfg.File)
+	}
+	if err != nil {
+		return err
+	}
+	engine, err := policy.NewEngine(rules)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.engine = engine
+	s.mu.Unlock()
+	return nil
+}
+
+// ReloadEvery reloads the policies until ctx is done, so edits to the file or
+// the table apply without a restart.
+func (s *policyService) ReloadEvery(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reload(ctx); err != nil {
+				log.Printf("Could not reload access policies: %v", err)
+			}
+		}
+	}
+}
+
+// Evaluate denies everything until policies were loaded.
+func (s *policyService) Evaluate(req policy.Request) policy.Decision {
+	s.mu.RLock()
+	engine := s.engine
+	s.mu.RUnlock()
+	if engine == nil {
+		return policy.Decision{Reason: "access policies are not loaded"}
+	}
+	return engine.Evaluate(req)
+}
+
+// Authorize looks up the role and organisation of the user and decides the
+// action. A context without a time is now, and one without an MFA level gets
+// the level of the user's account.
+func (s *policyService) Authorize(ctx context.Context, userId uuid.UUID, action policy.Action, resource policy.Resource, env policy.Context) (policy.Decision, error) {
+	subject, err := s.policyRepo.GetSubject(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return policy.Decision{}, ErrUserNotFound
+		}
+		return policy.Decision{}, err
+	}
+	if env.Time.IsZero() {
+		env.Time = time.Now()
+	}
+	if env.MfaLevel == policy.MfaLevelUnknown {
+		enabled, err := s.mfaService.IsEnabled(ctx, userId)
+		if err != nil {
+			return policy.Decision{}, err
+		}
+		env.MfaLevel = policy.MfaLevelSingle
+		if enabled {
+			env.MfaLevel = policy.MfaLevelMulti
+		}
+	}
+	return s.Evaluate(policy.Request{
+		Subject:  *subject,
+		Action:   action,
+		Resource: resource,
+		Context:  env,
+	}), nil
+}
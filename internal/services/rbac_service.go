@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"my-go-api/internal/constants"
+	"my-go-api/internal/dto"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleInUse         = errors.New("role is still assigned to users")
+	ErrBuiltInRole       = errors.New("built-in roles cannot be changed this way")
+	ErrLastAdmin         = errors.New("the last admin cannot be given another role")
+	ErrUnknownPermission = errors.New("unknown permission")
+)
+
+// IRBACService manages roles, the permissions they grant and which role each
+// user has.
+type IRBACService interface {
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	CreateRole(ctx context.Context, req dto.CreateRole) (*models.Role, error)
+	SetRolePermissions(ctx context.Context, name string, permissions []string) (*models.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	AssignRole(ctx context.Context, userId uuid.UUID, role string) error
+	Permissions(ctx context.Context, userId uuid.UUID) ([]string, error)
+	HasPermission(ctx context.Context, userId uuid.UUID, permission string) (bool, error)
+}
+
+type rbacService struct {
+	roleRepo repositories.IRoleRepository
+	userRepo repositories.IUserRepository
+}
+
+func NewRBACService(roleRepo repositories.IRoleRepository, userRepo repositories.IUserRepository) IRBACService {
+	return &rbacService{roleRepo: roleRepo, userRepo: userRepo}
+}
+
+func (s *rbacService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	return s.roleRepo.ListRoles(ctx)
+}
+
+func (s *rbacService) CreateRole(ctx context.Context, req dto.CreateRole) (*models.Role, error) {
+	if _, err := s.roleRepo.GetRole(ctx, req.Name); err == nil {
+		return nil, ErrRoleExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	permissions, err := s.knownPermissions(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	role := &models.Role{Name: req.Name, Description: req.Description, Permissions: permissions}
+	if err := s.roleRepo.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// SetRolePermissions replaces the permissions of a role. The admin role always
+// keeps every permission, so nobody can lock admins out of managing roles.
+func (s *rbacService) SetRolePermissions(ctx context.Context, name string, permissions []string) (*models.Role, error) {
+	if name == constants.ROLE_ADMIN {
+		return nil, ErrBuiltInRole
+	}
+	if _, err := s.getRole(ctx, name); err != nil {
+		return nil, err
+	}
+	permissions, err := s.knownPermissions(ctx, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.roleRepo.SetPermissions(ctx, name, permissions); err != nil {
+		return nil, err
+	}
+	return s.getRole(ctx, name)
+}
+
+// DeleteRole removes a role nobody has anymore. The built-in roles stay.
+func (s *rbacService) DeleteRole(ctx context.Context, name string) error {
+	if name == constants.ROLE_USER || name == constants.ROLE_ADMIN {
+		return ErrBuiltInRole
+	}
+	count, err := s.roleRepo.CountUsers(ctx, name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+	if err := s.roleRepo.DeleteRole(ctx, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *rbacService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	return s.roleRepo.ListPermissions(ctx)
+}
+
+// AssignRole gives the user the role in place of their current one. The last
+// admin keeps their role, or nobody would be left to assign it again.
+func (s *rbacService) AssignRole(ctx context.Context, userId uuid.UUID, role string) error {
+	if _, err := s.getRole(ctx, role); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.Role == role {
+		return nil
+	}
+	if user.Role == constants.ROLE_ADMIN {
+		admins, err := s.roleRepo.CountUsers(ctx, constants.ROLE_ADMIN)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+	return s.roleRepo.AssignRole(ctx, userId, role)
+}
+
+// Permissions resolves the permissions of the user from their current role, so
+// a change of role or of its permissions applies to the next request.
+func (s *rbacService) Permissions(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	return s.roleRepo.PermissionsOfUser(ctx, userId)
+}
+
+func (s *rbacService) HasPermission(ctx context.Context, userId uuid.UUID, permission string) (bool, error) {
+	permissions, err := s.Permissions(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(permissions, permission), nil
+}
+
+func (s *rbacService) getRole(ctx context.Context, name string) (*models.Role, error) {
+	role, err := s.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// knownPermissions returns the permissions sorted and without duplicates,
+// failing with ErrUnknownPermission for any that does not exist.
+func (s *rbacService) knownPermissions(ctx context.Context, permissions []string) ([]string, error) {
+	known, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range permissions {
+		if !slices.ContainsFunc(known, func(p models.Permission) bool { return p.Name == permission }) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+	}
+	return slices.Compact(slices.Sorted(slices.Values(permissions))), nil
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+	SecurityEventAccountLocked     = "account_locked"
+)
+
+// SecurityEvent records something that happened to an account which the user or
+// an operator may need to act on.
+type SecurityEvent struct {
+	Type       string         `json:"type"`
+	UserId     uuid.UUID      `json:"user_id"`
+	DeviceId   uuid.UUID      `json:"device_id,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
+	OccurredAt time.Time      `json:"occurred_at"`
+}
+
+type ISecurityEventPublisher interface {
+	Publish(ctx context.Context, event SecurityEvent)
+}
+
+type logSecurityEventPublisher struct{}
+
+// NewLogSecurityEventPublisher writes security events as JSON lines to the standard logger.
+func NewLogSecurityEventPublisher() ISecurityEventPublisher {
+	return &logSecurityEventPublisher{}
+}
+
+func (p *logSecurityEventPublisher) Publish(ctx context.Context, event SecurityEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+	log.Printf("security event: %s", data)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type ISessionService interface {
+	ListSessions(ctx context.Context, userId uuid.UUID) ([]models.Token, error)
+	RevokeSession(ctx context.Context, userId, deviceId uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userId, currentDeviceId uuid.UUID) error
+	RevokeSessionsExceptToken(ctx context.Context, userId, jti uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userId uuid.UUID) error
+}
+
+type sessionService struct {
+	sessions repositories.ISessionStore
+	denylist ITokenDenylist
+}
+
+func NewSessionService(sessions repositories.ISessionStore, denylist ITokenDenylist) ISessionService {
+	return &sessionService{sessions: sessions, denylist: denylist}
+}
+
+func (s *sessionService) ListSessions(ctx context.Context, userId uuid.UUID) ([]models.Token, error) {
+	return s.sessions.ListByUser(ctx, userId)
+}
+
+// RevokeSession signs a single device of the user out.
+func (s *sessionService) RevokeSession(ctx context.Context, userId, deviceId uuid.UUID) error {
+	token, err := s.sessions.Get(ctx, userId, deviceId)
+	if err != nil {
+		if errors.Is(err, repositories.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if err := s.denylist.RevokeSession(ctx, token); err != nil {
+		return err
+	}
+	return s.sessions.Remove(ctx, userId, deviceId)
+}
+
+// RevokeOtherSessions signs the user out everywhere except the device making the request.
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userId, currentDeviceId uuid.UUID) error {
+	tokens, err := s.sessions.ListByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for i, token := range tokens {
+		if token.DeviceId == currentDeviceId {
+			continue
+		}
+		if err := s.denylist.RevokeSession(ctx, &tokens[i]); err != nil {
+			return err
+		}
+		if err := s.sessions.Remove(ctx, userId, token.DeviceId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeSessionsExceptToken signs the user out everywhere except the session the
+// access token jti was issued with, found through its family so that a token
+// issued before the latest rotation still counts. When no session issued it,
+// every session is signed out.
+func (s *sessionService) RevokeSessionsExceptToken(ctx context.Context, userId, jti uuid.UUID) error {
+	tokens, err := s.sessions.ListByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	keep := uuid.Nil
+	for _, token := range tokens {
+		if jti == uuid.Nil {
+			break
+		}
+		family, err := s.sessions.ListFamily(ctx, token.FamilyId)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(family, func(issued models.Token) bool { return issued.Jti == jti }) {
+			keep = token.DeviceId
+			break
+		}
+	}
+	return s.RevokeOtherSessions(ctx, userId, keep)
+}
+
+func (s *sessionService) RevokeAllSessions(ctx context.Context, userId uuid.UUID) error {
+	tokens, err := s.sessions.ListByUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for i := range tokens {
+		if err := s.denylist.RevokeSession(ctx, &tokens[i]); err != nil {
+			return err
+		}
+	}
+	return s.sessions.RemoveAllByUser(ctx, userId)
+}
@@ -0,0 +1,115 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIAPIKeyRepository(ctrl)
+	mockUtils := mocks.NewMockIUtils(ctrl)
+	fakeHash(mockUtils)
+	service := services.NewAPIKeyService(mockRepo, mockUtils)
+	userId := uuid.New()
+
+	t.Run("it should store only the hash and prefix of the key", func(t *testing.T) {
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("0123456789abcdef", nil)
+		mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key *models.APIKey) error {
+			assert.Equal(t, "hash-mga_0123456789abcdef", key.Hash)
+			assert.Equal(t, "mga_01234567", key.Prefix)
+			assert.Equal(t, []string{"read"}, key.Scopes)
+			key.ID = uuid.New()
+			return nil
+		})
+
+		raw, key, err := service.Create(context.Background(), userId, "CI", []string{"read"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "mga_0123456789abcdef", raw)
+		assert.Equal(t, userId, key.UserId)
+	})
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIAPIKeyRepository(ctrl)
+	mockUtils := mocks.NewMockIUtils(ctrl)
+	fakeHash(mockUtils)
+	service := services.NewAPIKeyService(mockRepo, mockUtils)
+
+	t.Run("it should reject values that are not api keys without a lookup", func(t *testing.T) {
+		_, err := service.Authenticate(context.Background(), "eyJhbGciOi")
+		assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+	})
+
+	t.Run("it should reject unknown keys", func(t *testing.T) {
+		mockRepo.EXPECT().GetByHash(gomock.Any(), "hash-mga_unknown").Return(nil, sql.ErrNoRows)
+		_, err := service.Authenticate(context.Background(), "mga_unknown")
+		assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+	})
+
+	t.Run("it should reject expired keys", func(t *testing.T) {
+		expiredAt := time.Now().Add(-time.Minute)
+		mockRepo.EXPECT().GetByHash(gomock.Any(), "hash-mga_key").Return(&models.APIKey{ID: uuid.New(), ExpiresAt: &expiredAt}, nil)
+		_, err := service.Authenticate(context.Background(), "mga_key")
+		assert.ErrorIs(t, err, services.ErrInvalidAPIKey)
+	})
+
+	t.Run("it should record when a key was used", func(t *testing.T) {
+		key := &models.APIKey{ID: uuid.New(), UserId: uuid.New()}
+		mockRepo.EXPECT().GetByHash(gomock.Any(), "hash-mga_key").Return(key, nil)
+		mockRepo.EXPECT().UpdateLastUsed(gomock.Any(), key.ID, gomock.Any()).Return(nil)
+
+		found, err := service.Authenticate(context.Background(), "mga_key")
+		assert.NoError(t, err)
+		assert.Equal(t, key.UserId, found.UserId)
+		assert.NotNil(t, found.LastUsedAt)
+	})
+
+	t.Run("it should not write the last use again within a minute", func(t *testing.T) {
+		usedAt := time.Now().Add(-10 * time.Second)
+		mockRepo.EXPECT().GetByHash(gomock.Any(), "hash-mga_key").Return(&models.APIKey{ID: uuid.New(), LastUsedAt: &usedAt}, nil)
+
+		_, err := service.Authenticate(context.Background(), "mga_key")
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should still authenticate when the last use cannot be written", func(t *testing.T) {
+		key := &models.APIKey{ID: uuid.New()}
+		mockRepo.EXPECT().GetByHash(gomock.Any(), "hash-mga_key").Return(key, nil)
+		mockRepo.EXPECT().UpdateLastUsed(gomock.Any(), key.ID, gomock.Any()).Return(errors.New("db down"))
+
+		_, err := service.Authenticate(context.Background(), "mga_key")
+		assert.NoError(t, err)
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockIAPIKeyRepository(ctrl)
+	service := services.NewAPIKeyService(mockRepo, nil)
+	userId, keyId := uuid.New(), uuid.New()
+
+	mockRepo.EXPECT().Delete(gomock.Any(), userId, keyId).Return(sql.ErrNoRows)
+	assert.ErrorIs(t, service.Revoke(context.Background(), userId, keyId), services.ErrAPIKeyNotFound)
+
+	mockRepo.EXPECT().Delete(gomock.Any(), userId, keyId).Return(nil)
+	assert.NoError(t, service.Revoke(context.Background(), userId, keyId))
+}
@@ -4,13 +4,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"my-go-api/internal/config"
 	"my-go-api/internal/dto"
 	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
 	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
 	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
@@ -25,7 +30,7 @@ func TestCreateUser(t *testing.T) {
 	mockUserRepo := mocks.NewMockIUserRepository(ctrl)
 	mockUtils := mocks.NewMockIUtils(ctrl)
 
-	authService := services.NewAuthService(mockUserRepo, mockUtils, nil, "uri")
+	authService := services.NewAuthService(mockUserRepo, mockUtils, nil, nil, nil, nil, "uri", config.AuthConfig{})
 
 	ctx := context.Background()
 	req := dto.CreateUser{
@@ -102,52 +107,56 @@ func TestCreateUser(t *testing.T) {
 }
 
 func TestValidateToken(t *testing.T) {
-	t.Run("it should fail because utility.ValidateToken return err", func(t *testing.T) {
+	accessClaims := func(userId, jti uuid.UUID) *utils.AccessTokenClaims {
+		return &utils.AccessTokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId.String(),
+			ID:        jti.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}}
+	}
+
+	t.Run("it should pass the reason through when utility.ValidateToken fails", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockUtils := mocks.NewMockIUtils(ctrl)
-		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(nil, errors.New("some errors"))
-		authService := services.NewAuthService(nil, mockUtils, nil, "uri")
+		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(nil, fmt.Errorf("%w: token is expired", utils.ErrAccessTokenExpired))
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, nil, "uri", config.AuthConfig{})
 		payload, err := authService.ValidateToken("token")
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, utils.ErrAccessTokenExpired)
 		assert.Nil(t, payload)
-		assert.Equal(t, err.Error(), "invalid token")
 	})
 
-	t.Run("it should fail because token expired", func(t *testing.T) {
+	t.Run("it should works because mockClaims.UserId equals to payload.UserId", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockUtils := mocks.NewMockIUtils(ctrl)
-		mockClaims := &jwt.MapClaims{
-			"exp":    float64(time.Now().Add(-1 * time.Hour).UnixMilli()),
-			"userId": uuid.New().String(),
-		}
-		t.Log(time.Now())
-		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(mockClaims, nil)
-		authService := services.NewAuthService(nil, mockUtils, nil, "uri")
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		userId := uuid.New()
+		jti := uuid.New()
+		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(accessClaims(userId, jti), nil)
+		mockDenylist.EXPECT().IsRevoked(gomock.Any(), jti).Return(false, nil)
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, mockDenylist, "uri", config.AuthConfig{})
 		payload, err := authService.ValidateToken("token")
-		assert.Error(t, err)
-		assert.Nil(t, payload)
-		assert.Equal(t, err.Error(), "token expired")
+		assert.NoError(t, err)
+		assert.Equal(t, payload.UserId, userId)
+		assert.Equal(t, payload.Jti, jti)
 	})
 
-	t.Run("it should works because mockClaims.UserId equals to payload.UserId", func(t *testing.T) {
+	t.Run("it should fail because the token was revoked", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		mockUtils := mocks.NewMockIUtils(ctrl)
-		userId := uuid.New()
-		mockClaims := &jwt.MapClaims{
-			"exp":    float64(time.Now().Add(1 * time.Hour).UnixMilli()),
-			"userId": userId.String(),
-		}
-		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(mockClaims, nil)
-		authService := services.NewAuthService(nil, mockUtils, nil, "uri")
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		jti := uuid.New()
+		mockUtils.EXPECT().ValidateToken(gomock.Any()).Return(accessClaims(uuid.New(), jti), nil)
+		mockDenylist.EXPECT().IsRevoked(gomock.Any(), jti).Return(true, nil)
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, mockDenylist, "uri", config.AuthConfig{})
 		payload, err := authService.ValidateToken("token")
-		assert.NoError(t, err)
-		assert.Equal(t, payload.UserId, userId)
+		assert.ErrorIs(t, err, services.ErrTokenRevoked)
+		assert.Nil(t, payload)
 	})
 }
 
@@ -159,7 +168,7 @@ func TestGetUserByIdentity(t *testing.T) {
 
 		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
 		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), gomock.Any()).Return(&models.User{ID: uuid.New(), Email: "test@mail.com"}, nil)
-		authService := services.NewAuthService(mockUserRepo, nil, nil, "")
+		authService := services.NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, "", config.AuthConfig{})
 		user, err := authService.GetUserByIdentity(context.Background(), input)
 		assert.NoError(t, err)
 		assert.Equal(t, input, user.Email)
@@ -169,11 +178,22 @@ func TestGetUserByIdentity(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
 		mockUserRepo.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).Return(&models.User{ID: uuid.New(), Username: "test_username"}, nil)
-		authService := services.NewAuthService(mockUserRepo, nil, nil, "")
+		authService := services.NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, "", config.AuthConfig{})
 		user, err := authService.GetUserByIdentity(context.Background(), input)
 		assert.NoError(t, err)
 		assert.Equal(t, input, user.Username)
 	})
+	t.Run("It should work. Surrounding spaces are ignored", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "test@mail.com").Return(&models.User{ID: uuid.New(), Email: "test@mail.com"}, nil)
+		authService := services.NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, "", config.AuthConfig{})
+		user, err := authService.GetUserByIdentity(context.Background(), " test@mail.com ")
+		assert.NoError(t, err)
+		assert.Equal(t, "test@mail.com", user.Email)
+	})
 	t.Run("It should failed. Execute userRepo.GetByEmail but corresponding user is not found", func(t *testing.T) {
 		input := "test@mail.com"
 		ctrl := gomock.NewController(t)
@@ -181,7 +201,7 @@ func TestGetUserByIdentity(t *testing.T) {
 
 		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
 		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
-		authService := services.NewAuthService(mockUserRepo, nil, nil, "")
+		authService := services.NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, "", config.AuthConfig{})
 		user, err := authService.GetUserByIdentity(context.Background(), input)
 		assert.Nil(t, user)
 		assert.Error(t, err)
@@ -192,7 +212,7 @@ func TestGetUserByIdentity(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
 		mockUserRepo.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows)
-		authService := services.NewAuthService(mockUserRepo, nil, nil, "")
+		authService := services.NewAuthService(mockUserRepo, nil, nil, nil, nil, nil, "", config.AuthConfig{})
 		user, err := authService.GetUserByIdentity(context.Background(), input)
 		assert.Nil(t, user)
 		assert.Error(t, err)
@@ -206,7 +226,7 @@ func TestSendVerificationEmail(t *testing.T) {
 		defer ctrl.Finish()
 		mockUtils := mocks.NewMockIUtils(ctrl)
 		mockUtils.EXPECT().SendEmailWithGmail("Email verification", gomock.Any(), "test@example.com").Return(nil)
-		authService := services.NewAuthService(nil, mockUtils, nil, "")
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, nil, "", config.AuthConfig{})
 		err := authService.SendVerificationEmail("John", "test@example.com", "some-token")
 		assert.NoError(t, err)
 	})
@@ -215,149 +235,431 @@ func TestSendVerificationEmail(t *testing.T) {
 		defer ctrl.Finish()
 		mockUtils := mocks.NewMockIUtils(ctrl)
 		mockUtils.EXPECT().SendEmailWithGmail("Email verification", gomock.Any(), "test@example.com").Return(errors.New("some errors"))
-		authService := services.NewAuthService(nil, mockUtils, nil, "")
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, nil, "", config.AuthConfig{})
 		err := authService.SendVerificationEmail("John", "test@example.com", "some-token")
 		assert.Error(t, err)
 	})
 }
 
 func TestStoreRefreshToken(t *testing.T) {
-	t.Run("it should work", func(t *testing.T) {
+	t.Run("it should save the device session with the configured ttl", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().Insert(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&models.Token{}, nil)
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "")
-		err := authService.StoreRefreshToken(context.Background(), uuid.New(), uuid.New(), "some-hash")
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		jti, userId, deviceId := uuid.New(), uuid.New(), uuid.New()
+		mockSessionStore.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token *models.Token) error {
+			assert.Equal(t, "some-hash", token.Hash)
+			assert.Equal(t, jti, token.Jti)
+			assert.Equal(t, userId, token.UserId)
+			assert.Equal(t, deviceId, token.DeviceId)
+			assert.NotEqual(t, uuid.Nil, token.FamilyId, "a login starts a new token family")
+			assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiredAt, time.Second)
+			return nil
+		})
+		authService := services.NewAuthService(nil, nil, mockSessionStore, nil, nil, nil, "", config.AuthConfig{RefreshTokenTTL: time.Hour})
+		err := authService.StoreRefreshToken(context.Background(), jti, userId, deviceId, "some-hash", models.SessionMetadata{})
 		assert.NoError(t, err)
 	})
 	t.Run("it should fail", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().Insert(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some errors"))
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "")
-		err := authService.StoreRefreshToken(context.Background(), uuid.New(), uuid.New(), "some-hash")
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockSessionStore.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("some errors"))
+		authService := services.NewAuthService(nil, nil, mockSessionStore, nil, nil, nil, "", config.AuthConfig{})
+		err := authService.StoreRefreshToken(context.Background(), uuid.New(), uuid.New(), uuid.New(), "some-hash", models.SessionMetadata{})
 		assert.Error(t, err)
 	})
 }
 
 func TestDeleteRefreshToken(t *testing.T) {
-	t.Run("it should work because tokenRepo.Remove return nil", func(t *testing.T) {
+	t.Run("it should work because sessions.Remove return nil", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().Remove(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "")
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		token := &models.Token{Jti: uuid.New(), LastUsedAt: time.Now()}
+		mockSessionStore.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(token, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), token).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		authService := services.NewAuthService(nil, nil, mockSessionStore, nil, nil, mockDenylist, "", config.AuthConfig{})
 		err := authService.DeleteRefreshToken(context.Background(), uuid.New(), uuid.New())
 		assert.NoError(t, err)
 	})
-	t.Run("it should fail because tokenRepo.Remove return error", func(t *testing.T) {
+	t.Run("it should fail because sessions.Remove return error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().Remove(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some errors"))
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "")
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockSessionStore.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, repositories.ErrSessionNotFound)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("some errors"))
+		authService := services.NewAuthService(nil, nil, mockSessionStore, nil, nil, nil, "", config.AuthConfig{})
 		err := authService.DeleteRefreshToken(context.Background(), uuid.New(), uuid.New())
 		assert.Error(t, err)
 	})
 }
 
-func TestVerifyRefreshToken(t *testing.T) {
-	t.Run("it should fail because tokenRepo.GetToken return err", func(t *testing.T) {
+func TestRotateRefreshToken(t *testing.T) {
+	userId := uuid.New()
+	deviceId := uuid.New()
+	current := models.Token{
+		Hash:      "current-hash",
+		DeviceId:  deviceId,
+		UserId:    userId,
+		Jti:       uuid.New(),
+		FamilyId:  uuid.New(),
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+	cfg := config.AuthConfig{RefreshTokenTTL: time.Hour}
+
+	t.Run("it should fail for an unknown token", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().GetToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("some errors"))
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "test.com")
-		err := authService.VerifyRefreshToken(context.Background(), uuid.New(), uuid.New(), "token")
-		assert.Error(t, err)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockUtils.EXPECT().HashWithSHA256("token").Return("unknown-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "unknown-hash").Return(nil, repositories.ErrSessionNotFound)
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, nil, nil, "", cfg)
+		_, err := authService.RotateRefreshToken(context.Background(), uuid.New(), userId, deviceId, "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
 	})
 
-	t.Run("it should fail because token isRevoked true", func(t *testing.T) {
-		token := models.Token{
-			ID:        1,
-			Hash:      "some bytes",
-			IsRevoked: true,
-			DeviceId:  uuid.New(),
-			UserId:    uuid.New(),
-			ExpiredAt: "",
-		}
+	t.Run("it should fail for a token issued to another device", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().GetToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(&token, nil)
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "test.com")
-		err := authService.VerifyRefreshToken(context.Background(), uuid.New(), uuid.New(), "token")
-		assert.Error(t, err)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		token := current
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil)
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, nil, nil, "", cfg)
+		_, err := authService.RotateRefreshToken(context.Background(), uuid.New(), userId, uuid.New(), "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
 	})
 
-	t.Run("it should fail because current time exceeds token expiredAt", func(t *testing.T) {
-		exp := time.Now().Add(-5 * time.Hour).Format(time.RFC3339) // Corrected formatting
-		t.Log(exp)
-		token := models.Token{
-			ID:        1,
-			Hash:      "some bytes",
-			IsRevoked: false, // This should trigger "reuse token detected" before expiration check
-			DeviceId:  uuid.New(),
-			UserId:    uuid.New(),
-			ExpiredAt: exp,
-		}
+	t.Run("it should revoke the family and publish an event when a rotated token is reused", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
-		mockTokenRepo.EXPECT().GetToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(&token, nil)
-		authService := services.NewAuthService(nil, nil, mockTokenRepo, "test.com")
-		err := authService.VerifyRefreshToken(context.Background(), uuid.New(), uuid.New(), "token")
-		assert.Error(t, err)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockEvents := mock_services.NewMockISecurityEventPublisher(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		token := current
+		token.IsRevoked = true
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil)
+		active := current
+		active.Hash = "successor-hash"
+		active.Jti = uuid.New()
+		active.LastUsedAt = time.Now()
+		mockSessionStore.EXPECT().Get(gomock.Any(), userId, deviceId).Return(&active, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), &active).Return(nil)
+		mockSessionStore.EXPECT().RevokeFamily(gomock.Any(), current.FamilyId).Return(nil)
+		mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, event services.SecurityEvent) {
+			assert.Equal(t, services.SecurityEventRefreshTokenReuse, event.Type)
+			assert.Equal(t, userId, event.UserId)
+			assert.Equal(t, deviceId, event.DeviceId)
+		})
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, mockEvents, mockDenylist, "", cfg)
+		_, err := authService.RotateRefreshToken(context.Background(), uuid.New(), userId, deviceId, "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrRefreshTokenReused)
 	})
 
-	t.Run("it should fail because hash not matched", func(t *testing.T) {
-		exp := time.Now().Add(5 * time.Hour).Format(time.RFC3339)
-		token := models.Token{
-			ID:        1,
-			Hash:      "some bytes",
-			IsRevoked: false,
-			DeviceId:  uuid.New(),
-			UserId:    uuid.New(),
-			ExpiredAt: exp,
-		}
+	t.Run("it should treat losing a concurrent rotation as reuse", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockEvents := mock_services.NewMockISecurityEventPublisher(ctrl)
+		token := current
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("new-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("new-token").Return("new-hash")
+		mockSessionStore.EXPECT().Rotate(gomock.Any(), &token, gomock.Any()).Return(repositories.ErrSessionRevoked)
+		mockSessionStore.EXPECT().Get(gomock.Any(), userId, deviceId).Return(nil, repositories.ErrSessionNotFound)
+		mockSessionStore.EXPECT().RevokeFamily(gomock.Any(), current.FamilyId).Return(nil)
+		mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any())
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, mockEvents, nil, "", cfg)
+		_, err := authService.RotateRefreshToken(context.Background(), uuid.New(), userId, deviceId, "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrRefreshTokenReused)
+	})
 
+	t.Run("it should issue the successor in the same family", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
 		mockUtils := mocks.NewMockIUtils(ctrl)
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		token := current
+		jti := uuid.New()
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("new-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("new-token").Return("new-hash")
+		mockSessionStore.EXPECT().Rotate(gomock.Any(), &token, gomock.Any()).DoAndReturn(func(ctx context.Context, current, next *models.Token) error {
+			assert.Equal(t, "new-hash", next.Hash)
+			assert.Equal(t, jti, next.Jti)
+			assert.Equal(t, token.FamilyId, next.FamilyId)
+			assert.Equal(t, deviceId, next.DeviceId)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), next.ExpiredAt, time.Second)
+			return nil
+		})
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, nil, nil, "", cfg)
+		raw, err := authService.RotateRefreshToken(context.Background(), jti, userId, deviceId, "token", models.SessionMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "new-token", raw)
+	})
 
-		mockTokenRepo.EXPECT().GetToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(&token, nil)
-		mockUtils.EXPECT().HashWithSHA256(gomock.Any()).Return("hash")
+	t.Run("it should not rotate the session of an oauth client for our own apps", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		token := current
+		token.ClientId = uuid.New()
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash")
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil)
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, nil, nil, "", cfg)
+		_, err := authService.RotateRefreshToken(context.Background(), uuid.New(), userId, deviceId, "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+	})
 
-		authService := services.NewAuthService(nil, mockUtils, mockTokenRepo, "test.com")
-		err := authService.VerifyRefreshToken(context.Background(), uuid.New(), uuid.New(), "token")
-		assert.Error(t, err)
+	t.Run("it should rotate the session of an oauth client only for that client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		token := current
+		token.ClientId = uuid.New()
+		mockUtils.EXPECT().HashWithSHA256("token").Return("current-hash").Times(3)
+		mockSessionStore.EXPECT().GetByHash(gomock.Any(), "current-hash").Return(&token, nil).Times(3)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("new-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("new-token").Return("new-hash")
+		mockSessionStore.EXPECT().Rotate(gomock.Any(), &token, gomock.Any()).DoAndReturn(func(ctx context.Context, current, next *models.Token) error {
+			assert.Equal(t, token.ClientId, next.ClientId)
+			return nil
+		})
+		authService := services.NewAuthService(nil, mockUtils, mockSessionStore, nil, nil, nil, "", cfg)
+
+		_, err := authService.RotateClientRefreshToken(context.Background(), uuid.New(), userId, deviceId, uuid.New(), "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+		_, err = authService.RotateClientRefreshToken(context.Background(), uuid.New(), userId, deviceId, uuid.Nil, "token", models.SessionMetadata{})
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+		raw, err := authService.RotateClientRefreshToken(context.Background(), uuid.New(), userId, deviceId, token.ClientId, "token", models.SessionMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "new-token", raw)
 	})
+}
 
-	t.Run("it should work because token exists, isRevoked false, current time < token.expiredAt and has the same hash", func(t *testing.T) {
-		exp := time.Now().Add(5 * time.Hour).Format(time.RFC3339)
-		token := models.Token{
-			ID:        1,
-			Hash:      "same",
-			IsRevoked: false,
-			DeviceId:  uuid.New(),
-			UserId:    uuid.New(),
-			ExpiredAt: exp,
-		}
+func TestCreateEmailVerificationToken(t *testing.T) {
+	t.Run("it should store the hashed token with the configured ttl", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		userId := uuid.New()
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("raw-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("raw-token").Return("hashed-token")
+		mockRedisRepo.EXPECT().Set("email-verification:hashed-token", userId.String(), 24*time.Hour).Return(nil)
+		authService := services.NewAuthService(nil, mockUtils, nil, mockRedisRepo, nil, nil, "", config.AuthConfig{EmailVerificationTTL: 24 * time.Hour})
+		token, err := authService.CreateEmailVerificationToken(context.Background(), userId)
+		assert.NoError(t, err)
+		assert.Equal(t, "raw-token", token)
+	})
+}
 
+func TestVerifyEmail(t *testing.T) {
+	t.Run("it should fail when the token is unknown or already used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
 		mockUtils := mocks.NewMockIUtils(ctrl)
-		mockTokenRepo := mocks.NewMockITokenRepository(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils.EXPECT().HashWithSHA256("token").Return("hash")
+		mockRedisRepo.EXPECT().GetDel("email-verification:hash").Return("", errors.New("key not found"))
+		authService := services.NewAuthService(nil, mockUtils, nil, mockRedisRepo, nil, nil, "", config.AuthConfig{})
+		user, err := authService.VerifyEmail(context.Background(), "token")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrInvalidVerificationToken)
+	})
+	t.Run("it should mark the user as verified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		userId := uuid.New()
+		verifiedAt := time.Now().Format(time.RFC3339)
+		mockUtils.EXPECT().HashWithSHA256("token").Return("hash")
+		mockRedisRepo.EXPECT().GetDel("email-verification:hash").Return(userId.String(), nil)
+		mockUserRepo.EXPECT().VerifyEmail(gomock.Any(), userId).Return(&models.User{ID: userId, EmailVerifiedAt: &verifiedAt}, nil)
+		authService := services.NewAuthService(mockUserRepo, mockUtils, nil, mockRedisRepo, nil, nil, "", config.AuthConfig{})
+		user, err := authService.VerifyEmail(context.Background(), "token")
+		assert.NoError(t, err)
+		assert.Equal(t, &verifiedAt, user.EmailVerifiedAt)
+	})
+}
 
-		mockTokenRepo.EXPECT().GetToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(&token, nil)
+func TestResendVerificationEmail(t *testing.T) {
+	cfg := config.AuthConfig{EmailVerificationTTL: time.Hour, EmailVerificationResendInterval: time.Minute}
 
-		mockUtils.EXPECT().HashWithSHA256(gomock.Any()).Return("same")
+	t.Run("it should be throttled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().SetNX("email-verification-resend:john@example.com", gomock.Any(), time.Minute).Return(false, nil)
+		authService := services.NewAuthService(nil, nil, nil, mockRedisRepo, nil, nil, "", cfg)
+		err := authService.ResendVerificationEmail(context.Background(), "John@example.com")
+		assert.ErrorIs(t, err, services.ErrTooManyRequests)
+	})
+	t.Run("it should silently ignore unknown emails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockRedisRepo.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(nil, sql.ErrNoRows)
+		authService := services.NewAuthService(mockUserRepo, nil, nil, mockRedisRepo, nil, nil, "", cfg)
+		err := authService.ResendVerificationEmail(context.Background(), "john@example.com")
+		assert.NoError(t, err)
+	})
+	t.Run("it should send a new link to an unverified account", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		user := &models.User{ID: uuid.New(), Name: "John", Email: "john@example.com", Provider: "credentials"}
+		mockRedisRepo.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(user, nil)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("raw-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("raw-token").Return("hashed-token")
+		mockRedisRepo.EXPECT().Set("email-verification:hashed-token", user.ID.String(), time.Hour).Return(nil)
+		mockUtils.EXPECT().SendEmailWithGmail("Email verification", gomock.Any(), "john@example.com").Return(nil)
+		authService := services.NewAuthService(mockUserRepo, mockUtils, nil, mockRedisRepo, nil, nil, "", cfg)
+		err := authService.ResendVerificationEmail(context.Background(), "john@example.com")
+		assert.NoError(t, err)
+	})
+}
 
-		authService := services.NewAuthService(nil, mockUtils, mockTokenRepo, "test.com")
-		err := authService.VerifyRefreshToken(context.Background(), uuid.New(), uuid.New(), "token")
+func TestEnsureEmailVerified(t *testing.T) {
+	verifiedAt := time.Now().Format(time.RFC3339)
+	tests := []struct {
+		name    string
+		require bool
+		user    *models.User
+		wantErr error
+	}{
+		{"unverified credentials user is rejected", true, &models.User{Provider: "credentials"}, services.ErrEmailNotVerified},
+		{"verified credentials user is allowed", true, &models.User{Provider: "credentials", EmailVerifiedAt: &verifiedAt}, nil},
+		{"google user is allowed", true, &models.User{Provider: "google"}, nil},
+		{"rule disabled", false, &models.User{Provider: "credentials"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authService := services.NewAuthService(nil, nil, nil, nil, nil, nil, "", config.AuthConfig{RequireEmailVerification: tt.require})
+			err := authService.EnsureEmailVerified(tt.user)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	cfg := config.AuthConfig{PasswordResetTTL: time.Hour, PasswordResetRequestInterval: time.Minute}
+
+	t.Run("it should silently ignore unknown emails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockRedisRepo.EXPECT().SetNX("password-reset-request:john@example.com", gomock.Any(), time.Minute).Return(true, nil)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(nil, sql.ErrNoRows)
+		authService := services.NewAuthService(mockUserRepo, nil, nil, mockRedisRepo, nil, nil, "", cfg)
+		err := authService.RequestPasswordReset(context.Background(), "john@example.com")
 		assert.NoError(t, err)
 	})
+	t.Run("it should store the hashed token and email the raw one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		user := &models.User{ID: uuid.New(), Name: "John", Email: "john@example.com", Provider: "credentials"}
+		mockRedisRepo.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(user, nil)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("raw-token", nil)
+		mockUtils.EXPECT().HashWithSHA256("raw-token").Return("hashed-token")
+		mockRedisRepo.EXPECT().GetDel("password-reset-user:"+user.ID.String()).Return("old-hash", nil)
+		mockRedisRepo.EXPECT().Del("password-reset:old-hash").Return(nil)
+		mockRedisRepo.EXPECT().Set("password-reset:hashed-token", user.ID.String(), time.Hour).Return(nil)
+		mockRedisRepo.EXPECT().Set("password-reset-user:"+user.ID.String(), "hashed-token", time.Hour).Return(nil)
+		mockUtils.EXPECT().SendEmailWithGmail("Password reset", gomock.Any(), "john@example.com").Return(nil)
+		authService := services.NewAuthService(mockUserRepo, mockUtils, nil, mockRedisRepo, nil, nil, "", cfg)
+		err := authService.RequestPasswordReset(context.Background(), "john@example.com")
+		assert.NoError(t, err)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	t.Run("it should fail when the token is unknown or already used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils.EXPECT().HashWithSHA256("token").Return("hash")
+		mockRedisRepo.EXPECT().GetDel("password-reset:hash").Return("", errors.New("key not found"))
+		authService := services.NewAuthService(nil, mockUtils, nil, mockRedisRepo, nil, nil, "", config.AuthConfig{})
+		err := authService.ResetPassword(context.Background(), "token", "NewPassword1")
+		assert.ErrorIs(t, err, services.ErrInvalidResetToken)
+	})
+	t.Run("it should update the password and revoke all refresh tokens", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		userId := uuid.New()
+		mockUtils.EXPECT().HashWithSHA256("token").Return("hash")
+		mockRedisRepo.EXPECT().GetDel("password-reset:hash").Return(userId.String(), nil)
+		mockRedisRepo.EXPECT().Del("password-reset-user:" + userId.String()).Return(nil)
+		mockUtils.EXPECT().HashPassword("NewPassword1").Return("hashed-password", nil)
+		mockUserRepo.EXPECT().UpdatePassword(gomock.Any(), userId, "hashed-password").Return(nil)
+		tokens := []models.Token{
+			{UserId: userId, DeviceId: uuid.New(), Jti: uuid.New(), LastUsedAt: time.Now()},
+			{UserId: userId, DeviceId: uuid.New(), Jti: uuid.New(), LastUsedAt: time.Now()},
+		}
+		mockSessionStore.EXPECT().ListByUser(gomock.Any(), userId).Return(tokens, nil)
+		for _, token := range tokens {
+			mockDenylist.EXPECT().RevokeSession(gomock.Any(), &token).Return(nil)
+		}
+		mockSessionStore.EXPECT().RemoveAllByUser(gomock.Any(), userId).Return(nil)
+		authService := services.NewAuthService(mockUserRepo, mockUtils, mockSessionStore, mockRedisRepo, nil, mockDenylist, "", config.AuthConfig{})
+		err := authService.ResetPassword(context.Background(), "token", "NewPassword1")
+		assert.NoError(t, err)
+	})
+}
+
+func TestUpgradePasswordHash(t *testing.T) {
+	t.Run("it should keep a hash made with the current parameters", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		user := &models.User{ID: uuid.New(), Password: "$argon2id$current"}
+		mockUtils.EXPECT().PasswordNeedsRehash("$argon2id$current").Return(false)
+		authService := services.NewAuthService(nil, mockUtils, nil, nil, nil, nil, "", config.AuthConfig{})
+		assert.NoError(t, authService.UpgradePasswordHash(context.Background(), user, "Password1"))
+		assert.Equal(t, "$argon2id$current", user.Password)
+	})
+	t.Run("it should store a new hash of an outdated one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		user := &models.User{ID: uuid.New(), Password: "$2a$10$legacy"}
+		mockUtils.EXPECT().PasswordNeedsRehash("$2a$10$legacy").Return(true)
+		mockUtils.EXPECT().HashPassword("Password1").Return("$argon2id$current", nil)
+		mockUserRepo.EXPECT().UpdatePassword(gomock.Any(), user.ID, "$argon2id$current").Return(nil)
+		authService := services.NewAuthService(mockUserRepo, mockUtils, nil, nil, nil, nil, "", config.AuthConfig{})
+		assert.NoError(t, authService.UpgradePasswordHash(context.Background(), user, "Password1"))
+		assert.Equal(t, "$argon2id$current", user.Password)
+	})
 }
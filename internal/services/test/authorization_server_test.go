@@ -0,0 +1,646 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"my-go-api/internal/dto"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCodeVerifier = "dBjftJeZ4CVP-mJ92K9qXNfCZxC8_5ozfOlSsnl0ZWaA"
+
+func testCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type authorizationServerFixture struct {
+	server      services.IAuthorizationServer
+	redis       *miniredis.Miniredis
+	clientRepo  *mocks.MockIOAuthClientRepository
+	users       *mocks.MockIUserRepository
+	sessions    *mocks.MockISessionStore
+	denylist    *mock_services.MockITokenDenylist
+	authService *mock_services.MockIAuthService
+	utils       *mocks.MockIUtils
+}
+
+// newAuthorizationServerFixture keeps authorization codes in an in-memory Redis
+// so single use and expiry behave as in production.
+func newAuthorizationServerFixture(t *testing.T, ctrl *gomock.Controller) *authorizationServerFixture {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	f := &authorizationServerFixture{
+		redis:       mr,
+		clientRepo:  mocks.NewMockIOAuthClientRepository(ctrl),
+		users:       mocks.NewMockIUserRepository(ctrl),
+		sessions:    mocks.NewMockISessionStore(ctrl),
+		denylist:    mock_services.NewMockITokenDenylist(ctrl),
+		authService: mock_services.NewMockIAuthService(ctrl),
+		utils:       mocks.NewMockIUtils(ctrl),
+	}
+	fakeHash(f.utils)
+	f.utils.EXPECT().GenerateRandomBytes(32).DoAndReturn(func(size int) (string, error) {
+		return uuid.NewString(), nil
+	}).AnyTimes()
+	f.server = services.NewAuthorizationServer(
+		f.clientRepo,
+		f.users,
+		f.sessions,
+		repositories.NewRedisRepository(rdb),
+		f.denylist,
+		f.authService,
+		f.utils,
+		"https://api.example.com/",
+		"https://app.example.com/oauth/authorize",
+		time.Minute,
+		15*time.Minute,
+		[]string{"internal-key"},
+	)
+	return f
+}
+
+func (f *authorizationServerFixture) knows(clients ...*models.OAuthClient) {
+	f.clientRepo.EXPECT().GetById(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, id uuid.UUID) (*models.OAuthClient, error) {
+		for _, client := range clients {
+			if client.ID == id {
+				return client, nil
+			}
+		}
+		return nil, sql.ErrNoRows
+	}).AnyTimes()
+}
+
+func assertOAuthError(t *testing.T, err error, code string) {
+	var oauthErr *services.OAuthError
+	if assert.True(t, errors.As(err, &oauthErr), "expected an OAuthError, got %v", err) {
+		assert.Equal(t, code, oauthErr.Code)
+	}
+}
+
+func redirectParams(t *testing.T, redirectTo string) url.Values {
+	target, err := url.Parse(redirectTo)
+	assert.NoError(t, err)
+	return target.Query()
+}
+
+var (
+	spaClient = &models.OAuthClient{
+		ID:           uuid.New(),
+		Name:         "Dashboard",
+		Type:         "public",
+		RedirectURIs: []string{"https://dashboard.example.com/callback"},
+		Scopes:       []string{"openid", "read"},
+		GrantTypes:   []string{"authorization_code", "refresh_token"},
+	}
+	backendClient = &models.OAuthClient{
+		ID:         uuid.New(),
+		Name:       "Reports",
+		Type:       "confidential",
+		SecretHash: "hash-secret",
+		Scopes:     []string{"openid", "read", "write"},
+		GrantTypes: []string{"client_credentials"},
+	}
+)
+
+func TestRegisterOAuthClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+
+	t.Run("it should store a hash of the secret of a confidential client", func(t *testing.T) {
+		f.clientRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, client *models.OAuthClient) error {
+			assert.Equal(t, []string{"read", "write"}, client.Scopes)
+			client.ID = uuid.New()
+			return nil
+		})
+		client, secret, err := f.server.RegisterClient(context.Background(), dto.CreateOAuthClient{
+			Name:       "Reports",
+			Type:       "confidential",
+			Scopes:     []string{"write", "read", "read"},
+			GrantTypes: []string{"client_credentials"},
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, secret)
+		assert.Equal(t, "hash-"+secret, client.SecretHash)
+	})
+
+	t.Run("it should reject public clients asking for client credentials", func(t *testing.T) {
+		_, _, err := f.server.RegisterClient(context.Background(), dto.CreateOAuthClient{
+			Name:         "CLI",
+			Type:         "public",
+			RedirectURIs: []string{"http://localhost:8080/callback"},
+			Scopes:       []string{"read"},
+			GrantTypes:   []string{"authorization_code", "client_credentials"},
+		})
+		assertOAuthError(t, err, "invalid_client_metadata")
+	})
+
+	t.Run("it should only accept https redirect uris or http on loopback", func(t *testing.T) {
+		register := func(redirectURI string) error {
+			_, _, err := f.server.RegisterClient(context.Background(), dto.CreateOAuthClient{
+				Name:         "CLI",
+				Type:         "public",
+				RedirectURIs: []string{redirectURI},
+				Scopes:       []string{"read"},
+				GrantTypes:   []string{"authorization_code"},
+			})
+			return err
+		}
+		f.clientRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(4)
+		for _, valid := range []string{
+			"https://app.example.com/callback",
+			"http://localhost:8080/callback",
+			"http://127.0.0.1:8080/callback",
+			"http://[::1]:8080/callback",
+		} {
+			assert.NoError(t, register(valid), valid)
+		}
+		for _, invalid := range []string{
+			"http://app.example.com/callback",
+			"https://app.example.com/callback#state",
+			"javascript:alert(1)",
+			"data:text/html,hello",
+			"com.example.app:/callback",
+			"/callback",
+		} {
+			assertOAuthError(t, register(invalid), "invalid_redirect_uri")
+		}
+	})
+
+	t.Run("it should need a redirect uri for the authorization code grant", func(t *testing.T) {
+		_, _, err := f.server.RegisterClient(context.Background(), dto.CreateOAuthClient{
+			Name:       "CLI",
+			Type:       "public",
+			Scopes:     []string{"read"},
+			GrantTypes: []string{"authorization_code"},
+		})
+		assertOAuthError(t, err, "invalid_redirect_uri")
+	})
+}
+
+func TestAuthenticateOAuthClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	f.knows(spaClient, backendClient)
+
+	tests := []struct {
+		name     string
+		clientId string
+		secret   string
+		err      bool
+	}{
+		{"confidential client with its secret", backendClient.ID.String(), "secret", false},
+		{"confidential client with a wrong secret", backendClient.ID.String(), "guess", true},
+		{"confidential client without a secret", backendClient.ID.String(), "", true},
+		{"public client without a secret", spaClient.ID.String(), "", false},
+		{"public client with a secret", spaClient.ID.String(), "secret", true},
+		{"unknown client", uuid.NewString(), "secret", true},
+		{"malformed client id", "dashboard", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := f.server.AuthenticateClient(context.Background(), tt.clientId, tt.secret)
+			if tt.err {
+				assertOAuthError(t, err, "invalid_client")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.clientId, client.ID.String())
+		})
+	}
+}
+
+func TestAuthorizationCodeGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	f.knows(spaClient)
+	ctx := context.Background()
+	userId := uuid.New()
+
+	authorize := func(mutate func(req *dto.OAuthAuthorize)) (url.Values, error) {
+		req := dto.OAuthAuthorize{
+			ResponseType:        "code",
+			ClientId:            spaClient.ID.String(),
+			RedirectURI:         "https://dashboard.example.com/callback",
+			Scope:               "openid read",
+			State:               "xyz",
+			CodeChallenge:       testCodeChallenge(testCodeVerifier),
+			CodeChallengeMethod: "S256",
+			Nonce:               "n-0S6_WzA2Mj",
+		}
+		if mutate != nil {
+			mutate(&req)
+		}
+		redirectTo, err := f.server.Authorize(ctx, userId, req)
+		if err != nil {
+			return nil, err
+		}
+		return redirectParams(t, redirectTo), nil
+	}
+	exchange := func(code, verifier string) (*dto.OAuthToken, error) {
+		return f.server.Token(ctx, spaClient, services.TokenRequest{
+			GrantType:    "authorization_code",
+			Code:         code,
+			RedirectURI:  "https://dashboard.example.com/callback",
+			CodeVerifier: verifier,
+		}, models.SessionMetadata{IP: "203.0.113.7"})
+	}
+
+	t.Run("it should not redirect to an unregistered uri", func(t *testing.T) {
+		_, err := authorize(func(req *dto.OAuthAuthorize) { req.RedirectURI = "https://evil.example.com/callback" })
+		assertOAuthError(t, err, "invalid_request")
+		_, err = authorize(func(req *dto.OAuthAuthorize) { req.ClientId = uuid.NewString() })
+		assertOAuthError(t, err, "invalid_request")
+	})
+
+	t.Run("it should report other problems to the client", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			mutate func(req *dto.OAuthAuthorize)
+			code   string
+		}{
+			{"scope not allowed", func(req *dto.OAuthAuthorize) { req.Scope = "openid write" }, "invalid_scope"},
+			{"implicit flow", func(req *dto.OAuthAuthorize) { req.ResponseType = "token" }, "unsupported_response_type"},
+			{"public client without PKCE", func(req *dto.OAuthAuthorize) { req.CodeChallenge = "" }, "invalid_request"},
+			{"plain PKCE", func(req *dto.OAuthAuthorize) { req.CodeChallengeMethod = "plain" }, "invalid_request"},
+		}
+		for _, tt := range tests {
+			params, err := authorize(tt.mutate)
+			assert.NoError(t, err, tt.name)
+			assert.Equal(t, tt.code, params.Get("error"), tt.name)
+			assert.Equal(t, "xyz", params.Get("state"), tt.name)
+			assert.Empty(t, params.Get("code"), tt.name)
+		}
+	})
+
+	t.Run("it should exchange the code once for tokens", func(t *testing.T) {
+		params, err := authorize(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "xyz", params.Get("state"))
+
+		var deviceId uuid.UUID
+		f.authService.EXPECT().GenerateScopedToken(userId, spaClient.ID, "openid read", gomock.Any()).Return("access-token", nil)
+		f.users.EXPECT().GetById(gomock.Any(), userId).Return(&models.User{ID: userId, Name: "John", Email: "john@example.com"}, nil)
+		f.utils.EXPECT().GenerateIDToken(userId, spaClient.ID, "n-0S6_WzA2Mj", utils.UserClaims{}).Return("id-token", nil)
+		f.authService.EXPECT().GenerateRefreshToken().Return("refresh-token", "hash-refresh-token", nil)
+		f.clientRepo.EXPECT().CreateGrant(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, grant *models.OAuthGrant) error {
+			assert.Equal(t, spaClient.ID, grant.ClientId)
+			assert.Equal(t, "openid read", grant.Scope)
+			deviceId = grant.DeviceId
+			return nil
+		})
+		f.authService.EXPECT().StoreClientRefreshToken(gomock.Any(), gomock.Any(), userId, gomock.Any(), spaClient.ID, "hash-refresh-token", models.SessionMetadata{
+			DeviceLabel: "Dashboard",
+			IP:          "203.0.113.7",
+		}).DoAndReturn(func(ctx context.Context, jti, userId, device, clientId uuid.UUID, hash string, meta models.SessionMetadata) error {
+			assert.Equal(t, deviceId, device)
+			return nil
+		})
+
+		token, err := exchange(params.Get("code"), testCodeVerifier)
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.OAuthToken{
+			AccessToken:  "access-token",
+			TokenType:    "Bearer",
+			ExpiresIn:    900,
+			RefreshToken: "refresh-token",
+			Scope:        "openid read",
+			IDToken:      "id-token",
+		}, token)
+
+		_, err = exchange(params.Get("code"), testCodeVerifier)
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should burn the code on a wrong verifier", func(t *testing.T) {
+		params, err := authorize(nil)
+		assert.NoError(t, err)
+		_, err = exchange(params.Get("code"), "wrong-verifier-wrong-verifier-wrong-verifier")
+		assertOAuthError(t, err, "invalid_grant")
+		_, err = exchange(params.Get("code"), testCodeVerifier)
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should reject an expired code", func(t *testing.T) {
+		params, err := authorize(nil)
+		assert.NoError(t, err)
+		f.redis.FastForward(time.Minute)
+		_, err = exchange(params.Get("code"), testCodeVerifier)
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should reject a code issued to another client", func(t *testing.T) {
+		params, err := authorize(nil)
+		assert.NoError(t, err)
+		other := *spaClient
+		other.ID = uuid.New()
+		_, err = f.server.Token(ctx, &other, services.TokenRequest{
+			GrantType:    "authorization_code",
+			Code:         params.Get("code"),
+			RedirectURI:  "https://dashboard.example.com/callback",
+			CodeVerifier: testCodeVerifier,
+		}, models.SessionMetadata{})
+		assertOAuthError(t, err, "invalid_grant")
+	})
+}
+
+func TestRefreshTokenGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	ctx := context.Background()
+	userId := uuid.New()
+	deviceId := uuid.New()
+	session := &models.Token{UserId: userId, DeviceId: deviceId, ClientId: spaClient.ID}
+	f.sessions.EXPECT().GetByHash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, hash string) (*models.Token, error) {
+		switch hash {
+		case "hash-refresh-token":
+			return session, nil
+		case "hash-app-refresh-token":
+			return &models.Token{UserId: userId, DeviceId: uuid.New()}, nil
+		}
+		return nil, repositories.ErrSessionNotFound
+	}).AnyTimes()
+	f.clientRepo.EXPECT().GetGrant(gomock.Any(), deviceId).Return(&models.OAuthGrant{
+		DeviceId: deviceId,
+		UserId:   userId,
+		ClientId: spaClient.ID,
+		Scope:    "openid read",
+	}, nil).AnyTimes()
+
+	refresh := func(client *models.OAuthClient, refreshToken, scope string) (*dto.OAuthToken, error) {
+		return f.server.Token(ctx, client, services.TokenRequest{
+			GrantType:    "refresh_token",
+			RefreshToken: refreshToken,
+			Scope:        scope,
+		}, models.SessionMetadata{})
+	}
+
+	t.Run("it should rotate the refresh token of the client", func(t *testing.T) {
+		f.authService.EXPECT().RotateClientRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, spaClient.ID, "refresh-token", models.SessionMetadata{DeviceLabel: "Dashboard"}).Return("next-refresh-token", nil)
+		f.authService.EXPECT().GenerateScopedToken(userId, spaClient.ID, "read", gomock.Any()).Return("access-token", nil)
+		token, err := refresh(spaClient, "refresh-token", "read")
+		assert.NoError(t, err)
+		assert.Equal(t, "next-refresh-token", token.RefreshToken)
+		assert.Equal(t, "read", token.Scope)
+	})
+
+	t.Run("it should not widen the granted scope", func(t *testing.T) {
+		_, err := refresh(spaClient, "refresh-token", "openid write")
+		assertOAuthError(t, err, "invalid_scope")
+	})
+
+	t.Run("it should reject a reused refresh token", func(t *testing.T) {
+		f.authService.EXPECT().RotateClientRefreshToken(gomock.Any(), gomock.Any(), userId, deviceId, spaClient.ID, "refresh-token", gomock.Any()).Return("", services.ErrRefreshTokenReused)
+		_, err := refresh(spaClient, "refresh-token", "")
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should reject refresh tokens of other clients and unknown ones", func(t *testing.T) {
+		other := *spaClient
+		other.ID = uuid.New()
+		_, err := refresh(&other, "refresh-token", "")
+		assertOAuthError(t, err, "invalid_grant")
+		_, err = refresh(spaClient, "unknown", "")
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should reject refresh tokens of our own apps", func(t *testing.T) {
+		_, err := refresh(spaClient, "app-refresh-token", "")
+		assertOAuthError(t, err, "invalid_grant")
+	})
+
+	t.Run("it should reject refresh tokens of our own sessions", func(t *testing.T) {
+		own := uuid.New()
+		session.DeviceId = own
+		defer func() { session.DeviceId = deviceId }()
+		f.clientRepo.EXPECT().GetGrant(gomock.Any(), own).Return(nil, sql.ErrNoRows)
+		_, err := refresh(spaClient, "refresh-token", "")
+		assertOAuthError(t, err, "invalid_grant")
+	})
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	ctx := context.Background()
+
+	t.Run("it should issue a token with the client as subject", func(t *testing.T) {
+		f.authService.EXPECT().GenerateScopedToken(backendClient.ID, backendClient.ID, "read write", gomock.Any()).Return("access-token", nil)
+		token, err := f.server.Token(ctx, backendClient, services.TokenRequest{GrantType: "client_credentials"}, models.SessionMetadata{})
+		assert.NoError(t, err)
+		assert.Equal(t, "access-token", token.AccessToken)
+		assert.Empty(t, token.RefreshToken)
+	})
+
+	t.Run("it should not grant scopes that need a user", func(t *testing.T) {
+		_, err := f.server.Token(ctx, backendClient, services.TokenRequest{GrantType: "client_credentials", Scope: "openid"}, models.SessionMetadata{})
+		assertOAuthError(t, err, "invalid_scope")
+	})
+
+	t.Run("it should only run the grants the client was registered for", func(t *testing.T) {
+		_, err := f.server.Token(ctx, spaClient, services.TokenRequest{GrantType: "client_credentials"}, models.SessionMetadata{})
+		assertOAuthError(t, err, "unauthorized_client")
+		_, err = f.server.Token(ctx, backendClient, services.TokenRequest{GrantType: "password"}, models.SessionMetadata{})
+		assertOAuthError(t, err, "unsupported_grant_type")
+	})
+}
+
+func TestIntrospectAndRevoke(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	ctx := context.Background()
+	userId := uuid.New()
+	jti := uuid.New()
+	issuedAt := time.Now().Truncate(time.Second)
+	accessPayload := &services.TokenPayload{
+		UserId:    userId,
+		Jti:       jti,
+		ClientId:  spaClient.ID,
+		Scope:     "openid read",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(15 * time.Minute),
+	}
+	f.authService.EXPECT().ValidateToken(gomock.Any()).DoAndReturn(func(token string) (*services.TokenPayload, error) {
+		switch token {
+		case "access-token":
+			return accessPayload, nil
+		case "revoked-access-token":
+			return nil, services.ErrTokenRevoked
+		case "unchecked-access-token":
+			return nil, errors.New("redis is down")
+		}
+		return nil, utils.ErrAccessTokenMalformed
+	}).AnyTimes()
+
+	clientSession := &models.Token{UserId: userId, DeviceId: uuid.New(), ExpiredAt: issuedAt.Add(7 * 24 * time.Hour)}
+	ownSession := &models.Token{UserId: userId, DeviceId: uuid.New(), ExpiredAt: issuedAt.Add(7 * 24 * time.Hour)}
+	f.sessions.EXPECT().GetByHash(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, hash string) (*models.Token, error) {
+		switch hash {
+		case "hash-refresh-token":
+			return clientSession, nil
+		case "hash-own-refresh-token":
+			return ownSession, nil
+		}
+		return nil, repositories.ErrSessionNotFound
+	}).AnyTimes()
+	f.clientRepo.EXPECT().GetGrant(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, deviceId uuid.UUID) (*models.OAuthGrant, error) {
+		if deviceId == clientSession.DeviceId {
+			return &models.OAuthGrant{DeviceId: deviceId, UserId: userId, ClientId: spaClient.ID, Scope: "openid read"}, nil
+		}
+		return nil, sql.ErrNoRows
+	}).AnyTimes()
+
+	t.Run("it should describe an active access token", func(t *testing.T) {
+		introspection, err := f.server.Introspect(ctx, backendClient, "access-token", "")
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.OAuthIntrospection{
+			Active:    true,
+			Scope:     "openid read",
+			ClientId:  spaClient.ID.String(),
+			Subject:   userId.String(),
+			TokenType: "access_token",
+			ExpiresAt: issuedAt.Add(15 * time.Minute).Unix(),
+			IssuedAt:  issuedAt.Unix(),
+			Jti:       jti.String(),
+		}, introspection)
+	})
+
+	t.Run("it should describe a refresh token to the client it was issued to", func(t *testing.T) {
+		introspection, err := f.server.Introspect(ctx, spaClient, "refresh-token", "refresh_token")
+		assert.NoError(t, err)
+		assert.True(t, introspection.Active)
+		assert.Equal(t, "refresh_token", introspection.TokenType)
+		assert.Equal(t, "openid read", introspection.Scope)
+
+		introspection, err = f.server.Introspect(ctx, backendClient, "refresh-token", "refresh_token")
+		assert.NoError(t, err)
+		assert.False(t, introspection.Active)
+	})
+
+	t.Run("it should let internal callers see every token", func(t *testing.T) {
+		introspection, err := f.server.Introspect(ctx, nil, "own-refresh-token", "")
+		assert.NoError(t, err)
+		assert.True(t, introspection.Active)
+		assert.Empty(t, introspection.ClientId)
+	})
+
+	t.Run("it should report invalid tokens as inactive", func(t *testing.T) {
+		for _, token := range []string{"revoked-access-token", "unknown"} {
+			introspection, err := f.server.Introspect(ctx, nil, token, "")
+			assert.NoError(t, err)
+			assert.Equal(t, &dto.OAuthIntrospection{Active: false}, introspection)
+		}
+	})
+
+	t.Run("it should fail when the token cannot be checked", func(t *testing.T) {
+		_, err := f.server.Introspect(ctx, nil, "unchecked-access-token", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("it should revoke an access token of the client", func(t *testing.T) {
+		f.denylist.EXPECT().Revoke(gomock.Any(), jti, accessPayload.ExpiresAt).Return(nil)
+		assert.NoError(t, f.server.Revoke(ctx, spaClient, "access-token", "access_token"))
+	})
+
+	t.Run("it should sign out the session of a refresh token", func(t *testing.T) {
+		f.authService.EXPECT().DeleteRefreshToken(gomock.Any(), userId, clientSession.DeviceId).Return(nil)
+		assert.NoError(t, f.server.Revoke(ctx, spaClient, "refresh-token", ""))
+	})
+
+	t.Run("it should refuse revoking tokens of another client", func(t *testing.T) {
+		assertOAuthError(t, f.server.Revoke(ctx, backendClient, "access-token", ""), "unauthorized_client")
+		assertOAuthError(t, f.server.Revoke(ctx, spaClient, "own-refresh-token", ""), "unauthorized_client")
+	})
+
+	t.Run("it should ignore tokens that are already invalid", func(t *testing.T) {
+		assert.NoError(t, f.server.Revoke(ctx, spaClient, "unknown", "refresh_token"))
+	})
+
+	t.Run("it should only accept the configured internal keys", func(t *testing.T) {
+		assert.True(t, f.server.AuthenticateInternalKey("internal-key"))
+		assert.False(t, f.server.AuthenticateInternalKey("other-key"))
+		assert.False(t, f.server.AuthenticateInternalKey(""))
+	})
+}
+
+func TestOpenIDProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newAuthorizationServerFixture(t, ctrl)
+	ctx := context.Background()
+	verifiedAt := "2024-01-01T00:00:00Z"
+	user := &models.User{ID: uuid.New(), Name: "John Doe", Username: "john", Email: "john@example.com", EmailVerifiedAt: &verifiedAt}
+	f.users.EXPECT().GetById(gomock.Any(), user.ID).Return(user, nil).AnyTimes()
+
+	t.Run("it should publish its endpoints under the issuer", func(t *testing.T) {
+		f.utils.EXPECT().SigningAlgorithms().Return([]string{"RS256", "EdDSA"})
+		discovery := f.server.Discovery()
+		assert.Equal(t, "https://api.example.com", discovery.Issuer)
+		assert.Equal(t, "https://app.example.com/oauth/authorize", discovery.AuthorizationEndpoint)
+		assert.Equal(t, "https://api.example.com/oauth/token", discovery.TokenEndpoint)
+		assert.Equal(t, "https://api.example.com/userinfo", discovery.UserInfoEndpoint)
+		assert.Equal(t, "https://api.example.com/.well-known/jwks.json", discovery.JWKSURI)
+		assert.Equal(t, []string{"RS256", "EdDSA"}, discovery.IDTokenSigningAlgValuesSupported)
+		assert.Equal(t, []string{"S256"}, discovery.CodeChallengeMethodsSupported)
+	})
+
+	t.Run("it should only release the claims the scope covers", func(t *testing.T) {
+		verified := true
+		tests := []struct {
+			scope string
+			want  utils.UserClaims
+		}{
+			{"openid", utils.UserClaims{}},
+			{"openid profile", utils.UserClaims{Name: "John Doe", PreferredUsername: "john"}},
+			{"openid email", utils.UserClaims{Email: "john@example.com", EmailVerified: &verified}},
+			{"openid profile email read", utils.UserClaims{Name: "John Doe", PreferredUsername: "john", Email: "john@example.com", EmailVerified: &verified}},
+		}
+		for _, tt := range tests {
+			userInfo, err := f.server.UserInfo(ctx, user.ID, tt.scope)
+			assert.NoError(t, err, tt.scope)
+			assert.Equal(t, &dto.UserInfo{Subject: user.ID.String(), UserClaims: tt.want}, userInfo, tt.scope)
+		}
+	})
+
+	t.Run("it should report an unverified email", func(t *testing.T) {
+		unverified := &models.User{ID: uuid.New(), Email: "jane@example.com"}
+		f.users.EXPECT().GetById(gomock.Any(), unverified.ID).Return(unverified, nil)
+		userInfo, err := f.server.UserInfo(ctx, unverified.ID, "openid email")
+		assert.NoError(t, err)
+		if assert.NotNil(t, userInfo.EmailVerified) {
+			assert.False(t, *userInfo.EmailVerified)
+		}
+	})
+
+	t.Run("it should answer invalid_token for users that no longer exist", func(t *testing.T) {
+		f.users.EXPECT().GetById(gomock.Any(), gomock.Not(user.ID)).Return(nil, sql.ErrNoRows)
+		_, err := f.server.UserInfo(ctx, uuid.New(), "openid")
+		assertOAuthError(t, err, "invalid_token")
+	})
+}
@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoogleAuthenticate(t *testing.T) {
+	claims := &utils.IDTokenClaims{
+		Email:            "john.doe@gmail.com",
+		EmailVerified:    true,
+		Name:             "John Doe",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "google-sub"},
+	}
+
+	t.Run("it should fail when the id token is invalid", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(nil, fmt.Errorf("%w: expired", utils.ErrInvalidIDToken))
+		googleAuthService := services.NewGoogleAuthService(nil, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, utils.ErrInvalidIDToken)
+	})
+
+	t.Run("it should fail when google has not verified the email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		unverified := *claims
+		unverified.EmailVerified = false
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(&unverified, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(nil, sql.ErrNoRows)
+		googleAuthService := services.NewGoogleAuthService(nil, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrExternalEmailNotVerified)
+	})
+
+	t.Run("it should return the user already linked to the google subject", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		linked := &models.User{ID: uuid.New(), Email: "old-address@gmail.com"}
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(claims, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(&models.UserIdentity{UserId: linked.ID}, nil)
+		mockUserRepo.EXPECT().GetById(gomock.Any(), linked.ID).Return(linked, nil)
+		googleAuthService := services.NewGoogleAuthService(mockUserRepo, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.NoError(t, err)
+		assert.Equal(t, linked, user)
+	})
+
+	t.Run("it should link the existing user with the same email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		verifiedAt := time.Now().Format(time.RFC3339)
+		existing := &models.User{ID: uuid.New(), Email: claims.Email, Provider: "google", EmailVerifiedAt: &verifiedAt}
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(claims, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), claims.Email).Return(existing, nil)
+		mockIdentityRepo.EXPECT().Create(gomock.Any(), existing.ID, "google", "google-sub", claims.Email).Return(&models.UserIdentity{}, nil)
+		googleAuthService := services.NewGoogleAuthService(mockUserRepo, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.NoError(t, err)
+		assert.Equal(t, existing, user)
+	})
+
+	t.Run("it should not link an account whose email was never verified", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		squatted := &models.User{ID: uuid.New(), Email: claims.Email, Password: "attacker-hash", Provider: "credentials"}
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(claims, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), claims.Email).Return(squatted, nil)
+		googleAuthService := services.NewGoogleAuthService(mockUserRepo, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrExternalAccountUnverified)
+	})
+
+	t.Run("it should create a google user on first sign in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		created := &models.User{ID: uuid.New(), Email: claims.Email, Username: "john.doe", Provider: "google"}
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(claims, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), claims.Email).Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByUsername(gomock.Any(), "john.doe").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().CreateWithProvider(gomock.Any(), "John Doe", "john.doe", claims.Email, "google").Return(created, nil)
+		mockIdentityRepo.EXPECT().Create(gomock.Any(), created.ID, "google", "google-sub", claims.Email).Return(&models.UserIdentity{}, nil)
+		googleAuthService := services.NewGoogleAuthService(mockUserRepo, mockIdentityRepo, mockVerifier)
+		user, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.NoError(t, err)
+		assert.Equal(t, created, user)
+	})
+
+	t.Run("it should pick another username when the derived one is taken", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockVerifier := mocks.NewMockIIDTokenVerifier(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockVerifier.EXPECT().Verify(gomock.Any(), "id-token").Return(claims, nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "google", "google-sub").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), claims.Email).Return(nil, sql.ErrNoRows)
+		gomock.InOrder(
+			mockUserRepo.EXPECT().GetByUsername(gomock.Any(), "john.doe").Return(&models.User{}, nil),
+			mockUserRepo.EXPECT().GetByUsername(gomock.Any(), gomock.Any()).Return(nil, sql.ErrNoRows),
+		)
+		mockUserRepo.EXPECT().CreateWithProvider(gomock.Any(), "John Doe", gomock.Not("john.doe"), claims.Email, "google").
+			Return(&models.User{ID: uuid.New()}, nil)
+		mockIdentityRepo.EXPECT().Create(gomock.Any(), gomock.Any(), "google", "google-sub", claims.Email).Return(&models.UserIdentity{}, nil)
+		googleAuthService := services.NewGoogleAuthService(mockUserRepo, mockIdentityRepo, mockVerifier)
+		_, err := googleAuthService.Authenticate(context.Background(), "id-token")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+	mockAuditRepo := mocks.NewMockIAuditLogRepository(ctrl)
+	mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+	mockUtils := mocks.NewMockIUtils(ctrl)
+	mockRBACService := mock_services.NewMockIRBACService(ctrl)
+	service := services.NewImpersonationService(mockUserRepo, mockRBACService, mockAuditRepo, mockDenylist, mockUtils, 15*time.Minute)
+
+	ctx := context.Background()
+	actorId := uuid.New()
+	user := &models.User{ID: uuid.New(), Username: "john", Role: "user"}
+	moderator := &models.User{ID: uuid.New(), Username: "jane", Role: "moderator"}
+	mockRBACService.EXPECT().Permissions(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, userId uuid.UUID) ([]string, error) {
+		switch userId {
+		case actorId:
+			return []string{"users:read", "users:impersonate"}, nil
+		case moderator.ID:
+			return []string{"users:read", "audit_log:read"}, nil
+		}
+		return []string{"users:read"}, nil
+	}).AnyTimes()
+	meta := models.SessionMetadata{IP: "203.0.113.9", UserAgent: "curl/8.0"}
+
+	t.Run("it should issue a token naming the admin and audit the start", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(gomock.Any(), user.ID).Return(user, nil)
+		var jti uuid.UUID
+		mockUtils.EXPECT().GenerateImpersonationToken(user.ID, actorId, gomock.Any(), 15*time.Minute).
+			DoAndReturn(func(_, _, id uuid.UUID, _ time.Duration) (string, error) {
+				jti = id
+				return "impersonation-token", nil
+			})
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditLog) error {
+			assert.Equal(t, actorId, entry.ActorId)
+			assert.Equal(t, services.AuditImpersonationStart, entry.Action)
+			assert.Equal(t, user.ID, *entry.TargetId)
+			assert.Equal(t, "ticket #42", entry.Details["reason"])
+			assert.Equal(t, jti.String(), entry.Details["jti"])
+			assert.Equal(t, "203.0.113.9", entry.IP)
+			assert.Equal(t, "curl/8.0", entry.UserAgent)
+			return nil
+		})
+
+		impersonation, err := service.Start(ctx, actorId, user.ID, "ticket #42", meta)
+		assert.NoError(t, err)
+		assert.Equal(t, "impersonation-token", impersonation.AccessToken)
+		assert.Equal(t, user, impersonation.User)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), impersonation.ExpiresAt, time.Second)
+	})
+
+	t.Run("it should not hand out a token the audit log does not know of", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(gomock.Any(), user.ID).Return(user, nil)
+		mockUtils.EXPECT().GenerateImpersonationToken(user.ID, actorId, gomock.Any(), 15*time.Minute).Return("impersonation-token", nil)
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("db down"))
+
+		impersonation, err := service.Start(ctx, actorId, user.ID, "ticket #42", meta)
+		assert.Error(t, err)
+		assert.Nil(t, impersonation)
+	})
+
+	t.Run("it should refuse to impersonate oneself, admins, users with more permissions and unknown users", func(t *testing.T) {
+		_, err := service.Start(ctx, actorId, actorId, "curious", meta)
+		assert.ErrorIs(t, err, services.ErrImpersonateSelf)
+
+		admin := &models.User{ID: uuid.New(), Role: "admin"}
+		mockUserRepo.EXPECT().GetById(gomock.Any(), admin.ID).Return(admin, nil)
+		_, err = service.Start(ctx, actorId, admin.ID, "curious", meta)
+		assert.ErrorIs(t, err, services.ErrImpersonateAdmin)
+
+		mockUserRepo.EXPECT().GetById(gomock.Any(), moderator.ID).Return(moderator, nil)
+		_, err = service.Start(ctx, actorId, moderator.ID, "curious", meta)
+		assert.ErrorIs(t, err, services.ErrImpersonateAbove)
+
+		unknown := uuid.New()
+		mockUserRepo.EXPECT().GetById(gomock.Any(), unknown).Return(nil, sql.ErrNoRows)
+		_, err = service.Start(ctx, actorId, unknown, "curious", meta)
+		assert.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+
+	t.Run("it should revoke the token and audit the stop", func(t *testing.T) {
+		token := &services.TokenPayload{UserId: user.ID, ActorId: actorId, Jti: uuid.New(), ExpiresAt: time.Now().Add(10 * time.Minute)}
+		mockDenylist.EXPECT().Revoke(gomock.Any(), token.Jti, token.ExpiresAt).Return(nil)
+		mockAuditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *models.AuditLog) error {
+			assert.Equal(t, actorId, entry.ActorId)
+			assert.Equal(t, services.AuditImpersonationStop, entry.Action)
+			assert.Equal(t, user.ID, *entry.TargetId)
+			assert.Equal(t, token.Jti.String(), entry.Details["jti"])
+			return nil
+		})
+
+		assert.NoError(t, service.Stop(ctx, token, meta))
+	})
+
+	t.Run("it should only stop impersonations", func(t *testing.T) {
+		err := service.Stop(ctx, &services.TokenPayload{UserId: user.ID, Jti: uuid.New()}, meta)
+		assert.ErrorIs(t, err, services.ErrNotImpersonating)
+		assert.ErrorIs(t, service.Stop(ctx, nil, meta), services.ErrNotImpersonating)
+	})
+}
@@ -0,0 +1,192 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+var lockoutCfg = config.AuthConfig{
+	LoginFailureWindow:    time.Hour,
+	LoginLockoutThreshold: 6,
+	LoginLockoutDuration:  30 * time.Minute,
+	LoginMaxBackoff:       4 * time.Second,
+}
+
+type lockoutFixture struct {
+	service  services.ILockoutService
+	redis    *miniredis.Miniredis
+	userRepo *mocks.MockIUserRepository
+	utils    *mocks.MockIUtils
+	events   *mock_services.MockISecurityEventPublisher
+}
+
+// newLockoutFixture runs the service against an in-memory Redis so counters and
+// expiries behave as in production.
+func newLockoutFixture(t *testing.T, ctrl *gomock.Controller) *lockoutFixture {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	f := &lockoutFixture{
+		redis:    mr,
+		userRepo: mocks.NewMockIUserRepository(ctrl),
+		utils:    mocks.NewMockIUtils(ctrl),
+		events:   mock_services.NewMockISecurityEventPublisher(ctrl),
+	}
+	fakeHash(f.utils)
+	f.service = services.NewLockoutService(f.userRepo, repositories.NewRedisRepository(rdb), f.events, f.utils, "http://localhost:3000", lockoutCfg)
+	return f
+}
+
+func retryAfter(t *testing.T, err error) (time.Duration, bool) {
+	var throttled *services.LoginThrottledError
+	if !assert.True(t, errors.As(err, &throttled), "expected a LoginThrottledError, got %v", err) {
+		return 0, false
+	}
+	return throttled.RetryAfter, throttled.Locked
+}
+
+func TestLockoutBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newLockoutFixture(t, ctrl)
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "john@example.com"}
+
+	t.Run("it should allow the free attempts without waiting", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.1"))
+			assert.NoError(t, f.service.RecordFailure(ctx, user, "john@example.com", "10.0.0.1"))
+		}
+		assert.NoError(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.1"))
+	})
+
+	t.Run("it should double the wait with every further failure", func(t *testing.T) {
+		for _, want := range []time.Duration{time.Second, 2 * time.Second} {
+			assert.NoError(t, f.service.RecordFailure(ctx, user, "john@example.com", "10.0.0.1"))
+			wait, locked := retryAfter(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.1"))
+			assert.Equal(t, want, wait)
+			assert.False(t, locked)
+			f.redis.FastForward(want)
+		}
+	})
+
+	t.Run("it should throttle the account from another address too", func(t *testing.T) {
+		assert.NoError(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.2"))
+		f.redis.Set("login-backoff:user:"+user.ID.String(), "1")
+		f.redis.SetTTL("login-backoff:user:"+user.ID.String(), time.Second)
+		_, locked := retryAfter(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.2"))
+		assert.False(t, locked)
+		f.redis.FastForward(time.Second)
+	})
+
+	t.Run("it should forget the failures after a successful login", func(t *testing.T) {
+		assert.NoError(t, f.service.RecordSuccess(ctx, user))
+		assert.NoError(t, f.service.RecordFailure(ctx, user, "john@example.com", "10.0.0.1"))
+		assert.NoError(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.1"))
+	})
+}
+
+func TestLockoutPerIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newLockoutFixture(t, ctrl)
+	ctx := context.Background()
+
+	// a spray tries each identity once, which never trips the account counters
+	for i := 0; i < 21; i++ {
+		identity := uuid.NewString() + "@example.com"
+		assert.NoError(t, f.service.RecordFailure(ctx, nil, identity, "10.0.0.1"))
+	}
+
+	_, locked := retryAfter(t, f.service.Check(ctx, nil, "new@example.com", "10.0.0.1"))
+	assert.False(t, locked)
+	assert.NoError(t, f.service.Check(ctx, nil, "new@example.com", "10.0.0.2"))
+}
+
+func TestLockoutLock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newLockoutFixture(t, ctrl)
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Email: "john@example.com", Username: "john", Name: "John"}
+
+	fail := func(user *models.User, identity string, times int) {
+		for i := 0; i < times; i++ {
+			assert.NoError(t, f.service.RecordFailure(ctx, user, identity, "10.0.0.1"))
+		}
+	}
+
+	t.Run("it should lock the account at the threshold and email an unlock link", func(t *testing.T) {
+		f.events.EXPECT().Publish(gomock.Any(), gomock.Any()).Do(func(ctx context.Context, event services.SecurityEvent) {
+			assert.Equal(t, services.SecurityEventAccountLocked, event.Type)
+			assert.Equal(t, user.ID, event.UserId)
+		})
+		f.utils.EXPECT().GenerateRandomBytes(32).Return("unlock-token", nil)
+		f.utils.EXPECT().SendEmailWithGmail(gomock.Any(), gomock.Any(), "john@example.com").DoAndReturn(func(subject, body, to string) error {
+			assert.True(t, strings.Contains(body, "http://localhost:3000/unlock-account?token=unlock-token"))
+			return nil
+		})
+		fail(user, "john@example.com", 6)
+
+		wait, locked := retryAfter(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.9"))
+		assert.True(t, locked)
+		assert.Equal(t, 30*time.Minute, wait)
+	})
+
+	t.Run("it should list the locked account for admins", func(t *testing.T) {
+		f.userRepo.EXPECT().GetById(gomock.Any(), user.ID).Return(user, nil)
+		locked, err := f.service.ListLocked(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, locked, 1) {
+			assert.Equal(t, user, locked[0].User)
+			assert.WithinDuration(t, time.Now().Add(30*time.Minute), locked[0].LockedUntil, 2*time.Second)
+		}
+	})
+
+	t.Run("it should unlock the account with the emailed link only once", func(t *testing.T) {
+		assert.NoError(t, f.service.Unlock(ctx, "unlock-token"))
+		assert.NoError(t, f.service.Check(ctx, user, "john@example.com", "10.0.0.9"))
+		assert.ErrorIs(t, f.service.Unlock(ctx, "unlock-token"), services.ErrInvalidUnlockToken)
+
+		locked, err := f.service.ListLocked(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, locked)
+	})
+
+	t.Run("it should lock unknown identities the same way without an email", func(t *testing.T) {
+		fail(nil, "ghost@example.com", 3)
+		fail(nil, " GHOST@example.com", 3)
+		_, locked := retryAfter(t, f.service.Check(ctx, nil, "Ghost@example.com ", "10.0.0.9"))
+		assert.True(t, locked)
+	})
+
+	t.Run("it should drop accounts whose lock ended from the list", func(t *testing.T) {
+		f.events.EXPECT().Publish(gomock.Any(), gomock.Any())
+		f.utils.EXPECT().GenerateRandomBytes(32).Return("second-token", nil)
+		f.utils.EXPECT().SendEmailWithGmail(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		fail(user, "john", 6)
+
+		f.redis.FastForward(30 * time.Minute)
+		assert.NoError(t, f.service.Check(ctx, user, "john", "10.0.0.9"))
+		locked, err := f.service.ListLocked(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, locked)
+	})
+}
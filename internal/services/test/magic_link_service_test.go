@@ -0,0 +1,134 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMagicLink(t *testing.T) {
+	cfg := config.AuthConfig{MagicLinkTTL: 15 * time.Minute, MagicLinkRequestInterval: time.Minute}
+
+	t.Run("it should throttle requests per email", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().SetNX("magic-link-request:john@example.com", gomock.Any(), time.Minute).Return(false, nil)
+		magicLinkService := services.NewMagicLinkService(nil, mockRedisRepo, nil, "", cfg)
+		_, err := magicLinkService.RequestLink(context.Background(), "John@example.com")
+		assert.ErrorIs(t, err, services.ErrTooManyRequests)
+	})
+
+	t.Run("it should return a binding without sending an email to unknown addresses", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockRedisRepo.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("binding", nil)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(nil, sql.ErrNoRows)
+		magicLinkService := services.NewMagicLinkService(mockUserRepo, mockRedisRepo, mockUtils, "", cfg)
+		binding, err := magicLinkService.RequestLink(context.Background(), "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "binding", binding)
+	})
+
+	t.Run("it should store the hashed link bound to the device and email the raw one", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		user := &models.User{ID: uuid.New(), Name: "John", Email: "john@example.com"}
+		mockRedisRepo.EXPECT().SetNX(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil)
+		gomock.InOrder(
+			mockUtils.EXPECT().GenerateRandomBytes(32).Return("binding", nil),
+			mockUtils.EXPECT().GenerateRandomBytes(32).Return("raw-token", nil),
+		)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), "john@example.com").Return(user, nil)
+		mockRedisRepo.EXPECT().Set("magic-link:hash-raw-token", user.ID.String()+":hash-binding", 15*time.Minute).Return(nil)
+		mockUtils.EXPECT().SendEmailWithGmail("Your login link", gomock.Any(), "john@example.com").DoAndReturn(func(subject, body, address string) error {
+			assert.Contains(t, body, "https://app.example.com/magic-link?token=raw-token")
+			return nil
+		})
+		magicLinkService := services.NewMagicLinkService(mockUserRepo, mockRedisRepo, mockUtils, "https://app.example.com", cfg)
+		binding, err := magicLinkService.RequestLink(context.Background(), "john@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "binding", binding)
+	})
+}
+
+func TestConsumeMagicLink(t *testing.T) {
+	userId := uuid.New()
+	verifiedAt := "2026-01-01T00:00:00Z"
+
+	t.Run("it should fail when the link is unknown or already used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().GetDel("magic-link:hash-token").Return("", errors.New("key not found"))
+		magicLinkService := services.NewMagicLinkService(nil, mockRedisRepo, mockUtils, "", config.AuthConfig{})
+		_, err := magicLinkService.ConsumeLink(context.Background(), "token", "binding")
+		assert.ErrorIs(t, err, services.ErrInvalidMagicLink)
+	})
+
+	t.Run("it should fail when opened on another device", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().GetDel("magic-link:hash-token").Return(userId.String()+":hash-binding", nil)
+		magicLinkService := services.NewMagicLinkService(nil, mockRedisRepo, mockUtils, "", config.AuthConfig{})
+		_, err := magicLinkService.ConsumeLink(context.Background(), "token", "")
+		assert.ErrorIs(t, err, services.ErrInvalidMagicLink)
+	})
+
+	t.Run("it should return the user of the link", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		user := &models.User{ID: userId, EmailVerifiedAt: &verifiedAt}
+		mockRedisRepo.EXPECT().GetDel("magic-link:hash-token").Return(userId.String()+":hash-binding", nil)
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(user, nil)
+		magicLinkService := services.NewMagicLinkService(mockUserRepo, mockRedisRepo, mockUtils, "", config.AuthConfig{})
+		found, err := magicLinkService.ConsumeLink(context.Background(), "token", "binding")
+		assert.NoError(t, err)
+		assert.Equal(t, user, found)
+	})
+
+	t.Run("it should verify the email of an unverified user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		verified := &models.User{ID: userId, EmailVerifiedAt: &verifiedAt}
+		mockRedisRepo.EXPECT().GetDel("magic-link:hash-token").Return(userId.String()+":hash-binding", nil)
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(&models.User{ID: userId}, nil)
+		mockUserRepo.EXPECT().VerifyEmail(gomock.Any(), userId).Return(verified, nil)
+		magicLinkService := services.NewMagicLinkService(mockUserRepo, mockRedisRepo, mockUtils, "", config.AuthConfig{})
+		found, err := magicLinkService.ConsumeLink(context.Background(), "token", "binding")
+		assert.NoError(t, err)
+		assert.Equal(t, verified, found)
+	})
+}
@@ -0,0 +1,342 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func fakeHash(mockUtils *mocks.MockIUtils) {
+	mockUtils.EXPECT().HashWithSHA256(gomock.Any()).DoAndReturn(func(s string) string { return "hash-" + s }).AnyTimes()
+}
+
+func enabledMfa(userId uuid.UUID) *models.UserMFA {
+	enabledAt := time.Now()
+	return &models.UserMFA{UserId: userId, TOTPSecret: testTOTPSecret, EnabledAt: &enabledAt}
+}
+
+func TestMfaEnrollment(t *testing.T) {
+	cfg := config.AuthConfig{MfaIssuer: "my-go-api"}
+	user := &models.User{ID: uuid.New(), Email: "john@example.com"}
+
+	t.Run("it should refuse to enroll twice", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), user.ID).Return(enabledMfa(user.ID), nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, nil, nil, cfg)
+		_, err := mfaService.StartEnrollment(context.Background(), user)
+		assert.ErrorIs(t, err, services.ErrMfaAlreadyEnabled)
+	})
+
+	t.Run("it should store a new secret and return its otpauth uri", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), user.ID).Return(nil, sql.ErrNoRows)
+		var saved string
+		mockMfaRepo.EXPECT().SaveSecret(gomock.Any(), user.ID, gomock.Any()).DoAndReturn(func(ctx context.Context, userId uuid.UUID, secret string) error {
+			saved = secret
+			return nil
+		})
+		mfaService := services.NewMfaService(mockMfaRepo, nil, nil, nil, cfg)
+		enrollment, err := mfaService.StartEnrollment(context.Background(), user)
+		assert.NoError(t, err)
+		assert.Equal(t, saved, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/my-go-api:john@example.com?"))
+	})
+
+	t.Run("it should not enable two-factor with a wrong code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), user.ID).Return(&models.UserMFA{UserId: user.ID, TOTPSecret: testTOTPSecret}, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, nil, nil, cfg)
+		_, err := mfaService.ConfirmEnrollment(context.Background(), user.ID, "000000x")
+		assert.ErrorIs(t, err, services.ErrInvalidMfaCode)
+	})
+
+	t.Run("it should enable two-factor and hand out hashed recovery codes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		n := 0
+		mockUtils.EXPECT().GenerateRandomBytes(5).DoAndReturn(func(size int) (string, error) {
+			n++
+			return fmt.Sprintf("%010x", n), nil
+		}).Times(10)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), user.ID).Return(&models.UserMFA{UserId: user.ID, TOTPSecret: testTOTPSecret}, nil)
+		mockMfaRepo.EXPECT().Enable(gomock.Any(), user.ID, time.Now().Unix()/30, gomock.Any()).DoAndReturn(func(ctx context.Context, userId uuid.UUID, step int64, hashes []string) error {
+			assert.Len(t, hashes, 10)
+			assert.Equal(t, "hash-0000000001", hashes[0])
+			return nil
+		})
+		code, _ := totp.GenerateCode(testTOTPSecret, time.Now())
+		mfaService := services.NewMfaService(mockMfaRepo, nil, nil, mockUtils, cfg)
+		codes, err := mfaService.ConfirmEnrollment(context.Background(), user.ID, code)
+		assert.NoError(t, err)
+		assert.Len(t, codes, 10)
+		assert.Equal(t, "00000-00001", codes[0])
+	})
+}
+
+func TestRedeemMfaTicket(t *testing.T) {
+	userId := uuid.New()
+	cfg := config.AuthConfig{MfaTicketTTL: 5 * time.Minute}
+	ticketKey := "mfa-ticket:hash-ticket"
+
+	t.Run("it should store a hashed ticket", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockUtils.EXPECT().GenerateRandomBytes(32).Return("ticket", nil)
+		mockRedisRepo.EXPECT().HSet(ticketKey, map[string]any{"user_id": userId.String(), "attempts": 0}, 5*time.Minute).Return(nil)
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, mockUtils, cfg)
+		ticket, err := mfaService.CreateTicket(context.Background(), userId)
+		assert.NoError(t, err)
+		assert.Equal(t, "ticket", ticket)
+	})
+
+	t.Run("it should fail for an unknown ticket", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return("", errors.New("field user_id not found"))
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, mockUtils, cfg)
+		_, err := mfaService.RedeemTicket(context.Background(), "ticket", "123456")
+		assert.ErrorIs(t, err, services.ErrInvalidMfaTicket)
+	})
+
+	t.Run("it should burn the ticket after too many attempts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil)
+		mockRedisRepo.EXPECT().HIncrBy(ticketKey, "attempts", int64(1)).Return(int64(6), nil)
+		mockRedisRepo.EXPECT().Del(ticketKey).Return(nil)
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, mockUtils, cfg)
+		_, err := mfaService.RedeemTicket(context.Background(), "ticket", "123456")
+		assert.ErrorIs(t, err, services.ErrInvalidMfaTicket)
+	})
+
+	t.Run("it should accept a totp code once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		code, _ := totp.GenerateCode(testTOTPSecret, time.Now())
+		step := time.Now().Unix() / 30
+
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil).Times(2)
+		mockRedisRepo.EXPECT().HIncrBy(ticketKey, "attempts", int64(1)).Return(int64(1), nil).Times(2)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(enabledMfa(userId), nil).Times(2)
+		gomock.InOrder(
+			mockMfaRepo.EXPECT().ClaimStep(gomock.Any(), userId, step).Return(true, nil),
+			mockMfaRepo.EXPECT().ClaimStep(gomock.Any(), userId, step).Return(false, nil),
+		)
+		mockRedisRepo.EXPECT().Consume(ticketKey).Return(true, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, mockUtils, cfg)
+
+		redeemed, err := mfaService.RedeemTicket(context.Background(), "ticket", code)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, redeemed)
+
+		_, err = mfaService.RedeemTicket(context.Background(), "ticket", code)
+		assert.ErrorIs(t, err, services.ErrInvalidMfaCode)
+	})
+
+	t.Run("it should accept an unused recovery code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil)
+		mockRedisRepo.EXPECT().HIncrBy(ticketKey, "attempts", int64(1)).Return(int64(1), nil)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(enabledMfa(userId), nil)
+		mockMfaRepo.EXPECT().UseRecoveryCode(gomock.Any(), userId, "hash-abcde12345").Return(true, nil)
+		mockRedisRepo.EXPECT().Consume(ticketKey).Return(true, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, mockUtils, cfg)
+
+		redeemed, err := mfaService.RedeemTicket(context.Background(), "ticket", " ABCDE-12345 ")
+		assert.NoError(t, err)
+		assert.Equal(t, userId, redeemed)
+	})
+
+	t.Run("it should not redeem a ticket another request redeemed first", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil)
+		mockRedisRepo.EXPECT().HIncrBy(ticketKey, "attempts", int64(1)).Return(int64(1), nil)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(enabledMfa(userId), nil)
+		mockMfaRepo.EXPECT().UseRecoveryCode(gomock.Any(), userId, "hash-abcde12345").Return(true, nil)
+		mockRedisRepo.EXPECT().Consume(ticketKey).Return(false, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, mockUtils, cfg)
+
+		_, err := mfaService.RedeemTicket(context.Background(), "ticket", "ABCDE-12345")
+		assert.ErrorIs(t, err, services.ErrInvalidMfaTicket)
+	})
+}
+
+func TestDisableMfa(t *testing.T) {
+	userId := uuid.New()
+	attemptsKey := fmt.Sprintf("mfa-code-attempts:%s", userId)
+
+	t.Run("it should fail when two-factor is not enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().Incr(attemptsKey, 15*time.Minute).Return(int64(1), nil)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(nil, sql.ErrNoRows)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, nil, config.AuthConfig{})
+		err := mfaService.Disable(context.Background(), userId, "123456")
+		assert.ErrorIs(t, err, services.ErrMfaNotEnabled)
+	})
+
+	t.Run("it should remove the factor after a valid code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		code, _ := totp.GenerateCode(testTOTPSecret, time.Now())
+		mockRedisRepo.EXPECT().Incr(attemptsKey, 15*time.Minute).Return(int64(1), nil)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(enabledMfa(userId), nil)
+		mockMfaRepo.EXPECT().ClaimStep(gomock.Any(), userId, gomock.Any()).Return(true, nil)
+		mockRedisRepo.EXPECT().Del(attemptsKey).Return(nil)
+		mockMfaRepo.EXPECT().Delete(gomock.Any(), userId).Return(nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, nil, config.AuthConfig{})
+		err := mfaService.Disable(context.Background(), userId, code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should not check codes once too many were tried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		code, _ := totp.GenerateCode(testTOTPSecret, time.Now())
+		mockRedisRepo.EXPECT().Incr(attemptsKey, 15*time.Minute).Return(int64(6), nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, nil, config.AuthConfig{})
+		err := mfaService.Disable(context.Background(), userId, code)
+		assert.ErrorIs(t, err, services.ErrTooManyMfaCodes)
+	})
+}
+
+func TestRegenerateRecoveryCodes(t *testing.T) {
+	userId := uuid.New()
+	attemptsKey := fmt.Sprintf("mfa-code-attempts:%s", userId)
+
+	t.Run("it should count a wrong code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().Incr(attemptsKey, 15*time.Minute).Return(int64(5), nil)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(enabledMfa(userId), nil)
+		mfaService := services.NewMfaService(mockMfaRepo, nil, mockRedisRepo, nil, config.AuthConfig{})
+		_, err := mfaService.RegenerateRecoveryCodes(context.Background(), userId, "000000")
+		assert.ErrorIs(t, err, services.ErrInvalidMfaCode)
+	})
+
+	t.Run("it should not check codes once too many were tried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().Incr(attemptsKey, 15*time.Minute).Return(int64(6), nil)
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, nil, config.AuthConfig{})
+		_, err := mfaService.RegenerateRecoveryCodes(context.Background(), userId, "000000")
+		assert.ErrorIs(t, err, services.ErrTooManyMfaCodes)
+	})
+}
+
+func TestMfaIsEnabled(t *testing.T) {
+	userId := uuid.New()
+
+	t.Run("it should require a second factor for a user with only a passkey", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockWebAuthnRepo := mocks.NewMockIWebAuthnRepository(ctrl)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(nil, sql.ErrNoRows)
+		mockWebAuthnRepo.EXPECT().GetByUser(gomock.Any(), userId).Return([]models.WebAuthnCredential{{UserId: userId}}, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, mockWebAuthnRepo, nil, nil, config.AuthConfig{})
+		enabled, err := mfaService.IsEnabled(context.Background(), userId)
+		assert.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("it should not require a second factor without totp or passkeys", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockMfaRepo := mocks.NewMockIMfaRepository(ctrl)
+		mockWebAuthnRepo := mocks.NewMockIWebAuthnRepository(ctrl)
+		mockMfaRepo.EXPECT().GetByUserId(gomock.Any(), userId).Return(&models.UserMFA{UserId: userId, TOTPSecret: testTOTPSecret}, nil)
+		mockWebAuthnRepo.EXPECT().GetByUser(gomock.Any(), userId).Return(nil, nil)
+		mfaService := services.NewMfaService(mockMfaRepo, mockWebAuthnRepo, nil, nil, config.AuthConfig{})
+		enabled, err := mfaService.IsEnabled(context.Background(), userId)
+		assert.NoError(t, err)
+		assert.False(t, enabled)
+	})
+}
+
+func TestConsumeMfaTicket(t *testing.T) {
+	userId := uuid.New()
+	ticketKey := "mfa-ticket:hash-ticket"
+
+	t.Run("it should not let another user redeem the ticket", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil)
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, mockUtils, config.AuthConfig{})
+		err := mfaService.ConsumeTicket(context.Background(), "ticket", uuid.New())
+		assert.ErrorIs(t, err, services.ErrInvalidMfaTicket)
+	})
+
+	t.Run("it should delete the ticket of the user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		fakeHash(mockUtils)
+		mockRedisRepo.EXPECT().HGet(ticketKey, "user_id").Return(userId.String(), nil)
+		mockRedisRepo.EXPECT().Consume(ticketKey).Return(true, nil)
+		mfaService := services.NewMfaService(nil, nil, mockRedisRepo, mockUtils, config.AuthConfig{})
+		err := mfaService.ConsumeTicket(context.Background(), "ticket", userId)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,297 @@
+package services_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+	"my-go-api/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubIdP is a minimal OpenID Connect provider: discovery, jwks and a token
+// endpoint that only accepts the code it issued together with a valid PKCE verifier.
+type stubIdP struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	code          string
+	codeChallenge string
+	nonce         string
+	subject       string
+	email         string
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{key: key, code: "auth-code", subject: "idp-subject", email: "jane@example.com"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []utils.JWK{{
+			Kty: "RSA",
+			Kid: "idp-key",
+			N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != idp.code || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.codeChallenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            idp.server.URL,
+			"aud":            "client-id",
+			"sub":            idp.subject,
+			"email":          idp.email,
+			"email_verified": true,
+			"name":           "Jane Doe",
+			"nonce":          idp.nonce,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "idp-key"
+		idToken, _ := token.SignedString(key)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "idp-access-token",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func TestOAuthLoginFlow(t *testing.T) {
+	idp := newStubIdP(t)
+	stub := config.OAuthProviderConfig{
+		Name:         "stub",
+		Issuer:       idp.server.URL,
+		ClientId:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost/api/v1/auth/oauth/stub/callback",
+		Scopes:       []string{"openid", "email"},
+	}
+	registry := utils.NewOAuthProviderRegistry(map[string]config.OAuthProviderConfig{"stub": stub})
+	stub.TrustEmail = true
+	trustedRegistry := utils.NewOAuthProviderRegistry(map[string]config.OAuthProviderConfig{"stub": stub})
+
+	// hashing stands in for utils.HashWithSHA256 of the browser binding.
+	hashing := func(ctrl *gomock.Controller) *mocks.MockIUtils {
+		mockUtils := mocks.NewMockIUtils(ctrl)
+		mockUtils.EXPECT().HashWithSHA256(gomock.Any()).DoAndReturn(func(value string) string { return "hash-of-" + value }).AnyTimes()
+		return mockUtils
+	}
+
+	start := func(t *testing.T, ctrl *gomock.Controller, mockRedisRepo *mocks.MockIRedisRepository) (string, []byte) {
+		mockUtils := hashing(ctrl)
+		gomock.InOrder(
+			mockUtils.EXPECT().GenerateRandomBytes(32).Return("state-value", nil),
+			mockUtils.EXPECT().GenerateRandomBytes(32).Return("nonce-value", nil),
+			mockUtils.EXPECT().GenerateRandomBytes(32).Return("binding-value", nil),
+		)
+		var stored []byte
+		mockRedisRepo.EXPECT().Set("oauth-state:state-value", gomock.Any(), 10*time.Minute).
+			DoAndReturn(func(key string, value any, expiry time.Duration) error {
+				stored = value.([]byte)
+				return nil
+			})
+		oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, mockUtils, 10*time.Minute)
+		authURL, binding, err := oauthService.Start(context.Background(), "stub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "binding-value", binding)
+		return authURL, stored
+	}
+
+	t.Run("it should redirect to the provider with state, nonce and a S256 challenge", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		authURL, _ := start(t, ctrl, mocks.NewMockIRedisRepository(ctrl))
+		u, err := url.Parse(authURL)
+		assert.NoError(t, err)
+		assert.Equal(t, idp.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+		assert.Equal(t, "state-value", u.Query().Get("state"))
+		assert.Equal(t, "nonce-value", u.Query().Get("nonce"))
+		assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+		assert.NotEmpty(t, u.Query().Get("code_challenge"))
+		assert.Equal(t, "client-id", u.Query().Get("client_id"))
+	})
+
+	t.Run("it should exchange the code and create a user with a linked identity", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		authURL, stored := start(t, ctrl, mockRedisRepo)
+		u, _ := url.Parse(authURL)
+		idp.codeChallenge = u.Query().Get("code_challenge")
+		idp.nonce = u.Query().Get("nonce")
+
+		created := &models.User{ID: uuid.New(), Email: idp.email, Provider: "oidc"}
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "stub", idp.subject).Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), idp.email).Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByUsername(gomock.Any(), "jane_").Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().CreateWithProvider(gomock.Any(), "Jane Doe", "jane_", idp.email, "oidc").Return(created, nil)
+		mockIdentityRepo.EXPECT().Create(gomock.Any(), created.ID, "stub", idp.subject, idp.email).Return(&models.UserIdentity{}, nil)
+
+		oauthService := services.NewOAuthService(registry, mockUserRepo, mockIdentityRepo, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.NoError(t, err)
+		assert.Equal(t, created, user)
+	})
+
+	t.Run("it should refuse to sign in to an account with the same email of an untrusted provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		authURL, stored := start(t, ctrl, mockRedisRepo)
+		u, _ := url.Parse(authURL)
+		idp.codeChallenge = u.Query().Get("code_challenge")
+		idp.nonce = u.Query().Get("nonce")
+
+		verifiedAt := time.Now().Format(time.RFC3339)
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "stub", idp.subject).Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), idp.email).Return(&models.User{ID: uuid.New(), Email: idp.email, EmailVerifiedAt: &verifiedAt}, nil)
+
+		oauthService := services.NewOAuthService(registry, mockUserRepo, mockIdentityRepo, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrExternalEmailInUse)
+	})
+
+	t.Run("it should link the account with the same verified email of a trusted provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+		mockIdentityRepo := mocks.NewMockIIdentityRepository(ctrl)
+		authURL, stored := start(t, ctrl, mockRedisRepo)
+		u, _ := url.Parse(authURL)
+		idp.codeChallenge = u.Query().Get("code_challenge")
+		idp.nonce = u.Query().Get("nonce")
+
+		verifiedAt := time.Now().Format(time.RFC3339)
+		existing := &models.User{ID: uuid.New(), Email: idp.email, EmailVerifiedAt: &verifiedAt}
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+		mockIdentityRepo.EXPECT().GetByProviderSubject(gomock.Any(), "stub", idp.subject).Return(nil, sql.ErrNoRows)
+		mockUserRepo.EXPECT().GetByEmail(gomock.Any(), idp.email).Return(existing, nil)
+		mockIdentityRepo.EXPECT().Create(gomock.Any(), existing.ID, "stub", idp.subject, idp.email).Return(&models.UserIdentity{}, nil)
+
+		oauthService := services.NewOAuthService(trustedRegistry, mockUserRepo, mockIdentityRepo, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.NoError(t, err)
+		assert.Equal(t, existing, user)
+	})
+
+	t.Run("it should reject an id token carrying another nonce", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		authURL, stored := start(t, ctrl, mockRedisRepo)
+		u, _ := url.Parse(authURL)
+		idp.codeChallenge = u.Query().Get("code_challenge")
+		idp.nonce = "replayed-nonce"
+
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+		oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, utils.ErrInvalidIDToken)
+	})
+
+	t.Run("it should fail the exchange without the matching code verifier", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		_, stored := start(t, ctrl, mockRedisRepo)
+		idp.codeChallenge = "challenge-of-another-flow"
+
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+		oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrOAuthExchangeFailed)
+	})
+
+	t.Run("it should reject a callback from a browser that did not start the flow", func(t *testing.T) {
+		for _, binding := range []string{"", "binding-of-another-browser"} {
+			ctrl := gomock.NewController(t)
+			mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+			_, stored := start(t, ctrl, mockRedisRepo)
+			mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(string(stored), nil)
+			oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+			user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", binding)
+			assert.Nil(t, user)
+			assert.ErrorIs(t, err, services.ErrInvalidOAuthState)
+			ctrl.Finish()
+		}
+	})
+
+	t.Run("it should reject an unknown or reused state", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().GetDel("oauth-state:unknown").Return("", assert.AnError)
+		oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "unknown", "binding-value")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrInvalidOAuthState)
+	})
+
+	t.Run("it should reject a state issued for another provider", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().GetDel("oauth-state:state-value").Return(`{"provider":"other"}`, nil)
+		oauthService := services.NewOAuthService(registry, nil, nil, mockRedisRepo, hashing(ctrl), 10*time.Minute)
+		user, err := oauthService.Callback(context.Background(), "stub", idp.code, "state-value", "binding-value")
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, services.ErrInvalidOAuthState)
+	})
+
+	t.Run("it should fail for providers missing from the registry", func(t *testing.T) {
+		oauthService := services.NewOAuthService(registry, nil, nil, nil, nil, 10*time.Minute)
+		_, _, err := oauthService.Start(context.Background(), "unknown")
+		assert.ErrorIs(t, err, utils.ErrUnknownOAuthProvider)
+	})
+}
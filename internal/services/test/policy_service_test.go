@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"my-go-api/internal/config"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/policy"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const ownAccountPolicy = `
+policies:
+  - id: own-account
+    effect: allow
+    actions: ["user:*"]
+    resources: ["user"]
+    conditions:
+      - attribute: resource.owner
+        operator: eq
+        value_from: subject.user_id
+`
+
+func TestPolicyServiceReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPolicyRepo := mocks.NewMockIPolicyRepository(ctrl)
+	userId := uuid.New()
+	req := policy.Request{
+		Subject:  policy.Subject{UserId: userId},
+		Action:   policy.ReadUser,
+		Resource: policy.Resource{Type: policy.ResourceUser, Owner: userId},
+	}
+
+	t.Run("it should deny everything until policies are loaded", func(t *testing.T) {
+		service := services.NewPolicyService(mockPolicyRepo, mock_services.NewMockIMfaService(ctrl), config.PolicyConfig{Source: "file"})
+		assert.Equal(t, policy.Decision{Reason: "access policies are not loaded"}, service.Evaluate(req))
+	})
+
+	t.Run("it should load policies from the file and keep them when a reload fails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "policies.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte(ownAccountPolicy), 0o600))
+		service := services.NewPolicyService(mockPolicyRepo, mock_services.NewMockIMfaService(ctrl), config.PolicyConfig{Source: "file", File: path})
+
+		assert.NoError(t, service.Reload(context.Background()))
+		assert.True(t, service.Evaluate(req).Allowed)
+
+		assert.NoError(t, os.WriteFile(path, []byte("policies:\n  - id: broken\n    effect: perhaps\n"), 0o600))
+		assert.Error(t, service.Reload(context.Background()))
+		assert.True(t, service.Evaluate(req).Allowed)
+
+		assert.NoError(t, os.WriteFile(path, []byte("policies: []\n"), 0o600))
+		assert.NoError(t, service.Reload(context.Background()))
+		assert.False(t, service.Evaluate(req).Allowed)
+	})
+
+	t.Run("it should load policies from the database", func(t *testing.T) {
+		service := services.NewPolicyService(mockPolicyRepo, mock_services.NewMockIMfaService(ctrl), config.PolicyConfig{Source: "database"})
+		rules, _ := policy.ParseYAML([]byte(ownAccountPolicy))
+		mockPolicyRepo.EXPECT().ListPolicies(gomock.Any()).Return(rules, nil)
+
+		assert.NoError(t, service.Reload(context.Background()))
+		assert.Equal(t, policy.Decision{Allowed: true, Policy: "own-account"}, service.Evaluate(req))
+	})
+
+	t.Run("it should reload until the context is done", func(t *testing.T) {
+		service := services.NewPolicyService(mockPolicyRepo, mock_services.NewMockIMfaService(ctrl), config.PolicyConfig{Source: "database"})
+		ctx, cancel := context.WithCancel(context.Background())
+		reloaded := make(chan struct{})
+		mockPolicyRepo.EXPECT().ListPolicies(gomock.Any()).DoAndReturn(func(context.Context) ([]policy.Rule, error) {
+			cancel()
+			close(reloaded)
+			return nil, nil
+		})
+
+		done := make(chan struct{})
+		go func() {
+			service.ReloadEvery(ctx, time.Millisecond)
+			close(done)
+		}()
+		<-reloaded
+		<-done
+	})
+}
+
+func TestPolicyServiceAuthorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPolicyRepo := mocks.NewMockIPolicyRepository(ctrl)
+	mockMfaService := mock_services.NewMockIMfaService(ctrl)
+	service := services.NewPolicyService(mockPolicyRepo, mockMfaService, config.PolicyConfig{Source: "database"})
+	mockPolicyRepo.EXPECT().ListPolicies(gomock.Any()).Return([]policy.Rule{{
+		ID:        "acme-with-mfa",
+		Effect:    policy.EffectAllow,
+		Actions:   []string{"*"},
+		Resources: []string{"*"},
+		Conditions: []policy.Condition{
+			{Attribute: "subject.org", Operator: "eq", Value: "acme"},
+			{Attribute: "context.mfa_level", Operator: "gte", Value: 2},
+			{Attribute: "context.hour", Operator: "gte", Value: 0},
+		},
+	}}, nil)
+	assert.NoError(t, service.Reload(context.Background()))
+
+	ctx := context.Background()
+	userId := uuid.New()
+	resource := policy.Resource{Type: policy.ResourceUser}
+
+	t.Run("it should decide with the attributes of the user and their account", func(t *testing.T) {
+		mockPolicyRepo.EXPECT().GetSubject(gomock.Any(), userId).Return(&policy.Subject{UserId: userId, Role: "user", Org: "acme"}, nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), userId).Return(true, nil)
+
+		decision, err := service.Authorize(ctx, userId, policy.ReadUser, resource, policy.Context{})
+		assert.NoError(t, err)
+		assert.Equal(t, policy.Decision{Allowed: true, Policy: "acme-with-mfa"}, decision)
+	})
+
+	t.Run("it should keep the MFA level given by the caller", func(t *testing.T) {
+		mockPolicyRepo.EXPECT().GetSubject(gomock.Any(), userId).Return(&policy.Subject{UserId: userId, Role: "user", Org: "acme"}, nil)
+
+		decision, err := service.Authorize(ctx, userId, policy.ReadUser, resource, policy.Context{MfaLevel: policy.MfaLevelSingle})
+		assert.NoError(t, err)
+		assert.False(t, decision.Allowed)
+	})
+
+	t.Run("it should return ErrUserNotFound for unknown users", func(t *testing.T) {
+		mockPolicyRepo.EXPECT().GetSubject(gomock.Any(), userId).Return(nil, sql.ErrNoRows)
+
+		_, err := service.Authorize(ctx, userId, policy.ReadUser, resource, policy.Context{})
+		assert.ErrorIs(t, err, services.ErrUserNotFound)
+	})
+
+	t.Run("it should fail when the MFA status cannot be read", func(t *testing.T) {
+		mockPolicyRepo.EXPECT().GetSubject(gomock.Any(), userId).Return(&policy.Subject{UserId: userId, Org: "acme"}, nil)
+		mockMfaService.EXPECT().IsEnabled(gomock.Any(), userId).Return(false, errors.New("db down"))
+
+		_, err := service.Authorize(ctx, userId, policy.ReadUser, resource, policy.Context{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"my-go-api/internal/dto"
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var knownPermissions = []models.Permission{{Name: "users:read"}, {Name: "users:write"}, {Name: "roles:write"}}
+
+func TestCreateRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRoleRepo := mocks.NewMockIRoleRepository(ctrl)
+	service := services.NewRBACService(mockRoleRepo, mocks.NewMockIUserRepository(ctrl))
+	ctx := context.Background()
+	mockRoleRepo.EXPECT().ListPermissions(gomock.Any()).Return(knownPermissions, nil).AnyTimes()
+
+	t.Run("it should create a role with its permissions sorted once each", func(t *testing.T) {
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "support").Return(nil, sql.ErrNoRows)
+		mockRoleRepo.EXPECT().CreateRole(gomock.Any(), &models.Role{Name: "support", Permissions: []string{"users:read", "users:write"}}).Return(nil)
+
+		role, err := service.CreateRole(ctx, dto.CreateRole{Name: "support", Permissions: []string{"users:write", "users:read", "users:write"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"users:read", "users:write"}, role.Permissions)
+	})
+
+	t.Run("it should reject unknown permissions", func(t *testing.T) {
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "support").Return(nil, sql.ErrNoRows)
+		_, err := service.CreateRole(ctx, dto.CreateRole{Name: "support", Permissions: []string{"everything:write"}})
+		assert.ErrorIs(t, err, services.ErrUnknownPermission)
+	})
+
+	t.Run("it should not create a role twice", func(t *testing.T) {
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "admin").Return(&models.Role{Name: "admin"}, nil)
+		_, err := service.CreateRole(ctx, dto.CreateRole{Name: "admin"})
+		assert.ErrorIs(t, err, services.ErrRoleExists)
+	})
+}
+
+func TestManageRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRoleRepo := mocks.NewMockIRoleRepository(ctrl)
+	service := services.NewRBACService(mockRoleRepo, mocks.NewMockIUserRepository(ctrl))
+	ctx := context.Background()
+
+	t.Run("it should keep every permission on the admin role", func(t *testing.T) {
+		_, err := service.SetRolePermissions(ctx, "admin", nil)
+		assert.ErrorIs(t, err, services.ErrBuiltInRole)
+	})
+
+	t.Run("it should replace the permissions of other roles", func(t *testing.T) {
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "user").Return(&models.Role{Name: "user"}, nil)
+		mockRoleRepo.EXPECT().ListPermissions(gomock.Any()).Return(knownPermissions, nil)
+		mockRoleRepo.EXPECT().SetPermissions(gomock.Any(), "user", []string{"users:read"}).Return(nil)
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "user").Return(&models.Role{Name: "user", Permissions: []string{"users:read"}}, nil)
+
+		role, err := service.SetRolePermissions(ctx, "user", []string{"users:read"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"users:read"}, role.Permissions)
+	})
+
+	t.Run("it should not delete built-in roles or roles in use", func(t *testing.T) {
+		assert.ErrorIs(t, service.DeleteRole(ctx, "user"), services.ErrBuiltInRole)
+		assert.ErrorIs(t, service.DeleteRole(ctx, "admin"), services.ErrBuiltInRole)
+
+		mockRoleRepo.EXPECT().CountUsers(gomock.Any(), "support").Return(2, nil)
+		assert.ErrorIs(t, service.DeleteRole(ctx, "support"), services.ErrRoleInUse)
+	})
+
+	t.Run("it should report unknown roles", func(t *testing.T) {
+		mockRoleRepo.EXPECT().CountUsers(gomock.Any(), "unknown").Return(0, nil)
+		mockRoleRepo.EXPECT().DeleteRole(gomock.Any(), "unknown").Return(sql.ErrNoRows)
+		assert.ErrorIs(t, service.DeleteRole(ctx, "unknown"), services.ErrRoleNotFound)
+	})
+}
+
+func TestAssignRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRoleRepo := mocks.NewMockIRoleRepository(ctrl)
+	mockUserRepo := mocks.NewMockIUserRepository(ctrl)
+	service := services.NewRBACService(mockRoleRepo, mockUserRepo)
+	ctx := context.Background()
+	userId := uuid.New()
+	mockRoleRepo.EXPECT().GetRole(gomock.Any(), "admin").Return(&models.Role{Name: "admin"}, nil).AnyTimes()
+	mockRoleRepo.EXPECT().GetRole(gomock.Any(), "user").Return(&models.Role{Name: "user"}, nil).AnyTimes()
+
+	t.Run("it should assign the role", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(&models.User{ID: userId, Role: "user"}, nil)
+		mockRoleRepo.EXPECT().AssignRole(gomock.Any(), userId, "admin").Return(nil)
+		assert.NoError(t, service.AssignRole(ctx, userId, "admin"))
+	})
+
+	t.Run("it should not demote the last admin", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(&models.User{ID: userId, Role: "admin"}, nil)
+		mockRoleRepo.EXPECT().CountUsers(gomock.Any(), "admin").Return(1, nil)
+		assert.ErrorIs(t, service.AssignRole(ctx, userId, "user"), services.ErrLastAdmin)
+	})
+
+	t.Run("it should demote an admin while others remain", func(t *testing.T) {
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(&models.User{ID: userId, Role: "admin"}, nil)
+		mockRoleRepo.EXPECT().CountUsers(gomock.Any(), "admin").Return(2, nil)
+		mockRoleRepo.EXPECT().AssignRole(gomock.Any(), userId, "user").Return(nil)
+		assert.NoError(t, service.AssignRole(ctx, userId, "user"))
+	})
+
+	t.Run("it should report unknown roles and users", func(t *testing.T) {
+		mockRoleRepo.EXPECT().GetRole(gomock.Any(), "owner").Return(nil, sql.ErrNoRows)
+		assert.ErrorIs(t, service.AssignRole(ctx, userId, "owner"), services.ErrRoleNotFound)
+
+		mockUserRepo.EXPECT().GetById(gomock.Any(), userId).Return(nil, sql.ErrNoRows)
+		assert.ErrorIs(t, service.AssignRole(ctx, userId, "admin"), services.ErrUserNotFound)
+	})
+}
+
+func TestHasPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRoleRepo := mocks.NewMockIRoleRepository(ctrl)
+	service := services.NewRBACService(mockRoleRepo, mocks.NewMockIUserRepository(ctrl))
+	userId := uuid.New()
+	mockRoleRepo.EXPECT().PermissionsOfUser(gomock.Any(), userId).Return([]string{"users:read"}, nil).Times(2)
+
+	allowed, err := service.HasPermission(context.Background(), userId, "users:read")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.HasPermission(context.Background(), userId, "users:write")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
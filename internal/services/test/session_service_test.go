@@ -0,0 +1,125 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/mocks/mock_services"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeSession(t *testing.T) {
+	userId := uuid.New()
+	deviceId := uuid.New()
+
+	t.Run("it should fail for a device without a session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockSessionStore.EXPECT().Get(gomock.Any(), userId, deviceId).Return(nil, repositories.ErrSessionNotFound)
+		sessionService := services.NewSessionService(mockSessionStore, nil)
+		err := sessionService.RevokeSession(context.Background(), userId, deviceId)
+		assert.ErrorIs(t, err, services.ErrSessionNotFound)
+	})
+
+	t.Run("it should remove the session of the device", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		token := &models.Token{UserId: userId, DeviceId: deviceId, Jti: uuid.New(), LastUsedAt: time.Now()}
+		mockSessionStore.EXPECT().Get(gomock.Any(), userId, deviceId).Return(token, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), token).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, deviceId).Return(nil)
+		sessionService := services.NewSessionService(mockSessionStore, mockDenylist)
+		err := sessionService.RevokeSession(context.Background(), userId, deviceId)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	userId := uuid.New()
+	current := uuid.New()
+	others := []uuid.UUID{uuid.New(), uuid.New()}
+
+	t.Run("it should keep the current device signed in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		now := time.Now()
+		tokens := []models.Token{
+			{UserId: userId, DeviceId: others[0], Jti: uuid.New(), LastUsedAt: now},
+			{UserId: userId, DeviceId: current, Jti: uuid.New(), LastUsedAt: now},
+			{UserId: userId, DeviceId: others[1], Jti: uuid.New(), LastUsedAt: now},
+		}
+		mockSessionStore.EXPECT().ListByUser(gomock.Any(), userId).Return(tokens, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), &tokens[0]).Return(nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), &tokens[2]).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, others[0]).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, others[1]).Return(nil)
+		sessionService := services.NewSessionService(mockSessionStore, mockDenylist)
+		err := sessionService.RevokeOtherSessions(context.Background(), userId, current)
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should fail when the sessions cannot be listed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockSessionStore.EXPECT().ListByUser(gomock.Any(), userId).Return(nil, errors.New("some errors"))
+		sessionService := services.NewSessionService(mockSessionStore, nil)
+		err := sessionService.RevokeOtherSessions(context.Background(), userId, current)
+		assert.Error(t, err)
+	})
+}
+
+func TestRevokeSessionsExceptToken(t *testing.T) {
+	userId := uuid.New()
+	now := time.Now()
+	current := models.Token{UserId: userId, DeviceId: uuid.New(), FamilyId: uuid.New(), Jti: uuid.New(), LastUsedAt: now}
+	other := models.Token{UserId: userId, DeviceId: uuid.New(), FamilyId: uuid.New(), Jti: uuid.New(), LastUsedAt: now}
+	rotated := models.Token{UserId: userId, DeviceId: current.DeviceId, FamilyId: current.FamilyId, Jti: uuid.New()}
+
+	t.Run("it should keep the device the token was issued to", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		tokens := []models.Token{other, current}
+		mockSessionStore.EXPECT().ListByUser(gomock.Any(), userId).Return(tokens, nil).Times(2)
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), other.FamilyId).Return([]models.Token{other}, nil)
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), current.FamilyId).Return([]models.Token{rotated, current}, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, other.DeviceId).Return(nil)
+		sessionService := services.NewSessionService(mockSessionStore, mockDenylist)
+		err := sessionService.RevokeSessionsExceptToken(context.Background(), userId, rotated.Jti)
+		assert.NoError(t, err)
+	})
+
+	t.Run("it should sign every device out when no session issued the token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		mockDenylist := mock_services.NewMockITokenDenylist(ctrl)
+		tokens := []models.Token{other, current}
+		mockSessionStore.EXPECT().ListByUser(gomock.Any(), userId).Return(tokens, nil).Times(2)
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), other.FamilyId).Return([]models.Token{other}, nil)
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), current.FamilyId).Return([]models.Token{current}, nil)
+		mockDenylist.EXPECT().RevokeSession(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, other.DeviceId).Return(nil)
+		mockSessionStore.EXPECT().Remove(gomock.Any(), userId, current.DeviceId).Return(nil)
+		sessionService := services.NewSessionService(mockSessionStore, mockDenylist)
+		err := sessionService.RevokeSessionsExceptToken(context.Background(), userId, uuid.New())
+		assert.NoError(t, err)
+	})
+}
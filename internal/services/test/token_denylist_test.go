@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenDenylistRevoke(t *testing.T) {
+	t.Run("it should store the jti until the token expires", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		jti := uuid.New()
+		mockRedisRepo.EXPECT().Set("revoked-jti:"+jti.String(), 1, gomock.Any()).DoAndReturn(func(key string, value any, ttl time.Duration) error {
+			assert.InDelta(t, float64(30*time.Minute), float64(ttl), float64(time.Second))
+			return nil
+		})
+		denylist := services.NewTokenDenylist(mockRedisRepo, nil, time.Hour, time.Minute)
+		err := denylist.Revoke(context.Background(), jti, time.Now().Add(30*time.Minute))
+		assert.NoError(t, err)
+
+		// revoked answers are served from memory afterwards
+		revoked, err := denylist.IsRevoked(context.Background(), jti)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("it should skip tokens that already expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		denylist := services.NewTokenDenylist(mockRedisRepo, nil, time.Hour, time.Minute)
+		err := denylist.Revoke(context.Background(), uuid.New(), time.Now().Add(-time.Minute))
+		assert.NoError(t, err)
+	})
+}
+
+func TestTokenDenylistIsRevoked(t *testing.T) {
+	t.Run("it should cache the answer from redis", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		revokedJti := uuid.New()
+		activeJti := uuid.New()
+		mockRedisRepo.EXPECT().Exists("revoked-jti:"+revokedJti.String()).Return(true, nil).Times(1)
+		mockRedisRepo.EXPECT().Exists("revoked-jti:"+activeJti.String()).Return(false, nil).Times(1)
+		denylist := services.NewTokenDenylist(mockRedisRepo, nil, time.Hour, time.Minute)
+		for i := 0; i < 2; i++ {
+			revoked, err := denylist.IsRevoked(context.Background(), revokedJti)
+			assert.NoError(t, err)
+			assert.True(t, revoked)
+			revoked, err = denylist.IsRevoked(context.Background(), activeJti)
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+	})
+
+	t.Run("it should ask redis again once a negative answer is stale", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		jti := uuid.New()
+		gomock.InOrder(
+			mockRedisRepo.EXPECT().Exists("revoked-jti:"+jti.String()).Return(false, nil),
+			mockRedisRepo.EXPECT().Exists("revoked-jti:"+jti.String()).Return(true, nil),
+		)
+		denylist := services.NewTokenDenylist(mockRedisRepo, nil, time.Hour, 0)
+		revoked, _ := denylist.IsRevoked(context.Background(), jti)
+		assert.False(t, revoked)
+		revoked, _ = denylist.IsRevoked(context.Background(), jti)
+		assert.True(t, revoked)
+	})
+
+	t.Run("it should fail when redis is unavailable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockRedisRepo.EXPECT().Exists(gomock.Any()).Return(false, errors.New("connection refused"))
+		denylist := services.NewTokenDenylist(mockRedisRepo, nil, time.Hour, time.Minute)
+		_, err := denylist.IsRevoked(context.Background(), uuid.New())
+		assert.Error(t, err)
+	})
+}
+
+func TestTokenDenylistRevokeSession(t *testing.T) {
+	t.Run("it should revoke the access tokens of earlier rotations of the family", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		familyId := uuid.New()
+		now := time.Now()
+		expired := models.Token{Jti: uuid.New(), FamilyId: familyId, IsRevoked: true, LastUsedAt: now.Add(-2 * time.Hour)}
+		rotated := models.Token{Jti: uuid.New(), FamilyId: familyId, IsRevoked: true, LastUsedAt: now.Add(-20 * time.Minute)}
+		current := models.Token{Jti: uuid.New(), FamilyId: familyId, LastUsedAt: now}
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), familyId).Return([]models.Token{expired, rotated, current}, nil)
+		mockRedisRepo.EXPECT().Set("revoked-jti:"+rotated.Jti.String(), 1, gomock.Any()).Return(nil)
+		mockRedisRepo.EXPECT().Set("revoked-jti:"+current.Jti.String(), 1, gomock.Any()).Return(nil)
+		denylist := services.NewTokenDenylist(mockRedisRepo, mockSessionStore, time.Hour, time.Minute)
+		assert.NoError(t, denylist.RevokeSession(context.Background(), &current))
+	})
+
+	t.Run("it should revoke the session's own token when the family is gone", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+		mockSessionStore := mocks.NewMockISessionStore(ctrl)
+		current := models.Token{Jti: uuid.New(), FamilyId: uuid.New(), LastUsedAt: time.Now()}
+		mockSessionStore.EXPECT().ListFamily(gomock.Any(), current.FamilyId).Return([]models.Token{}, nil)
+		mockRedisRepo.EXPECT().Set("revoked-jti:"+current.Jti.String(), 1, gomock.Any()).Return(nil)
+		denylist := services.NewTokenDenylist(mockRedisRepo, mockSessionStore, time.Hour, time.Minute)
+		assert.NoError(t, denylist.RevokeSession(context.Background(), &current))
+	})
+}
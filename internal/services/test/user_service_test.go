@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"my-go-api/internal/mocks"
 	"my-go-api/internal/models"
@@ -18,7 +19,8 @@ func TestUserService(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockIUserRepository(ctrl)
-	userService := services.NewUserService(mockRepo)
+	mockUtils := mocks.NewMockIUtils(ctrl)
+	userService := services.NewUserService(mockRepo, mockUtils)
 
 	ctx := context.Background()
 	userID := uuid.New()
@@ -46,6 +48,21 @@ func TestUserService(t *testing.T) {
 		assert.Equal(t, mockUser.ID, users[0].ID)
 	})
 
+	t.Run("ChangePassword - stores the hash", func(t *testing.T) {
+		mockUtils.EXPECT().HashPassword("N3wPassword").Return("hashed", nil)
+		mockRepo.EXPECT().UpdatePassword(ctx, userID, "hashed").Return(nil)
+		assert.NoError(t, userService.ChangePassword(ctx, userID, "N3wPassword"))
+	})
+
+	t.Run("CheckEmailAvailable - free, own or taken", func(t *testing.T) {
+		mockRepo.EXPECT().GetByEmail(ctx, "free@example.com").Return(nil, sql.ErrNoRows)
+		assert.NoError(t, userService.CheckEmailAvailable(ctx, userID, "free@example.com"))
+		mockRepo.EXPECT().GetByEmail(ctx, "own@example.com").Return(mockUser, nil)
+		assert.NoError(t, userService.CheckEmailAvailable(ctx, userID, "own@example.com"))
+		mockRepo.EXPECT().GetByEmail(ctx, "taken@example.com").Return(&models.User{ID: uuid.New()}, nil)
+		assert.ErrorIs(t, userService.CheckEmailAvailable(ctx, userID, "taken@example.com"), services.ErrEmailInUse)
+	})
+
 	t.Run("GetUserById - not found", func(t *testing.T) {
 		mockRepo.EXPECT().GetById(ctx, userID).Return(nil, errors.New("user not found"))
 		user, err := userService.GetUserById(ctx, userID)
@@ -0,0 +1,272 @@
+package services_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"my-go-api/internal/mocks"
+	"my-go-api/internal/models"
+	"my-go-api/internal/services"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:3000"
+)
+
+// softAuthenticator is a passkey in memory that answers WebAuthn ceremonies the
+// way a browser and platform authenticator would.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialId []byte
+	userHandle   []byte
+	counter      uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	credentialId := make([]byte, 16)
+	rand.Read(credentialId)
+	return &softAuthenticator{key: key, credentialId: credentialId, origin: testOrigin}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": a.origin})
+	assert.NoError(t, err)
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIdHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	return append(data, attested...)
+}
+
+// create answers navigator.credentials.create with a "none" attestation.
+func (a *softAuthenticator) create(t *testing.T, challenge string, userHandle []byte) []byte {
+	a.userHandle = userHandle
+	coseKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	assert.NoError(t, err)
+	attested := make([]byte, 16) // zero aaguid
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialId)))
+	attested = append(attested, a.credentialId...)
+	attested = append(attested, coseKey...)
+	attestation, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": a.authData(0x01|0x04|0x40, attested),
+	})
+	assert.NoError(t, err)
+	response, err := json.Marshal(map[string]any{
+		"id":    b64(a.credentialId),
+		"rawId": b64(a.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(a.clientData(t, "webauthn.create", challenge)),
+			"attestationObject": b64(attestation),
+		},
+	})
+	assert.NoError(t, err)
+	return response
+}
+
+// get answers navigator.credentials.get, signing with the next counter value.
+func (a *softAuthenticator) get(t *testing.T, challenge string) []byte {
+	a.counter++
+	clientData := a.clientData(t, "webauthn.get", challenge)
+	authData := a.authData(0x01|0x04, nil)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	assert.NoError(t, err)
+	response, err := json.Marshal(map[string]any{
+		"id":    b64(a.credentialId),
+		"rawId": b64(a.credentialId),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    b64(clientData),
+			"authenticatorData": b64(authData),
+			"signature":         b64(signature),
+			"userHandle":        b64(a.userHandle),
+		},
+	})
+	assert.NoError(t, err)
+	return response
+}
+
+type webAuthnFixture struct {
+	service     services.IWebAuthnService
+	repo        *mocks.MockIWebAuthnRepository
+	credentials []models.WebAuthnCredential
+}
+
+func newWebAuthnFixture(t *testing.T, ctrl *gomock.Controller) *webAuthnFixture {
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "my-go-api",
+		RPOrigins:     []string{testOrigin},
+	})
+	assert.NoError(t, err)
+
+	f := &webAuthnFixture{repo: mocks.NewMockIWebAuthnRepository(ctrl)}
+	f.repo.EXPECT().GetByUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error) {
+		var owned []models.WebAuthnCredential
+		for _, credential := range f.credentials {
+			if credential.UserId == userId {
+				owned = append(owned, credential)
+			}
+		}
+		return owned, nil
+	}).AnyTimes()
+
+	store := map[string]string{}
+	mockRedisRepo := mocks.NewMockIRedisRepository(ctrl)
+	mockRedisRepo.EXPECT().Set(gomock.Any(), gomock.Any(), 5*time.Minute).DoAndReturn(func(key string, value any, expiry time.Duration) error {
+		store[key] = string(value.([]byte))
+		return nil
+	}).AnyTimes()
+	mockRedisRepo.EXPECT().GetDel(gomock.Any()).DoAndReturn(func(key string) (string, error) {
+		value, ok := store[key]
+		if !ok {
+			return "", errors.New("key not found")
+		}
+		delete(store, key)
+		return value, nil
+	}).AnyTimes()
+
+	mockUtils := mocks.NewMockIUtils(ctrl)
+	fakeHash(mockUtils)
+	mockUtils.EXPECT().GenerateRandomBytes(32).DoAndReturn(func(size int) (string, error) {
+		return uuid.NewString(), nil
+	}).AnyTimes()
+
+	f.service = services.NewWebAuthnService(relyingParty, f.repo, mockRedisRepo, mockUtils, 5*time.Minute)
+	return f
+}
+
+// register runs a full registration ceremony and keeps the stored credential.
+func (f *webAuthnFixture) register(t *testing.T, user *models.User, authenticator *softAuthenticator) {
+	creation, err := f.service.BeginRegistration(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, testRPID, creation.Response.RelyingParty.ID)
+
+	f.repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, credential *models.WebAuthnCredential) error {
+		f.credentials = append(f.credentials, *credential)
+		return nil
+	})
+	response := authenticator.create(t, creation.Response.Challenge.String(), user.ID[:])
+	credential, err := f.service.FinishRegistration(context.Background(), user, "Laptop", response)
+	assert.NoError(t, err)
+	assert.Equal(t, authenticator.credentialId, credential.ID)
+	assert.Equal(t, "Laptop", credential.Name)
+}
+
+func TestWebAuthnPasswordlessLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newWebAuthnFixture(t, ctrl)
+	user := &models.User{ID: uuid.New(), Email: "john@example.com", Name: "John Doe"}
+	authenticator := newSoftAuthenticator(t)
+	f.register(t, user, authenticator)
+
+	t.Run("it should sign the owner of the passkey in", func(t *testing.T) {
+		login, err := f.service.BeginLogin(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, login.PublicKey.AllowedCredentials)
+
+		f.repo.EXPECT().UpdateAfterLogin(gomock.Any(), authenticator.credentialId, uint32(1), false).Return(nil)
+		userId, err := f.service.FinishLogin(context.Background(), login.Session, authenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userId)
+
+		_, err = f.service.FinishLogin(context.Background(), login.Session, authenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.ErrorIs(t, err, services.ErrWebAuthnCeremonyNotFound)
+	})
+
+	t.Run("it should reject an assertion from another origin", func(t *testing.T) {
+		login, err := f.service.BeginLogin(context.Background())
+		assert.NoError(t, err)
+		authenticator.origin = "https://evil.example.com"
+		defer func() { authenticator.origin = testOrigin }()
+
+		_, err = f.service.FinishLogin(context.Background(), login.Session, authenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.ErrorIs(t, err, services.ErrWebAuthnVerificationFailed)
+	})
+
+	t.Run("it should reject a cloned authenticator", func(t *testing.T) {
+		f.credentials[0].SignCount = 100
+		login, err := f.service.BeginLogin(context.Background())
+		assert.NoError(t, err)
+
+		_, err = f.service.FinishLogin(context.Background(), login.Session, authenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.ErrorIs(t, err, services.ErrWebAuthnVerificationFailed)
+	})
+}
+
+func TestWebAuthnSecondFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	f := newWebAuthnFixture(t, ctrl)
+	user := &models.User{ID: uuid.New(), Email: "john@example.com"}
+	other := &models.User{ID: uuid.New(), Email: "jane@example.com"}
+	authenticator := newSoftAuthenticator(t)
+	otherAuthenticator := newSoftAuthenticator(t)
+
+	t.Run("it should fail for a user without passkeys", func(t *testing.T) {
+		_, err := f.service.BeginSecondFactor(context.Background(), user.ID)
+		assert.ErrorIs(t, err, services.ErrWebAuthnCredentialNotFound)
+	})
+
+	f.register(t, user, authenticator)
+	f.register(t, other, otherAuthenticator)
+
+	t.Run("it should only allow the passkeys of the user", func(t *testing.T) {
+		login, err := f.service.BeginSecondFactor(context.Background(), user.ID)
+		assert.NoError(t, err)
+		if assert.Len(t, login.PublicKey.AllowedCredentials, 1) {
+			assert.Equal(t, authenticator.credentialId, []byte(login.PublicKey.AllowedCredentials[0].CredentialID))
+		}
+
+		_, err = f.service.FinishLogin(context.Background(), login.Session, otherAuthenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.ErrorIs(t, err, services.ErrWebAuthnVerificationFailed)
+	})
+
+	t.Run("it should return the user after a valid assertion", func(t *testing.T) {
+		login, err := f.service.BeginSecondFactor(context.Background(), user.ID)
+		assert.NoError(t, err)
+
+		f.repo.EXPECT().UpdateAfterLogin(gomock.Any(), authenticator.credentialId, uint32(1), false).Return(nil)
+		userId, err := f.service.FinishLogin(context.Background(), login.Session, authenticator.get(t, login.PublicKey.Challenge.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, userId)
+	})
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ITokenDenylist remembers access tokens, by jti, that were revoked before they expired.
+type ITokenDenylist interface {
+	Revoke(ctx context.Context, jti uuid.UUID, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, token *models.Token) error
+	IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+}
+
+type denylistEntry struct {
+	revoked bool
+	until   time.Time
+}
+
+// maxDenylistCacheEntries bounds the in-process cache, expired entries are
+// swept once it grows past this size.
+const maxDenylistCacheEntries = 10000
+
+type tokenDenylist struct {
+	redisRepo      repositories.IRedisRepository
+	sessions       repositories.ISessionStore
+	accessTokenTTL time.Duration
+	// cacheTTL is how long a "not revoked" answer is trusted without asking
+	// Redis again, so a revocation made on another instance takes up to this
+	// long to be enforced here. Revoked answers are cached until the token expires.
+	cacheTTL time.Duration
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]denylistEntry
+}
+
+func NewTokenDenylist(redisRepo repositories.IRedisRepository, sessions repositories.ISessionStore, accessTokenTTL, cacheTTL time.Duration) ITokenDenylist {
+	return &tokenDenylist{
+		redisRepo:      redisRepo,
+		sessions:       sessions,
+		accessTokenTTL: accessTokenTTL,
+		cacheTTL:       cacheTTL,
+		cache:          make(map[uuid.UUID]denylistEntry),
+	}
+}
+
+func denylistKey(jti uuid.UUID) string {
+	return fmt.Sprintf("revoked-jti:%s", jti)
+}
+
+// Revoke denies the token until expiresAt, after which it is rejected as expired anyway.
+func (d *tokenDenylist) Revoke(ctx context.Context, jti uuid.UUID, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if jti == uuid.Nil || ttl <= 0 {
+		return nil
+	}
+	if err := d.redisRepo.Set(denylistKey(jti), 1, ttl); err != nil {
+		return err
+	}
+	d.remember(jti, denylistEntry{revoked: true, until: expiresAt})
+	return nil
+}
+
+func (d *tokenDenylist) IsRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	now := time.Now()
+	d.mu.Lock()
+	entry, ok := d.cache[jti]
+	d.mu.Unlock()
+	if ok && now.Before(entry.until) {
+		return entry.revoked, nil
+	}
+	revoked, err := d.redisRepo.Exists(denylistKey(jti))
+	if err != nil {
+		return false, err
+	}
+	until := now.Add(d.cacheTTL)
+	if revoked {
+		until = now.Add(d.accessTokenTTL)
+	}
+	d.remember(jti, denylistEntry{revoked: revoked, until: until})
+	return revoked, nil
+}
+
+func (d *tokenDenylist) remember(jti uuid.UUID, entry denylistEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.cache) >= maxDenylistCacheEntries {
+		now := time.Now()
+		for key, cached := range d.cache {
+			if !now.Before(cached.until) {
+				delete(d.cache, key)
+			}
+		}
+		if len(d.cache) >= maxDenylistCacheEntries {
+			d.cache = make(map[uuid.UUID]denylistEntry)
+		}
+	}
+	d.cache[jti] = entry
+}
+
+// RevokeSession denies every access token of the session's family that may
+// still be valid. Each was issued together with a refresh token of the family,
+// which the session store keeps after rotating it, so the access tokens of
+// earlier rotations are revoked too.
+func (d *tokenDenylist) RevokeSession(ctx context.Context, token *models.Token) error {
+	family, err := d.sessions.ListFamily(ctx, token.FamilyId)
+	if err != nil {
+		return err
+	}
+	revoked := false
+	for _, issued := range family {
+		if err := d.Revoke(ctx, issued.Jti, issued.LastUsedAt.Add(d.accessTokenTTL)); err != nil {
+			return err
+		}
+		revoked = revoked || issued.Jti == token.Jti
+	}
+	if revoked {
+		return nil
+	}
+	return d.Revoke(ctx, token.Jti, token.LastUsedAt.Add(d.accessTokenTTL))
+}
@@ -2,24 +2,32 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"my-go-api/internal/models"
 	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
 
 	"github.com/google/uuid"
 )
 
+var ErrEmailInUse = errors.New("this email is already used by another account")
+
 type IUserService interface {
 	UpdateUser(ctx context.Context, user *models.User) (*models.User, error)
 	GetUserById(ctx context.Context, userId uuid.UUID) (*models.User, error)
 	GetAllUsers(ctx context.Context) ([]models.User, error)
+	ChangePassword(ctx context.Context, userId uuid.UUID, password string) error
+	CheckEmailAvailable(ctx context.Context, userId uuid.UUID, email string) error
 }
 
 type userService struct {
 	userRepo repositories.IUserRepository
+	utility  utils.IUtils
 }
 
-func NewUserService(userRepo repositories.IUserRepository) IUserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repositories.IUserRepository, utility utils.IUtils) IUserService {
+	return &userService{userRepo: userRepo, utility: utility}
 }
 
 func (u *userService) UpdateUser(ctx context.Context, user *models.User) (*models.User, error) {
@@ -41,3 +49,28 @@ func (u *userService) GetUserById(ctx context.Context, userId uuid.UUID) (*model
 func (u *userService) GetAllUsers(ctx context.Context) ([]models.User, error) {
 	return u.userRepo.GetAll(ctx)
 }
+
+// ChangePassword stores the hash of the new password of the user.
+func (u *userService) ChangePassword(ctx context.Context, userId uuid.UUID, password string) error {
+	hash, err := u.utility.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return u.userRepo.UpdatePassword(ctx, userId, hash)
+}
+
+// CheckEmailAvailable fails with ErrEmailInUse when an account other than the
+// user's already has the email.
+func (u *userService) CheckEmailAvailable(ctx context.Context, userId uuid.UUID, email string) error {
+	owner, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if owner.ID != userId {
+		return ErrEmailInUse
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"my-go-api/internal/models"
+	"my-go-api/internal/repositories"
+	"my-go-api/internal/utils"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrWebAuthnCeremonyNotFound   = errors.New("passkey challenge expired or not started")
+	ErrWebAuthnVerificationFailed = errors.New("passkey verification failed")
+	ErrWebAuthnCredentialNotFound = errors.New("passkey not found")
+)
+
+// WebAuthnLogin is the challenge of a login ceremony. Session identifies the
+// ceremony and has to be sent back together with the signed assertion.
+type WebAuthnLogin struct {
+	Session   string                                     `json:"session"`
+	PublicKey protocol.PublicKeyCredentialRequestOptions `json:"publicKey"`
+}
+
+type IWebAuthnService interface {
+	BeginRegistration(ctx context.Context, user *models.User) (*protocol.CredentialCreation, error)
+	FinishRegistration(ctx context.Context, user *models.User, name string, response []byte) (*models.WebAuthnCredential, error)
+	BeginLogin(ctx context.Context) (*WebAuthnLogin, error)
+	BeginSecondFactor(ctx context.Context, userId uuid.UUID) (*WebAuthnLogin, error)
+	FinishLogin(ctx context.Context, session string, response []byte) (uuid.UUID, error)
+	ListCredentials(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error)
+	DeleteCredential(ctx context.Context, userId uuid.UUID, credentialId []byte) error
+}
+
+type webAuthnService struct {
+	relyingParty *webauthn.WebAuthn
+	webAuthnRepo repositories.IWebAuthnRepository
+	redisRepo    repositories.IRedisRepository
+	utility      utils.IUtils
+	challengeTTL time.Duration
+}
+
+func NewWebAuthnService(
+	relyingParty *webauthn.WebAuthn,
+	webAuthnRepo repositories.IWebAuthnRepository,
+	redisRepo repositories.IRedisRepository,
+	utility utils.IUtils,
+	challengeTTL time.Duration,
+) IWebAuthnService {
+	return &webAuthnService{
+		relyingParty: relyingParty,
+		webAuthnRepo: webAuthnRepo,
+		redisRepo:    redisRepo,
+		utility:      utility,
+		challengeTTL: challengeTTL,
+	}
+}
+
+// webAuthnUser adapts a user and their stored credentials to the webauthn library.
+// The user handle is the 16 bytes of the user id, so a discoverable credential
+// leads straight back to its owner.
+type webAuthnUser struct {
+	id          uuid.UUID
+	name        string
+	displayName string
+	credentials []models.WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return u.id[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.name
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.displayName
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+		for _, transport := range c.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+		credentials = append(credentials, webauthn.Credential{
+			ID:              c.ID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    c.AAGUID,
+				SignCount: c.SignCount,
+			},
+		})
+	}
+	return credentials
+}
+
+func (s *webAuthnService) loadUser(ctx context.Context, userId uuid.UUID) (*webAuthnUser, error) {
+	credentials, err := s.webAuthnRepo.GetByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{id: userId, credentials: credentials}, nil
+}
+
+// BeginRegistration asks the browser to create a discoverable credential, so the
+// passkey can later be used without typing an email. Credentials the user
+// already has are excluded to avoid registering the same authenticator twice.
+func (s *webAuthnService) BeginRegistration(ctx context.Context, user *models.User) (*protocol.CredentialCreation, error) {
+	waUser, err := s.loadUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	waUser.name = user.Email
+	waUser.displayName = user.Name
+	if waUser.displayName == "" {
+		waUser.displayName = user.Username
+	}
+	creation, session, err := s.relyingParty.BeginRegistration(
+		waUser,
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+		webauthn.WithExclusions(webauthn.Credentials(waUser.WebAuthnCredentials()).CredentialDescriptors()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storeSession(registrationKey(user.ID), session); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// FinishRegistration verifies the attestation against the pending registration
+// challenge of the user and stores the new credential.
+func (s *webAuthnService) FinishRegistration(ctx context.Context, user *models.User, name string, response []byte) (*models.WebAuthnCredential, error) {
+	session, err := s.takeSession(registrationKey(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+	waUser, err := s.loadUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := s.relyingParty.CreateCredential(waUser, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+	stored := &models.WebAuthnCredential{
+		ID:              credential.ID,
+		UserId:          user.ID,
+		Name:            name,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := s.webAuthnRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// BeginLogin starts a passwordless login, any passkey of any user may answer it.
+// User verification is required so the passkey alone counts as two factors.
+func (s *webAuthnService) BeginLogin(ctx context.Context) (*WebAuthnLogin, error) {
+	assertion, session, err := s.relyingParty.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s.startLogin(assertion, session)
+}
+
+// BeginSecondFactor starts a login that only the credentials of userId can
+// answer, used after the password was verified.
+func (s *webAuthnService) BeginSecondFactor(ctx context.Context, userId uuid.UUID) (*WebAuthnLogin, error) {
+	waUser, err := s.loadUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(waUser.credentials) == 0 {
+		return nil, ErrWebAuthnCredentialNotFound
+	}
+	assertion, session, err := s.relyingParty.BeginLogin(waUser)
+	if err != nil {
+		return nil, err
+	}
+	return s.startLogin(assertion, session)
+}
+
+func (s *webAuthnService) startLogin(assertion *protocol.CredentialAssertion, session *webauthn.SessionData) (*WebAuthnLogin, error) {
+	raw, err := s.utility.GenerateRandomBytes(32)
+	if err != nil {
+		return nil, errors.New("failed to generate webauthn session")
+	}
+	if err := s.storeSession(loginKey(s.utility.HashWithSHA256(raw)), session); err != nil {
+		return nil, err
+	}
+	return &WebAuthnLogin{Session: raw, PublicKey: assertion.Response}, nil
+}
+
+// FinishLogin verifies a signed assertion against a login ceremony and returns
+// the user the credential belongs to. A ceremony can be finished only once.
+func (s *webAuthnService) FinishLogin(ctx context.Context, session string, response []byte) (uuid.UUID, error) {
+	sessionData, err := s.takeSession(loginKey(s.utility.HashWithSHA256(session)))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+
+	var waUser *webAuthnUser
+	var credential *webauthn.Credential
+	if len(sessionData.UserID) == 0 {
+		var user webauthn.User
+		user, credential, err = s.relyingParty.ValidatePasskeyLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			userId, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, err
+			}
+			return s.loadUser(ctx, userId)
+		}, *sessionData, parsed)
+		if err == nil {
+			waUser = user.(*webAuthnUser)
+		}
+	} else {
+		userId, parseErr := uuid.FromBytes(sessionData.UserID)
+		if parseErr != nil {
+			return uuid.Nil, ErrWebAuthnCeremonyNotFound
+		}
+		if waUser, err = s.loadUser(ctx, userId); err != nil {
+			return uuid.Nil, err
+		}
+		credential, err = s.relyingParty.ValidateLogin(waUser, *sessionData, parsed)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrWebAuthnVerificationFailed, err)
+	}
+	if credential.Authenticator.CloneWarning {
+		log.Printf("webauthn: signature counter of credential %x of user %s went backwards, possible cloned authenticator", credential.ID, waUser.id)
+		return uuid.Nil, ErrWebAuthnVerificationFailed
+	}
+	if err := s.webAuthnRepo.UpdateAfterLogin(ctx, credential.ID, credential.Authenticator.SignCount, credential.Flags.BackupState); err != nil {
+		return uuid.Nil, err
+	}
+	return waUser.id, nil
+}
+
+func (s *webAuthnService) ListCredentials(ctx context.Context, userId uuid.UUID) ([]models.WebAuthnCredential, error) {
+	return s.webAuthnRepo.GetByUser(ctx, userId)
+}
+
+func (s *webAuthnService) DeleteCredential(ctx context.Context, userId uuid.UUID, credentialId []byte) error {
+	err := s.webAuthnRepo.Delete(ctx, userId, credentialId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return err
+}
+
+func (s *webAuthnService) storeSession(key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.redisRepo.Set(key, data, s.challengeTTL)
+}
+
+// takeSession consumes a stored ceremony so its challenge cannot be answered twice.
+func (s *webAuthnService) takeSession(key string) (*webauthn.SessionData, error) {
+	data, err := s.redisRepo.GetDel(key)
+	if err != nil {
+		log.Println(err.Error())
+		return nil, ErrWebAuthnCeremonyNotFound
+	}
+	var session webauthn.SessionData
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, ErrWebAuthnCeremonyNotFound
+	}
+	return &session, nil
+}
+
+func registrationKey(userId uuid.UUID) string {
+	return fmt.Sprintf("webauthn-registration:%s", userId)
+}
+
+func loginKey(sessionHash string) string {
+	return fmt.Sprintf("webauthn-login:%s", sessionHash)
+}
@@ -0,0 +1,124 @@
+package utils_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"my-go-api/internal/utils"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	t.Helper()
+	doc := map[string]any{
+		"keys": []utils.JWK{{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func signIDToken(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestIDTokenVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksPath := writeJWKS(t, "test-key", &key.PublicKey)
+	verifier := utils.NewIDTokenVerifier(
+		utils.NewJWKS(jwksPath, time.Hour),
+		[]string{"https://accounts.google.com"},
+		[]string{"client-id"},
+	)
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            "https://accounts.google.com",
+			"aud":            "client-id",
+			"sub":            "1234567890",
+			"email":          "john@example.com",
+			"email_verified": true,
+			"name":           "John Doe",
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	t.Run("it should accept a valid token", func(t *testing.T) {
+		claims, err := verifier.Verify(context.Background(), signIDToken(t, "test-key", key, validClaims()))
+		assert.NoError(t, err)
+		assert.Equal(t, "1234567890", claims.Subject)
+		assert.Equal(t, "john@example.com", claims.Email)
+		assert.True(t, bool(claims.EmailVerified))
+	})
+
+	t.Run("it should accept email_verified encoded as a string", func(t *testing.T) {
+		c := validClaims()
+		c["email_verified"] = "true"
+		claims, err := verifier.Verify(context.Background(), signIDToken(t, "test-key", key, c))
+		assert.NoError(t, err)
+		assert.True(t, bool(claims.EmailVerified))
+	})
+
+	tests := []struct {
+		name   string
+		kid    string
+		mutate func(jwt.MapClaims)
+	}{
+		{"wrong audience", "test-key", func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{"wrong issuer", "test-key", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{"expired", "test-key", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"missing expiry", "test-key", func(c jwt.MapClaims) { delete(c, "exp") }},
+		{"unknown key id", "other-key", func(c jwt.MapClaims) {}},
+	}
+	for _, tt := range tests {
+		t.Run("it should reject a token with "+tt.name, func(t *testing.T) {
+			c := validClaims()
+			tt.mutate(c)
+			claims, err := verifier.Verify(context.Background(), signIDToken(t, tt.kid, key, c))
+			assert.Nil(t, claims)
+			assert.ErrorIs(t, err, utils.ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("it should reject a token signed by another key", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := verifier.Verify(context.Background(), signIDToken(t, "test-key", otherKey, validClaims()))
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, utils.ErrInvalidIDToken)
+	})
+}
@@ -3,6 +3,7 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,34 +20,199 @@ const (
 	RefreshToken TokenType = "refresh"
 )
 
-var secretKey string
+var (
+	ErrAccessTokenMalformed        = errors.New("malformed token")
+	ErrAccessTokenInvalidSignature = errors.New("invalid token signature")
+	ErrAccessTokenExpired          = errors.New("token expired")
+	ErrAccessTokenNotYetValid      = errors.New("token not valid yet")
+	ErrAccessTokenInvalidIssuer    = errors.New("invalid token issuer")
+	ErrAccessTokenInvalidAudience  = errors.New("invalid token audience")
+	ErrAccessTokenInvalidClaims    = errors.New("invalid token claims")
+)
 
-func SetTokenSecretKey(key string) {
-	secretKey = key
+// AccessTokenClaims are the registered claims of our access tokens, the subject
+// is the user id and the jti identifies the token for revocation. Tokens issued
+// to an OAuth2 client also name the client and the scope it was granted; when
+// the client acts on its own behalf the client is the subject. Tokens of an
+// admin impersonating the subject name the admin in act.
+type AccessTokenClaims struct {
+	jwt.RegisteredClaims
+	ClientId string    `json:"client_id,omitempty"`
+	Scope    string    `json:"scope,omitempty"`
+	Act      *ActClaim `json:"act,omitempty"`
 }
 
-func (u *utility) GenerateToken(userId, jti uuid.UUID) (string, error) {
-	claims := jwt.MapClaims{
-		"userId": userId,
-		"jti":    jti,
-		"exp":    time.Now().Add(1 * time.Hour).UnixMilli(),
+// ActClaim is the actor claim of RFC 8693 section 4.1, the party acting on
+// behalf of the subject.
+type ActClaim struct {
+	Subject string `json:"sub"`
+}
+
+// Validate is called by the jwt parser after the registered claims were checked.
+func (c *AccessTokenClaims) Validate() error {
+	if _, err := uuid.Parse(c.Subject); err != nil {
+		return errors.New("sub is not a user id")
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return errors.New("jti is not a uuid")
+	}
+	if c.ClientId != "" {
+		if _, err := uuid.Parse(c.ClientId); err != nil {
+			return errors.New("client_id is not a uuid")
+		}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secretKey))
+	if c.Act != nil {
+		if _, err := uuid.Parse(c.Act.Subject); err != nil {
+			return errors.New("act.sub is not a user id")
+		}
+	}
+	return nil
+}
+
+// IsClientToken reports whether the token was issued to a client acting on its
+// own behalf rather than for a user.
+func (c *AccessTokenClaims) IsClientToken() bool {
+	return c.ClientId != "" && c.ClientId == c.Subject
+}
+
+// UserId returns the subject of claims returned by ValidateToken, uuid.Nil for
+// client tokens.
+func (c *AccessTokenClaims) UserId() uuid.UUID {
+	if c.IsClientToken() {
+		return uuid.Nil
+	}
+	id, _ := uuid.Parse(c.Subject)
+	return id
+}
+
+// OAuthClientId returns the client the token was issued to, uuid.Nil for tokens
+// of our own sessions.
+func (c *AccessTokenClaims) OAuthClientId() uuid.UUID {
+	id, _ := uuid.Parse(c.ClientId)
+	return id
+}
+
+// ActorId returns the admin impersonating the subject, uuid.Nil when the token
+// is the user's own.
+func (c *AccessTokenClaims) ActorId() uuid.UUID {
+	if c.Act == nil {
+		return uuid.Nil
+	}
+	id, _ := uuid.Parse(c.Act.Subject)
+	return id
+}
+
+// Jti returns the token id of claims returned by ValidateToken.
+func (c *AccessTokenClaims) Jti() uuid.UUID {
+	id, _ := uuid.Parse(c.ID)
+	return id
+}
+
+// UserClaims are the standard claims of OpenID Connect Core section 5.1 we
+// release about a user, each only when the scope asking for it was granted.
+type UserClaims struct {
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
 }
 
-func (u *utility) ValidateToken(tokenString string) (*jwt.MapClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
+// IssuedIDTokenClaims are the claims of the ID tokens we issue as an OpenID
+// provider, the audience is the client. IDTokenClaims are those of the ID
+// tokens we verify from other providers.
+type IssuedIDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce string `json:"nonce,omitempty"`
+	UserClaims
+}
+
+func (u *utility) GenerateToken(userId, jti uuid.UUID) (string, error) {
+	return u.keyring.Sign(u.accessTokenClaims(userId, jti))
+}
+
+// GenerateIDToken issues an OpenID Connect ID token about the user to a client.
+// The nonce of the authentication request is echoed back when there was one.
+func (u *utility) GenerateIDToken(userId, clientId uuid.UUID, nonce string, claims UserClaims) (string, error) {
+	return u.keyring.Sign(&IssuedIDTokenClaims{
+		RegisteredClaims: u.registeredClaims(userId, clientId.String(), uuid.New()),
+		Nonce:            nonce,
+		UserClaims:       claims,
 	})
-	if err != nil {
-		return nil, err
+}
+
+// GenerateScopedToken issues an access token to an OAuth2 client. The subject is
+// the user who authorized the client, or the client itself.
+func (u *utility) GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error) {
+	claims := u.accessTokenClaims(subject, jti)
+	claims.ClientId = clientId.String()
+	claims.Scope = scope
+	return u.keyring.Sign(claims)
+}
+
+// GenerateImpersonationToken issues an access token for the user to the admin
+// acting as them. It lives for ttl rather than the usual access token TTL.
+func (u *utility) GenerateImpersonationToken(userId, actorId, jti uuid.UUID, ttl time.Duration) (string, error) {
+	claims := u.accessTokenClaims(userId, jti)
+	claims.ExpiresAt = jwt.NewNumericDate(claims.IssuedAt.Add(ttl))
+	claims.Act = &ActClaim{Subject: actorId.String()}
+	return u.keyring.Sign(claims)
+}
+
+func (u *utility) accessTokenClaims(subject, jti uuid.UUID) *AccessTokenClaims {
+	return &AccessTokenClaims{RegisteredClaims: u.registeredClaims(subject, u.jwt.Audience, jti)}
+}
+
+func (u *utility) registeredClaims(subject uuid.UUID, audience string, jti uuid.UUID) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    u.jwt.Issuer,
+		Subject:   subject.String(),
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(u.jwt.AccessTokenTTL)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        jti.String(),
 	}
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, errors.New("unexpected signing method")
+}
+
+// ValidateToken verifies the signature and the registered claims, allowing
+// JWTConfig.Leeway of clock skew. Failures wrap one of the ErrAccessToken errors.
+func (u *utility) ValidateToken(tokenString string) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, u.keyring.Keyfunc,
+		jwt.WithValidMethods(u.keyring.ValidMethods()),
+		jwt.WithIssuer(u.jwt.Issuer),
+		jwt.WithAudience(u.jwt.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(u.jwt.Leeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", accessTokenError(err), err)
 	}
-	if claims, ok := token.Claims.(*jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	return claims, nil
+}
+
+func accessTokenError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrAccessTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrAccessTokenInvalidSignature
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrAccessTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrAccessTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrAccessTokenInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrAccessTokenInvalidAudience
+	default:
+		return ErrAccessTokenInvalidClaims
 	}
-	return nil, errors.New("invalid token")
+}
+
+// SigningAlgorithms lists the algorithms our tokens may be signed with.
+func (u *utility) SigningAlgorithms() []string {
+	return u.keyring.ValidMethods()
 }
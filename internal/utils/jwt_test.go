@@ -0,0 +1,177 @@
+package utils_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"my-go-api/internal/config"
+	"my-go-api/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessToken(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := utils.NewKeyring("test-key", key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtCfg := config.JWTConfig{
+		Issuer:         "https://api.example.com",
+		Audience:       "example-api",
+		AccessTokenTTL: 15 * time.Minute,
+		Leeway:         30 * time.Second,
+	}
+	utility := utils.NewUtilities(keyring, jwtCfg, "", config.GoogleOAuth2Config{}, nil)
+
+	t.Run("it should issue standard claims in seconds", func(t *testing.T) {
+		userId := uuid.New()
+		jti := uuid.New()
+		signed, err := utility.GenerateToken(userId, jti)
+		assert.NoError(t, err)
+
+		raw := jwt.MapClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(signed, raw)
+		assert.NoError(t, err)
+		assert.Equal(t, userId.String(), raw["sub"])
+		assert.Equal(t, jti.String(), raw["jti"])
+		assert.Equal(t, "https://api.example.com", raw["iss"])
+		assert.Equal(t, []any{"example-api"}, raw["aud"])
+		assert.InDelta(t, float64(time.Now().Add(15*time.Minute).Unix()), raw["exp"], 2)
+		assert.InDelta(t, float64(time.Now().Unix()), raw["iat"], 2)
+		assert.InDelta(t, float64(time.Now().Unix()), raw["nbf"], 2)
+
+		claims, err := utility.ValidateToken(signed)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, claims.UserId())
+		assert.Equal(t, jti, claims.Jti())
+	})
+
+	t.Run("it should name the client and scope of tokens issued to a client", func(t *testing.T) {
+		userId := uuid.New()
+		clientId := uuid.New()
+		signed, err := utility.GenerateScopedToken(userId, clientId, "openid read", uuid.New())
+		assert.NoError(t, err)
+
+		claims, err := utility.ValidateToken(signed)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, claims.UserId())
+		assert.Equal(t, clientId, claims.OAuthClientId())
+		assert.Equal(t, "openid read", claims.Scope)
+		assert.False(t, claims.IsClientToken())
+
+		signed, err = utility.GenerateScopedToken(clientId, clientId, "read", uuid.New())
+		assert.NoError(t, err)
+		claims, err = utility.ValidateToken(signed)
+		assert.NoError(t, err)
+		assert.True(t, claims.IsClientToken())
+		assert.Equal(t, uuid.Nil, claims.UserId())
+	})
+
+	t.Run("it should issue ID tokens to the client with the nonce", func(t *testing.T) {
+		userId := uuid.New()
+		clientId := uuid.New()
+		verified := true
+		signed, err := utility.GenerateIDToken(userId, clientId, "n-0S6_WzA2Mj", utils.UserClaims{
+			Email:             "john@example.com",
+			EmailVerified:     &verified,
+			PreferredUsername: "john",
+		})
+		assert.NoError(t, err)
+
+		raw := jwt.MapClaims{}
+		_, err = jwt.NewParser().ParseWithClaims(signed, raw, keyring.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, userId.String(), raw["sub"])
+		assert.Equal(t, []any{clientId.String()}, raw["aud"])
+		assert.Equal(t, "https://api.example.com", raw["iss"])
+		assert.Equal(t, "n-0S6_WzA2Mj", raw["nonce"])
+		assert.Equal(t, "john@example.com", raw["email"])
+		assert.Equal(t, true, raw["email_verified"])
+		assert.Equal(t, "john", raw["preferred_username"])
+		assert.NotContains(t, raw, "name")
+
+		// an ID token is not an access token for this API
+		_, err = utility.ValidateToken(signed)
+		assert.ErrorIs(t, err, utils.ErrAccessTokenInvalidAudience)
+	})
+
+	t.Run("it should name the impersonating admin in act", func(t *testing.T) {
+		userId := uuid.New()
+		actorId := uuid.New()
+		signed, err := utility.GenerateImpersonationToken(userId, actorId, uuid.New(), 5*time.Minute)
+		assert.NoError(t, err)
+
+		raw := jwt.MapClaims{}
+		_, _, err = jwt.NewParser().ParseUnverified(signed, raw)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"sub": actorId.String()}, raw["act"])
+		assert.InDelta(t, float64(time.Now().Add(5*time.Minute).Unix()), raw["exp"], 2)
+
+		claims, err := utility.ValidateToken(signed)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, claims.UserId())
+		assert.Equal(t, actorId, claims.ActorId())
+
+		own, _ := utility.GenerateToken(userId, uuid.New())
+		claims, err = utility.ValidateToken(own)
+		assert.NoError(t, err)
+		assert.Equal(t, uuid.Nil, claims.ActorId())
+	})
+
+	sign := func(mutate func(c *jwt.RegisteredClaims)) string {
+		now := time.Now()
+		claims := &utils.AccessTokenClaims{RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    jwtCfg.Issuer,
+			Subject:   uuid.New().String(),
+			Audience:  jwt.ClaimStrings{jwtCfg.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		}}
+		mutate(&claims.RegisteredClaims)
+		signed, err := keyring.Sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	otherRing, _ := utils.NewKeyring("test-key", otherKey, nil)
+	forged, _ := utils.NewUtilities(otherRing, jwtCfg, "", config.GoogleOAuth2Config{}, nil).GenerateToken(uuid.New(), uuid.New())
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"expired", sign(func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }), utils.ErrAccessTokenExpired},
+		{"expired within leeway", sign(func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-10 * time.Second)) }), nil},
+		{"not valid yet", sign(func(c *jwt.RegisteredClaims) { c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute)) }), utils.ErrAccessTokenNotYetValid},
+		{"issued in the future within leeway", sign(func(c *jwt.RegisteredClaims) { c.IssuedAt = jwt.NewNumericDate(time.Now().Add(10 * time.Second)) }), nil},
+		{"missing exp", sign(func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil }), utils.ErrAccessTokenInvalidClaims},
+		{"wrong audience", sign(func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"other-api"} }), utils.ErrAccessTokenInvalidAudience},
+		{"wrong issuer", sign(func(c *jwt.RegisteredClaims) { c.Issuer = "https://evil.example.com" }), utils.ErrAccessTokenInvalidIssuer},
+		{"subject is not a user id", sign(func(c *jwt.RegisteredClaims) { c.Subject = "admin" }), utils.ErrAccessTokenInvalidClaims},
+		{"missing jti", sign(func(c *jwt.RegisteredClaims) { c.ID = "" }), utils.ErrAccessTokenInvalidClaims},
+		{"malformed", "not-a-jwt", utils.ErrAccessTokenMalformed},
+		{"signed by another key", forged, utils.ErrAccessTokenInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := utility.ValidateToken(tt.token)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keyring holds the keys our own access tokens are signed and verified with.
+//
+// Keys are PEM files in a directory, named <kid>.pem. A private key (PKCS#8, or
+// PKCS#1 for RSA) can sign and verify, a public key (PKIX) only verifies, which
+// is how a retired key stays around until the tokens it signed have expired.
+// Exactly one key, chosen by kid, signs new tokens; every key in the directory
+// is accepted for verification and published in the JWKS.
+//
+// Rotation without downtime happens in three steps: add the new key and Reload
+// so every instance and every JWKS consumer knows it, switch the signing kid
+// with a rolling restart, then remove the old key once the last token it
+// signed has expired and Reload again. The signing kid is fixed for the life of
+// the keyring, Reload only changes which keys are accepted.
+type Keyring struct {
+	dir        string
+	signingKid string
+
+	mu     sync.RWMutex
+	signer crypto.Signer
+	keys   map[string]crypto.PublicKey
+}
+
+// LoadKeyring reads every *.pem file in dir and signs with the key named signingKid.
+func LoadKeyring(dir, signingKid string) (*Keyring, error) {
+	k := &Keyring{dir: dir, signingKid: signingKid}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// NewKeyring builds a keyring from keys already in memory, verification keys
+// are merged with the public half of the signer.
+func NewKeyring(signingKid string, signer crypto.Signer, verification map[string]crypto.PublicKey) (*Keyring, error) {
+	keys := make(map[string]crypto.PublicKey, len(verification)+1)
+	for kid, key := range verification {
+		keys[kid] = key
+	}
+	keys[signingKid] = signer.Public()
+	k := &Keyring{signingKid: signingKid}
+	if err := k.set(signer, keys); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload re-reads the key directory, keeping the signing kid the keyring was
+// loaded with. Tokens keep being verified with the previous keys if the
+// directory is unreadable or the signing key is missing.
+func (k *Keyring) Reload() error {
+	if k.dir == "" {
+		return errors.New("jwt keys directory is not configured")
+	}
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	var signer crypto.Signer
+	keys := make(map[string]crypto.PublicKey, len(paths))
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		private, public, err := parsePEMKey(raw)
+		if err != nil {
+			return fmt.Errorf("jwt key %q: %w", kid, err)
+		}
+		keys[kid] = public
+		if kid == k.signingKid {
+			if private == nil {
+				return fmt.Errorf("jwt signing key %q is a public key", kid)
+			}
+			signer = private
+		}
+	}
+	if signer == nil {
+		return fmt.Errorf("jwt signing key %q not found in %s", k.signingKid, k.dir)
+	}
+	return k.set(signer, keys)
+}
+
+func (k *Keyring) set(signer crypto.Signer, keys map[string]crypto.PublicKey) error {
+	for kid, key := range keys {
+		if _, err := signingMethodFor(key); err != nil {
+			return fmt.Errorf("jwt key %q: %w", kid, err)
+		}
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.signer = signer
+	k.keys = keys
+	return nil
+}
+
+// Sign signs the claims with the current signing key and sets the kid header.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	signer := k.signer
+	k.mu.RUnlock()
+	method, err := signingMethodFor(signer.Public())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = k.signingKid
+	return token.SignedString(signer)
+}
+
+// Keyfunc resolves the verification key of a token by its kid header and makes
+// sure the token's algorithm matches the type of that key.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key, nil
+}
+
+// ValidMethods lists the algorithms tokens signed by a keyring can use.
+func (k *Keyring) ValidMethods() []string {
+	return []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+}
+
+// JWKS returns every verification key as a JSON Web Key, ordered by kid.
+func (k *Keyring) JWKS() []JWK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	jwks := make([]JWK, 0, len(k.keys))
+	for kid, key := range k.keys {
+		jwk, err := PublicJWK(kid, key)
+		if err != nil {
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
+
+// PublicJWK encodes an RSA or Ed25519 public key as a signing JWK.
+func PublicJWK(kid string, key crypto.PublicKey) (JWK, error) {
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Kid: kid,
+			Use: "sig",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func signingMethodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", key)
+	}
+}
+
+// parsePEMKey returns the private key, if the PEM holds one, and the public key.
+func parsePEMKey(raw []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, signer.Public(), nil
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
@@ -0,0 +1,139 @@
+package utils_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"my-go-api/internal/utils"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKeyPEM(t *testing.T, dir, kid string, key crypto.Signer) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "PRIVATE KEY", der)
+}
+
+func writePublicKeyPEM(t *testing.T, dir, kid string, key crypto.PublicKey) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, kid, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, kid, blockType string, der []byte) {
+	t.Helper()
+	raw := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("it should sign with the configured key and set the kid header", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKeyPEM(t, dir, "ed-1", edKey)
+		writePrivateKeyPEM(t, dir, "rsa-1", rsaKey)
+		for kid, alg := range map[string]string{"ed-1": "EdDSA", "rsa-1": "RS256"} {
+			keyring, err := utils.LoadKeyring(dir, kid)
+			assert.NoError(t, err)
+			signed, err := keyring.Sign(jwt.MapClaims{"sub": "user"})
+			assert.NoError(t, err)
+			token, err := jwt.Parse(signed, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+			assert.NoError(t, err)
+			assert.Equal(t, kid, token.Header["kid"])
+			assert.Equal(t, alg, token.Method.Alg())
+		}
+	})
+
+	t.Run("it should keep verifying tokens of the previous key after rotation", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKeyPEM(t, dir, "old", rsaKey)
+		oldRing, err := utils.LoadKeyring(dir, "old")
+		assert.NoError(t, err)
+		oldToken, err := oldRing.Sign(jwt.MapClaims{"sub": "user"})
+		assert.NoError(t, err)
+
+		writePrivateKeyPEM(t, dir, "new", edKey)
+		assert.NoError(t, oldRing.Reload())
+		newRing, err := utils.LoadKeyring(dir, "new")
+		assert.NoError(t, err)
+		newToken, err := newRing.Sign(jwt.MapClaims{"sub": "user"})
+		assert.NoError(t, err)
+
+		// instances still signing with the old key already accept the new one and vice versa
+		for _, ring := range []*utils.Keyring{oldRing, newRing} {
+			for _, signed := range []string{oldToken, newToken} {
+				_, err := jwt.Parse(signed, ring.Keyfunc, jwt.WithValidMethods(ring.ValidMethods()))
+				assert.NoError(t, err)
+			}
+		}
+
+		// retiring the old key down to its public half still verifies its tokens
+		writePublicKeyPEM(t, dir, "old", rsaKey.Public())
+		assert.NoError(t, newRing.Reload())
+		_, err = jwt.Parse(oldToken, newRing.Keyfunc, jwt.WithValidMethods(newRing.ValidMethods()))
+		assert.NoError(t, err)
+
+		// and removing it rejects them
+		assert.NoError(t, os.Remove(filepath.Join(dir, "old.pem")))
+		assert.NoError(t, newRing.Reload())
+		_, err = jwt.Parse(oldToken, newRing.Keyfunc, jwt.WithValidMethods(newRing.ValidMethods()))
+		assert.Error(t, err)
+	})
+
+	t.Run("it should fail when the signing key is missing or public only", func(t *testing.T) {
+		dir := t.TempDir()
+		writePublicKeyPEM(t, dir, "public", edKey.Public())
+		_, err := utils.LoadKeyring(dir, "public")
+		assert.Error(t, err)
+		_, err = utils.LoadKeyring(dir, "missing")
+		assert.Error(t, err)
+	})
+
+	t.Run("it should reject a token whose algorithm does not match its key", func(t *testing.T) {
+		keyring, err := utils.NewKeyring("rsa-1", rsaKey, nil)
+		assert.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user"})
+		token.Header["kid"] = "rsa-1"
+		pubDER, _ := x509.MarshalPKIXPublicKey(rsaKey.Public())
+		forged, err := token.SignedString(pubDER)
+		assert.NoError(t, err)
+		_, err = jwt.Parse(forged, keyring.Keyfunc, jwt.WithValidMethods(keyring.ValidMethods()))
+		assert.Error(t, err)
+	})
+
+	t.Run("it should publish every verification key as a jwks", func(t *testing.T) {
+		keyring, err := utils.NewKeyring("ed-1", edKey, map[string]crypto.PublicKey{"rsa-1": rsaKey.Public()})
+		assert.NoError(t, err)
+		raw, err := json.Marshal(map[string]any{"keys": keyring.JWKS()})
+		assert.NoError(t, err)
+		keys, err := utils.ParseJWKS(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, edKey.Public(), keys["ed-1"])
+		assert.True(t, rsaKey.PublicKey.Equal(keys["rsa-1"]))
+	})
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"my-go-api/internal/config"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var ErrUnknownOAuthProvider = errors.New("unknown oauth provider")
+
+// OAuthProvider is a configured OpenID Connect provider ready for the
+// authorization code flow.
+type OAuthProvider struct {
+	Name       string
+	Issuer     string
+	TrustEmail bool
+	OAuth2     *oauth2.Config
+	Verifier   IIDTokenVerifier
+}
+
+type IOAuthProviderRegistry interface {
+	Get(ctx context.Context, name string) (*OAuthProvider, error)
+}
+
+type oauthProviderRegistry struct {
+	configs    map[string]config.OAuthProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	providers map[string]*OAuthProvider
+}
+
+func NewOAuthProviderRegistry(configs map[string]config.OAuthProviderConfig) IOAuthProviderRegistry {
+	return &oauthProviderRegistry{
+		configs:    configs,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		providers:  make(map[string]*OAuthProvider),
+	}
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Get returns the named provider. Providers are built on first use so a provider
+// whose discovery document is temporarily unreachable does not prevent startup.
+func (r *oauthProviderRegistry) Get(ctx context.Context, name string) (*OAuthProvider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if provider, ok := r.providers[name]; ok {
+		return provider, nil
+	}
+	cfg, ok := r.configs[name]
+	if !ok {
+		return nil, ErrUnknownOAuthProvider
+	}
+	if cfg.Issuer != "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.JWKSURL == "") {
+		doc, err := r.discover(ctx, cfg.Issuer)
+		if err != nil {
+			return nil, err
+		}
+		cfg.AuthURL = firstNonEmpty(cfg.AuthURL, doc.AuthorizationEndpoint)
+		cfg.TokenURL = firstNonEmpty(cfg.TokenURL, doc.TokenEndpoint)
+		cfg.JWKSURL = firstNonEmpty(cfg.JWKSURL, doc.JwksURI)
+	}
+	if cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("oauth provider %s is missing endpoints", name)
+	}
+	provider := &OAuthProvider{
+		Name:       name,
+		Issuer:     cfg.Issuer,
+		TrustEmail: cfg.TrustEmail,
+		OAuth2: &oauth2.Config{
+			ClientID:     cfg.ClientId,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Scopes:       cfg.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+		},
+		Verifier: NewIDTokenVerifier(NewJWKS(cfg.JWKSURL, time.Hour), []string{cfg.Issuer}, []string{cfg.ClientId}),
+	}
+	r.providers[name] = provider
+	return provider, nil
+}
+
+func (r *oauthProviderRegistry) discover(ctx context.Context, issuer string) (*discoveryDocument, error) {
+	url := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch discovery document: unexpected status %d", res.StatusCode)
+	}
+	var doc discoveryDocument
+	if err := json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid discovery document: %w", err)
+	}
+	if doc.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, issuer)
+	}
+	return &doc, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,21 +1,220 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"my-go-api/internal/config"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func (u *utility) HashPassword(password string) (string, error) {
-	// Generate a hashed version of the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+var (
+	ErrPasswordMismatch    = errors.New("password does not match")
+	ErrUnknownPasswordHash = errors.New("unknown password hash format")
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// PasswordHasher hashes passwords and verifies them against stored hashes.
+// NeedsRehash tells whether a hash that verified should be replaced by a new
+// one, because it was made with another algorithm, parameters or pepper.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hashedPassword, password string) error
+	NeedsRehash(hashedPassword string) bool
+}
+
+// passwordHasher hashes with argon2id, encoded as PHC strings, and still
+// verifies the bcrypt hashes of accounts created before argon2id.
+//
+// With a pepper the password is HMAC-SHA256ed with it before argon2id, and the
+// hash names the pepper in its keyid parameter so hashes made with another or
+// without a pepper are recognised. bcrypt hashes never have a pepper.
+type passwordHasher struct {
+	params argon2Params
+	pepper []byte
+	keyId  string
+
+	dummyOnce sync.Once
+	dummy     string
+}
+
+type argon2Params struct {
+	memory      uint32
+	time        uint32
+	parallelism uint8
+}
+
+func NewPasswordHasher(cfg config.PasswordConfig) PasswordHasher {
+	h := &passwordHasher{
+		params: argon2Params{
+			memory:      cfg.Argon2Memory,
+			time:        cfg.Argon2Time,
+			parallelism: cfg.Argon2Parallelism,
+		},
+	}
+	if cfg.Pepper != "" {
+		h.pepper = []byte(cfg.Pepper)
+		sum := sha256.Sum256(h.pepper)
+		h.keyId = base64.RawStdEncoding.EncodeToString(sum[:6])
+	}
+	return h
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
-	return string(hashedPassword), nil
+	key := argon2.IDKey(h.input(password, h.keyId), salt, h.params.time, h.params.memory, h.params.parallelism, argon2KeyLength)
+	params := fmt.Sprintf("m=%d,t=%d,p=%d", h.params.memory, h.params.time, h.params.parallelism)
+	if h.keyId != "" {
+		params += ",keyid=" + h.keyId
+	}
+	return fmt.Sprintf("$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against hashedPassword. An empty hash, of an account
+// without a password, is checked against a hash nobody knows the password of so
+// it takes as long as a wrong password.
+func (h *passwordHasher) Verify(hashedPassword, password string) error {
+	if hashedPassword == "" {
+		h.Verify(h.dummyHash(), password)
+		return ErrPasswordMismatch
+	}
+	if isBcrypt(hashedPassword) {
+		if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return ErrPasswordMismatch
+			}
+			return err
+		}
+		return nil
+	}
+	hash, err := parseArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+	if hash.keyId != "" && hash.keyId != h.keyId {
+		return errors.New("password hash was made with another pepper")
+	}
+	key := argon2.IDKey(h.input(password, hash.keyId), hash.salt, hash.params.time, hash.params.memory, hash.params.parallelism, uint32(len(hash.key)))
+	if subtle.ConstantTimeCompare(key, hash.key) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func (h *passwordHasher) NeedsRehash(hashedPassword string) bool {
+	hash, err := parseArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return hash.params != h.params || hash.keyId != h.keyId || len(hash.key) != argon2KeyLength
+}
+
+// input is what argon2id hashes, the password HMACed with the pepper when the
+// hash is peppered.
+func (h *passwordHasher) input(password, keyId string) []byte {
+	if keyId == "" {
+		return []byte(password)
+	}
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return mac.Sum(nil)
+}
+
+func (h *passwordHasher) dummyHash() string {
+	h.dummyOnce.Do(func() {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		h.dummy, _ = h.Hash(string(secret))
+	})
+	return h.dummy
+}
+
+func isBcrypt(hashedPassword string) bool {
+	return strings.HasPrefix(hashedPassword, "$2a$") || strings.HasPrefix(hashedPassword, "$2b$") || strings.HasPrefix(hashedPassword, "$2y$")
+}
+
+type argon2idHash struct {
+	params argon2Params
+	keyId  string
+	salt   []byte
+	key    []byte
+}
+
+// parseArgon2id reads a PHC string like
+// $argon2id$v=19$m=65536,t=3,p=2[,keyid=...]$<salt>$<hash>.
+func parseArgon2id(encoded string) (*argon2idHash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return nil, ErrUnknownPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	hash := &argon2idHash{}
+	for _, param := range strings.Split(parts[3], ",") {
+		name, value, _ := strings.Cut(param, "=")
+		var err error
+		switch name {
+		case "m":
+			_, err = fmt.Sscanf(value, "%d", &hash.params.memory)
+		case "t":
+			_, err = fmt.Sscanf(value, "%d", &hash.params.time)
+		case "p":
+			_, err = fmt.Sscanf(value, "%d", &hash.params.parallelism)
+		case "keyid":
+			hash.keyId = value
+		default:
+			err = fmt.Errorf("unknown argon2 parameter %q", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hash.params.memory == 0 || hash.params.time == 0 || hash.params.parallelism == 0 {
+		return nil, errors.New("argon2 hash without memory, time or parallelism")
+	}
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	if len(hash.key) == 0 {
+		return nil, errors.New("argon2 hash without a key")
+	}
+	return hash, nil
+}
+
+func (u *utility) HashPassword(password string) (string, error) {
+	return u.hasher.Hash(password)
 }
 
 func (u *utility) VerifyPassword(hashedPassword, password string) error {
-	// Compare the hashed password with the plaintext password
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	return u.hasher.Verify(hashedPassword, password)
+}
+
+func (u *utility) PasswordNeedsRehash(hashedPassword string) bool {
+	return u.hasher.NeedsRehash(hashedPassword)
 }
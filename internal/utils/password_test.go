@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"my-go-api/internal/config"
+	"my-go-api/internal/utils"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	cfg := config.PasswordConfig{Argon2Memory: 1024, Argon2Time: 1, Argon2Parallelism: 1}
+	hasher := utils.NewPasswordHasher(cfg)
+
+	t.Run("it should hash with argon2id as a PHC string", func(t *testing.T) {
+		hash, err := hasher.Hash("Password1")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+		assert.NoError(t, hasher.Verify(hash, "Password1"))
+		assert.ErrorIs(t, hasher.Verify(hash, "Password2"), utils.ErrPasswordMismatch)
+		assert.False(t, hasher.NeedsRehash(hash))
+
+		other, _ := hasher.Hash("Password1")
+		assert.NotEqual(t, hash, other)
+	})
+
+	t.Run("it should verify and rehash legacy bcrypt hashes", func(t *testing.T) {
+		legacy, _ := bcrypt.GenerateFromPassword([]byte("Password1"), bcrypt.MinCost)
+		assert.NoError(t, hasher.Verify(string(legacy), "Password1"))
+		assert.ErrorIs(t, hasher.Verify(string(legacy), "Password2"), utils.ErrPasswordMismatch)
+		assert.True(t, hasher.NeedsRehash(string(legacy)))
+	})
+
+	t.Run("it should rehash hashes made with other parameters", func(t *testing.T) {
+		hash, _ := hasher.Hash("Password1")
+		stronger := utils.NewPasswordHasher(config.PasswordConfig{Argon2Memory: 2048, Argon2Time: 2, Argon2Parallelism: 1})
+		assert.NoError(t, stronger.Verify(hash, "Password1"))
+		assert.True(t, stronger.NeedsRehash(hash))
+	})
+
+	t.Run("it should pepper new hashes and still verify the ones without", func(t *testing.T) {
+		plain, _ := hasher.Hash("Password1")
+		peppered := utils.NewPasswordHasher(config.PasswordConfig{Argon2Memory: 1024, Argon2Time: 1, Argon2Parallelism: 1, Pepper: "pepper"})
+		assert.NoError(t, peppered.Verify(plain, "Password1"))
+		assert.True(t, peppered.NeedsRehash(plain))
+
+		hash, err := peppered.Hash("Password1")
+		assert.NoError(t, err)
+		assert.Contains(t, hash, ",keyid=")
+		assert.NoError(t, peppered.Verify(hash, "Password1"))
+		assert.False(t, peppered.NeedsRehash(hash))
+
+		assert.Error(t, hasher.Verify(hash, "Password1"))
+		rotated := utils.NewPasswordHasher(config.PasswordConfig{Argon2Memory: 1024, Argon2Time: 1, Argon2Parallelism: 1, Pepper: "other"})
+		assert.Error(t, rotated.Verify(hash, "Password1"))
+	})
+
+	t.Run("it should never match an empty or unknown hash", func(t *testing.T) {
+		assert.ErrorIs(t, hasher.Verify("", "Password1"), utils.ErrPasswordMismatch)
+		assert.ErrorIs(t, hasher.Verify("plaintext", "plaintext"), utils.ErrUnknownPasswordHash)
+		assert.Error(t, hasher.Verify("$argon2id$v=19$m=1024,t=1,p=1$!!$!!", "Password1"))
+		assert.True(t, hasher.NeedsRehash("plaintext"))
+	})
+}
@@ -0,0 +1,53 @@
+package utils_test
+
+import (
+	"my-go-api/internal/utils"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTP(t *testing.T) {
+	secret, uri, err := utils.GenerateTOTPKey("my-go-api", "john@example.com")
+	assert.NoError(t, err)
+
+	t.Run("it should build an otpauth uri for authenticator apps", func(t *testing.T) {
+		parsed, err := url.Parse(uri)
+		assert.NoError(t, err)
+		assert.Equal(t, "otpauth", parsed.Scheme)
+		assert.Equal(t, "totp", parsed.Host)
+		assert.Equal(t, secret, parsed.Query().Get("secret"))
+		assert.Equal(t, "my-go-api", parsed.Query().Get("issuer"))
+	})
+
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name  string
+		at    time.Time
+		match bool
+	}{
+		{"current step", now, true},
+		{"previous step", now.Add(-30 * time.Second), true},
+		{"next step", now.Add(30 * time.Second), true},
+		{"two steps old", now.Add(-60 * time.Second), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totp.GenerateCode(secret, tt.at)
+			assert.NoError(t, err)
+			step, ok := utils.MatchTOTP(secret, code, now)
+			assert.Equal(t, tt.match, ok)
+			if tt.match {
+				assert.Equal(t, tt.at.Unix()/30, step)
+			}
+		})
+	}
+
+	t.Run("it should reject codes of the wrong length", func(t *testing.T) {
+		_, ok := utils.MatchTOTP(secret, "12345", now)
+		assert.False(t, ok)
+	})
+}
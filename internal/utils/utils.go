@@ -2,8 +2,8 @@ package utils
 
 import (
 	"my-go-api/internal/config"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
 )
@@ -12,24 +12,33 @@ type IUtils interface {
 	GenerateRandomBytes(size int) (string, error)
 	HashWithSHA256(randomStr string) string
 	GenerateToken(userId, jti uuid.UUID) (string, error)
-	ValidateToken(tokenString string) (*jwt.MapClaims, error)
+	GenerateScopedToken(subject, clientId uuid.UUID, scope string, jti uuid.UUID) (string, error)
+	GenerateIDToken(userId, clientId uuid.UUID, nonce string, claims UserClaims) (string, error)
+	GenerateImpersonationToken(userId, actorId, jti uuid.UUID, ttl time.Duration) (string, error)
+	SigningAlgorithms() []string
+	ValidateToken(tokenString string) (*AccessTokenClaims, error)
 	HashPassword(password string) (string, error)
 	VerifyPassword(hashedPassword, password string) error
+	PasswordNeedsRehash(hashedPassword string) bool
 	CreateGoogleOauth2Config() *oauth2.Config
 	GetTokenFromRefreshToken(config *oauth2.Config) *oauth2.Token
 	SendEmailWithGmail(subject, body, address string) error
 }
 
 type utility struct {
-	jwtSecretKey string
-	appUri       string
-	google       *config.GoogleOAuth2Config
+	keyring *Keyring
+	jwt     config.JWTConfig
+	appUri  string
+	google  *config.GoogleOAuth2Config
+	hasher  PasswordHasher
 }
 
-func NewUtilities(jwtSecretKey, appUri string, google config.GoogleOAuth2Config) IUtils {
+func NewUtilities(keyring *Keyring, jwtCfg config.JWTConfig, appUri string, google config.GoogleOAuth2Config, hasher PasswordHasher) IUtils {
 	return &utility{
-		jwtSecretKey: jwtSecretKey,
-		appUri:       appUri,
-		google:       &google,
+		keyring: keyring,
+		jwt:     jwtCfg,
+		appUri:  appUri,
+		google:  &google,
+		hasher:  hasher,
 	}
 }
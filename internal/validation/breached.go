@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const breachedPrefixes = 1 << 20
+
+// BreachedPasswords looks passwords up in a local copy of the Pwned Passwords
+// dataset of Have I Been Pwned, in the format of its range files: a directory
+// with a file per 5 character SHA-1 prefix, 00000.txt to FFFFF.txt, listing the
+// other 35 characters of each hash and how often it was seen as SUFFIX:COUNT.
+// The file names may be lower case, 00000.txt to fffff.txt, as long as all of
+// them are.
+//
+// Only a bitset of the prefixes present is kept in memory, 128 KiB whatever the
+// size of the dataset, and a lookup reads the single file of its prefix.
+type BreachedPasswords struct {
+	dir       string
+	threshold int
+	prefixes  []uint64
+	// lowerCase is set when the range files are named in lower case.
+	lowerCase bool
+}
+
+// OpenBreachedPasswords indexes the range files in dir. Passwords seen at least
+// threshold times count as breached.
+func OpenBreachedPasswords(dir string, threshold int) (*BreachedPasswords, error) {
+	if threshold < 1 {
+		return nil, fmt.Errorf("breached password threshold must be at least 1, got %d", threshold)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	b := &BreachedPasswords{dir: dir, threshold: threshold, prefixes: make([]uint64, breachedPrefixes/64)}
+	found := 0
+	var upperCase bool
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || !ok || len(name) != 5 {
+			continue
+		}
+		prefix, err := strconv.ParseUint(name, 16, 32)
+		if err != nil {
+			continue
+		}
+		// names of only digits are the same in either case
+		switch name {
+		case strings.ToUpper(name):
+			upperCase = upperCase || name != strings.ToLower(name)
+		case strings.ToLower(name):
+			b.lowerCase = true
+		default:
+			return nil, fmt.Errorf("breached password range file %s mixes upper and lower case", entry.Name())
+		}
+		b.prefixes[prefix/64] |= 1 << (prefix % 64)
+		found++
+	}
+	if found == 0 {
+		return nil, fmt.Errorf("no breached password range files in %s", dir)
+	}
+	if upperCase && b.lowerCase {
+		return nil, fmt.Errorf("breached password range files in %s are named in both upper and lower case", dir)
+	}
+	return b, nil
+}
+
+// Occurrences is how often the password was seen in breaches, 0 when never.
+func (b *BreachedPasswords) Occurrences(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+	index, _ := strconv.ParseUint(prefix, 16, 32)
+	if b.prefixes[index/64]&(1<<(index%64)) == 0 {
+		return 0, nil
+	}
+	name := prefix
+	if b.lowerCase {
+		name = strings.ToLower(prefix)
+	}
+	file, err := os.Open(filepath.Join(b.dir, name+".txt"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, count, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Range files are sorted, so the suffix is not in it once passed.
+		switch strings.Compare(strings.ToUpper(candidate), suffix) {
+		case 0:
+			return strconv.Atoi(count)
+		case 1:
+			return 0, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// IsBreached tells whether the password was seen at least the threshold of times.
+func (b *BreachedPasswords) IsBreached(password string) (bool, error) {
+	count, err := b.Occurrences(password)
+	if err != nil {
+		return false, err
+	}
+	return count >= b.threshold, nil
+}
@@ -0,0 +1,117 @@
+package validation_test
+
+import (
+	"my-go-api/internal/dto"
+	"my-go-api/internal/validation"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+// SHA-1 of "Password1" is 70CCD9007338D6D81DD3B6271621B9CF9A97EA00 and of
+// "Summer2024" is 6EA164759ADCCDF0B63C3E6A8A52792691F4C37B.
+func writeRangeFiles(t *testing.T) string {
+	return writeFiles(t, map[string]string{
+		"70CCD.txt": "0000000000000000000000000000000000A:3\r\n9007338D6D81DD3B6271621B9CF9A97EA00:52579\r\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:1\r\n",
+		"6EA16.txt": "4759ADCCDF0B63C3E6A8A52792691F4C37B:2\n",
+		"README.md": "not a range file",
+	})
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestBreachedPasswords(t *testing.T) {
+	dir := writeRangeFiles(t)
+	breached, err := validation.OpenBreachedPasswords(dir, 3)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		password    string
+		occurrences int
+		isBreached  bool
+	}{
+		{"Password1", 52579, true},
+		{"Summer2024", 2, false},
+		{"password1", 0, false},
+		{"Tr0ub4dor&3-horse-battery", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			occurrences, err := breached.Occurrences(tt.password)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.occurrences, occurrences)
+			isBreached, err := breached.IsBreached(tt.password)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.isBreached, isBreached)
+		})
+	}
+
+	t.Run("it should refuse a directory without range files or a threshold below 1", func(t *testing.T) {
+		_, err := validation.OpenBreachedPasswords(t.TempDir(), 1)
+		assert.Error(t, err)
+		_, err = validation.OpenBreachedPasswords(dir, 0)
+		assert.Error(t, err)
+		_, err = validation.OpenBreachedPasswords(filepath.Join(dir, "missing"), 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestBreachedPasswordsLowerCase(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"70ccd.txt": "9007338d6d81dd3b6271621b9cf9a97ea00:52579\n",
+		"00000.txt": "",
+	})
+	breached, err := validation.OpenBreachedPasswords(dir, 3)
+	assert.NoError(t, err)
+	occurrences, err := breached.Occurrences("Password1")
+	assert.NoError(t, err)
+	assert.Equal(t, 52579, occurrences)
+
+	t.Run("it should refuse range files named in both cases", func(t *testing.T) {
+		mixed := writeFiles(t, map[string]string{
+			"70ccd.txt": "9007338d6d81dd3b6271621b9cf9a97ea00:52579\n",
+			"6EA16.txt": "4759ADCCDF0B63C3E6A8A52792691F4C37B:2\n",
+		})
+		_, err := validation.OpenBreachedPasswords(mixed, 3)
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateNotBreached(t *testing.T) {
+	breached, err := validation.OpenBreachedPasswords(writeRangeFiles(t), 1)
+	assert.NoError(t, err)
+	validate := validation.Init(breached)
+
+	t.Run("it should refuse a breached password with its own message", func(t *testing.T) {
+		err := validate.Struct(dto.CreateUser{Name: "John Doe", Email: "john@example.com", Username: "johndoe", Password: "Password1"})
+		var validationErrors validator.ValidationErrors
+		assert.ErrorAs(t, err, &validationErrors)
+		assert.Len(t, validationErrors, 1)
+		assert.Equal(t, "notBreached", validationErrors[0].Tag())
+		assert.NotEqual(t, validation.Messages["strongPassword"], validation.Messages["notBreached"])
+	})
+
+	t.Run("it should report a weak password as weak before checking breaches", func(t *testing.T) {
+		err := validate.Struct(dto.ResetPassword{Token: "token", Password: "password1"})
+		var validationErrors validator.ValidationErrors
+		assert.ErrorAs(t, err, &validationErrors)
+		assert.Equal(t, "strongPassword", validationErrors[0].Tag())
+	})
+
+	t.Run("it should accept a password not in the dataset", func(t *testing.T) {
+		assert.NoError(t, validate.Struct(dto.ResetPassword{Token: "token", Password: "Summer2025x"}))
+	})
+
+	t.Run("it should accept every strong password without a dataset", func(t *testing.T) {
+		assert.NoError(t, validation.Init(nil).Struct(dto.ResetPassword{Token: "token", Password: "Password1"}))
+	})
+}
@@ -1,12 +1,15 @@
 package validation
 
 import (
+	"log"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
 )
 
-func Init() *validator.Validate {
+// Init registers the custom validations. Passwords are only checked against
+// breaches when breached is not nil.
+func Init(breached *BreachedPasswords) *validator.Validate {
 	validate := validator.New()
 
 	// Register custom validations
@@ -14,14 +17,26 @@ func Init() *validator.Validate {
 	if err != nil {
 		panic(err) // Handle error during initialization
 	}
+	if err := validate.RegisterValidation("roleName", ValidateRoleName); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("notBreached", ValidateNotBreached(breached)); err != nil {
+		panic(err)
+	}
 	return validate
 }
 
 var Messages = map[string]string{
 	"email":          "Invalid email",
 	"min":            "Too short. A minimum of %s characters is required",
+	"max":            "Too long. A maximum of %s characters is allowed",
 	"required":       "This field is required",
+	"oneof":          "Must be one of: %s",
+	"url":            "Invalid url",
+	"ip":             "Invalid IP address",
+	"roleName":       "Only lowercase letters, digits, _ and - are allowed",
 	"strongPassword": "A minimum of 5 characters including an uppercase letter, a lowercase letter, and a number is required",
+	"notBreached":    "This password has appeared in a data breach, please choose a different one",
 }
 
 func ValidatePassword(fl validator.FieldLevel) bool {
@@ -51,3 +66,31 @@ func ValidatePassword(fl validator.FieldLevel) bool {
 
 	return false
 }
+
+// ValidateRoleName accepts lowercase letters, digits, _ and -, so role names
+// read the same everywhere they show up.
+func ValidateRoleName(fl validator.FieldLevel) bool {
+	for _, char := range fl.Field().String() {
+		if !(char >= 'a' && char <= 'z') && !(char >= '0' && char <= '9') && char != '_' && char != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateNotBreached rejects passwords found in the breached password dataset.
+// A dataset that cannot be read lets passwords through rather than blocking
+// every sign up and reset.
+func ValidateNotBreached(breached *BreachedPasswords) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		if breached == nil {
+			return true
+		}
+		isBreached, err := breached.IsBreached(fl.Field().String())
+		if err != nil {
+			log.Printf("Could not check for a breached password: %v", err)
+			return true
+		}
+		return !isBreached
+	}
+}
@@ -0,0 +1,4 @@
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP(0)
+WITH
+  TIME ZONE;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- postgres cannot drop a single enum value, 'oidc' stays in the providers type
+DROP TABLE IF EXISTS user_identities;
\ No newline at end of file
@@ -0,0 +1,18 @@
+ALTER TYPE providers ADD VALUE IF NOT EXISTS 'oidc';
+
+CREATE TABLE
+  user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(100),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMP(0)
+    WITH
+      TIME ZONE NOT NULL DEFAULT NOW ()
+  );
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities (provider, subject);
+
+CREATE INDEX idx_user_identities_user ON user_identities (user_id);
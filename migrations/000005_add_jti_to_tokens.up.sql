@@ -0,0 +1,2 @@
+ALTER TABLE tokens
+ADD COLUMN jti UUID;
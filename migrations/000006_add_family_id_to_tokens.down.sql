@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_token_family;
+
+DROP INDEX IF EXISTS idx_token_hash;
+
+ALTER TABLE tokens
+DROP COLUMN IF EXISTS family_id;
@@ -0,0 +1,6 @@
+ALTER TABLE tokens
+ADD COLUMN family_id UUID NOT NULL DEFAULT gen_random_uuid ();
+
+CREATE INDEX idx_token_hash ON tokens (hash);
+
+CREATE INDEX idx_token_family ON tokens (family_id);
@@ -0,0 +1,6 @@
+ALTER TABLE tokens
+ADD COLUMN device_label VARCHAR(100) NOT NULL DEFAULT '',
+ADD COLUMN user_agent TEXT NOT NULL DEFAULT '',
+ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '',
+ADD COLUMN created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW (),
+ADD COLUMN last_used_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW ();
@@ -0,0 +1,22 @@
+CREATE TABLE
+  user_mfa (
+    user_id UUID PRIMARY KEY,
+    totp_secret VARCHAR(64) NOT NULL,
+    -- NULL until the user confirmed the enrollment with a first code
+    enabled_at TIMESTAMP(0) WITH TIME ZONE,
+    -- time step of the last accepted code, a code is never accepted twice
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW (),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE TABLE
+  mfa_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP(0) WITH TIME ZONE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_mfa_recovery_codes_user ON mfa_recovery_codes (user_id);
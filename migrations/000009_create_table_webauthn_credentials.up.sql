@@ -0,0 +1,21 @@
+CREATE TABLE
+  webauthn_credentials (
+    -- credential id chosen by the authenticator
+    id BYTEA PRIMARY KEY,
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    -- COSE encoded public key
+    public_key BYTEA NOT NULL,
+    attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+    -- comma separated authenticator transports, e.g. internal,hybrid
+    transports VARCHAR(255) NOT NULL DEFAULT '',
+    aaguid BYTEA,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW (),
+    last_used_at TIMESTAMP(0) WITH TIME ZONE,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE INDEX idx_webauthn_credentials_user ON webauthn_credentials (user_id);
@@ -0,0 +1,20 @@
+CREATE TABLE
+  api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    user_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- first characters of the key, shown to tell keys apart
+    prefix VARCHAR(16) NOT NULL,
+    -- SHA-256 of the key, the key itself is never stored
+    key_hash VARCHAR(64) NOT NULL,
+    -- comma separated scopes, e.g. read,write
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP(0) WITH TIME ZONE,
+    last_used_at TIMESTAMP(0) WITH TIME ZONE,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW (),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+  );
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
+
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
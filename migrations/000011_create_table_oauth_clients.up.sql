@@ -0,0 +1,27 @@
+CREATE TABLE
+  oauth_clients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4 (),
+    name VARCHAR(100) NOT NULL,
+    -- confidential or public
+    type VARCHAR(20) NOT NULL,
+    -- SHA-256 of the client secret, empty for public clients
+    secret_hash VARCHAR(255) NOT NULL DEFAULT '',
+    -- space separated lists, the way OAuth2 writes scopes
+    redirect_uris TEXT NOT NULL DEFAULT '',
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    grant_types VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW ()
+  );
+
+-- the client and scope a refresh token session was issued for, the session
+-- itself lives in the session store under the same device id
+CREATE TABLE
+  oauth_grants (
+    device_id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    client_id UUID NOT NULL,
+    scope VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW (),
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_client FOREIGN KEY (client_id) REFERENCES oauth_clients (id) ON DELETE CASCADE
+  );
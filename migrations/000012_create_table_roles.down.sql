@@ -0,0 +1,16 @@
+CREATE TYPE user_roles AS ENUM ('user', 'admin');
+
+ALTER TABLE users
+DROP CONSTRAINT fk_role;
+
+UPDATE users SET role = 'user' WHERE role NOT IN ('user', 'admin');
+
+ALTER TABLE users
+ALTER COLUMN role DROP NOT NULL,
+ALTER COLUMN role DROP DEFAULT,
+ALTER COLUMN role TYPE user_roles USING role::user_roles,
+ALTER COLUMN role SET DEFAULT 'user';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
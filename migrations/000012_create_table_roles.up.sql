@@ -0,0 +1,61 @@
+CREATE TABLE
+  roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW ()
+  );
+
+-- permissions are named resource:action and only added by migrations, as the
+-- routes checking them ship with the code
+CREATE TABLE
+  permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+  );
+
+CREATE TABLE
+  role_permissions (
+    role VARCHAR(50) NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    PRIMARY KEY (role, permission),
+    CONSTRAINT fk_role FOREIGN KEY (role) REFERENCES roles (name) ON DELETE CASCADE,
+    CONSTRAINT fk_permission FOREIGN KEY (permission) REFERENCES permissions (name) ON DELETE CASCADE
+  );
+
+INSERT INTO
+  roles (name, description)
+VALUES
+  ('user', 'Every account starts with this role'),
+  ('admin', 'Has every permission');
+
+INSERT INTO
+  permissions (name, description)
+VALUES
+  ('users:read', 'List and view user accounts'),
+  ('users:write', 'Edit user accounts'),
+  ('roles:read', 'View roles and permissions'),
+  ('roles:write', 'Manage roles and assign them to users'),
+  ('lockouts:read', 'List locked accounts'),
+  ('lockouts:write', 'Unlock accounts'),
+  ('oauth_clients:read', 'List OAuth2 clients'),
+  ('oauth_clients:write', 'Register and delete OAuth2 clients');
+
+INSERT INTO
+  role_permissions (role, permission)
+SELECT
+  'admin',
+  name
+FROM
+  permissions;
+
+-- the role of a user now references roles instead of the fixed user_roles enum
+UPDATE users SET role = 'user' WHERE role IS NULL;
+
+ALTER TABLE users
+ALTER COLUMN role DROP DEFAULT,
+ALTER COLUMN role TYPE VARCHAR(50) USING role::text,
+ALTER COLUMN role SET DEFAULT 'user',
+ALTER COLUMN role SET NOT NULL,
+ADD CONSTRAINT fk_role FOREIGN KEY (role) REFERENCES roles (name) ON UPDATE CASCADE;
+
+DROP TYPE user_roles;
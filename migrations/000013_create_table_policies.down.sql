@@ -0,0 +1,6 @@
+DELETE FROM permissions WHERE name = 'policies:read';
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS org;
+
+DROP TABLE IF EXISTS policies;
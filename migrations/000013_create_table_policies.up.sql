@@ -0,0 +1,32 @@
+-- attribute based access policies, used when POLICY_SOURCE is database;
+-- actions and resources are space separated, conditions a JSON list of
+-- {attribute, operator, value | value_from}
+CREATE TABLE
+  policies (
+    id VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    effect VARCHAR(10) NOT NULL CHECK (effect IN ('allow', 'deny')),
+    actions TEXT NOT NULL,
+    resources TEXT NOT NULL,
+    conditions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW ()
+  );
+
+INSERT INTO
+  policies (id, description, effect, actions, resources)
+VALUES
+  ('baseline', 'Anything the routes and roles already permit', 'allow', '*', '*');
+
+-- the organisation a user belongs to, set when accounts are provisioned
+ALTER TABLE users
+ADD COLUMN org VARCHAR(100);
+
+INSERT INTO
+  permissions (name, description)
+VALUES
+  ('policies:read', 'Dry-run access policies');
+
+INSERT INTO
+  role_permissions (role, permission)
+VALUES
+  ('admin', 'policies:read');
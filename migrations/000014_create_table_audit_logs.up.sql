@@ -0,0 +1,26 @@
+-- who did what to whom, kept after the users involved are deleted
+CREATE TABLE
+  audit_logs (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id UUID NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW ()
+  );
+
+CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
+
+INSERT INTO
+  permissions (name, description)
+VALUES
+  ('users:impersonate', 'Act as another user for a short time'),
+  ('audit_log:read', 'View the audit log');
+
+INSERT INTO
+  role_permissions (role, permission)
+VALUES
+  ('admin', 'users:impersonate'),
+  ('admin', 'audit_log:read');
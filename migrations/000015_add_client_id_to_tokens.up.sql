@@ -0,0 +1,3 @@
+-- the OAuth2 client a session was issued to, NULL for sessions of our own apps
+ALTER TABLE tokens
+ADD COLUMN client_id UUID;